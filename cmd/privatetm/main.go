@@ -0,0 +1,54 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of go-simplechain.
+//
+// go-simplechain is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-simplechain is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-simplechain. If not, see <http://www.gnu.org/licenses/>.
+// privatetm serves a local private transaction manager over HTTP, standing in for
+// a real one during development of consortium chains.
+package main
+
+import (
+	"flag"
+	"net/http"
+	"os"
+
+	"github.com/simplechain-org/go-simplechain/cmd/utils"
+	"github.com/simplechain-org/go-simplechain/log"
+	"github.com/simplechain-org/go-simplechain/private"
+)
+
+func main() {
+	var (
+		listenAddr = flag.String("addr", "127.0.0.1:9080", "HTTP listen address")
+		dir        = flag.String("dir", "", "payload directory shared by the participants")
+		identity   = flag.String("identity", "", "identity of the participant served")
+		verbosity  = flag.Int("verbosity", int(log.LvlInfo), "log verbosity (0-9)")
+	)
+	flag.Parse()
+
+	glogger := log.NewGlogHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
+	glogger.Verbosity(log.Lvl(*verbosity))
+	log.Root().SetHandler(glogger)
+
+	if *dir == "" {
+		utils.Fatalf("Use -dir to specify the payload directory")
+	}
+	manager, err := private.NewLocalManager(*dir, *identity)
+	if err != nil {
+		utils.Fatalf("Failed to create transaction manager: %v", err)
+	}
+	log.Info("Serving private transaction manager", "addr", *listenAddr, "identity", *identity)
+	if err := http.ListenAndServe(*listenAddr, private.NewHandler(manager)); err != nil {
+		utils.Fatalf("%v", err)
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/simplechain-org/go-simplechain/cross/trigger/simpletrigger"
 	"github.com/simplechain-org/go-simplechain/cross/trigger/simpletrigger/executor"
 	"github.com/simplechain-org/go-simplechain/eth"
+	"github.com/simplechain-org/go-simplechain/log"
 	"github.com/simplechain-org/go-simplechain/node"
 	"github.com/simplechain-org/go-simplechain/p2p/enode"
 	"github.com/simplechain-org/go-simplechain/params"
@@ -68,6 +69,10 @@ var tomlSettings = toml.Config{
 		return field
 	},
 	MissingField: func(rt reflect.Type, field string) error {
+		if rt == reflect.TypeOf(node.Config{}) && removedNodeFields[field] {
+			log.Warn("Ignoring deprecated config field, configure the subchain in Node.Chains instead", "field", "Node."+field)
+			return nil
+		}
 		link := ""
 		if unicode.IsUpper(rune(rt.Name()[0])) && rt.PkgPath() != "main" {
 			link = fmt.Sprintf(", see https://godoc.org/%s#%s for available fields", rt.PkgPath(), rt.Name())
@@ -76,15 +81,47 @@ var tomlSettings = toml.Config{
 	},
 }
 
+// removedNodeFields are the subchain endpoint settings dropped from node.Config
+// in favour of Node.Chains, config files dumped before still load without them.
+var removedNodeFields = map[string]bool{
+	"SubIPCPath":          true,
+	"SubHTTPHost":         true,
+	"SubHTTPPort":         true,
+	"SubHTTPCors":         true,
+	"SubHTTPVirtualHosts": true,
+	"SubHTTPModules":      true,
+	"SubHTTPTimeouts":     true,
+	"SubWSHost":           true,
+	"SubWSPort":           true,
+	"SubWSOrigins":        true,
+	"SubWSModules":        true,
+	"SubWSExposeAll":      true,
+}
+
 type ethstatsConfig struct {
-	URL string `toml:",omitempty"`
+	URL    string `toml:",omitempty"`
+	SubURL string `toml:",omitempty"` // Reporting URL of the subchain hosted by anchor nodes
+	V2     bool   `toml:",omitempty"` // Whether to report with the v2 protocol
 }
 
 type gethConfig struct {
-	Eth      eth.Config
-	Shh      whisper.Config
-	Node     node.Config
-	Ethstats ethstatsConfig
+	Eth       eth.Config
+	Shh       whisper.Config
+	Node      node.Config
+	Ethstats  ethstatsConfig
+	Subchains map[string]subchainConfig `toml:",omitempty"` // Client configs of the chains hosted besides the main ones
+}
+
+// subchainConfig is the client config of a hosted chain, unset fields take the
+// subchain defaults.
+type subchainConfig struct {
+	eth.Config
+}
+
+// UnmarshalTOML implements toml.UnmarshalerRec.
+func (c *subchainConfig) UnmarshalTOML(unmarshal func(interface{}) error) error {
+	c.Config = sub.DefaultConfig
+	return c.Config.UnmarshalTOML(unmarshal)
 }
 
 func loadConfig(file string, cfg *gethConfig) error {
@@ -109,10 +146,6 @@ func defaultNodeConfig() node.Config {
 	cfg.HTTPModules = append(cfg.HTTPModules, "eth", "shh")
 	cfg.WSModules = append(cfg.WSModules, "eth", "shh")
 	cfg.IPCPath = "sipe.ipc"
-
-	cfg.SubHTTPModules = append(cfg.SubHTTPModules, "eth", "shh")
-	cfg.SubWSModules = append(cfg.SubWSModules, "eth", "shh")
-	cfg.SubIPCPath = "subsipe.ipc"
 	return cfg
 }
 
@@ -141,6 +174,12 @@ func makeConfigNode(ctx *cli.Context) (*node.Node, gethConfig) {
 	if ctx.GlobalIsSet(utils.EthStatsURLFlag.Name) {
 		cfg.Ethstats.URL = ctx.GlobalString(utils.EthStatsURLFlag.Name)
 	}
+	if ctx.GlobalIsSet(utils.SUBEthStatsURLFlag.Name) {
+		cfg.Ethstats.SubURL = ctx.GlobalString(utils.SUBEthStatsURLFlag.Name)
+	}
+	if ctx.GlobalIsSet(utils.EthStatsV2Flag.Name) {
+		cfg.Ethstats.V2 = ctx.GlobalBool(utils.EthStatsV2Flag.Name)
+	}
 	utils.SetShhConfig(ctx, stack, &cfg.Shh)
 
 	if ctx.GlobalIsSet(utils.ContractMainFlag.Name) {
@@ -181,6 +220,19 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 	cfg.Eth.Role = role
 
 	raftChan := utils.RegisterEthService(stack, &cfg.Eth)
+
+	// Host the subchains configured besides the main ones
+	for _, chain := range cfg.Node.Chains {
+		if chain.Name == utils.SubChainName {
+			continue
+		}
+		// Hosted chains must be told apart from the subchain defaults
+		config, ok := cfg.Subchains[chain.Name]
+		if !ok || (config.Genesis == nil && config.NetworkId == sub.DefaultConfig.NetworkId) {
+			utils.Fatalf("Hosted chain %q needs a genesis or a network id in its [Subchains.%s] config", chain.Name, chain.Name)
+		}
+		utils.RegisterSubChainService(stack, chain.Name, &config.Config)
+	}
 	if ctx.GlobalBool(utils.RaftModeFlag.Name) {
 		RegisterRaftService(stack, ctx, cfg, raftChan)
 	}
@@ -201,9 +253,14 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 		utils.RegisterShhService(stack, &cfg.Shh)
 	}
 	// Configure GraphQL if requested
-	if ctx.GlobalIsSet(utils.GraphQLEnabledFlag.Name) {
+	if cfg.Node.GraphQLHost != "" {
 		utils.RegisterGraphQLService(stack, cfg.Node.GraphQLEndpoint(), cfg.Node.GraphQLCors, cfg.Node.GraphQLVirtualHosts, cfg.Node.HTTPTimeouts)
 	}
+	for i := range cfg.Node.Chains {
+		if chain := &cfg.Node.Chains[i]; chain.GraphQLHost != "" {
+			utils.RegisterSubGraphQLService(stack, chain, cfg.Node.HTTPTimeouts)
+		}
+	}
 	if ctx.GlobalIsSet(utils.ConfirmDepthFlag.Name) {
 		simpletrigger.DefaultConfirmDepth = ctx.GlobalInt(utils.ConfirmDepthFlag.Name)
 	}
@@ -212,7 +269,10 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 	}
 	// Add the Ethereum Stats daemon if requested.
 	if cfg.Ethstats.URL != "" {
-		utils.RegisterEthStatsService(stack, cfg.Ethstats.URL)
+		utils.RegisterEthStatsService(stack, cfg.Ethstats.URL, cfg.Ethstats.V2)
+	}
+	if cfg.Ethstats.SubURL != "" && cfg.Eth.Role.IsAnchor() {
+		utils.RegisterSubEthStatsService(stack, utils.SubChainName, cfg.Ethstats.SubURL, cfg.Ethstats.V2)
 	}
 	return stack
 }
@@ -224,7 +284,14 @@ func RegisterRaftService(stack *node.Node, ctx *cli.Context, cfg gethConfig, sub
 	blockTimeMillis := ctx.GlobalInt(utils.RaftBlockTimeFlag.Name)
 	useDns := ctx.GlobalBool(utils.RaftDNSEnabledFlag.Name)
 
-	if err := stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
+	// Anchor nodes run raft for the subchain they host
+	register := stack.Register
+	if cfg.Eth.Role.IsAnchor() {
+		register = func(constructor node.ServiceConstructor) error {
+			return stack.RegisterChain(utils.SubChainName, constructor)
+		}
+	}
+	if err := register(func(ctx *node.ServiceContext) (node.Service, error) {
 		privkey := cfg.Node.NodeKey()
 		strId := enode.PubkeyToIDV4(&privkey.PublicKey).String()
 		peers := cfg.Node.StaticNodes()
@@ -0,0 +1,84 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of go-simplechain.
+//
+// go-simplechain is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-simplechain is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-simplechain. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Node section as written by dumpconfig before the subchain endpoints moved to Node.Chains.
+const oldNodeConfig = `
+[Node]
+IPCPath = "sipe.ipc"
+HTTPHost = "127.0.0.1"
+HTTPPort = 8545
+HTTPModules = ["net", "web3", "eth", "shh"]
+SubIPCPath = "subsipe.ipc"
+SubHTTPHost = "127.0.0.1"
+SubHTTPPort = 8546
+SubHTTPVirtualHosts = ["localhost"]
+SubHTTPModules = ["net", "web3", "eth", "shh"]
+SubWSHost = ""
+SubWSPort = 8547
+SubWSModules = ["net", "web3", "eth", "shh"]
+
+[Node.SubHTTPTimeouts]
+ReadTimeout = 30000000000
+WriteTimeout = 30000000000
+IdleTimeout = 120000000000
+`
+
+func writeConfig(t *testing.T, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "sipe-config-test")
+	if err != nil {
+		t.Fatalf("failed to create temporary datadir: %v", err)
+	}
+	file := filepath.Join(dir, "config.toml")
+	if err := ioutil.WriteFile(file, []byte(content), 0600); err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return file, func() { os.RemoveAll(dir) }
+}
+
+// Tests that config files carrying the removed subchain endpoint fields still load.
+func TestLoadConfigRemovedFields(t *testing.T) {
+	file, cleanup := writeConfig(t, oldNodeConfig)
+	defer cleanup()
+
+	cfg := gethConfig{Node: defaultNodeConfig()}
+	if err := loadConfig(file, &cfg); err != nil {
+		t.Fatalf("failed to load old config: %v", err)
+	}
+	if cfg.Node.HTTPPort != 8545 || cfg.Node.IPCPath != "sipe.ipc" {
+		t.Errorf("node endpoints mismatch: have http port %d ipc %q, want 8545 sipe.ipc", cfg.Node.HTTPPort, cfg.Node.IPCPath)
+	}
+}
+
+// Tests that unknown fields are still rejected.
+func TestLoadConfigUnknownField(t *testing.T) {
+	file, cleanup := writeConfig(t, "[Node]\nSubUnknown = 1\n")
+	defer cleanup()
+
+	cfg := gethConfig{Node: defaultNodeConfig()}
+	if err := loadConfig(file, &cfg); err == nil {
+		t.Fatal("expected unknown field to be rejected")
+	}
+}
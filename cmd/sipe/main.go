@@ -93,6 +93,8 @@ var (
 		utils.SyncModeFlag,
 		utils.ExitWhenSyncedFlag,
 		utils.GCModeFlag,
+		utils.AddressIndexFlag,
+		utils.TokenIndexFlag,
 		utils.LightServeFlag,
 		utils.LightLegacyServFlag,
 		utils.LightIngressFlag,
@@ -108,6 +110,7 @@ var (
 		utils.CacheDatabaseFlag,
 		utils.CacheTrieFlag,
 		utils.CacheGCFlag,
+		utils.CacheSnapshotFlag,
 		utils.CacheNoPrefetchFlag,
 		utils.ListenPortFlag,
 		utils.MaxPeersFlag,
@@ -146,12 +149,16 @@ var (
 		utils.VMEnableDebugFlag,
 		utils.NetworkIdFlag,
 		utils.EthStatsURLFlag,
+		utils.EthStatsV2Flag,
 		utils.FakePoWFlag,
 		utils.NoCompactionFlag,
 		utils.GpoBlocksFlag,
 		utils.GpoPercentileFlag,
 		utils.EWASMInterpreterFlag,
 		utils.EVMInterpreterFlag,
+		utils.VMParallelWorkersFlag,
+		utils.PrivateManagerFlag,
+		utils.PrivateIdentityFlag,
 		utils.RoleFlag,
 		utils.ContractMainFlag,
 		utils.ContractSubFlag,
@@ -176,6 +183,10 @@ var (
 		utils.RPCPortFlag,
 		utils.RPCCORSDomainFlag,
 		utils.RPCVirtualHostsFlag,
+		utils.RPCTLSCertFlag,
+		utils.RPCTLSKeyFlag,
+		utils.RPCTLSClientCAFlag,
+		utils.RPCJWTSecretFlag,
 		utils.GraphQLEnabledFlag,
 		utils.GraphQLListenAddrFlag,
 		utils.GraphQLPortFlag,
@@ -187,6 +198,11 @@ var (
 		utils.WSPortFlag,
 		utils.WSApiFlag,
 		utils.WSAllowedOriginsFlag,
+		utils.WSTLSCertFlag,
+		utils.WSTLSKeyFlag,
+		utils.WSTLSClientCAFlag,
+		utils.WSJWTSecretFlag,
+		utils.RPCAPIKeysFlag,
 		utils.IPCDisabledFlag,
 		utils.IPCPathFlag,
 		utils.InsecureUnlockAllowedFlag,
@@ -206,6 +222,12 @@ var (
 		utils.SUBIPCPathFlag,
 		utils.SUBRPCCORSDomainFlag,
 		utils.SUBRPCVirtualHostsFlag,
+		utils.SUBGraphQLEnabledFlag,
+		utils.SUBGraphQLListenAddrFlag,
+		utils.SUBGraphQLPortFlag,
+		utils.SUBGraphQLCORSDomainFlag,
+		utils.SUBGraphQLVirtualHostsFlag,
+		utils.SUBEthStatsURLFlag,
 	}
 
 	whisperFlags = []cli.Flag{
@@ -243,6 +265,8 @@ func init() {
 		removedbCommand,
 		dumpCommand,
 		inspectCommand,
+		// See snapshotcmd.go:
+		snapshotCommand,
 		// See accountcmd.go:
 		accountCommand,
 		walletCommand,
@@ -266,6 +290,7 @@ func init() {
 	app.Flags = append(app.Flags, rpcFlags...)
 	app.Flags = append(app.Flags, subrpcFlags...)
 	app.Flags = append(app.Flags, consoleFlags...)
+	app.Flags = append(app.Flags, attachFlags...)
 	app.Flags = append(app.Flags, debug.Flags...)
 	app.Flags = append(app.Flags, whisperFlags...)
 	app.Flags = append(app.Flags, metricsFlags...)
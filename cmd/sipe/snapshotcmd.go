@@ -0,0 +1,112 @@
+// Copyright 2019 The go-simplechain Authors
+// This file is part of go-simplechain.
+//
+// go-simplechain is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-simplechain is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-simplechain. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"github.com/simplechain-org/go-simplechain/cmd/utils"
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/core/state/pruner"
+	"github.com/simplechain-org/go-simplechain/log"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	pruneChainFlag = cli.StringFlag{
+		Name:  "chain",
+		Usage: "Name of the hosted chain to prune (default = the chain of the node)",
+	}
+	pruneRetainFlag = cli.Uint64Flag{
+		Name:  "retain",
+		Usage: "Number of recent canonical blocks whose state is retained",
+		Value: 128,
+	}
+	pruneBloomSizeFlag = cli.Uint64Flag{
+		Name:  "bloomfilter.size",
+		Usage: "Megabytes of memory allocated to the bloom filter of reachable state",
+		Value: 2048,
+	}
+	pruneDryRunFlag = cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "Report the stale state without deleting it",
+	}
+	snapshotCommand = cli.Command{
+		Name:     "snapshot",
+		Usage:    "A set of commands operating on the state database",
+		Category: "BLOCKCHAIN COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Action:    utils.MigrateFlags(pruneState),
+				Name:      "prune-state",
+				Usage:     "Prune stale state trie nodes from the database",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.RoleFlag,
+					utils.CacheFlag,
+					utils.CacheDatabaseFlag,
+					pruneChainFlag,
+					pruneRetainFlag,
+					pruneBloomSizeFlag,
+					pruneDryRunFlag,
+				},
+				Description: `
+sipe snapshot prune-state [--retain N] [--dry-run]
+
+Deletes every state trie node that is not reachable from the state of the last
+N canonical blocks, the genesis block or the state snapshot. Reachable nodes
+are marked in a bloom filter, so a few stale nodes may survive, but no live one
+is ever removed. The node must be stopped while pruning.
+
+The bloom filter is saved before deleting anything, so an interrupted pruning
+resumes the deletion when the command is run again. With --dry-run the stale
+nodes are only counted.`,
+			},
+		},
+	}
+)
+
+// pruneState deletes the stale state trie nodes from the chain database of the
+// node or of one of its hosted chains.
+func pruneState(ctx *cli.Context) error {
+	stack, config := makeConfigNode(ctx)
+	defer stack.Close()
+
+	name := common.MainchainData
+	if config.Node.Role.IsSubChain() {
+		name = common.SubchainData
+	}
+	path := stack.ResolvePath(name)
+	if chain := ctx.String(pruneChainFlag.Name); chain != "" {
+		var err error
+		if path, err = stack.ResolveChainPath(chain, common.SubchainData); err != nil {
+			utils.Fatalf("Failed to resolve chain %q: %v", chain, err)
+		}
+	}
+	if !common.FileExist(path) {
+		utils.Fatalf("Chain database %s missing", path)
+	}
+	chainDb := utils.MakeChainDatabaseAt(ctx, path)
+	defer chainDb.Close()
+
+	log.Info("Pruning state database", "path", path)
+	return pruner.NewPruner(chainDb, pruner.Config{
+		Datadir:   path,
+		BloomSize: ctx.Uint64(pruneBloomSizeFlag.Name),
+		Retain:    ctx.Uint64(pruneRetainFlag.Name),
+		DryRun:    ctx.Bool(pruneDryRunFlag.Name),
+	}).Prune()
+}
@@ -76,7 +76,10 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.SyncModeFlag,
 			utils.ExitWhenSyncedFlag,
 			utils.GCModeFlag,
+			utils.AddressIndexFlag,
+			utils.TokenIndexFlag,
 			utils.EthStatsURLFlag,
+			utils.EthStatsV2Flag,
 			utils.IdentityFlag,
 			utils.LightKDFFlag,
 			utils.WhitelistFlag,
@@ -147,6 +150,7 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.CacheDatabaseFlag,
 			utils.CacheTrieFlag,
 			utils.CacheGCFlag,
+			utils.CacheSnapshotFlag,
 			utils.CacheNoPrefetchFlag,
 		},
 	},
@@ -181,11 +185,20 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.RPCGlobalGasCap,
 			utils.RPCCORSDomainFlag,
 			utils.RPCVirtualHostsFlag,
+			utils.RPCTLSCertFlag,
+			utils.RPCTLSKeyFlag,
+			utils.RPCTLSClientCAFlag,
+			utils.RPCJWTSecretFlag,
 			utils.WSEnabledFlag,
 			utils.WSListenAddrFlag,
 			utils.WSPortFlag,
 			utils.WSApiFlag,
 			utils.WSAllowedOriginsFlag,
+			utils.WSTLSCertFlag,
+			utils.WSTLSKeyFlag,
+			utils.WSTLSClientCAFlag,
+			utils.WSJWTSecretFlag,
+			utils.RPCAPIKeysFlag,
 			utils.GraphQLEnabledFlag,
 			utils.GraphQLListenAddrFlag,
 			utils.GraphQLPortFlag,
@@ -194,6 +207,10 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.JSpathFlag,
 			utils.ExecFlag,
 			utils.PreloadJSFlag,
+			utils.AttachTLSCAFlag,
+			utils.AttachTLSCertFlag,
+			utils.AttachTLSKeyFlag,
+			utils.AttachJWTSecretFlag,
 			utils.SUBRPCEnabledFlag,
 			utils.SUBRPCListenAddrFlag,
 			utils.SUBRPCPortFlag,
@@ -207,6 +224,12 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.SUBIPCPathFlag,
 			utils.SUBRPCCORSDomainFlag,
 			utils.SUBRPCVirtualHostsFlag,
+			utils.SUBGraphQLEnabledFlag,
+			utils.SUBGraphQLListenAddrFlag,
+			utils.SUBGraphQLPortFlag,
+			utils.SUBGraphQLCORSDomainFlag,
+			utils.SUBGraphQLVirtualHostsFlag,
+			utils.SUBEthStatsURLFlag,
 		},
 	},
 	{
@@ -254,6 +277,14 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.VMEnableDebugFlag,
 			utils.EVMInterpreterFlag,
 			utils.EWASMInterpreterFlag,
+			utils.VMParallelWorkersFlag,
+		},
+	},
+	{
+		Name: "PRIVATE TRANSACTIONS",
+		Flags: []cli.Flag{
+			utils.PrivateManagerFlag,
+			utils.PrivateIdentityFlag,
 		},
 	},
 	{
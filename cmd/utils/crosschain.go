@@ -26,6 +26,8 @@ import (
 	"github.com/simplechain-org/go-simplechain/cross/trigger/simpletrigger/retriever"
 	"github.com/simplechain-org/go-simplechain/cross/trigger/simpletrigger/subscriber"
 	"github.com/simplechain-org/go-simplechain/eth"
+	"github.com/simplechain-org/go-simplechain/ethstats"
+	"github.com/simplechain-org/go-simplechain/graphql"
 	"github.com/simplechain-org/go-simplechain/node"
 	"github.com/simplechain-org/go-simplechain/sub"
 )
@@ -68,6 +70,40 @@ func newSimpleChainContext(node *node.ServiceContext, chain simpletrigger.Simple
 		return nil, err
 	}
 	ctx.Retriever = retriever.NewSimpleRetriever(chain.BlockChain(), chain.ProtocolManager(), contract, ctx.Config, chain.ChainConfig())
-	ctx.Subscriber = subscriber.NewSimpleSubscriber(contract, chain.BlockChain(), node.ResolvePath(journal))
+	ctx.Subscriber = subscriber.NewSimpleSubscriber(contract, chain.BlockChain(), node.ResolvePath(journal),
+		config.ConfirmPolicy(chain.ChainConfig().ChainID, uint64(simpletrigger.DefaultConfirmDepth)))
 	return ctx, nil
 }
+
+// withCrossBackend returns a wrapper of the GraphQL service constructors making
+// the cross-chain transactions queryable if the node is an anchor.
+func withCrossBackend(ctx *node.ServiceContext) func(*graphql.Service, error) (node.Service, error) {
+	return func(service *graphql.Service, err error) (node.Service, error) {
+		if err != nil {
+			return nil, err
+		}
+		var crossServ *crossBackend.CrossService
+		if err := ctx.Service(&crossServ); err == nil {
+			service.SetCrossBackend(crossServ)
+		}
+		return service, nil
+	}
+}
+
+// withEthStatsV2 returns a wrapper of the stats service constructors switching
+// the service to the v2 protocol if requested, reporting the cross chain pool
+// too if the node runs the cross chain service.
+func withEthStatsV2(ctx *node.ServiceContext, v2 bool) func(*ethstats.Service, error) (node.Service, error) {
+	return func(service *ethstats.Service, err error) (node.Service, error) {
+		if err != nil || !v2 {
+			return service, err
+		}
+		service.UseV2()
+
+		var crossServ *crossBackend.CrossService
+		if err := ctx.Service(&crossServ); err == nil {
+			service.SetCrossPool(crossServ)
+		}
+		return service, nil
+	}
+}
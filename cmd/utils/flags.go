@@ -19,6 +19,7 @@ package utils
 
 import (
 	"crypto/ecdsa"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -40,8 +41,10 @@ import (
 	"github.com/simplechain-org/go-simplechain/consensus"
 	"github.com/simplechain-org/go-simplechain/consensus/clique"
 	"github.com/simplechain-org/go-simplechain/consensus/ethash"
+	raftBackend "github.com/simplechain-org/go-simplechain/consensus/raft/backend"
 	"github.com/simplechain-org/go-simplechain/consensus/scrypt"
 	"github.com/simplechain-org/go-simplechain/core"
+	"github.com/simplechain-org/go-simplechain/core/rawdb"
 	"github.com/simplechain-org/go-simplechain/core/vm"
 	"github.com/simplechain-org/go-simplechain/cross"
 	"github.com/simplechain-org/go-simplechain/cross/backend/synchronise"
@@ -49,6 +52,7 @@ import (
 	"github.com/simplechain-org/go-simplechain/eth"
 	"github.com/simplechain-org/go-simplechain/eth/downloader"
 	"github.com/simplechain-org/go-simplechain/eth/gasprice"
+	"github.com/simplechain-org/go-simplechain/eth/tokenindex"
 	"github.com/simplechain-org/go-simplechain/ethdb"
 	"github.com/simplechain-org/go-simplechain/ethstats"
 	"github.com/simplechain-org/go-simplechain/graphql"
@@ -133,6 +137,31 @@ func printHelp(out io.Writer, templ string, data interface{}) {
 	w.Flush()
 }
 
+const (
+	SubChainName       = "sub" // Name of the subchain hosted by anchor nodes, configured by the sub.* flags
+	DefaultSubHTTPPort = 9545  // Default TCP port for the HTTP RPC server of the subchain
+	DefaultSubWSPort   = 9546  // Default TCP port for the websocket RPC server of the subchain
+
+	DefaultSubGraphQLPort = 9547 // Default TCP port for the GraphQL server of the subchain
+)
+
+// DefaultSubChainConfig contains the default settings of the subchain hosted by
+// anchor nodes. The subchain data stays in the instance directory for backward
+// compatibility, and its protocols run on the p2p server of the node.
+var DefaultSubChainConfig = node.ChainConfig{
+	Name:             SubChainName,
+	DataDir:          ".",
+	IPCPath:          "subsipe.ipc",
+	HTTPPort:         DefaultSubHTTPPort,
+	HTTPModules:      []string{"net", "web3", "eth", "shh"},
+	HTTPVirtualHosts: []string{"localhost"},
+	WSPort:           DefaultSubWSPort,
+	WSModules:        []string{"net", "web3", "eth", "shh"},
+
+	GraphQLPort:         DefaultSubGraphQLPort,
+	GraphQLVirtualHosts: []string{"localhost"},
+}
+
 // These are all the command line flags we support.
 // If you add to this list, please remember to include the
 // flag in the appropriate command definition.
@@ -221,6 +250,14 @@ var (
 		Usage: `Blockchain garbage collection mode ("full", "archive")`,
 		Value: "full",
 	}
+	AddressIndexFlag = cli.BoolFlag{
+		Name:  "addrindex",
+		Usage: "Index the transactions touching each address (enables eth_getTransactionsByAddress, requires --gcmode=archive)",
+	}
+	TokenIndexFlag = cli.BoolFlag{
+		Name:  "tokenindex",
+		Usage: "Index the ERC-20 and ERC-721 balances and transfers of each address (enables eth_getTokenBalances and eth_getTokenTransfers)",
+	}
 	LightKDFFlag = cli.BoolFlag{
 		Name:  "lightkdf",
 		Usage: "Reduce key-derivation RAM & CPU usage at some expense of KDF strength",
@@ -383,6 +420,11 @@ var (
 		Usage: "Percentage of cache memory allowance to use for trie pruning (default = 25% full mode, 0% archive mode)",
 		Value: 25,
 	}
+	CacheSnapshotFlag = cli.IntFlag{
+		Name:  "cache.snapshot",
+		Usage: "Percentage of cache memory allowance to use for state snapshot caching (0 = disable snapshots)",
+		Value: 10,
+	}
 	CacheNoPrefetchFlag = cli.BoolFlag{
 		Name:  "cache.noprefetch",
 		Usage: "Disable heuristic state prefetch during block import (less CPU and disk IO, more time waiting for data)",
@@ -523,6 +565,14 @@ var (
 		Name:  "ethstats",
 		Usage: "Reporting URL of a ethstats service (nodename:secret@host:port)",
 	}
+	SUBEthStatsURLFlag = cli.StringFlag{
+		Name:  "sub.ethstats",
+		Usage: "Reporting URL of a ethstats service for the subchain hosted by anchor nodes (nodename:secret@host:port)",
+	}
+	EthStatsV2Flag = cli.BoolFlag{
+		Name:  "ethstats.v2",
+		Usage: "Report with the ethstats v2 protocol (consensus and cross chain stats, node key authentication)",
+	}
 	FakePoWFlag = cli.BoolFlag{
 		Name:  "fakepow",
 		Usage: "Disables proof-of-work verification",
@@ -569,6 +619,31 @@ var (
 		Usage: "API's offered over the HTTP-RPC interface",
 		Value: "",
 	}
+	RPCTLSCertFlag = cli.StringFlag{
+		Name:  "rpc.tlscert",
+		Usage: "PEM certificate file enabling TLS on the HTTP-RPC server",
+		Value: "",
+	}
+	RPCTLSKeyFlag = cli.StringFlag{
+		Name:  "rpc.tlskey",
+		Usage: "PEM private key file of the HTTP-RPC server certificate",
+		Value: "",
+	}
+	RPCTLSClientCAFlag = cli.StringFlag{
+		Name:  "rpc.tlsclientca",
+		Usage: "PEM file of the CAs the HTTP-RPC client certificates must be signed by (requires client certificates)",
+		Value: "",
+	}
+	RPCJWTSecretFlag = cli.StringFlag{
+		Name:  "rpc.jwtsecret",
+		Usage: "File holding the hex encoded 32 byte secret of the HS256 bearer tokens required by the HTTP-RPC server",
+		Value: "",
+	}
+	RPCAPIKeysFlag = cli.StringFlag{
+		Name:  "rpc.apikeys",
+		Usage: "JSON file with the API keys required by the HTTP-RPC and WS-RPC servers, along with their allowed methods and limits",
+		Value: "",
+	}
 	WSEnabledFlag = cli.BoolFlag{
 		Name:  "ws",
 		Usage: "Enable the WS-RPC server",
@@ -593,6 +668,46 @@ var (
 		Usage: "Origins from which to accept websockets requests",
 		Value: "",
 	}
+	WSTLSCertFlag = cli.StringFlag{
+		Name:  "ws.tlscert",
+		Usage: "PEM certificate file enabling TLS on the WS-RPC server",
+		Value: "",
+	}
+	WSTLSKeyFlag = cli.StringFlag{
+		Name:  "ws.tlskey",
+		Usage: "PEM private key file of the WS-RPC server certificate",
+		Value: "",
+	}
+	WSTLSClientCAFlag = cli.StringFlag{
+		Name:  "ws.tlsclientca",
+		Usage: "PEM file of the CAs the WS-RPC client certificates must be signed by (requires client certificates)",
+		Value: "",
+	}
+	WSJWTSecretFlag = cli.StringFlag{
+		Name:  "ws.jwtsecret",
+		Usage: "File holding the hex encoded 32 byte secret of the HS256 bearer tokens required by the WS-RPC server",
+		Value: "",
+	}
+	AttachTLSCAFlag = cli.StringFlag{
+		Name:  "attach.tlsca",
+		Usage: "PEM file of the CAs the attached HTTPS/WSS endpoint certificate must be signed by",
+		Value: "",
+	}
+	AttachTLSCertFlag = cli.StringFlag{
+		Name:  "attach.tlscert",
+		Usage: "PEM client certificate file presented to the attached endpoint",
+		Value: "",
+	}
+	AttachTLSKeyFlag = cli.StringFlag{
+		Name:  "attach.tlskey",
+		Usage: "PEM private key file of the client certificate",
+		Value: "",
+	}
+	AttachJWTSecretFlag = cli.StringFlag{
+		Name:  "attach.jwtsecret",
+		Usage: "File holding the hex encoded 32 byte secret of the bearer tokens sent to the attached endpoint",
+		Value: "",
+	}
 	SUBRPCEnabledFlag = cli.BoolFlag{
 		Name:  "sub.rpc",
 		Usage: "Enable the HTTP-RPC server for subchain",
@@ -600,12 +715,12 @@ var (
 	SUBRPCListenAddrFlag = cli.StringFlag{
 		Name:  "sub.rpcaddr",
 		Usage: "HTTP-RPC server listening interface for subchain",
-		Value: node.DefaultSubHTTPHost,
+		Value: node.DefaultHTTPHost,
 	}
 	SUBRPCPortFlag = cli.IntFlag{
 		Name:  "sub.rpcport",
 		Usage: "HTTP-RPC server listening port for subchain",
-		Value: node.DefaultSubHTTPPort,
+		Value: DefaultSubHTTPPort,
 	}
 	SUBRPCApiFlag = cli.StringFlag{
 		Name:  "sub.rpcapi",
@@ -627,12 +742,12 @@ var (
 	SUBWSListenAddrFlag = cli.StringFlag{
 		Name:  "sub.wsaddr",
 		Usage: "WS-RPC server listening interface for subchain",
-		Value: node.DefaultSubWSHost,
+		Value: node.DefaultWSHost,
 	}
 	SUBWSPortFlag = cli.IntFlag{
 		Name:  "sub.wsport",
 		Usage: "WS-RPC server listening port for subchain",
-		Value: node.DefaultSubWSPort,
+		Value: DefaultSubWSPort,
 	}
 	SUBWSApiFlag = cli.StringFlag{
 		Name:  "sub.wsapi",
@@ -654,6 +769,30 @@ var (
 		Usage: "Comma separated list of virtual hostnames from which to accept requests (server enforced). Accepts '*' wildcard.for subchain",
 		Value: strings.Join(node.DefaultConfig.HTTPVirtualHosts, ","),
 	}
+	SUBGraphQLEnabledFlag = cli.BoolFlag{
+		Name:  "sub.graphql",
+		Usage: "Enable the GraphQL server for subchain",
+	}
+	SUBGraphQLListenAddrFlag = cli.StringFlag{
+		Name:  "sub.graphql.addr",
+		Usage: "GraphQL server listening interface for subchain",
+		Value: node.DefaultGraphQLHost,
+	}
+	SUBGraphQLPortFlag = cli.IntFlag{
+		Name:  "sub.graphql.port",
+		Usage: "GraphQL server listening port for subchain",
+		Value: DefaultSubGraphQLPort,
+	}
+	SUBGraphQLCORSDomainFlag = cli.StringFlag{
+		Name:  "sub.graphql.corsdomain",
+		Usage: "Comma separated list of domains from which to accept cross origin requests (browser enforced) for subchain",
+		Value: "",
+	}
+	SUBGraphQLVirtualHostsFlag = cli.StringFlag{
+		Name:  "sub.graphql.vhosts",
+		Usage: "Comma separated list of virtual hostnames from which to accept requests (server enforced). Accepts '*' wildcard. for subchain",
+		Value: strings.Join(DefaultSubChainConfig.GraphQLVirtualHosts, ","),
+	}
 	GraphQLEnabledFlag = cli.BoolFlag{
 		Name:  "graphql",
 		Usage: "Enable the GraphQL server",
@@ -875,6 +1014,19 @@ var (
 		Usage: "External EVM configuration (default = built-in interpreter)",
 		Value: "",
 	}
+	VMParallelWorkersFlag = cli.IntFlag{
+		Name:  "vm.parallel",
+		Usage: "Number of workers speculatively executing block transactions in parallel (0 = sequential)",
+		Value: 0,
+	}
+	PrivateManagerFlag = cli.StringFlag{
+		Name:  "private.manager",
+		Usage: "Private transaction manager, directory of a local manager or URL of a remote one",
+	}
+	PrivateIdentityFlag = cli.StringFlag{
+		Name:  "private.identity",
+		Usage: "Identity of the node in a local private transaction manager",
+	}
 	role = eth.DefaultConfig.Role
 
 	RoleFlag = TextMarshalerFlag{
@@ -1066,7 +1218,73 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(RPCVirtualHostsFlag.Name) {
 		cfg.HTTPVirtualHosts = splitAndTrim(ctx.GlobalString(RPCVirtualHostsFlag.Name))
 	}
-	setSubHTTP(ctx, cfg)
+	if auth := makeAuthConfig(ctx, RPCTLSCertFlag, RPCTLSKeyFlag, RPCTLSClientCAFlag, RPCJWTSecretFlag); auth != nil {
+		cfg.HTTPAuth = auth
+	}
+}
+
+// makeAuthConfig creates the transport security of an endpoint from the given
+// flags, returning nil if none is set.
+func makeAuthConfig(ctx *cli.Context, cert, key, clientCA, jwtSecret cli.StringFlag) *rpc.AuthConfig {
+	auth := &rpc.AuthConfig{
+		TLSCert:     ctx.GlobalString(cert.Name),
+		TLSKey:      ctx.GlobalString(key.Name),
+		TLSClientCA: ctx.GlobalString(clientCA.Name),
+		JWTSecret:   ctx.GlobalString(jwtSecret.Name),
+	}
+	if *auth == (rpc.AuthConfig{}) {
+		return nil
+	}
+	if (auth.TLSCert == "") != (auth.TLSKey == "") {
+		Fatalf("Flags --%s and --%s must be set together", cert.Name, key.Name)
+	}
+	if auth.TLSClientCA != "" && auth.TLSCert == "" {
+		Fatalf("Flag --%s requires --%s", clientCA.Name, cert.Name)
+	}
+	return auth
+}
+
+// MakeDialOptions creates the transport options of an RPC client attaching to a
+// node from the attach.* flags.
+func MakeDialOptions(ctx *cli.Context) []rpc.ClientOption {
+	var options []rpc.ClientOption
+	if path := ctx.GlobalString(AttachJWTSecretFlag.Name); path != "" {
+		secret, err := rpc.ReadJWTSecret(path)
+		if err != nil {
+			Fatalf("Failed to read JWT secret: %v", err)
+		}
+		options = append(options, rpc.WithHTTPAuth(rpc.NewJWTAuth(secret)))
+	}
+	ca, cert, key := ctx.GlobalString(AttachTLSCAFlag.Name), ctx.GlobalString(AttachTLSCertFlag.Name), ctx.GlobalString(AttachTLSKeyFlag.Name)
+	if ca != "" || cert != "" || key != "" {
+		if (cert == "") != (key == "") {
+			Fatalf("Flags --%s and --%s must be set together", AttachTLSCertFlag.Name, AttachTLSKeyFlag.Name)
+		}
+		config, err := rpc.ClientTLSConfig(ca, cert, key)
+		if err != nil {
+			Fatalf("Failed to load TLS config: %v", err)
+		}
+		options = append(options, rpc.WithTLSConfig(config))
+	}
+	return options
+}
+
+// setAPIKeys loads the API keys required by the HTTP and WS endpoints from the
+// file set on the command line.
+func setAPIKeys(ctx *cli.Context, cfg *node.Config) {
+	path := ctx.GlobalString(RPCAPIKeysFlag.Name)
+	if path == "" {
+		return
+	}
+	blob, err := ioutil.ReadFile(path)
+	if err != nil {
+		Fatalf("Failed to read API key file: %v", err)
+	}
+	var keys []rpc.APIKey
+	if err := json.Unmarshal(blob, &keys); err != nil {
+		Fatalf("Invalid API key file: %v", err)
+	}
+	cfg.APIKeys = keys
 }
 
 // setGraphQL creates the GraphQL listener interface string from the set
@@ -1105,7 +1323,9 @@ func setWS(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(WSApiFlag.Name) {
 		cfg.WSModules = splitAndTrim(ctx.GlobalString(WSApiFlag.Name))
 	}
-	setSubWS(ctx, cfg)
+	if auth := makeAuthConfig(ctx, WSTLSCertFlag, WSTLSKeyFlag, WSTLSClientCAFlag, WSJWTSecretFlag); auth != nil {
+		cfg.WSAuth = auth
+	}
 }
 
 // setIPC creates an IPC path configuration from the set command line flags,
@@ -1118,7 +1338,6 @@ func setIPC(ctx *cli.Context, cfg *node.Config) {
 	case ctx.GlobalIsSet(IPCPathFlag.Name):
 		cfg.IPCPath = ctx.GlobalString(IPCPathFlag.Name)
 	}
-	setSubIPC(ctx, cfg)
 }
 
 // setLes configures the les server and ultra light client settings from the command line flags.
@@ -1320,6 +1539,7 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	setHTTP(ctx, cfg)
 	setGraphQL(ctx, cfg)
 	setWS(ctx, cfg)
+	setAPIKeys(ctx, cfg)
 	setNodeUserIdent(ctx, cfg)
 	setDataDir(ctx, cfg)
 	setSmartCard(ctx, cfg)
@@ -1343,6 +1563,7 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(RoleFlag.Name) {
 		cfg.Role = *GlobalTextMarshaler(ctx, RoleFlag.Name).(*common.ChainRole)
 	}
+	setSubChain(ctx, cfg)
 }
 
 func setSmartCard(ctx *cli.Context, cfg *node.Config) {
@@ -1613,12 +1834,24 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 	if ctx.GlobalIsSet(CacheNoPrefetchFlag.Name) {
 		cfg.NoPrefetch = ctx.GlobalBool(CacheNoPrefetchFlag.Name)
 	}
+	if ctx.GlobalIsSet(AddressIndexFlag.Name) {
+		cfg.AddressIndex = ctx.GlobalBool(AddressIndexFlag.Name)
+	}
+	if cfg.AddressIndex && !cfg.NoPruning {
+		Fatalf("--%s requires --%s=archive to trace the internal transfers of past blocks", AddressIndexFlag.Name, GCModeFlag.Name)
+	}
+	if ctx.GlobalIsSet(TokenIndexFlag.Name) {
+		cfg.TokenIndex = ctx.GlobalBool(TokenIndexFlag.Name)
+	}
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheTrieFlag.Name) {
 		cfg.TrieCleanCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheTrieFlag.Name) / 100
 	}
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheGCFlag.Name) {
 		cfg.TrieDirtyCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheGCFlag.Name) / 100
 	}
+	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheSnapshotFlag.Name) {
+		cfg.SnapshotCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheSnapshotFlag.Name) / 100
+	}
 	if ctx.GlobalIsSet(DocRootFlag.Name) {
 		cfg.DocRoot = ctx.GlobalString(DocRootFlag.Name)
 	}
@@ -1634,9 +1867,18 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 	if ctx.GlobalIsSet(EVMInterpreterFlag.Name) {
 		cfg.EVMInterpreter = ctx.GlobalString(EVMInterpreterFlag.Name)
 	}
+	if ctx.GlobalIsSet(VMParallelWorkersFlag.Name) {
+		cfg.ParallelWorkers = ctx.GlobalInt(VMParallelWorkersFlag.Name)
+	}
 	if ctx.GlobalIsSet(RPCGlobalGasCap.Name) {
 		cfg.RPCGasCap = new(big.Int).SetUint64(ctx.GlobalUint64(RPCGlobalGasCap.Name))
 	}
+	if ctx.GlobalIsSet(PrivateManagerFlag.Name) {
+		cfg.PrivateManager = ctx.GlobalString(PrivateManagerFlag.Name)
+	}
+	if ctx.GlobalIsSet(PrivateIdentityFlag.Name) {
+		cfg.PrivateIdentity = ctx.GlobalString(PrivateIdentityFlag.Name)
+	}
 
 	// Override any default configs for hard coded networks.
 	switch {
@@ -1722,7 +1964,7 @@ func RegisterEthService(stack *node.Node, cfg *eth.Config) <-chan *sub.Ethereum
 				return nil
 			}
 			//subchain
-			err = stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
+			err = stack.RegisterChain(SubChainName, func(ctx *node.ServiceContext) (node.Service, error) {
 				fullNode, err := sub.New(ctx, &subConfig)
 				raftChan <- fullNode
 				crossSubChan <- fullNode
@@ -1743,6 +1985,16 @@ func RegisterEthService(stack *node.Node, cfg *eth.Config) <-chan *sub.Ethereum
 	return raftChan
 }
 
+// RegisterSubChainService adds a subchain client to a chain hosted by the stack.
+func RegisterSubChainService(stack *node.Node, name string, cfg *eth.Config) {
+	err := stack.RegisterChain(name, func(ctx *node.ServiceContext) (node.Service, error) {
+		return sub.New(ctx, cfg)
+	})
+	if err != nil {
+		Fatalf("Failed to register the %s chain service: %v", name, err)
+	}
+}
+
 // RegisterShhService configures Whisper and adds it to the given node.
 func RegisterShhService(stack *node.Node, cfg *whisper.Config) {
 	if err := stack.Register(func(n *node.ServiceContext) (node.Service, error) {
@@ -1753,30 +2005,61 @@ func RegisterShhService(stack *node.Node, cfg *whisper.Config) {
 }
 
 // RegisterEthStatsService configures the Ethereum Stats daemon and adds it to
-// the given node.
-func RegisterEthStatsService(stack *node.Node, url string) {
+// the given node, reporting with the v2 protocol if requested.
+func RegisterEthStatsService(stack *node.Node, url string, v2 bool) {
 	if err := stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
-		// Retrieve both eth and les services
+		// Retrieve the eth, sub and les services
 		var ethServ *eth.Ethereum
 		ctx.Service(&ethServ)
 
+		var subServ *sub.Ethereum
+		if err := ctx.Service(&subServ); err == nil && ethServ == nil {
+			return newSubEthStats(ctx, url, v2, subServ)
+		}
 		var lesServ *les.LightEthereum
 		ctx.Service(&lesServ)
 
 		// Let ethstats use whichever is not nil
-		return ethstats.New(url, ethServ, lesServ)
+		return withEthStatsV2(ctx, v2)(ethstats.New(url, ethServ, lesServ))
 	}); err != nil {
 		Fatalf("Failed to register the Ethereum Stats service: %v", err)
 	}
 }
 
+// RegisterSubEthStatsService configures the Ethereum Stats daemon reporting a
+// hosted subchain and adds it to the chain.
+func RegisterSubEthStatsService(stack *node.Node, chain string, url string, v2 bool) {
+	if err := stack.RegisterChain(chain, func(ctx *node.ServiceContext) (node.Service, error) {
+		var subServ *sub.Ethereum
+		if err := ctx.Service(&subServ); err != nil {
+			return nil, err
+		}
+		return newSubEthStats(ctx, url, v2, subServ)
+	}); err != nil {
+		Fatalf("Failed to register the %s chain Ethereum Stats service: %v", chain, err)
+	}
+}
+
+// newSubEthStats creates the stats service of a subchain, reporting the raft role
+// of the node if the subchain runs raft.
+func newSubEthStats(ctx *node.ServiceContext, url string, v2 bool, subServ *sub.Ethereum) (node.Service, error) {
+	var raftServ *raftBackend.RaftService
+	ctx.Service(&raftServ)
+	return withEthStatsV2(ctx, v2)(ethstats.NewSub(url, subServ, raftServ))
+}
+
 // RegisterGraphQLService is a utility function to construct a new service and register it against a node.
 func RegisterGraphQLService(stack *node.Node, endpoint string, cors, vhosts []string, timeouts rpc.HTTPTimeouts) {
 	if err := stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
 		// Try to construct the GraphQL service backed by a full node
 		var ethServ *eth.Ethereum
 		if err := ctx.Service(&ethServ); err == nil {
-			return graphql.New(ethServ.APIBackend, endpoint, cors, vhosts, timeouts)
+			return withCrossBackend(ctx)(withTokenBackend(ethServ.TokenIndex())(graphql.New(ethServ.APIBackend, endpoint, cors, vhosts, timeouts)))
+		}
+		// Try to construct the GraphQL service backed by a subchain node
+		var subServ *sub.Ethereum
+		if err := ctx.Service(&subServ); err == nil {
+			return newSubGraphQL(ctx, subServ, endpoint, cors, vhosts, timeouts)
 		}
 		// Try to construct the GraphQL service backed by a light node
 		var lesServ *les.LightEthereum
@@ -1790,6 +2073,43 @@ func RegisterGraphQLService(stack *node.Node, endpoint string, cors, vhosts []st
 	}
 }
 
+// RegisterSubGraphQLService constructs a GraphQL service serving a hosted subchain
+// on the GraphQL endpoint of the chain and registers it against the chain.
+func RegisterSubGraphQLService(stack *node.Node, chain *node.ChainConfig, timeouts rpc.HTTPTimeouts) {
+	if err := stack.RegisterChain(chain.Name, func(ctx *node.ServiceContext) (node.Service, error) {
+		var subServ *sub.Ethereum
+		if err := ctx.Service(&subServ); err != nil {
+			return nil, err
+		}
+		return newSubGraphQL(ctx, subServ, chain.GraphQLEndpoint(), chain.GraphQLCors, chain.GraphQLVirtualHosts, timeouts)
+	}); err != nil {
+		Fatalf("Failed to register the %s chain GraphQL service: %v", chain.Name, err)
+	}
+}
+
+// newSubGraphQL creates the GraphQL service of a subchain, reporting the raft role
+// of the node if the subchain runs raft.
+func newSubGraphQL(ctx *node.ServiceContext, subServ *sub.Ethereum, endpoint string, cors, vhosts []string, timeouts rpc.HTTPTimeouts) (node.Service, error) {
+	tokens := withTokenBackend(subServ.TokenIndex())
+
+	var raftServ *raftBackend.RaftService
+	if err := ctx.Service(&raftServ); err == nil {
+		return withCrossBackend(ctx)(tokens(graphql.NewSub(subServ.APIBackend, raftServ, endpoint, cors, vhosts, timeouts)))
+	}
+	return withCrossBackend(ctx)(tokens(graphql.NewSub(subServ.APIBackend, nil, endpoint, cors, vhosts, timeouts)))
+}
+
+// withTokenBackend returns a wrapper of the GraphQL service constructors making
+// the token balances and transfers queryable if the chain indexes them.
+func withTokenBackend(tokens *tokenindex.PublicTokenIndexAPI) func(*graphql.Service, error) (*graphql.Service, error) {
+	return func(service *graphql.Service, err error) (*graphql.Service, error) {
+		if err == nil && tokens != nil {
+			service.SetTokenBackend(tokens)
+		}
+		return service, err
+	}
+}
+
 func SetupMetrics(ctx *cli.Context) {
 	if metrics.Enabled {
 		log.Info("Enabling metrics collection")
@@ -1845,6 +2165,20 @@ func MakeChainDatabase(ctx *cli.Context, stack *node.Node) ethdb.Database {
 	return chainDb
 }
 
+// MakeChainDatabaseAt opens the chain database at the given path, along with
+// its ancient store in the default location.
+func MakeChainDatabaseAt(ctx *cli.Context, path string) ethdb.Database {
+	var (
+		cache   = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheDatabaseFlag.Name) / 100
+		handles = makeDatabaseHandles()
+	)
+	chainDb, err := rawdb.NewLevelDBDatabaseWithFreezer(path, cache, handles, filepath.Join(path, "ancient"), "")
+	if err != nil {
+		Fatalf("Could not open database: %v", err)
+	}
+	return chainDb
+}
+
 func MakeGenesis(ctx *cli.Context) *core.Genesis {
 	var genesis *core.Genesis
 	switch {
@@ -1891,6 +2225,7 @@ func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chai
 		TrieDirtyLimit:      eth.DefaultConfig.TrieDirtyCache,
 		TrieDirtyDisabled:   ctx.GlobalString(GCModeFlag.Name) == "archive",
 		TrieTimeLimit:       eth.DefaultConfig.TrieTimeout,
+		SnapshotLimit:       eth.DefaultConfig.SnapshotCache,
 	}
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheTrieFlag.Name) {
 		cache.TrieCleanLimit = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheTrieFlag.Name) / 100
@@ -1898,7 +2233,13 @@ func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chai
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheGCFlag.Name) {
 		cache.TrieDirtyLimit = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheGCFlag.Name) / 100
 	}
-	vmcfg := vm.Config{EnablePreimageRecording: ctx.GlobalBool(VMEnableDebugFlag.Name)}
+	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheSnapshotFlag.Name) {
+		cache.SnapshotLimit = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheSnapshotFlag.Name) / 100
+	}
+	vmcfg := vm.Config{
+		EnablePreimageRecording: ctx.GlobalBool(VMEnableDebugFlag.Name),
+		ParallelWorkers:         ctx.GlobalInt(VMParallelWorkersFlag.Name),
+	}
 	chain, err = core.NewBlockChain(chainDb, cache, config, engine, vmcfg, nil)
 	if err != nil {
 		Fatalf("Can't create BlockChain: %v", err)
@@ -1947,60 +2288,134 @@ func MigrateFlags(action func(ctx *cli.Context) error) func(*cli.Context) error
 	}
 }
 
-// setHTTP creates the HTTP RPC listener interface string from the set
-// command line flags, returning empty if the HTTP endpoint is disabled.
-func setSubHTTP(ctx *cli.Context, cfg *node.Config) {
-	if ctx.GlobalBool(SUBRPCEnabledFlag.Name) && cfg.SubHTTPHost == "" {
-		cfg.SubHTTPHost = "127.0.0.1"
+// setSubChain configures the subchain hosted by anchor nodes from the sub.* flags.
+// Subchain nodes host nothing but the subchain, so it is served by the node
+// endpoints instead.
+func setSubChain(ctx *cli.Context, cfg *node.Config) {
+	if !cfg.Role.IsSubChain() && !cfg.Role.IsAnchor() {
+		return
+	}
+	index := -1
+	for i := range cfg.Chains {
+		if cfg.Chains[i].Name == SubChainName {
+			index = i
+		}
+	}
+	if index < 0 {
+		chain := DefaultSubChainConfig
+		chain.HTTPModules = append([]string(nil), chain.HTTPModules...)
+		chain.HTTPVirtualHosts = append([]string(nil), chain.HTTPVirtualHosts...)
+		chain.WSModules = append([]string(nil), chain.WSModules...)
+		chain.GraphQLVirtualHosts = append([]string(nil), chain.GraphQLVirtualHosts...)
+
+		cfg.Chains = append(cfg.Chains, chain)
+		index = len(cfg.Chains) - 1
+	}
+	chain := &cfg.Chains[index]
+	setSubIPC(ctx, chain)
+	setSubHTTP(ctx, chain)
+	setSubWS(ctx, chain)
+	setSubGraphQL(ctx, chain)
+
+	if len(chain.APIKeys) == 0 {
+		chain.APIKeys = cfg.APIKeys
+	}
+	if chain.HTTPAuth == nil {
+		chain.HTTPAuth = cfg.HTTPAuth
+	}
+	if chain.WSAuth == nil {
+		chain.WSAuth = cfg.WSAuth
+	}
+	if cfg.Role.IsSubChain() {
+		cfg.IPCPath = chain.IPCPath
+		cfg.HTTPHost, cfg.HTTPPort = chain.HTTPHost, chain.HTTPPort
+		cfg.HTTPCors, cfg.HTTPVirtualHosts, cfg.HTTPModules = chain.HTTPCors, chain.HTTPVirtualHosts, chain.HTTPModules
+		cfg.WSHost, cfg.WSPort = chain.WSHost, chain.WSPort
+		cfg.WSOrigins, cfg.WSModules, cfg.WSExposeAll = chain.WSOrigins, chain.WSModules, chain.WSExposeAll
+		cfg.APIKeys, cfg.HTTPAuth, cfg.WSAuth = chain.APIKeys, chain.HTTPAuth, chain.WSAuth
+		cfg.GraphQLHost, cfg.GraphQLPort = chain.GraphQLHost, chain.GraphQLPort
+		cfg.GraphQLCors, cfg.GraphQLVirtualHosts = chain.GraphQLCors, chain.GraphQLVirtualHosts
+
+		cfg.Chains = append(cfg.Chains[:index], cfg.Chains[index+1:]...)
+	}
+}
+
+// setSubHTTP creates the HTTP RPC listener interface string of the subchain from
+// the set command line flags, returning empty if the HTTP endpoint is disabled.
+func setSubHTTP(ctx *cli.Context, cfg *node.ChainConfig) {
+	if ctx.GlobalBool(SUBRPCEnabledFlag.Name) && cfg.HTTPHost == "" {
+		cfg.HTTPHost = "127.0.0.1"
 		if ctx.GlobalIsSet(SUBRPCListenAddrFlag.Name) {
-			cfg.SubHTTPHost = ctx.GlobalString(SUBRPCListenAddrFlag.Name)
+			cfg.HTTPHost = ctx.GlobalString(SUBRPCListenAddrFlag.Name)
 		}
 	}
 	if ctx.GlobalIsSet(SUBRPCPortFlag.Name) {
-		cfg.SubHTTPPort = ctx.GlobalInt(SUBRPCPortFlag.Name)
+		cfg.HTTPPort = ctx.GlobalInt(SUBRPCPortFlag.Name)
 	}
 	if ctx.GlobalIsSet(SUBRPCCORSDomainFlag.Name) {
-		cfg.SubHTTPCors = splitAndTrim(ctx.GlobalString(SUBRPCCORSDomainFlag.Name))
+		cfg.HTTPCors = splitAndTrim(ctx.GlobalString(SUBRPCCORSDomainFlag.Name))
 	}
 	if ctx.GlobalIsSet(SUBRPCApiFlag.Name) {
-		cfg.SubHTTPModules = splitAndTrim(ctx.GlobalString(SUBRPCApiFlag.Name))
+		cfg.HTTPModules = splitAndTrim(ctx.GlobalString(SUBRPCApiFlag.Name))
 	}
 	if ctx.GlobalIsSet(SUBRPCVirtualHostsFlag.Name) {
-		cfg.SubHTTPVirtualHosts = splitAndTrim(ctx.GlobalString(SUBRPCVirtualHostsFlag.Name))
+		cfg.HTTPVirtualHosts = splitAndTrim(ctx.GlobalString(SUBRPCVirtualHostsFlag.Name))
 	}
 }
 
-// setWS creates the WebSocket RPC listener interface string from the set
-// command line flags, returning empty if the HTTP endpoint is disabled.
-func setSubWS(ctx *cli.Context, cfg *node.Config) {
-	if ctx.GlobalBool(SUBWSEnabledFlag.Name) && cfg.SubWSHost == "" {
-		cfg.SubWSHost = "127.0.0.1"
+// setSubWS creates the WebSocket RPC listener interface string of the subchain
+// from the set command line flags, returning empty if the WS endpoint is disabled.
+func setSubWS(ctx *cli.Context, cfg *node.ChainConfig) {
+	if ctx.GlobalBool(SUBWSEnabledFlag.Name) && cfg.WSHost == "" {
+		cfg.WSHost = "127.0.0.1"
 		if ctx.GlobalIsSet(SUBWSListenAddrFlag.Name) {
-			cfg.SubWSHost = ctx.GlobalString(SUBWSListenAddrFlag.Name)
+			cfg.WSHost = ctx.GlobalString(SUBWSListenAddrFlag.Name)
 		}
 	}
 	if ctx.GlobalIsSet(SUBWSPortFlag.Name) {
-		cfg.SubWSPort = ctx.GlobalInt(SUBWSPortFlag.Name)
+		cfg.WSPort = ctx.GlobalInt(SUBWSPortFlag.Name)
 	}
 	if ctx.GlobalIsSet(SUBWSAllowedOriginsFlag.Name) {
-		cfg.SubWSOrigins = splitAndTrim(ctx.GlobalString(SUBWSAllowedOriginsFlag.Name))
+		cfg.WSOrigins = splitAndTrim(ctx.GlobalString(SUBWSAllowedOriginsFlag.Name))
 	}
 	if ctx.GlobalIsSet(SUBWSApiFlag.Name) {
-		cfg.SubWSModules = splitAndTrim(ctx.GlobalString(SUBWSApiFlag.Name))
+		cfg.WSModules = splitAndTrim(ctx.GlobalString(SUBWSApiFlag.Name))
 	}
 }
 
-// setIPC creates an IPC path configuration from the set command line flags,
-// returning an empty string if IPC was explicitly disabled, or the set path.
-func setSubIPC(ctx *cli.Context, cfg *node.Config) {
+// setSubGraphQL creates the GraphQL listener interface string of the subchain
+// from the set command line flags, returning empty if the GraphQL endpoint is
+// disabled.
+func setSubGraphQL(ctx *cli.Context, cfg *node.ChainConfig) {
+	if ctx.GlobalBool(SUBGraphQLEnabledFlag.Name) && cfg.GraphQLHost == "" {
+		cfg.GraphQLHost = "127.0.0.1"
+		if ctx.GlobalIsSet(SUBGraphQLListenAddrFlag.Name) {
+			cfg.GraphQLHost = ctx.GlobalString(SUBGraphQLListenAddrFlag.Name)
+		}
+	}
+	if ctx.GlobalIsSet(SUBGraphQLPortFlag.Name) {
+		cfg.GraphQLPort = ctx.GlobalInt(SUBGraphQLPortFlag.Name)
+	}
+	if ctx.GlobalIsSet(SUBGraphQLCORSDomainFlag.Name) {
+		cfg.GraphQLCors = splitAndTrim(ctx.GlobalString(SUBGraphQLCORSDomainFlag.Name))
+	}
+	if ctx.GlobalIsSet(SUBGraphQLVirtualHostsFlag.Name) {
+		cfg.GraphQLVirtualHosts = splitAndTrim(ctx.GlobalString(SUBGraphQLVirtualHostsFlag.Name))
+	}
+}
+
+// setSubIPC creates the IPC path configuration of the subchain from the set
+// command line flags, returning an empty string if IPC was explicitly disabled.
+func setSubIPC(ctx *cli.Context, cfg *node.ChainConfig) {
 	CheckExclusive(ctx, SUBIPCDisabledFlag, SUBIPCPathFlag)
 	switch {
 	case ctx.GlobalBool(SUBIPCDisabledFlag.Name):
-		cfg.SubIPCPath = ""
+		cfg.IPCPath = ""
 	case ctx.GlobalIsSet(SUBIPCPathFlag.Name):
-		cfg.SubIPCPath = ctx.GlobalString(SUBIPCPathFlag.Name)
+		cfg.IPCPath = ctx.GlobalString(SUBIPCPathFlag.Name)
 	}
 }
+
 func setAnchorSign(ctx *cli.Context, ks *keystore.KeyStore, cfg *eth.Config) {
 	// Extract the current etherbase, new flag overriding legacy one
 	var anchorSign string
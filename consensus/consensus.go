@@ -138,4 +138,38 @@ type Istanbul interface {
 	SetBroadcaster(broadcaster Broadcaster)
 	HandleMsg(addr common.Address, msg p2p.Msg) (bool, error)
 	NewChainHead() error
+
+	// Signers extracts the validators whose committed seals are in the header.
+	Signers(header *types.Header) ([]common.Address, error)
+
+	// CurrentRound returns the round of the running consensus, or nil if the
+	// engine is not started.
+	CurrentRound() *big.Int
+
+	// CurrentProposer returns the proposer of the current round, or the zero
+	// address if the engine is not started.
+	CurrentProposer() common.Address
+
+	// RoundChanges returns the number of round changes since the engine started.
+	RoundChanges() uint64
+}
+
+// DPoS is a consensus engine sealing blocks by a queue of delegated signers
+type DPoS interface {
+	Engine
+
+	// SignerQueue returns the queue of signers in turn at the given header.
+	SignerQueue(chain ChainReader, header *types.Header) ([]common.Address, error)
+
+	// Confirmations returns the signers having confirmed the block of the given
+	// number, as known at the given head.
+	Confirmations(chain ChainReader, head *types.Header, number uint64) ([]common.Address, error)
+
+	// MissedSigners returns the signers that missed their slot before the given
+	// header was sealed.
+	MissedSigners(header *types.Header) ([]common.Address, error)
+
+	// Punishments returns the punishment credits of the signers that missed
+	// their slots, as known at the given header.
+	Punishments(chain ChainReader, header *types.Header) (map[common.Address]uint64, error)
 }
@@ -825,6 +825,63 @@ func (d *DPoS) Close() error {
 	return nil
 }
 
+// SignerQueue implements consensus.DPoS, returning the signers queue of the
+// snapshot at the given header.
+func (d *DPoS) SignerQueue(chain consensus.ChainReader, header *types.Header) ([]common.Address, error) {
+	snap, err := d.snapshot(chain, header.Number.Uint64(), header.Hash(), nil, nil, defaultLoopCntRecalculateSigners)
+	if err != nil {
+		return nil, err
+	}
+	return derefAddresses(snap.Signers), nil
+}
+
+// Confirmations implements consensus.DPoS, returning the signers having
+// confirmed the given block number in the snapshot at the head.
+func (d *DPoS) Confirmations(chain consensus.ChainReader, head *types.Header, number uint64) ([]common.Address, error) {
+	snap, err := d.snapshot(chain, head.Number.Uint64(), head.Hash(), nil, nil, defaultLoopCntRecalculateSigners)
+	if err != nil {
+		return nil, err
+	}
+	return derefAddresses(snap.Confirmations[number]), nil
+}
+
+// MissedSigners implements consensus.DPoS, returning the signers recorded in the
+// header as having missed their slot.
+func (d *DPoS) MissedSigners(header *types.Header) ([]common.Address, error) {
+	if len(header.Extra) < extraVanity+extraSeal {
+		return nil, errMissingSignature
+	}
+	var extra HeaderExtra
+	if err := decodeHeaderExtra(header.Extra[extraVanity:len(header.Extra)-extraSeal], &extra); err != nil {
+		return nil, err
+	}
+	return extra.SignerMissing, nil
+}
+
+// Punishments implements consensus.DPoS, returning the punishment credits of the
+// snapshot at the given header.
+func (d *DPoS) Punishments(chain consensus.ChainReader, header *types.Header) (map[common.Address]uint64, error) {
+	snap, err := d.snapshot(chain, header.Number.Uint64(), header.Hash(), nil, nil, defaultLoopCntRecalculateSigners)
+	if err != nil {
+		return nil, err
+	}
+	punished := make(map[common.Address]uint64, len(snap.Punished))
+	for signer, credit := range snap.Punished {
+		punished[signer] = credit
+	}
+	return punished, nil
+}
+
+func derefAddresses(addrs []*common.Address) []common.Address {
+	list := make([]common.Address, 0, len(addrs))
+	for _, addr := range addrs {
+		if addr != nil {
+			list = append(list, *addr)
+		}
+	}
+	return list
+}
+
 // AccumulateRewards credits the coinbase of the given block with the mining reward.
 func accumulateRewards(config *params.ChainConfig, state *state.StateDB, header *types.Header, snap *Snapshot, refundGas RefundGas) error {
 	// Calculate the block reword by year
@@ -552,6 +552,39 @@ func (sb *backend) Stop() error {
 	return nil
 }
 
+// CurrentRound implements consensus.Istanbul.CurrentRound
+func (sb *backend) CurrentRound() *big.Int {
+	sb.coreMu.RLock()
+	defer sb.coreMu.RUnlock()
+	if !sb.coreStarted {
+		return nil
+	}
+	if view := sb.core.CurrentView(); view != nil {
+		return view.Round
+	}
+	return nil
+}
+
+// CurrentProposer implements consensus.Istanbul.CurrentProposer
+func (sb *backend) CurrentProposer() common.Address {
+	sb.coreMu.RLock()
+	defer sb.coreMu.RUnlock()
+	if !sb.coreStarted {
+		return common.Address{}
+	}
+	return sb.core.CurrentProposer()
+}
+
+// RoundChanges implements consensus.Istanbul.RoundChanges
+func (sb *backend) RoundChanges() uint64 {
+	sb.coreMu.RLock()
+	defer sb.coreMu.RUnlock()
+	if !sb.coreStarted {
+		return 0
+	}
+	return sb.core.RoundChanges()
+}
+
 // snapshot retrieves the authorization snapshot at a given point in time.
 func (sb *backend) snapshot(chain consensus.ChainReader, number uint64, hash common.Hash, parents []*types.Header) (*Snapshot, error) {
 	// Search for a snapshot in memory or on disk for checkpoints
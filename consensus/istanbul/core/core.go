@@ -21,6 +21,7 @@ import (
 	"math"
 	"math/big"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/simplechain-org/go-simplechain/common"
@@ -82,6 +83,8 @@ type core struct {
 	backlogsMu *sync.Mutex
 
 	current   *roundState
+	view      atomic.Value // Latest *istanbul.View, for readers outside the event loop
+	proposer  atomic.Value // Proposer of the latest round, for readers outside the event loop
 	handlerWg *sync.WaitGroup
 
 	roundChangeSet   *roundChangeSet
@@ -91,6 +94,8 @@ type core struct {
 	pendingRequestsMu *sync.Mutex
 
 	consensusTimestamp time.Time
+	// the number of round changes since the core started, accessed atomically
+	roundChanges uint64
 	// the meter to record the round change rate
 	roundMeter metrics.Meter
 	// the meter to record the sequence update rate
@@ -157,6 +162,32 @@ func (c *core) currentView() *istanbul.View {
 	}
 }
 
+// CurrentProposer returns the proposer of the current round, or the zero address
+// if the core hasn't started yet. It is safe for concurrent use.
+func (c *core) CurrentProposer() common.Address {
+	proposer, _ := c.proposer.Load().(common.Address)
+	return proposer
+}
+
+// RoundChanges returns the number of round changes since the core started. It
+// is safe for concurrent use.
+func (c *core) RoundChanges() uint64 {
+	return atomic.LoadUint64(&c.roundChanges)
+}
+
+// CurrentView returns the sequence and round the core is currently at, or nil if
+// it hasn't started yet. It is safe for concurrent use.
+func (c *core) CurrentView() *istanbul.View {
+	view, _ := c.view.Load().(*istanbul.View)
+	if view == nil {
+		return nil
+	}
+	return &istanbul.View{
+		Sequence: new(big.Int).Set(view.Sequence),
+		Round:    new(big.Int).Set(view.Round),
+	}
+}
+
 func (c *core) IsProposer() bool {
 	v := c.valSet
 	if v == nil {
@@ -247,6 +278,12 @@ func (c *core) startNewRound(round *big.Int) {
 	c.updateRoundState(newView, c.valSet, roundChange)
 	// Calculate new proposer
 	c.valSet.CalcProposer(lastProposer, newView.Round.Uint64())
+	if proposer := c.valSet.GetProposer(); proposer != nil {
+		c.proposer.Store(proposer.Address())
+	}
+	if roundChange {
+		atomic.AddUint64(&c.roundChanges, 1)
+	}
 	c.waitingForRoundChange = false
 	c.setState(StateAcceptRequest)
 	if roundChange && c.IsProposer() && c.current != nil {
@@ -271,6 +308,7 @@ func (c *core) catchUpRound(view *istanbul.View) {
 
 	if view.Round.Cmp(c.current.Round()) > 0 {
 		c.roundMeter.Mark(new(big.Int).Sub(view.Round, c.current.Round()).Int64())
+		atomic.AddUint64(&c.roundChanges, 1)
 	}
 	c.waitingForRoundChange = true
 
@@ -294,6 +332,7 @@ func (c *core) updateRoundState(view *istanbul.View, validatorSet istanbul.Valid
 	} else {
 		c.current = newRoundState(view, validatorSet, common.Hash{}, nil, nil, c.backend.HasBadProposal)
 	}
+	c.view.Store(c.currentView())
 }
 
 func (c *core) setState(state State) {
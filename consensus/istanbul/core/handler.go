@@ -74,6 +74,8 @@ func (c *core) handleEvents() {
 	// Clear state
 	defer func() {
 		c.current = nil
+		c.view.Store((*istanbul.View)(nil))
+		c.proposer.Store(common.Address{})
 		c.handlerWg.Done()
 	}()
 
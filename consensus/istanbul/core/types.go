@@ -22,6 +22,7 @@ import (
 	"io"
 
 	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/consensus/istanbul"
 	"github.com/simplechain-org/go-simplechain/rlp"
 )
 
@@ -31,6 +32,15 @@ type Engine interface {
 
 	IsProposer() bool
 
+	// CurrentView returns the sequence and round of the running consensus.
+	CurrentView() *istanbul.View
+
+	// CurrentProposer returns the proposer of the current round.
+	CurrentProposer() common.Address
+
+	// RoundChanges returns the number of round changes since the engine started.
+	RoundChanges() uint64
+
 	// verify if a hash is the same as the proposed block in the current pending request
 	//
 	// this is useful when the engine is currently the proposer
@@ -25,14 +25,7 @@ func NewPublicRaftAPI(raftService *RaftService) *PublicRaftAPI {
 }
 
 func (s *PublicRaftAPI) Role() string {
-	if err := s.checkIfNodeInCluster(); err != nil {
-		return ""
-	}
-	_, err := s.raftService.raftProtocolManager.LeaderAddress()
-	if err != nil {
-		return ""
-	}
-	return s.raftService.raftProtocolManager.NodeInfo().Role
+	return s.raftService.Role()
 }
 
 // helper function to check if self node is part of cluster
@@ -82,6 +82,25 @@ func (service *RaftService) ChainDb() ethdb.Database           { return service.
 func (service *RaftService) EventMux() *event.TypeMux          { return service.eventMux }
 func (service *RaftService) TxPool() *core.TxPool              { return service.txPool }
 
+// Role returns the role of the node in the raft cluster, or an empty string if
+// it isn't part of it or there is no leader.
+func (service *RaftService) Role() string {
+	pm := service.raftProtocolManager
+	if pm.IsIDRemoved(uint64(pm.raftId)) {
+		return ""
+	}
+	if _, err := pm.LeaderAddress(); err != nil {
+		return ""
+	}
+	return pm.NodeInfo().Role
+}
+
+// Status returns the raft id of the leader of the cluster (0 if none is elected),
+// the current term and the index of the last-applied raft entry.
+func (service *RaftService) Status() (leader uint16, term uint64, applied uint64) {
+	return service.raftProtocolManager.Status()
+}
+
 // node.Service interface methods:
 
 func (service *RaftService) Protocols() []p2p.Protocol { return []p2p.Protocol{} }
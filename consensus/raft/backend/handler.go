@@ -52,6 +52,7 @@ type ProtocolManager struct {
 	role          int    // Role: minter or verifier
 	appliedIndex  uint64 // The index of the last-applied raft entry
 	snapshotIndex uint64 // The index of the latest snapshot.
+	term          uint64 // The current raft term
 
 	// Remote peer state (protected by mu vs concurrent access via JS)
 	leader       uint16
@@ -838,6 +839,9 @@ func (pm *ProtocolManager) eventLoop() {
 			if rd.SoftState != nil {
 				pm.updateLeader(rd.SoftState.Lead)
 			}
+			if !etcdRaft.IsEmptyHardState(rd.HardState) {
+				pm.updateTerm(rd.HardState.Term)
+			}
 
 			if snap := rd.Snapshot; !etcdRaft.IsEmptySnap(snap) {
 				pm.saveRaftSnapshot(snap)
@@ -1056,6 +1060,22 @@ func (pm *ProtocolManager) updateLeader(leader uint64) {
 	pm.leader = uint16(leader)
 }
 
+func (pm *ProtocolManager) updateTerm(term uint64) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pm.term = term
+}
+
+// Status returns the raft id of the current leader (0 if none is elected), the
+// current term and the index of the last-applied raft entry.
+func (pm *ProtocolManager) Status() (uint16, uint64, uint64) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	return pm.leader, pm.term, pm.appliedIndex
+}
+
 // The Address for the current leader, or an error if no leader is elected.
 func (pm *ProtocolManager) LeaderAddress() (*Address, error) {
 	pm.mu.RLock()
@@ -0,0 +1,62 @@
+pragma solidity ^0.5.10;
+
+/**
+ * @title AccountPermission
+ * @dev Roles of the accounts allowed to transact on a permissioned chain. The contract
+ * is deployed in genesis and referenced by the accountPermission.contract field of
+ * the chain config, nodes read slot 0 (the role mapping) directly from state, so
+ * the storage layout must not be changed. At least one admin must be allocated in genesis.
+ */
+contract AccountPermission {
+    /*
+        Events
+    */
+
+    // RoleUpdated is emitted when the roles of an account are changed.
+    event RoleUpdated(address indexed account, uint256 roles);
+
+    /*
+        Constants
+    */
+
+    uint256 constant CAN_TRANSACT = 1;
+    uint256 constant CAN_DEPLOY = 2;
+    uint256 constant ADMIN = 4;
+
+    /*
+        Public Functions
+    */
+
+    /**
+     * @dev Get roles of an account.
+     * @return bit set of roles, 1 = can transact, 2 = can deploy, 4 = admin
+     */
+    function getRoles(address account)
+    view
+    external
+    returns(uint256) {
+        return roles[account];
+    }
+
+    function setRoles(address account, uint256 role)
+    external
+    onlyAdmin {
+        require(role <= (CAN_TRANSACT | CAN_DEPLOY | ADMIN), "invalid role");
+        require(account != msg.sender, "admin could not update itself");
+        roles[account] = role;
+        emit RoleUpdated(account, role);
+    }
+
+    /*
+        Modifiers
+    */
+    modifier onlyAdmin {
+        require(roles[msg.sender] & ADMIN != 0, "only admin");
+        _;
+    }
+
+    /*
+        Fields
+    */
+    mapping(address => uint256) roles;
+}
@@ -0,0 +1,103 @@
+pragma solidity ^0.5.10;
+
+/**
+ * @title NodePermission
+ * @dev Registry of the nodes allowed to join a permissioned network. The contract is
+ * deployed in genesis, so its code and storage are put into the genesis alloc:
+ * slot 0 is the node mapping, slot 1 is the organization mapping and slot 2 is
+ * the admin mapping, at least one admin must be allocated in genesis.
+ */
+contract NodePermission {
+    /*
+        Events
+    */
+
+    // NodeUpdated is emitted when a node is added, revoked or moved to another organization.
+    event NodeUpdated(bytes32 indexed id, bool allowed, bytes32 org, uint8 role);
+
+    // OrgUpdated is emitted when an organization is activated or suspended.
+    event OrgUpdated(bytes32 indexed org, bool active);
+
+    // AdminUpdated is emitted when an admin is added or removed.
+    event AdminUpdated(address indexed admin, bool active);
+
+    /*
+        Public Functions
+    */
+
+    /**
+     * @dev Get permission of a node.
+     * @param id keccak256 hash of the node public key (enode ID)
+     * @return whether the node is allowed
+     * @return organization of the node
+     * @return role of the node, 0 = none, 1 = observer, 2 = peer, 3 = validator
+     */
+    function getNode(bytes32 id)
+    view
+    external
+    returns(bool, bytes32, uint8) {
+        Node memory node = nodes[id];
+        return (node.allowed, node.org, node.role);
+    }
+
+    /**
+     * @dev Get whether an organization is active, nodes of suspended organizations are not allowed.
+     */
+    function isOrgActive(bytes32 org)
+    view
+    external
+    returns(bool) {
+        return orgs[org];
+    }
+
+    function isAdmin(address admin)
+    view
+    external
+    returns(bool) {
+        return admins[admin];
+    }
+
+    function setNode(bytes32 id, bool allowed, bytes32 org, uint8 role)
+    external
+    onlyAdmin {
+        require(role <= 3, "invalid role");
+        nodes[id] = Node(allowed, org, role);
+        emit NodeUpdated(id, allowed, org, role);
+    }
+
+    function setOrg(bytes32 org, bool active)
+    external
+    onlyAdmin {
+        orgs[org] = active;
+        emit OrgUpdated(org, active);
+    }
+
+    function setAdmin(address admin, bool active)
+    external
+    onlyAdmin {
+        require(admin != msg.sender, "admin could not update itself");
+        admins[admin] = active;
+        emit AdminUpdated(admin, active);
+    }
+
+    /*
+        Modifiers
+    */
+    modifier onlyAdmin {
+        require(admins[msg.sender], "only admin");
+        _;
+    }
+
+    /*
+        Fields
+    */
+    struct Node {
+        bool allowed;
+        bytes32 org;
+        uint8 role;
+    }
+
+    mapping(bytes32 => Node) nodes;
+    mapping(bytes32 => bool) orgs;
+    mapping(address => bool) admins;
+}
@@ -0,0 +1,231 @@
+// Copyright 2019 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package permission is a Go wrapper around the on-chain node permission contract,
+// which decides the nodes allowed to join a permissioned p2p network.
+package permission
+
+//go:generate abigen --abi contract/permission.abi --pkg contract --type NodePermission --out contract/permission.go
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/simplechain-org/go-simplechain"
+	"github.com/simplechain-org/go-simplechain/accounts/abi/bind"
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/contracts/permission/contract"
+	"github.com/simplechain-org/go-simplechain/core"
+	"github.com/simplechain-org/go-simplechain/core/state"
+	"github.com/simplechain-org/go-simplechain/core/types"
+	"github.com/simplechain-org/go-simplechain/core/vm"
+	"github.com/simplechain-org/go-simplechain/event"
+	"github.com/simplechain-org/go-simplechain/log"
+	"github.com/simplechain-org/go-simplechain/p2p"
+	"github.com/simplechain-org/go-simplechain/p2p/enode"
+	"github.com/simplechain-org/go-simplechain/params"
+)
+
+// callGas is the gas allowance of a permission contract call
+const callGas = 1000000
+
+var errNoContract = errors.New("no permission contract code")
+
+// Role is the role of a node in the permissioned network
+type Role uint8
+
+const (
+	RoleNone      Role = iota // node is not registered
+	RoleObserver              // node syncs the chain only
+	RolePeer                  // node joins the network as a regular peer
+	RoleValidator             // node takes part in the consensus
+)
+
+func (r Role) String() string {
+	switch r {
+	case RoleNone:
+		return "none"
+	case RoleObserver:
+		return "observer"
+	case RolePeer:
+		return "peer"
+	case RoleValidator:
+		return "validator"
+	default:
+		return "unknown"
+	}
+}
+
+// Node is the permission of a node read from the contract
+type Node struct {
+	ID        enode.ID    `json:"id"`
+	Allowed   bool        `json:"allowed"`
+	Org       common.Hash `json:"org"`
+	OrgActive bool        `json:"orgActive"`
+	Role      Role        `json:"role"`
+}
+
+// Permitted reports whether the node is allowed to join the network, the node
+// must be allowed, have a role and belong to an active organization.
+func (n *Node) Permitted() bool {
+	return n.Allowed && n.OrgActive && n.Role != RoleNone
+}
+
+// Chain is the local blockchain the permission contract is read from
+type Chain interface {
+	core.ChainContext
+	Config() *params.ChainConfig
+	CurrentBlock() *types.Block
+	StateAt(root common.Hash) (*state.StateDB, error)
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+}
+
+// Permission reads node permissions from the contract at the head of local chain.
+// It implements p2p.NodePermission, and drops the revoked peers on every new head.
+type Permission struct {
+	chain    Chain
+	address  common.Address
+	contract *contract.NodePermissionCaller
+
+	nodes map[enode.ID]*Node // node permissions read at current head
+	head  common.Hash
+	mu    sync.Mutex
+
+	server *p2p.Server
+	remove func() // Removes the permission from the server
+	quit   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func NewPermission(chain Chain, address common.Address) (*Permission, error) {
+	c, err := contract.NewNodePermissionCaller(address, &chainCaller{chain: chain})
+	if err != nil {
+		return nil, err
+	}
+	return &Permission{
+		chain:    chain,
+		address:  address,
+		contract: c,
+		nodes:    make(map[enode.ID]*Node),
+		quit:     make(chan struct{}),
+	}, nil
+}
+
+// Start enforces the permission on the given protocols of p2p server
+func (p *Permission) Start(server *p2p.Server, protocols ...string) {
+	p.server = server
+	p.remove = server.AddNodePermission(p, protocols...)
+	p.wg.Add(1)
+	go p.loop()
+	log.Info("Node permissioning started", "contract", p.address)
+}
+
+func (p *Permission) Stop() {
+	close(p.quit)
+	p.wg.Wait()
+	if p.remove != nil {
+		p.remove()
+	}
+}
+
+func (p *Permission) loop() {
+	defer p.wg.Done()
+
+	heads := make(chan core.ChainHeadEvent, 16)
+	sub := p.chain.SubscribeChainHeadEvent(heads)
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case <-heads:
+			// permissions may be changed in the new block
+			if dropped := p.server.CheckPermissions(); dropped > 0 {
+				log.Info("Dropped revoked peers", "count", dropped)
+			}
+		case <-sub.Err():
+			return
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+// IsNodePermitted implements p2p.NodePermission, nodes are not permitted if the contract is unavailable
+func (p *Permission) IsNodePermitted(node *enode.Node) bool {
+	n, err := p.Node(node.ID())
+	if err != nil {
+		log.Warn("Failed to read node permission", "id", node.ID(), "err", err)
+		return false
+	}
+	return n.Permitted()
+}
+
+// Node reads the permission of node at the head of local chain
+func (p *Permission) Node(id enode.ID) (*Node, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if head := p.chain.CurrentBlock().Hash(); head != p.head {
+		p.nodes, p.head = make(map[enode.ID]*Node), head
+	}
+	if n, ok := p.nodes[id]; ok {
+		return n, nil
+	}
+
+	opts := &bind.CallOpts{Context: context.Background()}
+	allowed, org, role, err := p.contract.GetNode(opts, id)
+	if err != nil {
+		return nil, err
+	}
+	n := &Node{ID: id, Allowed: allowed, Org: org, Role: Role(role)}
+	if n.Allowed {
+		if n.OrgActive, err = p.contract.IsOrgActive(opts, org); err != nil {
+			return nil, err
+		}
+	}
+	p.nodes[id] = n
+	return n, nil
+}
+
+// chainCaller executes read-only contract calls on the head state of local chain
+type chainCaller struct {
+	chain Chain
+}
+
+func (c *chainCaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	statedb, err := c.chain.StateAt(c.chain.CurrentBlock().Root())
+	if err != nil {
+		return nil, err
+	}
+	return statedb.GetCode(contract), nil
+}
+
+func (c *chainCaller) CallContract(ctx context.Context, call simplechain.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	block := c.chain.CurrentBlock()
+	statedb, err := c.chain.StateAt(block.Root())
+	if err != nil {
+		return nil, err
+	}
+	if len(statedb.GetCode(*call.To)) == 0 {
+		return nil, errNoContract
+	}
+	msg := types.NewMessage(call.From, call.To, 0, new(big.Int), callGas, new(big.Int), call.Data, nil, false)
+	evm := vm.NewEVM(core.NewEVMContext(msg, block.Header(), c.chain, &common.Address{}), statedb, c.chain.Config(), vm.Config{})
+	ret, _, err := evm.StaticCall(vm.AccountRef(call.From), *call.To, call.Data, callGas)
+	return ret, err
+}
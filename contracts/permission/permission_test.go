@@ -0,0 +1,122 @@
+// Copyright 2019 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package permission
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/consensus/ethash"
+	"github.com/simplechain-org/go-simplechain/core"
+	"github.com/simplechain-org/go-simplechain/core/rawdb"
+	"github.com/simplechain-org/go-simplechain/core/vm"
+	"github.com/simplechain-org/go-simplechain/crypto"
+	"github.com/simplechain-org/go-simplechain/p2p/enode"
+	"github.com/simplechain-org/go-simplechain/params"
+)
+
+// storageCode answers every call with the storage slots (key, key+1, key+2), where key is the
+// first call argument, so that getNode and isOrgActive can be mocked by genesis storage.
+var storageCode = common.Hex2Bytes("600435805460005280600101546020526002015460405260606000f3")
+
+func nodeStorage(storage map[common.Hash]common.Hash, id enode.ID, allowed bool, org common.Hash, role Role) {
+	key := new(big.Int).SetBytes(id[:])
+	if allowed {
+		storage[common.Hash(id)] = common.BigToHash(common.Big1)
+	}
+	storage[common.BigToHash(new(big.Int).Add(key, common.Big1))] = org
+	storage[common.BigToHash(new(big.Int).Add(key, common.Big2))] = common.BigToHash(big.NewInt(int64(role)))
+}
+
+func newTestNode(t *testing.T) *enode.Node {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return enode.NewV4(&key.PublicKey, nil, 0, 0)
+}
+
+func TestPermission(t *testing.T) {
+	var (
+		contract   = common.HexToAddress("0x0000000000000000000000000000000000009999")
+		activeOrg  = common.HexToHash("0x01")
+		pausedOrg  = common.HexToHash("0x02")
+		peer       = newTestNode(t)
+		validator  = newTestNode(t)
+		revoked    = newTestNode(t)
+		suspended  = newTestNode(t)
+		noRole     = newTestNode(t)
+		unknown    = newTestNode(t)
+		storage    = make(map[common.Hash]common.Hash)
+		db         = rawdb.NewMemoryDatabase()
+		permission *Permission
+	)
+	storage[activeOrg] = common.BigToHash(common.Big1)
+	nodeStorage(storage, peer.ID(), true, activeOrg, RolePeer)
+	nodeStorage(storage, validator.ID(), true, activeOrg, RoleValidator)
+	nodeStorage(storage, revoked.ID(), false, activeOrg, RolePeer)
+	nodeStorage(storage, suspended.ID(), true, pausedOrg, RolePeer)
+	nodeStorage(storage, noRole.ID(), true, activeOrg, RoleNone)
+
+	gspec := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc:  core.GenesisAlloc{contract: {Balance: new(big.Int), Code: storageCode, Storage: storage}},
+	}
+	gspec.MustCommit(db)
+	chain, _ := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	defer chain.Stop()
+
+	permission, err := NewPermission(chain, contract)
+	if err != nil {
+		t.Fatalf("failed to create permission: %v", err)
+	}
+
+	tests := []struct {
+		node      *enode.Node
+		role      Role
+		permitted bool
+	}{
+		{peer, RolePeer, true},
+		{validator, RoleValidator, true},
+		{revoked, RolePeer, false},
+		{suspended, RolePeer, false},
+		{noRole, RoleNone, false},
+		{unknown, RoleNone, false},
+	}
+	for i, test := range tests {
+		n, err := permission.Node(test.node.ID())
+		if err != nil {
+			t.Fatalf("test %d: failed to read node: %v", i, err)
+		}
+		if n.Role != test.role {
+			t.Errorf("test %d: role mismatch: have %v, want %v", i, n.Role, test.role)
+		}
+		if permitted := permission.IsNodePermitted(test.node); permitted != test.permitted {
+			t.Errorf("test %d: permitted mismatch: have %v, want %v", i, permitted, test.permitted)
+		}
+	}
+
+	// nodes are not permitted without the contract
+	permission, _ = NewPermission(chain, common.HexToAddress("0x1"))
+	if _, err := permission.Node(peer.ID()); err != errNoContract {
+		t.Errorf("error mismatch: have %v, want %v", err, errNoContract)
+	}
+	if permission.IsNodePermitted(peer) {
+		t.Error("node is permitted without contract")
+	}
+}
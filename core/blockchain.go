@@ -34,6 +34,7 @@ import (
 	"github.com/simplechain-org/go-simplechain/consensus"
 	"github.com/simplechain-org/go-simplechain/core/rawdb"
 	"github.com/simplechain-org/go-simplechain/core/state"
+	"github.com/simplechain-org/go-simplechain/core/state/snapshot"
 	"github.com/simplechain-org/go-simplechain/core/types"
 	"github.com/simplechain-org/go-simplechain/core/vm"
 	"github.com/simplechain-org/go-simplechain/ethdb"
@@ -41,6 +42,7 @@ import (
 	"github.com/simplechain-org/go-simplechain/log"
 	"github.com/simplechain-org/go-simplechain/metrics"
 	"github.com/simplechain-org/go-simplechain/params"
+	"github.com/simplechain-org/go-simplechain/private"
 	"github.com/simplechain-org/go-simplechain/rlp"
 	"github.com/simplechain-org/go-simplechain/trie"
 
@@ -117,6 +119,8 @@ type CacheConfig struct {
 	TrieDirtyLimit      int           // Memory limit (MB) at which to start flushing dirty trie nodes to disk
 	TrieDirtyDisabled   bool          // Whether to disable trie write caching and GC altogether (archive node)
 	TrieTimeLimit       time.Duration // Time limit after which to flush the current in-memory trie to disk
+	SnapshotLimit       int           // Memory allowance (MB) to use for caching snapshot entries in memory
+	SnapshotWait        bool          // Wait for snapshot construction on startup
 }
 
 // BlockChain represents the canonical chain given a database with a genesis
@@ -157,6 +161,7 @@ type BlockChain struct {
 	currentFastBlock atomic.Value // Current head of the fast-sync chain (may be above the block chain!)
 
 	stateCache    state.Database // State database to reuse between imports (contains state cache)
+	snaps         *snapshot.Tree // Snapshot tree for fast trie leaf access
 	bodyCache     *lru.Cache     // Cache for the most recent block bodies
 	bodyRLPCache  *lru.Cache     // Cache for the most recent block bodies in RLP encoded format
 	receiptsCache *lru.Cache     // Cache for the most recent receipts per block
@@ -180,6 +185,7 @@ type BlockChain struct {
 	shouldPreserve  func(*types.Block) bool        // Function used to determine whether should preserve the given block.
 	terminateInsert func(common.Hash, uint64) bool // Testing hook used to terminate ancient receipt chain insertion.
 	crossSubscriber simpleSubscriber
+	privateManager  private.TransactionManager // Private transaction manager, nil if the node takes part in no private transaction
 }
 
 // NewBlockChain returns a fully initialised block chain using information
@@ -191,6 +197,8 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 			TrieCleanLimit: 256,
 			TrieDirtyLimit: 256,
 			TrieTimeLimit:  5 * time.Minute,
+			SnapshotLimit:  256,
+			SnapshotWait:   true,
 		}
 	}
 	bodyCache, _ := lru.New(bodyCacheLimit)
@@ -296,6 +304,10 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 			}
 		}
 	}
+	// Load any existing snapshot, regenerating it if loading failed
+	if bc.cacheConfig.SnapshotLimit > 0 {
+		bc.snaps = snapshot.New(bc.db, bc.stateCache.TrieDB(), bc.cacheConfig.SnapshotLimit, bc.CurrentBlock().Root(), !bc.cacheConfig.SnapshotWait)
+	}
 	// Take ownership of this particular state
 	go bc.update()
 
@@ -460,7 +472,14 @@ func (bc *BlockChain) SetHead(head uint64) error {
 	bc.txLookupCache.Purge()
 	bc.futureBlocks.Purge()
 
-	return bc.loadLastState()
+	if err := bc.loadLastState(); err != nil {
+		return err
+	}
+	// The snapshot can't be rewound, regenerate it for the new head
+	if bc.snaps != nil {
+		bc.snaps.Rebuild(bc.CurrentBlock().Root())
+	}
+	return nil
 }
 
 // FastSyncCommitHead sets the current head block to the one defined by the hash
@@ -480,6 +499,11 @@ func (bc *BlockChain) FastSyncCommitHead(hash common.Hash) error {
 	headBlockGauge.Update(int64(block.NumberU64()))
 	bc.chainmu.Unlock()
 
+	// Destroy any existing state snapshot and regenerate it in the background
+	if bc.snaps != nil {
+		bc.snaps.Rebuild(block.Root())
+	}
+
 	log.Info("Committed new head block", "number", block.Number(), "hash", hash)
 	return nil
 }
@@ -518,7 +542,19 @@ func (bc *BlockChain) State() (*state.StateDB, error) {
 
 // StateAt returns a new mutable state based on a particular point in time.
 func (bc *BlockChain) StateAt(root common.Hash) (*state.StateDB, error) {
-	return state.New(root, bc.stateCache)
+	return state.NewWithSnapshot(root, bc.stateCache, bc.snaps)
+}
+
+// PrivateStateAt returns a new mutable private state after the given block. Blocks
+// written without a private transaction manager have an empty private state.
+func (bc *BlockChain) PrivateStateAt(hash common.Hash) (*state.StateDB, error) {
+	return state.New(rawdb.ReadPrivateStateRoot(bc.db, hash), bc.stateCache)
+}
+
+// GetPrivateReceipt retrieves the receipt of a private transaction executed in
+// the block, nil if the node doesn't take part in it.
+func (bc *BlockChain) GetPrivateReceipt(blockHash, txHash common.Hash) *types.Receipt {
+	return rawdb.ReadPrivateReceipt(bc.db, blockHash, txHash)
 }
 
 // StateCache returns the caching database underpinning the blockchain instance.
@@ -833,6 +869,14 @@ func (bc *BlockChain) Stop() {
 		bc.crossSubscriber.Stop()
 	}
 
+	// Persist the in-memory snapshot layers so they don't need regenerating
+	var snapBase common.Hash
+	if bc.snaps != nil {
+		var err error
+		if snapBase, err = bc.snaps.Journal(bc.CurrentBlock().Root()); err != nil {
+			log.Error("Failed to journal state snapshot", "err", err)
+		}
+	}
 	// Ensure the state of a recent block is also stored to disk before exiting.
 	// We're writing three different states to catch different restart scenarios:
 	//  - HEAD:     So we don't need to reprocess any blocks in the general case
@@ -851,6 +895,13 @@ func (bc *BlockChain) Stop() {
 				}
 			}
 		}
+		// The snapshot base is needed to resume an interrupted generation
+		if snapBase != (common.Hash{}) {
+			log.Info("Writing snapshot state to disk", "root", snapBase)
+			if err := triedb.Commit(snapBase, true); err != nil {
+				log.Error("Failed to commit recent state trie", "err", err)
+			}
+		}
 		for !bc.triegc.Empty() {
 			triedb.Dereference(bc.triegc.PopItem().(common.Hash))
 		}
@@ -1286,6 +1337,34 @@ func (bc *BlockChain) isRaft() bool {
 	return bc.chainConfig.Raft && bc.chainConfig.Istanbul == nil && bc.chainConfig.Clique == nil
 }
 
+// writePrivateState runs the private transactions of the block on top of the
+// private state of its parent, and stores the resulting private state and
+// receipts. Private states are always flushed, they can't be regenerated from
+// the chain by nodes that lost them.
+func (bc *BlockChain) writePrivateState(block *types.Block) error {
+	if bc.privateManager == nil || !bc.chainConfig.Privacy {
+		return nil
+	}
+	privateState, err := bc.PrivateStateAt(block.ParentHash())
+	if err != nil {
+		return err
+	}
+	receipts, err := bc.processor.ProcessPrivate(block, privateState, bc.privateManager, bc.vmConfig)
+	if err != nil {
+		return err
+	}
+	root, err := privateState.Commit(true)
+	if err != nil {
+		return err
+	}
+	if err := bc.stateCache.TrieDB().Commit(root, false); err != nil {
+		return err
+	}
+	rawdb.WritePrivateStateRoot(bc.db, block.Hash(), root)
+	rawdb.WritePrivateReceipts(bc.db, block.Hash(), receipts)
+	return nil
+}
+
 // writeBlockWithState writes the block and all associated state to the database,
 // but is expects the chain mutex to be held.
 func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.Receipt, logs []*types.Log, state *state.StateDB, emitHeadEvent bool) (status WriteStatus, err error) {
@@ -1308,6 +1387,9 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	}
 	rawdb.WriteBlock(bc.db, block)
 
+	if err := bc.writePrivateState(block); err != nil {
+		return NonStatTy, err
+	}
 	root, err := state.Commit(true)
 	if err != nil {
 		return NonStatTy, err
@@ -1652,7 +1734,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 		if parent == nil {
 			parent = bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
 		}
-		statedb, err := state.New(parent.Root, bc.stateCache)
+		statedb, err := state.NewWithSnapshot(parent.Root, bc.stateCache, bc.snaps)
 		if err != nil {
 			return it.index, err
 		}
@@ -1663,7 +1745,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 		if !bc.cacheConfig.TrieCleanNoPrefetch {
 			if followup, err := it.peek(); followup != nil && err == nil {
 				go func(start time.Time) {
-					throwaway, _ := state.New(parent.Root, bc.stateCache)
+					throwaway, _ := state.NewWithSnapshot(parent.Root, bc.stateCache, bc.snaps)
 					bc.prefetcher.Prefetch(followup, throwaway, bc.vmConfig, &followupInterrupt)
 
 					blockPrefetchExecuteTimer.Update(time.Since(start))
@@ -1906,7 +1988,7 @@ func (bc *BlockChain) insertSideChain(block *types.Block, it *insertIterator) (i
 // blocks and inserts them to be part of the new canonical chain and accumulates
 // potential missing transactions and post an event about them.
 func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
-	reorgNumber := newBlock.Number()
+	reorgNumber, headRoot := newBlock.Number(), newBlock.Root()
 	var (
 		newChain    types.Blocks
 		oldChain    types.Blocks
@@ -2072,6 +2154,11 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 			bc.chainSideFeed.Send(ChainSideEvent{Block: oldChain[i]})
 		}
 	}
+	// A reorg deeper than the retained diff layers leaves the new head without
+	// a snapshot, regenerate it so reads stay accelerated
+	if bc.snaps != nil && bc.snaps.Snapshot(headRoot) == nil {
+		bc.snaps.Rebuild(headRoot)
+	}
 	return nil
 }
 
@@ -2305,3 +2392,10 @@ type simpleSubscriber interface {
 func (bc *BlockChain) SetCrossSubscriber(s trigger.Subscriber) {
 	bc.crossSubscriber = s.(simpleSubscriber) // panic if failed
 }
+
+// SetPrivateManager sets the transaction manager resolving the payloads of the
+// private transactions the node takes part in. It must be set before any block
+// is imported.
+func (bc *BlockChain) SetPrivateManager(manager private.TransactionManager) {
+	bc.privateManager = manager
+}
@@ -17,6 +17,7 @@
 package core
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"math/big"
@@ -37,6 +38,7 @@ import (
 	"github.com/simplechain-org/go-simplechain/crypto"
 	"github.com/simplechain-org/go-simplechain/ethdb"
 	"github.com/simplechain-org/go-simplechain/params"
+	"github.com/simplechain-org/go-simplechain/private"
 )
 
 // So we can deterministically seed different blockchains
@@ -2196,3 +2198,269 @@ func TestDeleteCreateRevert(t *testing.T) {
 func TestStoreContractLog(t *testing.T) {
 	//todo
 }
+
+// Tests that private transactions only run against the private state of their
+// participants, while the public state just sees the nonce consumed.
+func TestPrivateTransactions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "private-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		config  = *params.TestChainConfig
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: &config, Alloc: GenesisAlloc{addr: {Balance: big.NewInt(10000000000000)}}}
+		genesis = gspec.MustCommit(db)
+
+		// PUSH1 42 PUSH1 0 SSTORE
+		code     = common.Hex2Bytes("602a600055")
+		contract = crypto.CreateAddress(addr, 0)
+	)
+	config.Privacy = true
+
+	sender, _ := private.NewLocalManager(dir, "alice")
+	hash, err := sender.Send(code, "", []string{"bob"})
+	if err != nil {
+		t.Fatalf("failed to send private payload: %v", err)
+	}
+	chain, _ := GenerateChain(&config, genesis, ethash.NewFaker(), db, 1, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewContractCreation(gen.TxNonce(addr), new(big.Int), 100000, new(big.Int), hash.Bytes()), types.HomesteadSigner{}, key)
+		gen.AddTx(tx.AsPrivate())
+	})
+	block := chain[0]
+
+	for _, identity := range []string{"bob", "carol"} {
+		db := rawdb.NewMemoryDatabase()
+		gspec.MustCommit(db)
+
+		blockchain, _ := NewBlockChain(db, nil, &config, ethash.NewFaker(), vm.Config{}, nil)
+		manager, _ := private.NewLocalManager(dir, identity)
+		blockchain.SetPrivateManager(manager)
+
+		if _, err := blockchain.InsertChain(chain); err != nil {
+			t.Fatalf("%s: failed to insert chain: %v", identity, err)
+		}
+		public, _ := blockchain.State()
+		if public.GetNonce(addr) != 1 {
+			t.Errorf("%s: public nonce mismatch: have %d, want 1", identity, public.GetNonce(addr))
+		}
+		if value := public.GetState(contract, common.Hash{}); value != (common.Hash{}) {
+			t.Errorf("%s: private payload ran against public state: %x", identity, value)
+		}
+		privateState, err := blockchain.PrivateStateAt(block.Hash())
+		if err != nil {
+			t.Fatalf("%s: failed to open private state: %v", identity, err)
+		}
+		receipt := blockchain.GetPrivateReceipt(block.Hash(), block.Transactions()[0].Hash())
+
+		want := common.Hash{}
+		if identity == "bob" {
+			want = common.BigToHash(big.NewInt(42))
+			if receipt == nil || receipt.Status != types.ReceiptStatusSuccessful {
+				t.Errorf("%s: private receipt mismatch: %v", identity, receipt)
+			}
+		} else if receipt != nil {
+			t.Errorf("%s: private receipt of foreign transaction: %v", identity, receipt)
+		}
+		if value := privateState.GetState(contract, common.Hash{}); value != want {
+			t.Errorf("%s: private storage mismatch: have %x, want %x", identity, value, want)
+		}
+		blockchain.Stop()
+	}	// Private transactions breaking the rules must not be processed, even if a
+	// miner put them into a block
+	statedb, _ := state.New(genesis.Root(), state.NewDatabase(db))
+	invalid := func(value int64, data []byte) *types.Transaction {
+		tx, _ := types.SignTx(types.NewTransaction(0, addr, big.NewInt(value), 100000, new(big.Int), data), types.HomesteadSigner{}, key)
+		return tx.AsPrivate()
+	}
+	for _, test := range []struct {
+		config *params.ChainConfig
+		tx     *types.Transaction
+		err    error
+	}{
+		{params.TestChainConfig, invalid(0, hash.Bytes()), ErrPrivateNotSupported},
+		{&config, invalid(0, code), ErrPrivatePayload},
+		{&config, invalid(1, hash.Bytes()), ErrPrivateValue},
+	} {
+		_, err := ApplyTransaction(test.config, nil, &addr, new(GasPool).AddGas(100000), statedb, block.Header(), test.tx, new(uint64), vm.Config{})
+		if err != test.err {
+			t.Errorf("invalid private transaction error mismatch: have %v, want %v", err, test.err)
+		}
+	}
+}
+
+// Tests that the state snapshot follows the chain head on import and is
+// restored from the journal after a restart.
+func TestSnapshotMaintenance(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig, Alloc: GenesisAlloc{addr: {Balance: big.NewInt(10000000000000)}}}
+		genesis = gspec.MustCommit(db)
+		signer  = types.NewEIP155Signer(gspec.Config.ChainID)
+
+		// PUSH1 42 PUSH1 0 SSTORE
+		code     = common.Hex2Bytes("602a600055")
+		contract = crypto.CreateAddress(addr, 0)
+	)
+	chain, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 3, func(i int, gen *BlockGen) {
+		var tx *types.Transaction
+		if i == 0 {
+			tx, _ = types.SignTx(types.NewContractCreation(gen.TxNonce(addr), new(big.Int), 100000, new(big.Int), code), signer, key)
+		} else {
+			tx, _ = types.SignTx(types.NewTransaction(gen.TxNonce(addr), common.Address{0x01}, big.NewInt(1000), params.TxGas, new(big.Int), nil), signer, key)
+		}
+		gen.AddTx(tx)
+	})
+	blockchain, _ := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	check := func(bc *BlockChain) {
+		snap := bc.snaps.Snapshot(bc.CurrentBlock().Root())
+		if snap == nil {
+			t.Fatalf("no snapshot for head %d", bc.CurrentBlock().NumberU64())
+		}
+		account, err := snap.Account(crypto.Keccak256Hash(addr[:]))
+		if err != nil || account == nil || account.Nonce != 3 {
+			t.Fatalf("snapshot account mismatch: %+v, err %v", account, err)
+		}
+		slot, err := snap.Storage(crypto.Keccak256Hash(contract[:]), crypto.Keccak256Hash(common.Hash{}.Bytes()))
+		if err != nil || !bytes.Equal(slot, []byte{42}) {
+			t.Fatalf("snapshot slot mismatch: %x, err %v", slot, err)
+		}
+		statedb, _ := bc.State()
+		if value := statedb.GetState(contract, common.Hash{}); value != common.BigToHash(big.NewInt(42)) {
+			t.Fatalf("state slot mismatch: %x", value)
+		}
+	}
+	check(blockchain)
+	blockchain.Stop()
+
+	// Restart the chain and ensure the diff layers were journalled
+	blockchain, _ = NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	defer blockchain.Stop()
+
+	if blockchain.snaps.Snapshot(chain[1].Root()) == nil {
+		t.Fatalf("diff layer not restored from journal")
+	}
+	check(blockchain)
+}
+
+// Tests that access list transactions are processed after the access list fork,
+// with typed receipts and access list gas, and rejected before it.
+func TestAccessListTransactions(t *testing.T) {
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		db     = rawdb.NewMemoryDatabase()
+		config = *params.TestChainConfig
+	)
+	config.AccessListBlock = big.NewInt(1)
+	config.TxExpiryBlock = big.NewInt(1)
+
+	gspec := &Genesis{Config: &config, Alloc: GenesisAlloc{addr: {Balance: big.NewInt(10000000000000)}}}
+	genesis := gspec.MustCommit(db)
+	signer := types.MakeSigner(&config)
+
+	tx, err := types.SignNewTx(key, signer, &types.AccessListTx{
+		ChainID:    config.ChainID,
+		Nonce:      0,
+		To:         &common.Address{0x01},
+		Value:      big.NewInt(1000),
+		Gas:        100000,
+		GasPrice:   new(big.Int),
+		AccessList: types.AccessList{{Address: common.Address{0x01}, StorageKeys: []common.Hash{{0x01}, {0x02}}}},
+	})
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	// Typed transactions are invalid before the fork
+	statedb, _ := state.New(genesis.Root(), state.NewDatabase(db))
+	header := &types.Header{Number: big.NewInt(0), GasLimit: genesis.GasLimit(), Difficulty: big.NewInt(1)}
+	if _, err := ApplyTransaction(&config, nil, &common.Address{}, new(GasPool).AddGas(header.GasLimit), statedb, header, tx, new(uint64), vm.Config{}); err != ErrTxTypeNotSupported {
+		t.Fatalf("typed transaction applied before the fork: %v", err)
+	}
+	chain, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 1, func(i int, gen *BlockGen) {
+		gen.AddTx(tx)
+	})
+	blockchain, _ := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	defer blockchain.Stop()
+
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	stored, _, _, _ := rawdb.ReadTransaction(db, tx.Hash())
+	if stored == nil || stored.Type() != types.AccessListTxType || stored.Hash() != tx.Hash() {
+		t.Fatalf("stored transaction mismatch: %v", stored)
+	}
+	receipts := blockchain.GetReceiptsByHash(chain[0].Hash())
+	if len(receipts) != 1 {
+		t.Fatalf("receipt count mismatch: have %d, want 1", len(receipts))
+	}
+	if receipts[0].Type != types.AccessListTxType {
+		t.Errorf("receipt type mismatch: have %d, want %d", receipts[0].Type, types.AccessListTxType)
+	}
+	if want := params.TxGas + params.TxAccessListAddressGas + 2*params.TxAccessListStorageKeyGas; receipts[0].GasUsed != want {
+		t.Errorf("gas used mismatch: have %d, want %d", receipts[0].GasUsed, want)
+	}
+}
+
+// Tests that expiring transactions are only valid after the transaction expiry
+// fork and within their inclusion window.
+func TestExpiringTransactions(t *testing.T) {
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		db     = rawdb.NewMemoryDatabase()
+		config = *params.TestChainConfig
+	)
+	config.TxExpiryBlock = big.NewInt(1)
+
+	gspec := &Genesis{Config: &config, Alloc: GenesisAlloc{addr: {Balance: big.NewInt(10000000000000)}}}
+	genesis := gspec.MustCommit(db)
+	signer := types.MakeSigner(&config)
+
+	tx, err := types.SignNewTx(key, signer, &types.ExpiringTx{
+		ChainID:     config.ChainID,
+		Nonce:       0,
+		To:          &common.Address{0x01},
+		Value:       big.NewInt(1000),
+		Gas:         100000,
+		GasPrice:    new(big.Int),
+		ExpiryBlock: 1,
+	})
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	// Expiring transactions are invalid before the fork and past their expiry
+	for _, number := range []int64{0, 2} {
+		statedb, _ := state.New(genesis.Root(), state.NewDatabase(db))
+		header := &types.Header{Number: big.NewInt(number), GasLimit: genesis.GasLimit(), Difficulty: big.NewInt(1)}
+		if _, err := ApplyTransaction(&config, nil, &common.Address{}, new(GasPool).AddGas(header.GasLimit), statedb, header, tx, new(uint64), vm.Config{}); err == nil {
+			t.Fatalf("expiring transaction applied at block %d", number)
+		}
+	}
+	chain, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 1, func(i int, gen *BlockGen) {
+		gen.AddTx(tx)
+	})
+	blockchain, _ := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	defer blockchain.Stop()
+
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	stored, _, _, _ := rawdb.ReadTransaction(db, tx.Hash())
+	if stored == nil || stored.Type() != types.ExpiringTxType || stored.ExpiryBlock() != 1 {
+		t.Fatalf("stored transaction mismatch: %v", stored)
+	}
+	receipts := blockchain.GetReceiptsByHash(chain[0].Hash())
+	if len(receipts) != 1 || receipts[0].Type != types.ExpiringTxType {
+		t.Fatalf("receipt mismatch: %v", receipts)
+	}
+}
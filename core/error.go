@@ -33,6 +33,22 @@ var (
 	// next one expected based on the local chain.
 	ErrNonceTooHigh = errors.New("nonce too high")
 
+	// ErrTxExpired is returned if a transaction is included past its expiry block
+	// number or timestamp.
+	ErrTxExpired = errors.New("transaction expired")
+
+	// ErrPrivateNotSupported is returned if a private transaction is sent to a chain
+	// without privacy.
+	ErrPrivateNotSupported = errors.New("private transactions not supported")
+
+	// ErrPrivatePayload is returned if the payload of a private transaction is not
+	// the hash of its encrypted payload.
+	ErrPrivatePayload = errors.New("private payload is not a hash")
+
+	// ErrPrivateValue is returned if a private transaction transfers value, which
+	// the private state can't account for.
+	ErrPrivateValue = errors.New("private transaction with value")
+
 	// ErrNoGenesis is returned when there is no Genesis Block.
 	ErrNoGenesis = errors.New("genesis not found in chain")
 
@@ -0,0 +1,188 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/core/state"
+	"github.com/simplechain-org/go-simplechain/core/types"
+	"github.com/simplechain-org/go-simplechain/core/vm"
+	"github.com/simplechain-org/go-simplechain/metrics"
+	"github.com/simplechain-org/go-simplechain/params"
+)
+
+var (
+	errExecutorStopped = errors.New("parallel executor stopped")
+
+	parallelHitMeter  = metrics.NewRegisteredMeter("chain/parallel/hits", nil)
+	parallelMissMeter = metrics.NewRegisteredMeter("chain/parallel/misses", nil)
+)
+
+// speculation is the outcome of executing a transaction against the state the
+// parallel executor was created with.
+type speculation struct {
+	tx      *types.Transaction
+	done    chan struct{}
+	access  *state.AccessSet
+	receipt *types.Receipt
+	origin  common.Address
+	err     error
+}
+
+// ParallelExecutor applies the transactions of a block in order, while a pool of
+// workers speculatively executes all of them concurrently against copies of the
+// starting state. A speculative result is reused if none of the state it read
+// was modified by the transactions applied before it, otherwise the transaction
+// is executed again on the live state. Either way the resulting state and the
+// receipts are identical to sequential execution.
+type ParallelExecutor struct {
+	config *params.ChainConfig
+	bc     ChainContext
+	author *common.Address
+	header *types.Header
+	cfg    vm.Config
+
+	specs       map[common.Hash]*speculation // Speculative results by transaction hash
+	writes      *state.AccessSet             // State modified since the speculation base
+	speculating bool                         // Whether any transaction is speculated at all
+
+	quit     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewParallelExecutor starts speculatively executing txs on top of statedb. The
+// speculation is skipped if the configuration does not allow it (fewer than two
+// workers, debugging or private transactions), in which case the executor will
+// apply all transactions sequentially.
+func NewParallelExecutor(config *params.ChainConfig, bc ChainContext, author *common.Address, statedb *state.StateDB, header *types.Header, txs types.Transactions, cfg vm.Config) *ParallelExecutor {
+	e := &ParallelExecutor{
+		config: config,
+		bc:     bc,
+		author: author,
+		header: header,
+		cfg:    cfg,
+		specs:  make(map[common.Hash]*speculation),
+		writes: state.NewAccessSet(),
+		quit:   make(chan struct{}),
+	}
+	if cfg.ParallelWorkers < 2 || cfg.Debug || cfg.EnablePreimageRecording || config.Privacy || len(txs) < 2 {
+		return e
+	}
+	// Speculate on a private copy, the live state is modified meanwhile
+	e.speculating = true
+	base := statedb.Copy()
+
+	tasks := make(chan *speculation, len(txs))
+	for _, tx := range txs {
+		if _, ok := e.specs[tx.Hash()]; ok {
+			continue
+		}
+		spec := &speculation{tx: tx, done: make(chan struct{})}
+		e.specs[tx.Hash()] = spec
+		tasks <- spec
+	}
+	close(tasks)
+
+	workers := cfg.ParallelWorkers
+	if workers > len(txs) {
+		workers = len(txs)
+	}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for spec := range tasks {
+				select {
+				case <-e.quit:
+					spec.err = errExecutorStopped
+				default:
+					e.speculate(base.Copy(), spec)
+				}
+				close(spec.done)
+			}
+		}()
+	}
+	return e
+}
+
+// speculate executes a transaction against a private copy of the starting state,
+// recording the state it accessed.
+func (e *ParallelExecutor) speculate(statedb *state.StateDB, spec *speculation) {
+	tx := spec.tx
+
+	spec.access = state.NewAccessSet()
+	statedb.SetAccessSet(spec.access)
+	statedb.Prepare(tx.Hash(), common.Hash{}, 0)
+
+	spec.receipt, spec.err = ApplyTransaction(e.config, e.bc, e.author, new(GasPool).AddGas(e.header.GasLimit), statedb, e.header, tx, new(uint64), e.cfg)
+	if spec.err == nil {
+		spec.origin, spec.err = types.Sender(types.MakeSigner(e.config), tx)
+	}
+}
+
+// ApplyTransaction applies the next transaction to statedb, which must be the
+// state the executor was created with, modified only through this method. The
+// semantics are the same as those of the package level ApplyTransaction.
+func (e *ParallelExecutor) ApplyTransaction(gp *GasPool, statedb *state.StateDB, tx *types.Transaction, usedGas *uint64) (*types.Receipt, error) {
+	// Without speculation there is nothing to validate, skip the access tracking
+	if !e.speculating {
+		return ApplyTransaction(e.config, e.bc, e.author, gp, statedb, e.header, tx, usedGas, e.cfg)
+	}
+	if spec, ok := e.specs[tx.Hash()]; ok {
+		delete(e.specs, tx.Hash())
+		<-spec.done
+
+		if spec.err == nil && gp.Gas() >= tx.Gas() && !spec.access.Conflicts(e.writes) {
+			parallelHitMeter.Mark(1)
+			return e.commit(gp, statedb, tx, spec, usedGas), nil
+		}
+	}
+	parallelMissMeter.Mark(1)
+
+	// Speculation unusable, execute on the live state recording the writes
+	access := state.NewAccessSet()
+	statedb.SetAccessSet(access)
+	defer statedb.SetAccessSet(nil)
+
+	receipt, err := ApplyTransaction(e.config, e.bc, e.author, gp, statedb, e.header, tx, usedGas, e.cfg)
+	if err != nil {
+		return nil, err
+	}
+	e.writes.Merge(access)
+	return receipt, nil
+}
+
+// commit applies the changes of a conflict free speculation to the live state.
+func (e *ParallelExecutor) commit(gp *GasPool, statedb *state.StateDB, tx *types.Transaction, spec *speculation, usedGas *uint64) *types.Receipt {
+	gp.SubGas(spec.receipt.GasUsed)
+
+	statedb.ApplyAccessChanges(spec.access)
+	for _, log := range spec.receipt.Logs {
+		statedb.AddLog(log)
+	}
+	statedb.Finalise(true)
+	e.writes.Merge(spec.access)
+
+	failed := spec.receipt.Status == types.ReceiptStatusFailed
+	return newReceipt(statedb, e.header, tx, spec.origin, tx.To() == nil, spec.receipt.GasUsed, failed, usedGas)
+}
+
+// Stop aborts any speculation not yet started.
+func (e *ParallelExecutor) Stop() {
+	e.stopOnce.Do(func() { close(e.quit) })
+}
@@ -0,0 +1,138 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/consensus/ethash"
+	"github.com/simplechain-org/go-simplechain/core/rawdb"
+	"github.com/simplechain-org/go-simplechain/core/state"
+	"github.com/simplechain-org/go-simplechain/core/types"
+	"github.com/simplechain-org/go-simplechain/core/vm"
+	"github.com/simplechain-org/go-simplechain/crypto"
+	"github.com/simplechain-org/go-simplechain/params"
+)
+
+// Tests that blocks processed with speculative parallel execution produce the
+// same state and receipts as sequential processing, for both independent and
+// conflicting transactions.
+func TestParallelExecution(t *testing.T) {
+	var (
+		db       = rawdb.NewMemoryDatabase()
+		coinbase = common.Address{0xc0}
+		counter  = common.Address{0x01} // Increments slot 0, conflicting across callers
+		ledger   = common.Address{0x02} // Increments the slot of the caller and emits a log
+		keys     []*ecdsa.PrivateKey
+		alloc    = GenesisAlloc{
+			counter: {Code: common.FromHex("0x60005460010160005500"), Balance: new(big.Int)},
+			ledger:  {Code: common.FromHex("0x3354600101335560006000a000"), Balance: new(big.Int)},
+		}
+	)
+	for i := 0; i < 16; i++ {
+		key, _ := crypto.GenerateKey()
+		keys = append(keys, key)
+		alloc[crypto.PubkeyToAddress(key.PublicKey)] = GenesisAccount{Balance: big.NewInt(params.Ether)}
+	}
+	gspec := &Genesis{Config: params.TestChainConfig, GasLimit: 10000000, Alloc: alloc}
+	genesis := gspec.MustCommit(db)
+	signer := types.MakeSigner(gspec.Config)
+
+	blocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 3, func(i int, gen *BlockGen) {
+		// Mine the last block by a sender, so its free transactions touch only its nonce
+		miner := coinbase
+		if i == 2 {
+			miner = crypto.PubkeyToAddress(keys[0].PublicKey)
+		}
+		gen.SetCoinbase(miner)
+
+		send := func(key *ecdsa.PrivateKey, to *common.Address, value *big.Int, data []byte) {
+			from := crypto.PubkeyToAddress(key.PublicKey)
+			price := big.NewInt(1)
+			if from == miner {
+				price, value = new(big.Int), new(big.Int) // Leaves only the nonce of the sender modified
+			}
+			tx, err := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: gen.TxNonce(from), To: to, Value: value, Gas: 200000, GasPrice: price, Data: data})
+			if err != nil {
+				t.Fatalf("failed to sign transaction: %v", err)
+			}
+			gen.AddTx(tx)
+		}
+		for j, key := range keys {
+			var (
+				next  = crypto.PubkeyToAddress(keys[(j+1)%len(keys)].PublicKey)
+				fresh = common.BigToAddress(big.NewInt(int64(1000*(i+1) + j)))
+			)
+			switch j % 6 {
+			case 0:
+				send(key, &fresh, big.NewInt(1000), nil) // Independent, creating an account
+			case 1:
+				send(key, &ledger, new(big.Int), nil) // Independent storage of a shared contract
+			case 2:
+				send(key, &counter, new(big.Int), nil) // Conflicting storage
+			case 3:
+				send(key, &next, big.NewInt(int64(j+1)), nil) // Conflicting balance with the next sender
+			case 4:
+				send(key, &coinbase, big.NewInt(1), nil) // Credit to the fee recipient
+			case 5:
+				send(key, nil, new(big.Int), common.FromHex("00")) // Contract creation
+			}
+		}
+		// Chained transactions of the same senders
+		for _, key := range keys[:4] {
+			send(key, &counter, new(big.Int), nil)
+		}
+	})
+	// Process the first block both ways and compare the results
+	chain, _ := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	defer chain.Stop()
+
+	process := func(cfg vm.Config) (common.Hash, types.Receipts) {
+		statedb, _ := state.New(genesis.Root(), state.NewDatabase(db))
+		processor := NewStateProcessor(gspec.Config, chain, chain.Engine())
+		receipts, _, _, err := processor.Process(blocks[0], statedb, cfg)
+		if err != nil {
+			t.Fatalf("failed to process block: %v", err)
+		}
+		return statedb.IntermediateRoot(true), receipts
+	}
+	seqRoot, seqReceipts := process(vm.Config{})
+	parRoot, parReceipts := process(vm.Config{ParallelWorkers: 4})
+	if seqRoot != parRoot {
+		t.Fatalf("state root mismatch: have %x, want %x", parRoot, seqRoot)
+	}
+	if !reflect.DeepEqual(seqReceipts, parReceipts) {
+		t.Fatalf("receipts mismatch:\nhave %v\nwant %v", parReceipts, seqReceipts)
+	}
+	// Import the whole chain with parallel execution, validating every block
+	diskdb := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(diskdb)
+
+	blockchain, _ := NewBlockChain(diskdb, nil, gspec.Config, ethash.NewFaker(), vm.Config{ParallelWorkers: 4}, nil)
+	defer blockchain.Stop()
+
+	if _, err := blockchain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if head := blockchain.CurrentBlock(); head.Hash() != blocks[len(blocks)-1].Hash() {
+		t.Fatalf("head mismatch: have %x, want %x", head.Hash(), blocks[len(blocks)-1].Hash())
+	}
+}
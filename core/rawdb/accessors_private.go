@@ -0,0 +1,68 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/core/types"
+	"github.com/simplechain-org/go-simplechain/ethdb"
+	"github.com/simplechain-org/go-simplechain/log"
+	"github.com/simplechain-org/go-simplechain/rlp"
+)
+
+// ReadPrivateStateRoot retrieves the root of the private state after the block,
+// or the empty hash if the block has no private state.
+func ReadPrivateStateRoot(db ethdb.KeyValueReader, hash common.Hash) common.Hash {
+	data, _ := db.Get(privateRootKey(hash))
+	return common.BytesToHash(data)
+}
+
+// WritePrivateStateRoot stores the root of the private state after the block.
+func WritePrivateStateRoot(db ethdb.KeyValueWriter, hash common.Hash, root common.Hash) {
+	if err := db.Put(privateRootKey(hash), root.Bytes()); err != nil {
+		log.Crit("Failed to store private state root", "err", err)
+	}
+}
+
+// ReadPrivateReceipt retrieves the receipt of a private transaction executed in
+// the block. Only the consensus fields are populated.
+func ReadPrivateReceipt(db ethdb.KeyValueReader, blockHash, txHash common.Hash) *types.Receipt {
+	data, _ := db.Get(privateReceiptKey(blockHash, txHash))
+	if len(data) == 0 {
+		return nil
+	}
+	var receipt types.ReceiptForStorage
+	if err := rlp.DecodeBytes(data, &receipt); err != nil {
+		log.Error("Invalid private receipt RLP", "hash", txHash, "err", err)
+		return nil
+	}
+	return (*types.Receipt)(&receipt)
+}
+
+// WritePrivateReceipts stores the receipts of the private transactions executed
+// in the block.
+func WritePrivateReceipts(db ethdb.KeyValueWriter, blockHash common.Hash, receipts types.Receipts) {
+	for _, receipt := range receipts {
+		data, err := rlp.EncodeToBytes((*types.ReceiptForStorage)(receipt))
+		if err != nil {
+			log.Crit("Failed to encode private receipt", "err", err)
+		}
+		if err := db.Put(privateReceiptKey(blockHash, receipt.TxHash), data); err != nil {
+			log.Crit("Failed to store private receipt", "err", err)
+		}
+	}
+}
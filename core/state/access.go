@@ -0,0 +1,253 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"math/big"
+
+	"github.com/simplechain-org/go-simplechain/common"
+)
+
+// AccessKind is the part of an account a state access touches.
+type AccessKind uint8
+
+const (
+	AccessAccount    AccessKind = iota // Existence of the account
+	AccessBalance                      // Balance of the account
+	AccessNonce                        // Nonce of the account
+	AccessCode                         // Code of the account
+	AccessStorage                      // Single storage slot of the account
+	AccessStorageAll                   // Any storage slot of the account
+)
+
+// AccessKey identifies a single piece of state.
+type AccessKey struct {
+	Address common.Address
+	Kind    AccessKind
+	Slot    common.Hash // Only set for storage accesses
+}
+
+// accountChange is the net modification of an account by the tracked execution.
+type accountChange struct {
+	created  bool                        // Account was (re)created
+	suicided bool                        // Account self destructed
+	touched  bool                        // Balance was modified or touched
+	delta    *big.Int                    // Net balance change, relative to the first modification
+	nonce    *uint64                     // New nonce, if modified
+	code     []byte                      // New code, if modified
+	codeSet  bool                        // Whether the code was modified
+	storage  map[common.Hash]common.Hash // New storage values, if modified
+}
+
+// AccessSet records the state read and written while executing transactions,
+// along with the net changes done to each account. The reads are gathered on
+// every state query, the writes are gathered from the journal when the state
+// is finalised, so anything reverted during execution is not accounted.
+//
+// Balance modifications are tracked as deltas, which allows concurrent credits
+// to the same account (e.g. fees to the coinbase) to be merged without being
+// considered a conflict, as long as the balance itself is not read.
+type AccessSet struct {
+	reads   map[AccessKey]struct{}
+	writes  map[AccessKey]struct{}
+	touched map[common.Address]struct{} // Accounts with any written key
+	changes map[common.Address]*accountChange
+}
+
+// NewAccessSet creates an empty access set.
+func NewAccessSet() *AccessSet {
+	return &AccessSet{
+		reads:   make(map[AccessKey]struct{}),
+		writes:  make(map[AccessKey]struct{}),
+		touched: make(map[common.Address]struct{}),
+		changes: make(map[common.Address]*accountChange),
+	}
+}
+
+// read marks a piece of state as read.
+func (set *AccessSet) read(addr common.Address, kind AccessKind, slot common.Hash) {
+	set.reads[AccessKey{addr, kind, slot}] = struct{}{}
+}
+
+// write marks a piece of state as written.
+func (set *AccessSet) write(addr common.Address, kind AccessKind, slot common.Hash) {
+	set.writes[AccessKey{addr, kind, slot}] = struct{}{}
+	if kind == AccessStorage {
+		set.writes[AccessKey{Address: addr, Kind: AccessStorageAll}] = struct{}{}
+	}
+	set.touched[addr] = struct{}{}
+}
+
+// change returns the change record of an account, creating it if needed.
+func (set *AccessSet) change(addr common.Address) *accountChange {
+	change := set.changes[addr]
+	if change == nil {
+		change = new(accountChange)
+		set.changes[addr] = change
+	}
+	return change
+}
+
+// Conflicts reports whether any state read by set was modified by the writes
+// recorded in other. Any read conflicts with the account being created or
+// destructed.
+func (set *AccessSet) Conflicts(other *AccessSet) bool {
+	for key := range set.reads {
+		if _, ok := other.touched[key.Address]; !ok {
+			continue
+		}
+		if _, ok := other.writes[key]; ok {
+			return true
+		}
+		if _, ok := other.writes[AccessKey{Address: key.Address, Kind: AccessAccount}]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Merge adds the writes recorded in other to the set.
+func (set *AccessSet) Merge(other *AccessSet) {
+	for key := range other.writes {
+		set.write(key.Address, key.Kind, key.Slot)
+	}
+}
+
+// SetAccessSet starts recording the state accesses into set, or stops the
+// recording if set is nil.
+func (s *StateDB) SetAccessSet(set *AccessSet) {
+	s.access = set
+}
+
+// trackRead records a state read if access tracking is enabled.
+func (s *StateDB) trackRead(addr common.Address, kind AccessKind, slot common.Hash) {
+	if s.access != nil {
+		s.access.read(addr, kind, slot)
+	}
+}
+
+// trackChanges records the writes of the current journal and the resulting
+// account changes, called before the journal is cleared.
+func (s *StateDB) trackChanges(deleteEmptyObjects bool) {
+	set := s.access
+	for _, entry := range s.journal.entries {
+		switch ch := entry.(type) {
+		// Creations depend on the previous account, even if implicit (e.g. a transfer)
+		case createObjectChange:
+			set.read(*ch.account, AccessAccount, common.Hash{})
+			set.write(*ch.account, AccessAccount, common.Hash{})
+			change := set.change(*ch.account)
+			change.created, change.suicided, change.storage = true, false, nil
+		case resetObjectChange:
+			set.read(ch.prev.address, AccessAccount, common.Hash{})
+			set.write(ch.prev.address, AccessAccount, common.Hash{})
+			change := set.change(ch.prev.address)
+			change.created, change.suicided, change.storage = true, false, nil
+		case suicideChange:
+			set.write(*ch.account, AccessAccount, common.Hash{})
+			set.write(*ch.account, AccessBalance, common.Hash{})
+			set.change(*ch.account).suicided = true
+		case balanceChange:
+			set.write(*ch.account, AccessBalance, common.Hash{})
+			if change := set.change(*ch.account); change.delta == nil {
+				// Store the negated starting balance, the final one is added below
+				change.delta = new(big.Int).Neg(ch.prev)
+			}
+		case touchChange:
+			set.write(*ch.account, AccessBalance, common.Hash{})
+			set.change(*ch.account).touched = true
+		case nonceChange:
+			set.write(*ch.account, AccessNonce, common.Hash{})
+			set.change(*ch.account)
+		case codeChange:
+			set.write(*ch.account, AccessCode, common.Hash{})
+			set.change(*ch.account).codeSet = true
+		case storageChange:
+			set.write(*ch.account, AccessStorage, ch.key)
+			change := set.change(*ch.account)
+			if change.storage == nil {
+				change.storage = make(map[common.Hash]common.Hash)
+			}
+			change.storage[ch.key] = common.Hash{}
+		}
+	}
+	// Resolve the final values of all modified accounts
+	for addr := range s.journal.dirties {
+		obj, exist := s.stateObjects[addr]
+		if !exist {
+			continue
+		}
+		if obj.suicided || (deleteEmptyObjects && obj.empty()) {
+			set.write(addr, AccessAccount, common.Hash{})
+		}
+		change, ok := set.changes[addr]
+		if !ok {
+			continue
+		}
+		if change.delta != nil {
+			change.delta.Add(change.delta, obj.Balance())
+			change.touched = true
+		}
+		if _, ok := set.writes[AccessKey{addr, AccessNonce, common.Hash{}}]; ok {
+			nonce := obj.Nonce()
+			change.nonce = &nonce
+		}
+		if change.codeSet {
+			change.code = common.CopyBytes(obj.code)
+		}
+		for key := range change.storage {
+			if value, ok := obj.dirtyStorage[key]; ok {
+				change.storage[key] = value
+			} else {
+				delete(change.storage, key)
+			}
+		}
+	}
+}
+
+// ApplyAccessChanges replays the account changes recorded in set onto the state.
+// The set must have been recorded by a single tracked execution whose reads do
+// not conflict with any modification done to this state since.
+func (s *StateDB) ApplyAccessChanges(set *AccessSet) {
+	for addr, change := range set.changes {
+		if change.created {
+			s.CreateAccount(addr)
+		}
+		if change.nonce != nil {
+			s.SetNonce(addr, *change.nonce)
+		}
+		if change.codeSet {
+			s.SetCode(addr, change.code)
+		}
+		for key, value := range change.storage {
+			s.SetState(addr, key, value)
+		}
+		if change.touched && !change.suicided {
+			switch {
+			case change.delta == nil:
+				s.AddBalance(addr, new(big.Int))
+			case change.delta.Sign() >= 0:
+				s.AddBalance(addr, change.delta)
+			default:
+				s.SubBalance(addr, new(big.Int).Neg(change.delta))
+			}
+		}
+		if change.suicided {
+			s.Suicide(addr)
+		}
+	}
+}
@@ -0,0 +1,340 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package pruner implements offline pruning of the state trie nodes that are
+// not reachable from the recent canonical state roots.
+package pruner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/core/rawdb"
+	"github.com/simplechain-org/go-simplechain/core/state"
+	"github.com/simplechain-org/go-simplechain/crypto"
+	"github.com/simplechain-org/go-simplechain/ethdb"
+	"github.com/simplechain-org/go-simplechain/log"
+	"github.com/simplechain-org/go-simplechain/rlp"
+	"github.com/simplechain-org/go-simplechain/trie"
+	"github.com/steakknife/bloomfilter"
+)
+
+const (
+	// stateBloomFileName is the name of the file the state bloom is persisted
+	// to between the marking and the sweeping phase. Its presence signals that
+	// a previous pruning was interrupted while deleting.
+	stateBloomFileName = "statebloom.bf.gz"
+
+	// stateBloomFileTempSuffix is the suffix of the state bloom while it's being
+	// written, so a crash mid-write doesn't leave a truncated filter behind.
+	stateBloomFileTempSuffix = ".tmp"
+
+	// stateBloomHashes is the number of bits set in the bloom per trie node.
+	stateBloomHashes = 4
+)
+
+var (
+	// emptyRoot is the known root hash of an empty trie.
+	emptyRoot = common.HexToHash("56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+
+	// emptyCode is the known hash of the empty EVM bytecode.
+	emptyCode = crypto.Keccak256(nil)
+
+	// errNoRetainedState is returned if none of the recent canonical blocks has
+	// its state available in the database.
+	errNoRetainedState = errors.New("no recent state available to retain")
+)
+
+// Config includes all the configurations for pruning.
+type Config struct {
+	Datadir   string // Directory to persist the state bloom in
+	BloomSize uint64 // Megabytes of memory allocated to the state bloom
+	Retain    uint64 // Number of recent canonical blocks whose state is retained
+	DryRun    bool   // Report the stale trie nodes without deleting them
+}
+
+// Pruner is an offline tool to prune the stale state trie nodes. In full gc
+// mode the trie database only garbage-collects in memory, so nodes flushed to
+// disk are never deleted. The pruner marks every trie node and contract code
+// reachable from the retained state roots in a bloom filter, then deletes all
+// the other trie nodes from the database.
+//
+// The bloom may have false positives, so some stale nodes may survive, but
+// it never drops a reachable one. The filter is persisted before deleting,
+// so an interrupted pruning resumes with the sweep on the next run.
+type Pruner struct {
+	db     ethdb.Database
+	config Config
+}
+
+// NewPruner creates a pruner over the given chain database.
+func NewPruner(db ethdb.Database, config Config) *Pruner {
+	return &Pruner{db: db, config: config}
+}
+
+// stateBloomHasher is a wrapper around a byte blob to satisfy the interface
+// API requirements of the bloom library used. Trie node keys are hashes, so
+// their leading bytes are already uniformly distributed.
+type stateBloomHasher []byte
+
+func (h stateBloomHasher) Write(p []byte) (n int, err error) { panic("not implemented") }
+func (h stateBloomHasher) Sum(b []byte) []byte               { panic("not implemented") }
+func (h stateBloomHasher) Reset()                            { panic("not implemented") }
+func (h stateBloomHasher) BlockSize() int                    { panic("not implemented") }
+func (h stateBloomHasher) Size() int                         { return 8 }
+func (h stateBloomHasher) Sum64() uint64                     { return binary.BigEndian.Uint64(h) }
+
+// Prune deletes all the trie nodes not reachable from the retained state
+// roots. If a state bloom of an interrupted run exists, marking is skipped and
+// the deletion resumes with it.
+func (p *Pruner) Prune() error {
+	path := filepath.Join(p.config.Datadir, stateBloomFileName)
+
+	bloom, err := loadStateBloom(path)
+	if err != nil {
+		return err
+	}
+	if bloom != nil {
+		log.Info("Resuming interrupted state pruning", "bloom", path)
+	} else {
+		if bloom, err = p.mark(); err != nil {
+			return err
+		}
+		if !p.config.DryRun {
+			if err := commitStateBloom(bloom, path); err != nil {
+				return err
+			}
+		}
+	}
+	if err := p.sweep(bloom); err != nil {
+		return err
+	}
+	if p.config.DryRun {
+		return nil
+	}
+	// Pruning is done, drop the bloom and reclaim the disk space
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	log.Info("Compacting database, this may take a while")
+	start := time.Now()
+	if err := p.db.Compact(nil, nil); err != nil {
+		return err
+	}
+	log.Info("Compacted database", "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// retainedRoots collects the state roots to keep: those of the recent canonical
+// blocks available on disk, their private states, the genesis state and the
+// base of the state snapshot.
+func (p *Pruner) retainedRoots() ([]common.Hash, error) {
+	head := rawdb.ReadHeadBlockHash(p.db)
+	if head == (common.Hash{}) {
+		return nil, errors.New("head block missing")
+	}
+	number := rawdb.ReadHeaderNumber(p.db, head)
+	if number == nil {
+		return nil, fmt.Errorf("head block %x number missing", head)
+	}
+	var (
+		roots  []common.Hash
+		public int
+		seen   = make(map[common.Hash]bool)
+	)
+	retain := func(root common.Hash) {
+		if root == (common.Hash{}) || root == emptyRoot || seen[root] {
+			return
+		}
+		if ok, _ := p.db.Has(root[:]); !ok {
+			return
+		}
+		seen[root] = true
+		roots = append(roots, root)
+	}
+	for i := uint64(0); i < p.config.Retain && i <= *number; i++ {
+		hash := rawdb.ReadCanonicalHash(p.db, *number-i)
+		header := rawdb.ReadHeader(p.db, hash, *number-i)
+		if header == nil {
+			return nil, fmt.Errorf("canonical header #%d missing", *number-i)
+		}
+		if ok, _ := p.db.Has(header.Root[:]); ok {
+			if public == 0 && i > 0 {
+				log.Warn("Head state missing, the chain will be rewound", "head", *number, "state", *number-i)
+			}
+			public++
+		}
+		retain(header.Root)
+		retain(rawdb.ReadPrivateStateRoot(p.db, hash))
+	}
+	if public == 0 {
+		return nil, errNoRetainedState
+	}
+	if genesis := rawdb.ReadHeader(p.db, rawdb.ReadCanonicalHash(p.db, 0), 0); genesis != nil {
+		retain(genesis.Root)
+	}
+	retain(rawdb.ReadSnapshotRoot(p.db))
+	return roots, nil
+}
+
+// mark walks the retained states and adds all their trie nodes and contract
+// codes to a fresh state bloom.
+func (p *Pruner) mark() (*bloomfilter.Filter, error) {
+	roots, err := p.retainedRoots()
+	if err != nil {
+		return nil, err
+	}
+	bloom, err := bloomfilter.New(p.config.BloomSize*1024*1024*8, stateBloomHashes)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		triedb  = trie.NewDatabase(p.db)
+		storage = make(map[common.Hash]struct{})
+		start   = time.Now()
+		logged  = time.Now()
+		nodes   uint64
+	)
+	log.Info("Marking reachable state", "roots", len(roots))
+
+	// markTrie adds all the nodes of a trie to the bloom, invoking onleaf for
+	// every value stored in it.
+	markTrie := func(root common.Hash, onleaf func([]byte) error) error {
+		t, err := trie.NewSecure(root, triedb)
+		if err != nil {
+			return err
+		}
+		it := t.NodeIterator(nil)
+		for it.Next(true) {
+			if hash := it.Hash(); hash != (common.Hash{}) {
+				bloom.Add(stateBloomHasher(hash[:]))
+				nodes++
+			}
+			if it.Leaf() && onleaf != nil {
+				if err := onleaf(it.LeafBlob()); err != nil {
+					return err
+				}
+			}
+			if time.Since(logged) > 8*time.Second {
+				log.Info("Marking reachable state", "nodes", nodes, "elapsed", common.PrettyDuration(time.Since(start)))
+				logged = time.Now()
+			}
+		}
+		return it.Error()
+	}
+	for _, root := range roots {
+		err := markTrie(root, func(blob []byte) error {
+			var account state.Account
+			if err := rlp.DecodeBytes(blob, &account); err != nil {
+				return err
+			}
+			if !bytes.Equal(account.CodeHash, emptyCode) {
+				bloom.Add(stateBloomHasher(account.CodeHash))
+			}
+			if account.Root == emptyRoot {
+				return nil
+			}
+			if _, ok := storage[account.Root]; ok {
+				return nil
+			}
+			storage[account.Root] = struct{}{}
+			return markTrie(account.Root, nil)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to mark state %x: %v", root, err)
+		}
+	}
+	log.Info("Marked reachable state", "roots", len(roots), "nodes", nodes, "elapsed", common.PrettyDuration(time.Since(start)))
+	return bloom, nil
+}
+
+// sweep deletes every trie node and contract code not contained in the state
+// bloom, or only counts them in dry-run mode.
+func (p *Pruner) sweep(bloom *bloomfilter.Filter) error {
+	var (
+		count  uint64
+		size   common.StorageSize
+		start  = time.Now()
+		logged = time.Now()
+		batch  = p.db.NewBatch()
+	)
+	it := p.db.NewIterator()
+	defer it.Release()
+
+	for it.Next() {
+		// Trie nodes and contract codes are the only entries keyed by bare hashes
+		key := it.Key()
+		if len(key) != common.HashLength || bloom.Contains(stateBloomHasher(key)) {
+			continue
+		}
+		count++
+		size += common.StorageSize(len(key) + len(it.Value()))
+
+		if !p.config.DryRun {
+			batch.Delete(key)
+			if batch.ValueSize() > ethdb.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					return err
+				}
+				batch.Reset()
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Pruning stale state", "nodes", count, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if p.config.DryRun {
+		log.Info("Found stale state", "nodes", count, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+		return nil
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("Pruned stale state", "nodes", count, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// loadStateBloom loads the state bloom of an interrupted pruning, returning nil
+// if there's none.
+func loadStateBloom(path string) (*bloomfilter.Filter, error) {
+	if !common.FileExist(path) {
+		return nil, nil
+	}
+	bloom, _, err := bloomfilter.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load state bloom %s: %v", path, err)
+	}
+	return bloom, nil
+}
+
+// commitStateBloom atomically persists the state bloom to disk.
+func commitStateBloom(bloom *bloomfilter.Filter, path string) error {
+	tmp := path + stateBloomFileTempSuffix
+	if _, err := bloom.WriteFile(tmp); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,171 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/consensus/ethash"
+	"github.com/simplechain-org/go-simplechain/core"
+	"github.com/simplechain-org/go-simplechain/core/rawdb"
+	"github.com/simplechain-org/go-simplechain/core/state"
+	"github.com/simplechain-org/go-simplechain/core/types"
+	"github.com/simplechain-org/go-simplechain/core/vm"
+	"github.com/simplechain-org/go-simplechain/crypto"
+	"github.com/simplechain-org/go-simplechain/ethdb"
+	"github.com/simplechain-org/go-simplechain/params"
+	"github.com/simplechain-org/go-simplechain/trie"
+)
+
+// newTestChain creates an archive chain whose every state is flushed to disk,
+// returning the database and the imported blocks.
+func newTestChain(t *testing.T, n int) (ethdb.Database, []*types.Block) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &core.Genesis{Config: params.TestChainConfig, Alloc: core.GenesisAlloc{addr: {Balance: big.NewInt(10000000000000)}}}
+		gendb   = rawdb.NewMemoryDatabase()
+		genesis = gspec.MustCommit(gendb)
+		signer  = types.NewEIP155Signer(gspec.Config.ChainID)
+	)
+	gspec.MustCommit(db)
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), gendb, n, func(i int, gen *core.BlockGen) {
+		var tx *types.Transaction
+		if i == 0 {
+			// PUSH1 42 PUSH1 0 SSTORE
+			tx, _ = types.SignTx(types.NewContractCreation(gen.TxNonce(addr), new(big.Int), 100000, new(big.Int), common.Hex2Bytes("602a600055")), signer, key)
+		} else {
+			tx, _ = types.SignTx(types.NewTransaction(gen.TxNonce(addr), common.Address{byte(i)}, big.NewInt(1000), params.TxGas, new(big.Int), nil), signer, key)
+		}
+		gen.AddTx(tx)
+	})
+	chain, _ := core.NewBlockChain(db, &core.CacheConfig{TrieDirtyDisabled: true}, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	chain.Stop()
+	return db, blocks
+}
+
+// checkState ensures that every node of the state with the given root is
+// still available.
+func checkState(t *testing.T, db ethdb.Database, root common.Hash) {
+	statedb, err := state.New(root, state.NewDatabase(db))
+	if err != nil {
+		t.Fatalf("state %x missing: %v", root, err)
+	}
+	it := state.NewNodeIterator(statedb)
+	for it.Next() {
+	}
+	if it.Error != nil {
+		t.Fatalf("state %x incomplete: %v", root, it.Error)
+	}
+}
+
+// countNodes counts the entries keyed by bare hashes in the database.
+func countNodes(db ethdb.Database) int {
+	it := db.NewIterator()
+	defer it.Release()
+
+	var count int
+	for it.Next() {
+		if len(it.Key()) == common.HashLength {
+			count++
+		}
+	}
+	return count
+}
+
+func TestPrune(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pruner-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, blocks := newTestChain(t, 5)
+	config := Config{Datadir: dir, BloomSize: 1, Retain: 2}
+
+	// A dry run must not touch the database
+	before := countNodes(db)
+	dry := config
+	dry.DryRun = true
+	if err := NewPruner(db, dry).Prune(); err != nil {
+		t.Fatalf("failed to dry run pruning: %v", err)
+	}
+	if after := countNodes(db); after != before {
+		t.Fatalf("dry run deleted nodes: have %d, want %d", after, before)
+	}
+	// Prune and ensure only the retained states survived
+	if err := NewPruner(db, config).Prune(); err != nil {
+		t.Fatalf("failed to prune state: %v", err)
+	}
+	if after := countNodes(db); after >= before {
+		t.Fatalf("no nodes pruned: have %d, had %d", after, before)
+	}
+	genesis := rawdb.ReadHeader(db, rawdb.ReadCanonicalHash(db, 0), 0)
+	for _, root := range []common.Hash{genesis.Root, blocks[3].Root(), blocks[4].Root()} {
+		checkState(t, db, root)
+	}
+	for _, block := range blocks[:3] {
+		if ok, _ := db.Has(block.Root().Bytes()); ok {
+			t.Errorf("state of block #%d not pruned", block.NumberU64())
+		}
+	}
+	if common.FileExist(filepath.Join(dir, stateBloomFileName)) {
+		t.Errorf("state bloom left behind")
+	}
+}
+
+// Tests that an interrupted pruning resumes the deletion with the persisted
+// bloom instead of marking anew.
+func TestPruneResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pruner-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, blocks := newTestChain(t, 5)
+	pruner := NewPruner(db, Config{Datadir: dir, BloomSize: 1, Retain: 1})
+
+	bloom, err := pruner.mark()
+	if err != nil {
+		t.Fatalf("failed to mark state: %v", err)
+	}
+	if err := commitStateBloom(bloom, filepath.Join(dir, stateBloomFileName)); err != nil {
+		t.Fatalf("failed to persist state bloom: %v", err)
+	}
+	// Resuming with a larger retention must still use the persisted bloom
+	pruner.config.Retain = 5
+	if err := pruner.Prune(); err != nil {
+		t.Fatalf("failed to resume pruning: %v", err)
+	}
+	checkState(t, db, blocks[4].Root())
+	if ok, _ := db.Has(blocks[3].Root().Bytes()); ok {
+		t.Errorf("resumed pruning didn't use the persisted bloom")
+	}
+	if _, err := trie.NewSecure(blocks[4].Root(), trie.NewDatabase(db)); err != nil {
+		t.Errorf("head state missing: %v", err)
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/fastcache"
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/core/rawdb"
+	"github.com/simplechain-org/go-simplechain/crypto"
+	"github.com/simplechain-org/go-simplechain/rlp"
+	"github.com/simplechain-org/go-simplechain/trie"
+)
+
+// randomHash generates a random blob of data and returns it as a hash.
+func randomHash() common.Hash {
+	var hash common.Hash
+	if n, err := rand.Read(hash[:]); n != common.HashLength || err != nil {
+		panic(err)
+	}
+	return hash
+}
+
+// randomAccount generates a random account and returns it RLP encoded.
+func randomAccount() []byte {
+	return SlimAccountRLP(rand.Uint64(), big.NewInt(rand.Int63()), randomHash(), randomHash().Bytes())
+}
+
+// newTestTree creates a snapshot tree with an empty disk layer on top of an
+// in-memory database.
+func newTestTree() *Tree {
+	db := rawdb.NewMemoryDatabase()
+	base := &diskLayer{
+		diskdb: db,
+		triedb: trie.NewDatabase(db),
+		cache:  fastcache.New(1024 * 500),
+		root:   common.HexToHash("0x01"),
+	}
+	return &Tree{
+		diskdb: db,
+		triedb: base.triedb,
+		layers: map[common.Hash]snapshot{base.root: base},
+	}
+}
+
+// Tests that account and storage reads are resolved through the diff layers,
+// honouring destructions and falling back to the disk layer.
+func TestDiffLayerReads(t *testing.T) {
+	snaps := newTestTree()
+	base := snaps.layers[common.HexToHash("0x01")].(*diskLayer)
+
+	acc, slot := randomHash(), randomHash()
+	rawdb.WriteAccountSnapshot(base.diskdb, acc, randomAccount())
+	rawdb.WriteStorageSnapshot(base.diskdb, acc, slot, []byte{0x01})
+
+	// Modify the storage in the first layer, destruct the account in the second
+	// and resurrect it without storage in the third
+	if err := snaps.Update(common.HexToHash("0x02"), common.HexToHash("0x01"), nil,
+		map[common.Hash][]byte{acc: randomAccount()},
+		map[common.Hash]map[common.Hash][]byte{acc: {slot: {0x02}}}); err != nil {
+		t.Fatalf("failed to create diff layer: %v", err)
+	}
+	if err := snaps.Update(common.HexToHash("0x03"), common.HexToHash("0x02"),
+		map[common.Hash]struct{}{acc: {}}, nil, nil); err != nil {
+		t.Fatalf("failed to create diff layer: %v", err)
+	}
+	resurrected := randomAccount()
+	if err := snaps.Update(common.HexToHash("0x04"), common.HexToHash("0x03"), nil,
+		map[common.Hash][]byte{acc: resurrected}, nil); err != nil {
+		t.Fatalf("failed to create diff layer: %v", err)
+	}
+	tests := []struct {
+		root    common.Hash
+		account bool
+		slot    []byte
+	}{
+		{common.HexToHash("0x01"), true, []byte{0x01}},
+		{common.HexToHash("0x02"), true, []byte{0x02}},
+		{common.HexToHash("0x03"), false, nil},
+		{common.HexToHash("0x04"), true, nil},
+	}
+	for i, tt := range tests {
+		snap := snaps.Snapshot(tt.root)
+		blob, err := snap.AccountRLP(acc)
+		if err != nil {
+			t.Fatalf("test %d: failed to retrieve account: %v", i, err)
+		}
+		if (len(blob) > 0) != tt.account {
+			t.Errorf("test %d: account presence mismatch: have %v, want %v", i, len(blob) > 0, tt.account)
+		}
+		data, err := snap.Storage(acc, slot)
+		if err != nil {
+			t.Fatalf("test %d: failed to retrieve slot: %v", i, err)
+		}
+		if !bytes.Equal(data, tt.slot) {
+			t.Errorf("test %d: slot mismatch: have %x, want %x", i, data, tt.slot)
+		}
+	}
+	if blob, _ := snaps.Snapshot(common.HexToHash("0x04")).AccountRLP(acc); !bytes.Equal(blob, resurrected) {
+		t.Errorf("resurrected account mismatch: have %x, want %x", blob, resurrected)
+	}
+}
+
+// Tests that capping the tree flattens the layers beyond the limit without
+// altering the data visible from the head, and that full capping persists
+// everything into the disk layer.
+func TestCap(t *testing.T) {
+	snaps := newTestTree()
+
+	var (
+		accounts = make(map[common.Hash][]byte)
+		parent   = common.HexToHash("0x01")
+	)
+	for i := 2; i <= 6; i++ {
+		acc, blob := randomHash(), randomAccount()
+		accounts[acc] = blob
+
+		root := common.BigToHash(big.NewInt(int64(i)))
+		if err := snaps.Update(root, parent, nil, map[common.Hash][]byte{acc: blob}, nil); err != nil {
+			t.Fatalf("failed to create diff layer %d: %v", i, err)
+		}
+		parent = root
+	}
+	check := func() {
+		head := snaps.Snapshot(parent)
+		for acc, want := range accounts {
+			if have, err := head.AccountRLP(acc); err != nil || !bytes.Equal(have, want) {
+				t.Fatalf("account %x mismatch: have %x, want %x, err %v", acc, have, want, err)
+			}
+		}
+	}
+	if n := len(snaps.layers); n != 6 {
+		t.Fatalf("layer count mismatch: have %d, want 6", n)
+	}
+	if err := snaps.Cap(parent, 2); err != nil {
+		t.Fatalf("failed to cap tree: %v", err)
+	}
+	// The head is retained, everything below is flattened into an accumulator
+	if n := len(snaps.layers); n != 3 {
+		t.Fatalf("layer count mismatch after cap: have %d, want 3", n)
+	}
+	check()
+
+	if err := snaps.Cap(parent, 0); err != nil {
+		t.Fatalf("failed to flatten tree: %v", err)
+	}
+	if n := len(snaps.layers); n != 1 {
+		t.Fatalf("layer count mismatch after flatten: have %d, want 1", n)
+	}
+	if _, ok := snaps.layers[parent].(*diskLayer); !ok {
+		t.Fatalf("head not persisted into disk layer")
+	}
+	if root := rawdb.ReadSnapshotRoot(snaps.diskdb); root != parent {
+		t.Fatalf("persisted root mismatch: have %x, want %x", root, parent)
+	}
+	for acc, want := range accounts {
+		if have := rawdb.ReadAccountSnapshot(snaps.diskdb, acc); !bytes.Equal(have, want) {
+			t.Fatalf("persisted account %x mismatch: have %x, want %x", acc, have, want)
+		}
+	}
+	check()
+}
+
+// Tests that the snapshot generated from a state trie contains all its accounts
+// and storage slots, and that the diff layers survive a journal round trip.
+func TestGenerateAndJournal(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	triedb := trie.NewDatabase(db)
+
+	// Create a storage trie and an account trie referencing it
+	stTrie, _ := trie.NewSecure(common.Hash{}, triedb)
+	stTrie.Update([]byte("key-1"), []byte("val-1"))
+	stTrie.Update([]byte("key-2"), []byte("val-2"))
+	stRoot, _ := stTrie.Commit(nil)
+	triedb.Commit(stRoot, false)
+
+	accTrie, _ := trie.NewSecure(common.Hash{}, triedb)
+	accounts := map[string]Account{
+		"acc-1": {Nonce: 1, Balance: big.NewInt(1), Root: stRoot.Bytes(), CodeHash: emptyCode.Bytes()},
+		"acc-2": {Nonce: 2, Balance: big.NewInt(2), Root: emptyRoot.Bytes(), CodeHash: emptyCode.Bytes()},
+	}
+	for key, acc := range accounts {
+		blob, _ := rlp.EncodeToBytes(struct {
+			Nonce    uint64
+			Balance  *big.Int
+			Root     common.Hash
+			CodeHash []byte
+		}{acc.Nonce, acc.Balance, common.BytesToHash(acc.Root), acc.CodeHash})
+		accTrie.Update([]byte(key), blob)
+	}
+	root, _ := accTrie.Commit(nil)
+	triedb.Commit(root, false)
+
+	snaps := New(db, triedb, 16, root, false)
+	head := snaps.Snapshot(root)
+	if head == nil {
+		t.Fatalf("snapshot not generated")
+	}
+	for key, want := range accounts {
+		have, err := head.Account(crypto.Keccak256Hash([]byte(key)))
+		if err != nil {
+			t.Fatalf("failed to retrieve account %s: %v", key, err)
+		}
+		if have.Nonce != want.Nonce || !bytes.Equal(have.Root, want.Root) {
+			t.Errorf("account %s mismatch: have %+v, want %+v", key, have, want)
+		}
+	}
+	for _, key := range []string{"key-1", "key-2"} {
+		blob, err := head.Storage(crypto.Keccak256Hash([]byte("acc-1")), crypto.Keccak256Hash([]byte(key)))
+		if err != nil {
+			t.Fatalf("failed to retrieve slot %s: %v", key, err)
+		}
+		if want := "val-" + key[4:]; string(blob) != want {
+			t.Errorf("slot %s mismatch: have %q, want %q", key, blob, want)
+		}
+	}
+	// Stack a diff layer, journal it and reload the tree
+	acc, blob := randomHash(), randomAccount()
+	next := randomHash()
+	if err := snaps.Update(next, root, nil, map[common.Hash][]byte{acc: blob}, nil); err != nil {
+		t.Fatalf("failed to create diff layer: %v", err)
+	}
+	if base, err := snaps.Journal(next); err != nil || base != root {
+		t.Fatalf("failed to journal snapshot: base %x, err %v", base, err)
+	}
+	reloaded := New(db, triedb, 16, next, false)
+	if n := len(reloaded.layers); n != 2 {
+		t.Fatalf("reloaded layer count mismatch: have %d, want 2", n)
+	}
+	if have, err := reloaded.Snapshot(next).AccountRLP(acc); err != nil || !bytes.Equal(have, blob) {
+		t.Fatalf("reloaded account mismatch: have %x, want %x, err %v", have, blob, err)
+	}
+	// A journal not matching the expected head must trigger a regeneration
+	regenerated := New(db, triedb, 16, root, false)
+	if regenerated.Snapshot(next) != nil {
+		t.Fatalf("stale diff layer retained after regeneration")
+	}
+	select {
+	case <-regenerated.layers[root].(*diskLayer).genPending:
+	case <-time.After(time.Second):
+		t.Fatalf("snapshot regeneration not finished")
+	}
+}
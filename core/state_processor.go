@@ -17,6 +17,8 @@
 package core
 
 import (
+	"math/big"
+
 	"github.com/simplechain-org/go-simplechain/common"
 	"github.com/simplechain-org/go-simplechain/consensus"
 	"github.com/simplechain-org/go-simplechain/core/state"
@@ -24,6 +26,7 @@ import (
 	"github.com/simplechain-org/go-simplechain/core/vm"
 	"github.com/simplechain-org/go-simplechain/crypto"
 	"github.com/simplechain-org/go-simplechain/params"
+	"github.com/simplechain-org/go-simplechain/private"
 )
 
 // StateProcessor is a basic Processor, which takes care of transitioning
@@ -61,10 +64,25 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 		gp       = new(GasPool).AddGas(block.GasLimit())
 	)
 
+	// Speculate the transactions concurrently if parallel execution is enabled
+	var executor *ParallelExecutor
+	if cfg.ParallelWorkers > 1 {
+		executor = NewParallelExecutor(p.config, p.bc, nil, statedb, header, block.Transactions(), cfg)
+		defer executor.Stop()
+	}
 	// Iterate over and process the individual transactions
 	for i, tx := range block.Transactions() {
 		statedb.Prepare(tx.Hash(), block.Hash(), i)
-		receipt, err := ApplyTransaction(p.config, p.bc, nil, gp, statedb, header, tx, usedGas, cfg)
+
+		var (
+			receipt *types.Receipt
+			err     error
+		)
+		if executor != nil {
+			receipt, err = executor.ApplyTransaction(gp, statedb, tx, usedGas)
+		} else {
+			receipt, err = ApplyTransaction(p.config, p.bc, nil, gp, statedb, header, tx, usedGas, cfg)
+		}
 		if err != nil {
 			return nil, nil, 0, err
 		}
@@ -80,10 +98,25 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 // for the transaction, gas used and an error if the transaction failed,
 // indicating the block was invalid.
 func ApplyTransaction(config *params.ChainConfig, bc ChainContext, author *common.Address, gp *GasPool, statedb *state.StateDB, header *types.Header, tx *types.Transaction, usedGas *uint64, cfg vm.Config) (*types.Receipt, error) {
+	// Typed transactions are only valid after the access list fork
+	if tx.Type() != types.LegacyTxType && !config.IsAccessList(header.Number) {
+		return nil, ErrTxTypeNotSupported
+	}
+	// Expiring transactions are only valid after the transaction expiry fork
+	if tx.Type() == types.ExpiringTxType && !config.IsTxExpiry(header.Number) {
+		return nil, ErrTxTypeNotSupported
+	}
+	// Private transactions must carry the hash of their payload and no value
+	if err := validatePrivateTx(config, tx); err != nil {
+		return nil, err
+	}
 	msg, err := tx.AsMessage(types.MakeSigner(config))
 	if err != nil {
 		return nil, err
 	}
+	if tx.IsPrivate() {
+		msg = msg.AsPrivate()
+	}
 	// Create a new context to be used in the EVM environment
 	context := NewEVMContext(msg, header, bc, author)
 	// Create a new environment which holds all relevant information
@@ -95,20 +128,27 @@ func ApplyTransaction(config *params.ChainConfig, bc ChainContext, author *commo
 		return nil, err
 	}
 	// Update the state with pending changes
-	var root []byte
-
 	statedb.Finalise(true)
 
+	return newReceipt(statedb, header, tx, vmenv.Context.Origin, msg.To() == nil, gas, failed, usedGas), nil
+}
+
+// newReceipt creates the receipt of a transaction already applied and finalised
+// on the given state, accumulating its gas into usedGas.
+func newReceipt(statedb *state.StateDB, header *types.Header, tx *types.Transaction, origin common.Address, create bool, gas uint64, failed bool, usedGas *uint64) *types.Receipt {
+	var root []byte
+
 	*usedGas += gas
 
 	// Create a new receipt for the transaction, storing the intermediate root and gas used by the tx
 	// based on the eip phase, we're passing whether the root touch-delete accounts.
 	receipt := types.NewReceipt(root, failed, *usedGas)
+	receipt.Type = tx.Type()
 	receipt.TxHash = tx.Hash()
 	receipt.GasUsed = gas
 	// if the transaction created a contract, store the creation address in the receipt.
-	if msg.To() == nil {
-		receipt.ContractAddress = crypto.CreateAddress(vmenv.Context.Origin, tx.Nonce())
+	if create {
+		receipt.ContractAddress = crypto.CreateAddress(origin, tx.Nonce())
 	}
 	// Set the receipt logs and create a bloom for filtering
 	receipt.Logs = statedb.GetLogs(tx.Hash())
@@ -117,5 +157,94 @@ func ApplyTransaction(config *params.ChainConfig, bc ChainContext, author *commo
 	receipt.BlockNumber = header.Number
 	receipt.TransactionIndex = uint(statedb.TxIndex())
 
-	return receipt, err
+	return receipt
+}
+
+// validatePrivateTx checks that a private transaction is allowed by the chain, and
+// that it carries the hash of its payload and transfers no value. Public
+// transactions are always valid.
+func validatePrivateTx(config *params.ChainConfig, tx *types.Transaction) error {
+	if !tx.IsPrivate() {
+		return nil
+	}
+	if !config.Privacy {
+		return ErrPrivateNotSupported
+	}
+	if len(tx.Data()) != common.HashLength {
+		return ErrPrivatePayload
+	}
+	if tx.Value().Sign() != 0 {
+		return ErrPrivateValue
+	}
+	return nil
+}
+
+// ProcessPrivate executes the private transactions of the block the node takes
+// part in against the private state, and returns their receipts. Transactions
+// whose payload is unknown to the manager are skipped.
+func (p *StateProcessor) ProcessPrivate(block *types.Block, privateState *state.StateDB, manager private.TransactionManager, cfg vm.Config) (types.Receipts, error) {
+	var (
+		receipts types.Receipts
+		usedGas  = new(uint64)
+		header   = block.Header()
+	)
+	for i, tx := range block.Transactions() {
+		if !tx.IsPrivate() {
+			continue
+		}
+		payload, err := manager.Receive(common.BytesToHash(tx.Data()))
+		if err != nil {
+			return nil, err
+		}
+		if payload == nil {
+			continue
+		}
+		privateState.Prepare(tx.Hash(), block.Hash(), i)
+		receipt, err := applyPrivateTransaction(p.config, p.bc, privateState, header, tx, payload, usedGas, cfg)
+		if err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, receipt)
+	}
+	return receipts, nil
+}
+
+// applyPrivateTransaction runs the payload of a private transaction against the
+// private state, returning its private receipt.
+func applyPrivateTransaction(config *params.ChainConfig, bc ChainContext, privateState *state.StateDB, header *types.Header, tx *types.Transaction, payload []byte, usedGas *uint64, cfg vm.Config) (*types.Receipt, error) {
+	from, err := types.Sender(types.MakeSigner(config), tx)
+	if err != nil {
+		return nil, err
+	}
+	// Private execution is free, the public side paid for the gas. Permissions are
+	// checked against the public state, which the private one has no contracts of.
+	msg := types.NewMessage(from, tx.To(), tx.Nonce(), new(big.Int), tx.Gas(), new(big.Int), payload, nil, false)
+	privateConfig := *config
+	privateConfig.AccountPermission = nil
+
+	context := NewEVMContext(msg, header, bc, nil)
+	vmenv := vm.NewEVM(context, privateState, &privateConfig, cfg)
+
+	// Private contracts get the address public ones would get
+	privateState.SetNonce(from, tx.Nonce())
+	_, gas, failed, err := ApplyMessage(vmenv, msg, new(GasPool).AddGas(tx.Gas()))
+	if err != nil {
+		return nil, err
+	}
+	privateState.Finalise(true)
+	*usedGas += gas
+
+	receipt := types.NewReceipt(nil, failed, *usedGas)
+	receipt.TxHash = tx.Hash()
+	receipt.GasUsed = gas
+	if msg.To() == nil {
+		receipt.ContractAddress = crypto.CreateAddress(from, tx.Nonce())
+	}
+	receipt.Logs = privateState.GetLogs(tx.Hash())
+	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+	receipt.BlockHash = privateState.BlockHash()
+	receipt.BlockNumber = header.Number
+	receipt.TransactionIndex = uint(privateState.TxIndex())
+
+	return receipt, nil
 }
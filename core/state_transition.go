@@ -22,6 +22,7 @@ import (
 	"math/big"
 
 	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/core/types"
 	"github.com/simplechain-org/go-simplechain/core/vm"
 	"github.com/simplechain-org/go-simplechain/log"
 	"github.com/simplechain-org/go-simplechain/params"
@@ -73,10 +74,24 @@ type Message interface {
 	Nonce() uint64
 	CheckNonce() bool
 	Data() []byte
+	AccessList() types.AccessList
 }
 
-// IntrinsicGas computes the 'intrinsic gas' for a message with the given data.
-func IntrinsicGas(data []byte, contractCreation, isEIP2028 bool) (uint64, error) {
+// privateMessage is implemented by messages that may be the public side of a
+// private transaction.
+type privateMessage interface {
+	IsPrivate() bool
+}
+
+// expiringMessage is implemented by messages that may carry an expiry limit.
+type expiringMessage interface {
+	ExpiryBlock() uint64
+	ExpiryTime() uint64
+}
+
+// IntrinsicGas computes the 'intrinsic gas' for a message with the given data
+// and access list.
+func IntrinsicGas(data []byte, accessList types.AccessList, contractCreation, isEIP2028 bool) (uint64, error) {
 	// Set the starting gas for the raw transaction
 	var gas uint64
 	if contractCreation {
@@ -109,6 +124,10 @@ func IntrinsicGas(data []byte, contractCreation, isEIP2028 bool) (uint64, error)
 		}
 		gas += z * params.TxDataZeroGas
 	}
+	if accessList != nil {
+		gas += uint64(len(accessList)) * params.TxAccessListAddressGas
+		gas += uint64(accessList.StorageKeys()) * params.TxAccessListStorageKeyGas
+	}
 	return gas, nil
 }
 
@@ -169,6 +188,15 @@ func (st *StateTransition) buyGas() error {
 }
 
 func (st *StateTransition) preCheck() error {
+	// Make sure the transaction has not expired yet.
+	if em, ok := st.msg.(expiringMessage); ok {
+		if block := em.ExpiryBlock(); block != 0 && st.evm.BlockNumber.Uint64() > block {
+			return ErrTxExpired
+		}
+		if time := em.ExpiryTime(); time != 0 && st.evm.Time.Uint64() > time {
+			return ErrTxExpired
+		}
+	}
 	// Make sure this transaction's nonce is correct.
 	if st.msg.CheckNonce() {
 		nonce := st.state.GetNonce(st.msg.From())
@@ -177,6 +205,10 @@ func (st *StateTransition) preCheck() error {
 		} else if nonce > st.msg.Nonce() {
 			return ErrNonceTooLow
 		}
+		// Make sure the sender is permitted to transact or deploy contracts.
+		if err := vm.CheckAccountPermission(st.evm.ChainConfig(), st.state, st.msg.From(), st.msg.To() == nil); err != nil {
+			return err
+		}
 	}
 	return st.buyGas()
 }
@@ -194,7 +226,7 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 	contractCreation := msg.To() == nil
 
 	// Pay intrinsic gas
-	gas, err := IntrinsicGas(st.data, contractCreation, isSingularity)
+	gas, err := IntrinsicGas(st.data, msg.AccessList(), contractCreation, isSingularity)
 	if err != nil {
 		return nil, 0, false, err
 	}
@@ -209,7 +241,11 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 		// error.
 		vmerr error
 	)
-	if contractCreation {
+	if pm, ok := msg.(privateMessage); ok && pm.IsPrivate() {
+		// The payload of a private transaction only runs against the private state
+		// of its participants, publicly it just consumes the nonce.
+		st.state.SetNonce(msg.From(), st.state.GetNonce(sender.Address())+1)
+	} else if contractCreation {
 		ret, _, st.gas, vmerr = evm.Create(sender, st.data, st.gas, st.value)
 	} else {
 		// Increment the nonce for the next transaction
@@ -0,0 +1,108 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/common/hexutil"
+	"github.com/simplechain-org/go-simplechain/core/types"
+)
+
+// TxLane is a priority lane of the transaction pool. Transactions matching a
+// lane are exempt from the price based eviction rules and are accounted against
+// the slot limit of the lane instead of the global ones. Only the transactions
+// of whitelisted senders skip the minimum gas price, as anyone can match the
+// destination and payload rules. For the same reason a full lane drops its
+// cheapest transaction, unless local or whitelisted, for a better paying one.
+// Miners include lane transactions ahead of all others, in lane order.
+type TxLane struct {
+	Name     string           // Human readable name of the lane
+	Senders  []common.Address // Senders whose transactions belong to the lane
+	To       []common.Address // Destination contracts whose transactions belong to the lane
+	Prefixes []string         // Payload prefixes, either plain text or 0x prefixed hex
+	Slots    uint64           // Maximum number of transactions kept in the lane
+}
+
+// DefaultTxLaneSlots is the slot limit of a lane that does not configure one.
+const DefaultTxLaneSlots = 256
+
+// txLaneMatcher is the preprocessed form of a TxLane used for matching.
+type txLaneMatcher struct {
+	senders  map[common.Address]struct{}
+	to       map[common.Address]struct{}
+	prefixes [][]byte
+}
+
+// newTxLaneMatcher preprocesses the match rules of a lane.
+func newTxLaneMatcher(lane TxLane) *txLaneMatcher {
+	m := &txLaneMatcher{
+		senders: make(map[common.Address]struct{}),
+		to:      make(map[common.Address]struct{}),
+	}
+	for _, addr := range lane.Senders {
+		m.senders[addr] = struct{}{}
+	}
+	for _, addr := range lane.To {
+		m.to[addr] = struct{}{}
+	}
+	for _, prefix := range lane.Prefixes {
+		m.prefixes = append(m.prefixes, parseLanePrefix(prefix))
+	}
+	return m
+}
+
+// match checks whether a transaction sent by from belongs to the lane.
+func (m *txLaneMatcher) match(from common.Address, tx *types.Transaction) bool {
+	if _, ok := m.senders[from]; ok {
+		return true
+	}
+	if to := tx.To(); to != nil {
+		if _, ok := m.to[*to]; ok {
+			return true
+		}
+	}
+	for _, prefix := range m.prefixes {
+		if bytes.HasPrefix(tx.Data(), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseLanePrefix converts a configured payload prefix into raw bytes. Hex
+// prefixes allow matching method selectors, anything else is taken verbatim
+// (e.g. "dpos:1:event:").
+func parseLanePrefix(prefix string) []byte {
+	if strings.HasPrefix(prefix, "0x") || strings.HasPrefix(prefix, "0X") {
+		if blob, err := hexutil.Decode(prefix); err == nil {
+			return blob
+		}
+	}
+	return []byte(prefix)
+}
+
+// validLanePrefix reports whether a configured payload prefix is usable.
+func validLanePrefix(prefix string) bool {
+	if strings.HasPrefix(prefix, "0x") || strings.HasPrefix(prefix, "0X") {
+		_, err := hexutil.Decode(prefix)
+		return err == nil
+	}
+	return prefix != ""
+}
@@ -453,8 +453,8 @@ func (l *txPricedList) Cap(threshold *big.Int, local *accountSet) types.Transact
 			save = append(save, tx)
 			break
 		}
-		// Non stale transaction found, discard unless local
-		if local.containsTx(tx) {
+		// Non stale transaction found, discard unless local or in a priority lane
+		if local.containsTx(tx) || l.all.InLane(tx.Hash()) {
 			save = append(save, tx)
 		} else {
 			drop = append(drop, tx)
@@ -473,7 +473,9 @@ func (l *txPricedList) Underpriced(tx *types.Transaction, local *accountSet) boo
 	if local.containsTx(tx) {
 		return false
 	}
-	// Discard stale price points if found at the heap start
+	// Discard stale price points if found at the heap start, and set aside
+	// priority lane ones as they are never evicted
+	var lanes []*types.Transaction
 	for len(*l.items) > 0 {
 		head := []*types.Transaction(*l.items)[0]
 		if l.all.Get(head.Hash()) == nil {
@@ -481,11 +483,22 @@ func (l *txPricedList) Underpriced(tx *types.Transaction, local *accountSet) boo
 			heap.Pop(l.items)
 			continue
 		}
+		if l.all.InLane(head.Hash()) {
+			lanes = append(lanes, heap.Pop(l.items).(*types.Transaction))
+			continue
+		}
 		break
 	}
+	defer func() {
+		for _, tx := range lanes {
+			heap.Push(l.items, tx)
+		}
+	}()
 	// Check if the transaction is underpriced or not
 	if len(*l.items) == 0 {
-		log.Error("Pricing query for empty pool") // This cannot happen, print to catch programming errors
+		if len(lanes) == 0 {
+			log.Error("Pricing query for empty pool") // This cannot happen, print to catch programming errors
+		}
 		return false
 	}
 	cheapest := []*types.Transaction(*l.items)[0]
@@ -505,8 +518,8 @@ func (l *txPricedList) Discard(count int, local *accountSet) types.Transactions
 			l.stales--
 			continue
 		}
-		// Non stale transaction found, discard unless local
-		if local.containsTx(tx) {
+		// Non stale transaction found, discard unless local or in a priority lane
+		if local.containsTx(tx) || l.all.InLane(tx.Hash()) {
 			save = append(save, tx)
 		} else {
 			drop = append(drop, tx)
@@ -30,6 +30,7 @@ import (
 	"github.com/simplechain-org/go-simplechain/consensus"
 	"github.com/simplechain-org/go-simplechain/core/state"
 	"github.com/simplechain-org/go-simplechain/core/types"
+	"github.com/simplechain-org/go-simplechain/core/vm"
 	"github.com/simplechain-org/go-simplechain/event"
 	"github.com/simplechain-org/go-simplechain/log"
 	"github.com/simplechain-org/go-simplechain/metrics"
@@ -57,6 +58,10 @@ var (
 	// with a different one without the required price bump.
 	ErrReplaceUnderpriced = errors.New("replacement transaction underpriced")
 
+	// ErrLaneFull is returned if a transaction belongs to a priority lane which
+	// has no free slots left.
+	ErrLaneFull = errors.New("transaction lane full")
+
 	// ErrInsufficientFunds is returned if the total cost of executing a transaction
 	// is higher than the balance of the user's account.
 	ErrInsufficientFunds = errors.New("insufficient funds for gas * price + value")
@@ -65,6 +70,10 @@ var (
 	// than required to start the invocation.
 	ErrIntrinsicGas = errors.New("intrinsic gas too low")
 
+	// ErrTxTypeNotSupported is returned if a transaction is not supported in the
+	// current network configuration.
+	ErrTxTypeNotSupported = types.ErrTxTypeNotSupported
+
 	// ErrGasLimit is returned if a transaction's requested gas limit exceeds the
 	// maximum allowance of the current block.
 	ErrGasLimit = errors.New("exceeds block gas limit")
@@ -102,6 +111,7 @@ var (
 	validTxMeter       = metrics.NewRegisteredMeter("txpool/valid", nil)
 	invalidTxMeter     = metrics.NewRegisteredMeter("txpool/invalid", nil)
 	underpricedTxMeter = metrics.NewRegisteredMeter("txpool/underpriced", nil)
+	expiredTxMeter     = metrics.NewRegisteredMeter("txpool/expired", nil)
 
 	pendingGauge = metrics.NewRegisteredGauge("txpool/pending", nil)
 	queuedGauge  = metrics.NewRegisteredGauge("txpool/queued", nil)
@@ -150,6 +160,8 @@ type TxPoolConfig struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	Lanes []TxLane // Priority lanes for system and consensus transactions
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -205,6 +217,23 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool lifetime", "provided", conf.Lifetime, "updated", DefaultTxPoolConfig.Lifetime)
 		conf.Lifetime = DefaultTxPoolConfig.Lifetime
 	}
+	conf.Lanes = make([]TxLane, len(config.Lanes))
+	for i, lane := range config.Lanes {
+		if lane.Slots < 1 {
+			log.Warn("Sanitizing invalid txpool lane slots", "lane", lane.Name, "provided", lane.Slots, "updated", DefaultTxLaneSlots)
+			lane.Slots = DefaultTxLaneSlots
+		}
+		prefixes := make([]string, 0, len(lane.Prefixes))
+		for _, prefix := range lane.Prefixes {
+			if !validLanePrefix(prefix) {
+				log.Warn("Dropping invalid txpool lane prefix", "lane", lane.Name, "prefix", prefix)
+				continue
+			}
+			prefixes = append(prefixes, prefix)
+		}
+		lane.Prefixes = prefixes
+		conf.Lanes[i] = lane
+	}
 	return conf
 }
 
@@ -226,13 +255,17 @@ type TxPool struct {
 	mu          sync.RWMutex
 
 	singularity bool // Fork indicator whether we are in the singularity stage.
+	accessList  bool // Fork indicator whether typed transactions are accepted.
+	txExpiry    bool // Fork indicator whether expiring transactions are accepted.
 
+	currentHead   *types.Header  // Current head of the blockchain
 	currentState  *state.StateDB // Current state in the blockchain head
 	pendingNonces *txNoncer      // Pending state tracking virtual nonces
 	currentMaxGas uint64         // Current gas limit for transaction caps
 
-	locals  *accountSet // Set of local transaction to exempt from eviction rules
-	journal *txJournal  // Journal of local transaction to back up to disk
+	locals  *accountSet      // Set of local transaction to exempt from eviction rules
+	lanes   []*txLaneMatcher // Priority lanes to exempt from price and global slot rules
+	journal *txJournal       // Journal of local transaction to back up to disk
 
 	pending         map[common.Address]*txList   // All currently processable transactions
 	queue           map[common.Address]*txList   // Queued but non-processable transactions
@@ -264,7 +297,7 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		config:          config,
 		chainconfig:     chainconfig,
 		chain:           chain,
-		signer:          types.NewEIP155Signer(chainconfig.ChainID),
+		signer:          types.MakeSigner(chainconfig),
 		pending:         make(map[common.Address]*txList),
 		queue:           make(map[common.Address]*txList),
 		beats:           make(map[common.Address]time.Time),
@@ -282,6 +315,11 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		log.Info("Setting new local account", "address", addr)
 		pool.locals.add(addr)
 	}
+	for _, lane := range config.Lanes {
+		log.Info("Setting up transaction lane", "name", lane.Name, "slots", lane.Slots)
+		pool.lanes = append(pool.lanes, newTxLaneMatcher(lane))
+	}
+	pool.all.classify = pool.Lane
 	pool.priced = newTxPricedList(pool.all)
 	pool.reset(nil, chain.CurrentBlock().Header())
 
@@ -513,9 +551,66 @@ func (pool *TxPool) local() map[common.Address]types.Transactions {
 	return txs
 }
 
+// Lane returns the index of the first priority lane the transaction belongs to,
+// or -1 if it is a regular transaction.
+func (pool *TxPool) Lane(tx *types.Transaction) int {
+	if len(pool.lanes) == 0 {
+		return -1
+	}
+	from, err := types.Sender(pool.signer, tx)
+	if err != nil {
+		return -1
+	}
+	for i, lane := range pool.lanes {
+		if lane.match(from, tx) {
+			return i
+		}
+	}
+	return -1
+}
+
+// laneSender reports whether an account is whitelisted by any priority lane.
+func (pool *TxPool) laneSender(addr common.Address) bool {
+	for _, lane := range pool.lanes {
+		if _, ok := lane.senders[addr]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Lanes returns the number of configured priority lanes.
+func (pool *TxPool) Lanes() int {
+	return len(pool.lanes)
+}
+
+// LaneName returns the name of the given priority lane, empty if there is no
+// such lane.
+func (pool *TxPool) LaneName(lane int) string {
+	if lane < 0 || lane >= len(pool.config.Lanes) {
+		return ""
+	}
+	return pool.config.Lanes[lane].Name
+}
+
 // validateTx checks whether a transaction is valid according to the consensus
 // rules and adheres to some heuristic limits of the local node (price and size).
 func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
+	// Accept only legacy transactions until the access list fork is enabled
+	if !pool.accessList && tx.Type() != types.LegacyTxType {
+		return ErrTxTypeNotSupported
+	}
+	if !pool.txExpiry && tx.Type() == types.ExpiringTxType {
+		return ErrTxTypeNotSupported
+	}
+	// Reject transactions which can't make it into the next block anymore
+	head := pool.currentHead
+	if head == nil {
+		head = pool.chain.CurrentBlock().Header() // Initial reset failed
+	}
+	if tx.Expired(head.Number.Uint64()+1, head.Time+1) {
+		return ErrTxExpired
+	}
 	// Heuristic limit, reject transactions over 32KB to prevent DOS attacks
 	if tx.Size() > 32*1024 {
 		return ErrOversizedData
@@ -536,9 +631,17 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	}
 	// Drop non-local transactions under our own minimal accepted gas price
 	local = local || pool.locals.contains(from) // account may be local even if the transaction arrived from the network
-	if !local && pool.gasPrice.Cmp(tx.GasPrice()) > 0 {
+	if !local && !pool.laneSender(from) && pool.gasPrice.Cmp(tx.GasPrice()) > 0 {
 		return ErrUnderpriced
 	}
+	// Private transactions carry the hash of their payload and no value
+	if err := validatePrivateTx(pool.chainconfig, tx); err != nil {
+		return err
+	}
+	// Ensure the sender is permitted to transact or deploy contracts
+	if err := vm.CheckAccountPermission(pool.chainconfig, pool.currentState, from, tx.To() == nil); err != nil {
+		return err
+	}
 	// Ensure the transaction adheres to nonce ordering
 	if pool.currentState.GetNonce(from) > tx.Nonce() {
 		return ErrNonceTooLow
@@ -549,7 +652,7 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 		return ErrInsufficientFunds
 	}
 	// Ensure the transaction has more gas than the basic tx fee.
-	intrGas, err := IntrinsicGas(tx.Data(), tx.To() == nil, pool.singularity)
+	intrGas, err := IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, pool.singularity)
 	if err != nil {
 		return err
 	}
@@ -581,8 +684,25 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 		return false, err
 	}
 	from, _ := types.Sender(pool.signer, tx) // already validated
-	// If the transaction pool is full, discard underpriced transactions
-	if uint64(pool.all.Count()) >= pool.config.GlobalSlots+pool.config.GlobalQueue {
+	// Priority lane transactions are only limited by the slots of their lane
+	if lane := pool.Lane(tx); lane >= 0 {
+		if uint64(pool.all.LaneCount(lane)) >= pool.config.Lanes[lane].Slots {
+			// A full lane only makes room for a better paying transaction, the ones
+			// of locals and whitelisted senders are never evicted
+			cheapest := pool.all.LaneCheapest(lane, func(tx *types.Transaction) bool {
+				from, _ := types.Sender(pool.signer, tx)
+				return pool.locals.contains(from) || pool.laneSender(from)
+			})
+			if cheapest == nil || cheapest.GasPrice().Cmp(tx.GasPrice()) >= 0 {
+				log.Trace("Discarding transaction for full lane", "hash", hash, "lane", pool.config.Lanes[lane].Name)
+				return false, ErrLaneFull
+			}
+			log.Trace("Discarding cheapest lane transaction", "hash", cheapest.Hash(), "lane", pool.config.Lanes[lane].Name, "price", cheapest.GasPrice())
+			underpricedTxMeter.Mark(1)
+			pool.removeTx(cheapest.Hash(), true)
+		}
+	} else if uint64(pool.all.RegularCount()) >= pool.config.GlobalSlots+pool.config.GlobalQueue {
+		// If the transaction pool is full, discard underpriced transactions
 		// If the new transaction is underpriced, don't accept it
 		if !local && pool.priced.Underpriced(tx, pool.locals) {
 			log.Trace("Discarding underpriced transaction", "hash", hash, "price", tx.GasPrice())
@@ -590,7 +710,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 			return false, ErrUnderpriced
 		}
 		// New transaction is better than our worse ones, make room for it
-		drop := pool.priced.Discard(pool.all.Count()-int(pool.config.GlobalSlots+pool.config.GlobalQueue-1), pool.locals)
+		drop := pool.priced.Discard(pool.all.RegularCount()-int(pool.config.GlobalSlots+pool.config.GlobalQueue-1), pool.locals)
 		for _, tx := range drop {
 			log.Trace("Discarding freshly underpriced transaction", "hash", tx.Hash(), "price", tx.GasPrice())
 			underpricedTxMeter.Mark(1)
@@ -1020,6 +1140,7 @@ func (pool *TxPool) runReorg(done chan struct{}, reset *txpoolResetRequest, dirt
 	if reset != nil {
 		// Reset from the old head to the new, rescheduling any reorged transactions
 		pool.reset(reset.oldHead, reset.newHead)
+		pool.evictExpired()
 
 		// Nonces were reset, discard any events that became stale
 		for addr := range events {
@@ -1145,6 +1266,7 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 		log.Error("Failed to reset txpool state", "err", err)
 		return
 	}
+	pool.currentHead = newHead
 	pool.currentState = statedb
 	pool.pendingNonces = newTxNoncer(statedb)
 	pool.currentMaxGas = newHead.GasLimit
@@ -1157,6 +1279,35 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	// Update all fork indicator by next pending block number.
 	next := new(big.Int).Add(newHead.Number, big.NewInt(1))
 	pool.singularity = pool.chainconfig.IsSingularity(next)
+	pool.accessList = pool.chainconfig.IsAccessList(next)
+	pool.txExpiry = pool.chainconfig.IsTxExpiry(next)
+}
+
+// evictExpired removes all transactions which can no longer be included in the
+// next block due to their expiry limits.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) evictExpired() {
+	if !pool.txExpiry {
+		return
+	}
+	number, time := pool.currentHead.Number.Uint64()+1, pool.currentHead.Time+1
+
+	var expired []common.Hash
+	for _, lists := range []map[common.Address]*txList{pool.pending, pool.queue} {
+		for _, list := range lists {
+			for _, tx := range list.txs.items {
+				if tx.Expired(number, time) {
+					expired = append(expired, tx.Hash())
+				}
+			}
+		}
+	}
+	for _, hash := range expired {
+		log.Trace("Removed expired transaction", "hash", hash)
+		pool.removeTx(hash, true)
+	}
+	expiredTxMeter.Mark(int64(len(expired)))
 }
 
 // promoteExecutables moves transactions that have become processable from the
@@ -1230,7 +1381,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 func (pool *TxPool) truncatePending() {
 	pending := uint64(0)
 	for _, list := range pool.pending {
-		pending += uint64(list.Len())
+		pending += uint64(list.Len() - pool.all.laneTxs(list))
 	}
 	if pending <= pool.config.GlobalSlots {
 		return
@@ -1241,7 +1392,7 @@ func (pool *TxPool) truncatePending() {
 	spammers := prque.New(nil)
 	for addr, list := range pool.pending {
 		// Only evict transactions from high rollers
-		if !pool.locals.contains(addr) && uint64(list.Len()) > pool.config.AccountSlots {
+		if !pool.locals.contains(addr) && !pool.laneSender(addr) && uint64(list.Len()) > pool.config.AccountSlots {
 			spammers.Push(addr, int64(list.Len()))
 		}
 	}
@@ -1315,7 +1466,7 @@ func (pool *TxPool) truncatePending() {
 func (pool *TxPool) truncateQueue() {
 	queued := uint64(0)
 	for _, list := range pool.queue {
-		queued += uint64(list.Len())
+		queued += uint64(list.Len() - pool.all.laneTxs(list))
 	}
 	if queued <= pool.config.GlobalQueue {
 		return
@@ -1324,7 +1475,7 @@ func (pool *TxPool) truncateQueue() {
 	// Sort all accounts with queued transactions by heartbeat
 	addresses := make(addressesByHeartbeat, 0, len(pool.queue))
 	for addr := range pool.queue {
-		if !pool.locals.contains(addr) { // don't drop locals
+		if !pool.locals.contains(addr) && !pool.laneSender(addr) { // don't drop locals and lane senders
 			addresses = append(addresses, addressByHeartbeat{addr, pool.beats[addr]})
 		}
 	}
@@ -1502,12 +1653,18 @@ func (as *accountSet) merge(other *accountSet) {
 type txLookup struct {
 	all  map[common.Hash]*types.Transaction
 	lock sync.RWMutex
+
+	classify func(*types.Transaction) int // Priority lane classifier, nil if lanes are unused
+	lanes    map[common.Hash]int          // Priority lane of each lane transaction
+	counts   map[int]int                  // Number of transactions in each priority lane
 }
 
 // newTxLookup returns a new txLookup structure.
 func newTxLookup() *txLookup {
 	return &txLookup{
-		all: make(map[common.Hash]*types.Transaction),
+		all:    make(map[common.Hash]*types.Transaction),
+		lanes:  make(map[common.Hash]int),
+		counts: make(map[int]int),
 	}
 }
 
@@ -1539,12 +1696,93 @@ func (t *txLookup) Count() int {
 	return len(t.all)
 }
 
+// RegularCount returns the number of items in the lookup not belonging to any
+// priority lane.
+func (t *txLookup) RegularCount() int {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return len(t.all) - len(t.lanes)
+}
+
+// LaneCount returns the number of items in the lookup belonging to a lane.
+func (t *txLookup) LaneCount(lane int) int {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.counts[lane]
+}
+
+// InLane reports whether the transaction with the given hash belongs to a
+// priority lane.
+func (t *txLookup) InLane(hash common.Hash) bool {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	_, ok := t.lanes[hash]
+	return ok
+}
+
+// LaneCheapest returns the lowest priced transaction of a lane, preferring the
+// highest nonce among equally priced ones. Transactions for which protected
+// returns true are skipped, nil is returned if none is left.
+func (t *txLookup) LaneCheapest(lane int, protected func(*types.Transaction) bool) *types.Transaction {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	var cheapest *types.Transaction
+	for hash, l := range t.lanes {
+		if l != lane {
+			continue
+		}
+		tx := t.all[hash]
+		if protected(tx) {
+			continue
+		}
+		if cheapest == nil {
+			cheapest = tx
+			continue
+		}
+		if cmp := tx.GasPrice().Cmp(cheapest.GasPrice()); cmp < 0 || (cmp == 0 && tx.Nonce() > cheapest.Nonce()) {
+			cheapest = tx
+		}
+	}
+	return cheapest
+}
+
+// laneTxs returns the number of priority lane transactions in a list.
+func (t *txLookup) laneTxs(list *txList) int {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if len(t.lanes) == 0 {
+		return 0
+	}
+	count := 0
+	for _, tx := range list.txs.items {
+		if _, ok := t.lanes[tx.Hash()]; ok {
+			count++
+		}
+	}
+	return count
+}
+
 // Add adds a transaction to the lookup.
 func (t *txLookup) Add(tx *types.Transaction) {
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
-	t.all[tx.Hash()] = tx
+	hash := tx.Hash()
+	if _, ok := t.all[hash]; ok {
+		return
+	}
+	t.all[hash] = tx
+	if t.classify != nil {
+		if lane := t.classify(tx); lane >= 0 {
+			t.lanes[hash] = lane
+			t.counts[lane]++
+		}
+	}
 }
 
 // Remove removes a transaction from the lookup.
@@ -1553,4 +1791,8 @@ func (t *txLookup) Remove(hash common.Hash) {
 	defer t.lock.Unlock()
 
 	delete(t.all, hash)
+	if lane, ok := t.lanes[hash]; ok {
+		delete(t.lanes, hash)
+		t.counts[lane]--
+	}
 }
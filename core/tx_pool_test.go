@@ -31,6 +31,7 @@ import (
 	"github.com/simplechain-org/go-simplechain/core/rawdb"
 	"github.com/simplechain-org/go-simplechain/core/state"
 	"github.com/simplechain-org/go-simplechain/core/types"
+	"github.com/simplechain-org/go-simplechain/core/vm"
 	"github.com/simplechain-org/go-simplechain/crypto"
 	"github.com/simplechain-org/go-simplechain/event"
 	"github.com/simplechain-org/go-simplechain/params"
@@ -282,6 +283,207 @@ func TestInvalidTransactions(t *testing.T) {
 	}
 }
 
+// Tests that access list transactions pay for their access list and are only
+// accepted once the access list fork is enabled.
+func TestTransactionAccessList(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	pool.currentState.AddBalance(from, big.NewInt(0xffffffffffffff))
+
+	accessListTx := func(nonce, gas uint64) *types.Transaction {
+		tx, _ := types.SignNewTx(key, types.NewEIP2930Signer(params.TestChainConfig.ChainID), &types.AccessListTx{
+			ChainID:    params.TestChainConfig.ChainID,
+			Nonce:      nonce,
+			To:         &common.Address{},
+			Gas:        gas,
+			GasPrice:   big.NewInt(1),
+			AccessList: types.AccessList{{Address: common.Address{1}, StorageKeys: []common.Hash{{1}}}},
+		})
+		return tx
+	}
+	intrinsic := params.TxGas + params.TxAccessListAddressGas + params.TxAccessListStorageKeyGas
+	if err := pool.AddRemote(accessListTx(0, intrinsic-1)); err != ErrIntrinsicGas {
+		t.Error("expected", ErrIntrinsicGas, "got", err)
+	}
+	if err := pool.AddRemote(accessListTx(0, intrinsic)); err != nil {
+		t.Error("expected", nil, "got", err)
+	}
+	// Before the fork, only legacy transactions are accepted
+	pool.mu.Lock()
+	pool.accessList = false
+	pool.mu.Unlock()
+
+	if err := pool.AddRemote(accessListTx(1, intrinsic)); err != ErrTxTypeNotSupported {
+		t.Error("expected", ErrTxTypeNotSupported, "got", err)
+	}
+	if err := pool.AddRemote(transaction(1, 100000, key)); err != nil {
+		t.Error("expected", nil, "got", err)
+	}
+}
+
+// Tests that expiring transactions are rejected once they can't make it into the
+// next block, and evicted from the pool when a new head expires them.
+func TestTransactionExpiry(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	pool.currentState.AddBalance(from, big.NewInt(0xffffffffffffff))
+
+	expiringTx := func(nonce, expiryBlock, expiryTime uint64) *types.Transaction {
+		tx, _ := types.SignNewTx(key, types.NewEIP2930Signer(params.TestChainConfig.ChainID), &types.ExpiringTx{
+			ChainID:     params.TestChainConfig.ChainID,
+			Nonce:       nonce,
+			To:          &common.Address{},
+			Gas:         100000,
+			GasPrice:    big.NewInt(1),
+			ExpiryBlock: expiryBlock,
+			ExpiryTime:  expiryTime,
+		})
+		return tx
+	}
+	pool.mu.Lock()
+	pool.currentHead = &types.Header{Number: big.NewInt(10), Time: 100}
+	pool.mu.Unlock()
+
+	if err := pool.AddRemote(expiringTx(0, 10, 0)); err != ErrTxExpired {
+		t.Error("expected", ErrTxExpired, "got", err)
+	}
+	if err := pool.AddRemote(expiringTx(0, 0, 100)); err != ErrTxExpired {
+		t.Error("expected", ErrTxExpired, "got", err)
+	}
+	if err := pool.AddRemotesSync([]*types.Transaction{expiringTx(0, 11, 0), expiringTx(1, 0, 200), expiringTx(2, 20, 0)})[0]; err != nil {
+		t.Error("expected", nil, "got", err)
+	}
+	if err := pool.AddRemote(expiringTx(4, 11, 0)); err != nil {
+		t.Error("expected", nil, "got", err)
+	}
+	if pending, queued := pool.Stats(); pending != 3 || queued != 1 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want %d/%d", pending, queued, 3, 1)
+	}
+	// Advance the head past the first expiry, dropping the head of the account
+	pool.mu.Lock()
+	pool.currentHead = &types.Header{Number: big.NewInt(11), Time: 110}
+	pool.evictExpired()
+	pool.mu.Unlock()
+
+	if pending, queued := pool.Stats(); pending != 0 || queued != 2 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want %d/%d", pending, queued, 0, 2)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	// Before the fork, expiring transactions are not accepted
+	pool.mu.Lock()
+	pool.txExpiry = false
+	pool.mu.Unlock()
+
+	if err := pool.AddRemote(expiringTx(0, 20, 0)); err != ErrTxTypeNotSupported {
+		t.Error("expected", ErrTxTypeNotSupported, "got", err)
+	}
+}
+
+// Tests that expiring transactions are checked against the chain head if the
+// pool failed to set up its own, instead of crashing.
+func TestTransactionExpiryWithoutHead(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	pool.currentState.AddBalance(from, big.NewInt(0xffffffffffffff))
+
+	pool.mu.Lock()
+	pool.currentHead = nil
+	pool.mu.Unlock()
+
+	tx, _ := types.SignNewTx(key, types.NewEIP2930Signer(params.TestChainConfig.ChainID), &types.ExpiringTx{
+		ChainID:     params.TestChainConfig.ChainID,
+		To:          &common.Address{},
+		Gas:         100000,
+		GasPrice:    big.NewInt(1),
+		ExpiryBlock: 1,
+	})
+	if err := pool.AddRemote(tx); err != nil {
+		t.Error("expected", nil, "got", err)
+	}
+}
+
+// Tests that transactions from accounts without the required roles are rejected
+// on chains with account permissioning.
+func TestTransactionPermission(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	pool.currentState.AddBalance(from, big.NewInt(0xffffffffffffff))
+
+	config := *params.TestChainConfig
+	config.AccountPermission = &params.AccountPermissionConfig{Accounts: make(map[common.Address]params.AccountRole)}
+	pool.chainconfig = &config
+
+	deploy := func(nonce uint64) *types.Transaction {
+		tx, _ := types.SignTx(types.NewContractCreation(nonce, big.NewInt(0), 100000, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+		return tx
+	}
+	if err := pool.AddRemote(transaction(0, 100000, key)); err != vm.ErrTxNotPermitted {
+		t.Error("expected", vm.ErrTxNotPermitted, "got", err)
+	}
+	config.AccountPermission.Accounts[from] = params.AccountCanTransact
+	if err := pool.AddRemote(deploy(0)); err != vm.ErrDeployNotPermitted {
+		t.Error("expected", vm.ErrDeployNotPermitted, "got", err)
+	}
+	if err := pool.AddRemote(transaction(0, 100000, key)); err != nil {
+		t.Error("expected", nil, "got", err)
+	}
+	config.AccountPermission.Accounts[from] = params.AccountAdmin
+	if err := pool.AddRemote(deploy(1)); err != nil {
+		t.Error("expected", nil, "got", err)
+	}
+}
+
+func TestTransactionPrivate(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	pool.currentState.AddBalance(from, big.NewInt(0xffffffffffffff))
+
+	private := func(nonce uint64, value int64, data []byte) *types.Transaction {
+		tx, _ := types.SignTx(types.NewTransaction(nonce, common.Address{}, big.NewInt(value), 100000, big.NewInt(1), data), types.HomesteadSigner{}, key)
+		return tx.AsPrivate()
+	}
+	hash := common.HexToHash("0x01").Bytes()
+	if err := pool.AddRemote(private(0, 0, hash)); err != ErrInvalidSender {
+		t.Error("expected", ErrInvalidSender, "got", err)
+	}
+	config := *params.TestChainConfig
+	config.Privacy = true
+	pool.chainconfig = &config
+	pool.signer = types.MakeSigner(&config)
+
+	if err := pool.AddRemote(private(0, 0, []byte{0x01})); err != ErrPrivatePayload {
+		t.Error("expected", ErrPrivatePayload, "got", err)
+	}
+	if err := pool.AddRemote(private(0, 1, hash)); err != ErrPrivateValue {
+		t.Error("expected", ErrPrivateValue, "got", err)
+	}
+	if err := pool.AddRemote(private(0, 0, hash)); err != nil {
+		t.Error("expected", nil, "got", err)
+	}
+}
+
 func TestTransactionQueue(t *testing.T) {
 	t.Parallel()
 
@@ -1395,6 +1597,105 @@ func TestTransactionPoolUnderpricing(t *testing.T) {
 	}
 }
 
+// Tests that priority lane transactions bypass the eviction and global slot rules
+// of the pool, but are limited by the slots of their own lane.
+func TestTransactionPoolLanes(t *testing.T) {
+	t.Parallel()
+
+	// Create the pool with a payload prefix lane and a sender lane
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	keys := make([]*ecdsa.PrivateKey, 5)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	config := testTxPoolConfig
+	config.GlobalSlots = 2
+	config.GlobalQueue = 2
+	config.Lanes = []TxLane{
+		{Name: "dpos", Prefixes: []string{"dpos:1:event:"}, Slots: 2},
+		{Name: "anchors", Senders: []common.Address{crypto.PubkeyToAddress(keys[3].PublicKey)}},
+	}
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	for _, key := range keys {
+		pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+	}
+	laneTransaction := func(nonce uint64, price int64, data string, key *ecdsa.PrivateKey) *types.Transaction {
+		tx, _ := types.SignTx(types.NewTransaction(nonce, common.Address{}, big.NewInt(0), 100000, big.NewInt(price), []byte(data)), types.HomesteadSigner{}, key)
+		return tx
+	}
+	// Fill up the regular slots of the pool
+	pool.AddRemotesSync([]*types.Transaction{
+		pricedTransaction(0, 100000, big.NewInt(2), keys[0]),
+		pricedTransaction(1, 100000, big.NewInt(2), keys[0]),
+		pricedTransaction(0, 100000, big.NewInt(2), keys[1]),
+		pricedTransaction(1, 100000, big.NewInt(2), keys[1]),
+	})
+	// Cheap lane transactions must be accepted without evicting anything, but
+	// only whitelisted senders may skip the minimum gas price
+	dpos := laneTransaction(0, 1, "dpos:1:event:vote", keys[2])
+	if lane := pool.Lane(dpos); lane != 0 {
+		t.Fatalf("payload lane mismatch: have %d, want %d", lane, 0)
+	}
+	if err := pool.addRemoteSync(laneTransaction(0, 0, "dpos:1:event:vote", keys[2])); err != ErrUnderpriced {
+		t.Fatalf("free payload lane transaction error mismatch: have %v, want %v", err, ErrUnderpriced)
+	}
+	if err := pool.addRemoteSync(dpos); err != nil {
+		t.Fatalf("failed to add lane transaction: %v", err)
+	}
+	if err := pool.addRemoteSync(laneTransaction(1, 1, "dpos:1:event:confirm", keys[2])); err != nil {
+		t.Fatalf("failed to add lane transaction: %v", err)
+	}
+	if err := pool.addRemoteSync(laneTransaction(2, 1, "dpos:1:event:proposal", keys[2])); err != ErrLaneFull {
+		t.Fatalf("full lane error mismatch: have %v, want %v", err, ErrLaneFull)
+	}
+	// A better paying transaction evicts the cheapest one of the full lane
+	if err := pool.addRemoteSync(laneTransaction(0, 1, "dpos:1:event:vote", keys[4])); err != ErrLaneFull {
+		t.Fatalf("equally priced lane transaction error mismatch: have %v, want %v", err, ErrLaneFull)
+	}
+	if err := pool.addRemoteSync(laneTransaction(0, 2, "dpos:1:event:vote", keys[4])); err != nil {
+		t.Fatalf("failed to add better paying lane transaction: %v", err)
+	}
+	if pool.Get(dpos.Hash()) == nil {
+		t.Fatalf("lowest nonce lane transaction evicted")
+	}
+	anchor := laneTransaction(0, 0, "", keys[3])
+	if lane := pool.Lane(anchor); lane != 1 {
+		t.Fatalf("sender lane mismatch: have %d, want %d", lane, 1)
+	}
+	if err := pool.addRemoteSync(anchor); err != nil {
+		t.Fatalf("failed to add lane transaction: %v", err)
+	}
+	if lane := pool.Lane(pricedTransaction(0, 100000, big.NewInt(2), keys[0])); lane != -1 {
+		t.Fatalf("regular transaction lane mismatch: have %d, want %d", lane, -1)
+	}
+	pending, queued := pool.Stats()
+	if pending != 7 || queued != 0 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want %d/%d", pending, queued, 7, 0)
+	}
+	// Regular transactions must still be priced against regular ones only
+	if err := pool.addRemoteSync(pricedTransaction(2, 100000, big.NewInt(1), keys[1])); err != ErrUnderpriced {
+		t.Fatalf("adding underpriced transaction error mismatch: have %v, want %v", err, ErrUnderpriced)
+	}
+	if err := pool.addRemoteSync(pricedTransaction(2, 100000, big.NewInt(3), keys[1])); err != nil {
+		t.Fatalf("failed to add well priced transaction: %v", err)
+	}
+	if count := pool.all.RegularCount(); count != 4 {
+		t.Fatalf("regular transaction count mismatch: have %d, want %d", count, 4)
+	}
+	for _, lane := range []int{0, 1} {
+		if count, want := pool.all.LaneCount(lane), 2-lane; count != want {
+			t.Fatalf("lane %d transaction count mismatch: have %d, want %d", lane, count, want)
+		}
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // Tests that more expensive transactions push out cheap ones from the pool, but
 // without producing instability by creating gaps that start jumping transactions
 // back and forth between queued/pending.
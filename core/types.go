@@ -20,6 +20,7 @@ import (
 	"github.com/simplechain-org/go-simplechain/core/state"
 	"github.com/simplechain-org/go-simplechain/core/types"
 	"github.com/simplechain-org/go-simplechain/core/vm"
+	"github.com/simplechain-org/go-simplechain/private"
 )
 
 // Validator is an interface which defines the standard for block validation. It
@@ -48,4 +49,8 @@ type Processor interface {
 	// the transaction messages using the statedb and applying any rewards to both
 	// the processor (coinbase) and any included uncles.
 	Process(block *types.Block, statedb *state.StateDB, cfg vm.Config) (types.Receipts, []*types.Log, uint64, error)
+
+	// ProcessPrivate runs the private transactions of the block the node takes part
+	// in against the private state, returning their receipts.
+	ProcessPrivate(block *types.Block, privateState *state.StateDB, manager private.TransactionManager, cfg vm.Config) (types.Receipts, error)
 }
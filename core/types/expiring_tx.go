@@ -0,0 +1,98 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"math/big"
+
+	"github.com/simplechain-org/go-simplechain/common"
+)
+
+// ExpiringTx is the data of expiring transactions, which are only valid up to a
+// maximum block number and/or block timestamp. A zero limit is not enforced.
+type ExpiringTx struct {
+	ChainID     *big.Int        // destination chain ID
+	Nonce       uint64          // nonce of sender account
+	GasPrice    *big.Int        // wei per gas
+	Gas         uint64          // gas limit
+	To          *common.Address `rlp:"nil"` // nil means contract creation
+	Value       *big.Int        // wei amount
+	Data        []byte          // contract invocation input data
+	AccessList  AccessList      // EIP-2930 access list
+	ExpiryBlock uint64          // last block number the transaction may be included in
+	ExpiryTime  uint64          // last block timestamp the transaction may be included at
+	V, R, S     *big.Int        // signature values
+}
+
+// copy creates a deep copy of the transaction data and initializes all fields.
+func (tx *ExpiringTx) copy() TxData {
+	cpy := &ExpiringTx{
+		Nonce:       tx.Nonce,
+		To:          copyAddressPtr(tx.To),
+		Data:        common.CopyBytes(tx.Data),
+		Gas:         tx.Gas,
+		ExpiryBlock: tx.ExpiryBlock,
+		ExpiryTime:  tx.ExpiryTime,
+		// These are copied below.
+		AccessList: make(AccessList, len(tx.AccessList)),
+		Value:      new(big.Int),
+		ChainID:    new(big.Int),
+		GasPrice:   new(big.Int),
+		V:          new(big.Int),
+		R:          new(big.Int),
+		S:          new(big.Int),
+	}
+	copy(cpy.AccessList, tx.AccessList)
+	if tx.Value != nil {
+		cpy.Value.Set(tx.Value)
+	}
+	if tx.ChainID != nil {
+		cpy.ChainID.Set(tx.ChainID)
+	}
+	if tx.GasPrice != nil {
+		cpy.GasPrice.Set(tx.GasPrice)
+	}
+	if tx.V != nil {
+		cpy.V.Set(tx.V)
+	}
+	if tx.R != nil {
+		cpy.R.Set(tx.R)
+	}
+	if tx.S != nil {
+		cpy.S.Set(tx.S)
+	}
+	return cpy
+}
+
+// accessors for innerTx.
+func (tx *ExpiringTx) txType() byte           { return ExpiringTxType }
+func (tx *ExpiringTx) chainID() *big.Int      { return tx.ChainID }
+func (tx *ExpiringTx) accessList() AccessList { return tx.AccessList }
+func (tx *ExpiringTx) data() []byte           { return tx.Data }
+func (tx *ExpiringTx) gas() uint64            { return tx.Gas }
+func (tx *ExpiringTx) gasPrice() *big.Int     { return tx.GasPrice }
+func (tx *ExpiringTx) value() *big.Int        { return tx.Value }
+func (tx *ExpiringTx) nonce() uint64          { return tx.Nonce }
+func (tx *ExpiringTx) to() *common.Address    { return tx.To }
+
+func (tx *ExpiringTx) rawSignatureValues() (v, r, s *big.Int) {
+	return tx.V, tx.R, tx.S
+}
+
+func (tx *ExpiringTx) setSignatureValues(v, r, s *big.Int) {
+	tx.V, tx.R, tx.S = v, r, s
+}
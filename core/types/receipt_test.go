@@ -281,3 +281,66 @@ func clearComputedFieldsOnLog(t *testing.T, log *Log) {
 	log.TxIndex = math.MaxUint32
 	log.Index = math.MaxUint32
 }
+
+// Tests that receipts of typed transactions are encoded in their envelope, and
+// that their type is restored on decoding and derivation.
+func TestTypedReceiptEncoding(t *testing.T) {
+	receipt := &Receipt{
+		Type:              AccessListTxType,
+		Status:            ReceiptStatusSuccessful,
+		CumulativeGasUsed: 1,
+		Logs: []*Log{
+			{Address: common.BytesToAddress([]byte{0x11}), Topics: []common.Hash{{1}}, Data: []byte{0x01}},
+		},
+	}
+	receipt.Bloom = CreateBloom(Receipts{receipt})
+
+	// The trie encoding is the raw envelope, the stream encoding wraps it in a string
+	binary, err := receipt.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+	if binary[0] != AccessListTxType {
+		t.Fatalf("envelope type mismatch: have %d, want %d", binary[0], AccessListTxType)
+	}
+	if have := (Receipts{receipt}).GetRlp(0); !bytes.Equal(have, binary) {
+		t.Errorf("trie encoding mismatch: have %x, want %x", have, binary)
+	}
+	enc, err := rlp.EncodeToBytes(receipt)
+	if err != nil {
+		t.Fatalf("RLP encoding failed: %v", err)
+	}
+	if want, _ := rlp.EncodeToBytes(binary); !bytes.Equal(enc, want) {
+		t.Errorf("stream encoding mismatch: have %x, want %x", enc, want)
+	}
+	var dec Receipt
+	if err := rlp.DecodeBytes(enc, &dec); err != nil {
+		t.Fatalf("RLP decoding failed: %v", err)
+	}
+	if dec.Type != AccessListTxType || dec.Status != receipt.Status || dec.CumulativeGasUsed != receipt.CumulativeGasUsed || dec.Bloom != receipt.Bloom {
+		t.Errorf("decoded receipt mismatch: have %+v, want %+v", dec, receipt)
+	}
+	// Unknown receipt types are rejected
+	binary[0] = 0x7f
+	enc, _ = rlp.EncodeToBytes(binary)
+	if err := rlp.DecodeBytes(enc, &dec); err != ErrTxTypeNotSupported {
+		t.Errorf("unknown receipt type decoded: %v", err)
+	}
+	// The storage encoding drops the type, it is derived from the transaction
+	stored, err := rlp.EncodeToBytes((*ReceiptForStorage)(receipt))
+	if err != nil {
+		t.Fatalf("storage encoding failed: %v", err)
+	}
+	var restored ReceiptForStorage
+	if err := rlp.DecodeBytes(stored, &restored); err != nil {
+		t.Fatalf("storage decoding failed: %v", err)
+	}
+	tx := NewTx(&AccessListTx{ChainID: big.NewInt(1), To: &common.Address{1}, GasPrice: new(big.Int)})
+	receipts := Receipts{(*Receipt)(&restored)}
+	if err := receipts.DeriveFields(params.TestChainConfig, common.Hash{1}, 1, Transactions{tx}); err != nil {
+		t.Fatalf("DeriveFields failed: %v", err)
+	}
+	if receipts[0].Type != AccessListTxType {
+		t.Errorf("derived type mismatch: have %d, want %d", receipts[0].Type, AccessListTxType)
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/common/hexutil"
+	"github.com/simplechain-org/go-simplechain/crypto"
+)
+
+// txJSON is the JSON representation of transactions.
+type txJSON struct {
+	Type hexutil.Uint64 `json:"type"`
+
+	// Common transaction fields:
+	Nonce    *hexutil.Uint64 `json:"nonce"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	Gas      *hexutil.Uint64 `json:"gas"`
+	Value    *hexutil.Big    `json:"value"`
+	Data     *hexutil.Bytes  `json:"input"`
+	V        *hexutil.Big    `json:"v"`
+	R        *hexutil.Big    `json:"r"`
+	S        *hexutil.Big    `json:"s"`
+	To       *common.Address `json:"to"`
+
+	// Access list transaction fields:
+	ChainID    *hexutil.Big `json:"chainId,omitempty"`
+	AccessList *AccessList  `json:"accessList,omitempty"`
+
+	// Expiring transaction fields:
+	ExpiryBlock *hexutil.Uint64 `json:"expiryBlock,omitempty"`
+	ExpiryTime  *hexutil.Uint64 `json:"expiryTime,omitempty"`
+
+	// Only used for encoding:
+	Hash common.Hash `json:"hash"`
+}
+
+// MarshalJSON encodes the web3 RPC transaction format.
+func (tx *Transaction) MarshalJSON() ([]byte, error) {
+	var enc txJSON
+	// These are set for all tx types.
+	enc.Hash = tx.Hash()
+	enc.Type = hexutil.Uint64(tx.Type())
+
+	// Other fields are set conditionally depending on tx type.
+	switch tx := tx.inner.(type) {
+	case *LegacyTx:
+		enc.Nonce = (*hexutil.Uint64)(&tx.Nonce)
+		enc.Gas = (*hexutil.Uint64)(&tx.Gas)
+		enc.GasPrice = (*hexutil.Big)(tx.GasPrice)
+		enc.Value = (*hexutil.Big)(tx.Value)
+		enc.Data = (*hexutil.Bytes)(&tx.Data)
+		enc.To = tx.To
+		enc.V = (*hexutil.Big)(tx.V)
+		enc.R = (*hexutil.Big)(tx.R)
+		enc.S = (*hexutil.Big)(tx.S)
+	case *AccessListTx:
+		enc.ChainID = (*hexutil.Big)(tx.ChainID)
+		enc.AccessList = &tx.AccessList
+		enc.Nonce = (*hexutil.Uint64)(&tx.Nonce)
+		enc.Gas = (*hexutil.Uint64)(&tx.Gas)
+		enc.GasPrice = (*hexutil.Big)(tx.GasPrice)
+		enc.Value = (*hexutil.Big)(tx.Value)
+		enc.Data = (*hexutil.Bytes)(&tx.Data)
+		enc.To = tx.To
+		enc.V = (*hexutil.Big)(tx.V)
+		enc.R = (*hexutil.Big)(tx.R)
+		enc.S = (*hexutil.Big)(tx.S)
+	case *ExpiringTx:
+		enc.ChainID = (*hexutil.Big)(tx.ChainID)
+		enc.AccessList = &tx.AccessList
+		enc.ExpiryBlock = (*hexutil.Uint64)(&tx.ExpiryBlock)
+		enc.ExpiryTime = (*hexutil.Uint64)(&tx.ExpiryTime)
+		enc.Nonce = (*hexutil.Uint64)(&tx.Nonce)
+		enc.Gas = (*hexutil.Uint64)(&tx.Gas)
+		enc.GasPrice = (*hexutil.Big)(tx.GasPrice)
+		enc.Value = (*hexutil.Big)(tx.Value)
+		enc.Data = (*hexutil.Bytes)(&tx.Data)
+		enc.To = tx.To
+		enc.V = (*hexutil.Big)(tx.V)
+		enc.R = (*hexutil.Big)(tx.R)
+		enc.S = (*hexutil.Big)(tx.S)
+	}
+	return json.Marshal(&enc)
+}
+
+// UnmarshalJSON decodes the web3 RPC transaction format.
+func (tx *Transaction) UnmarshalJSON(input []byte) error {
+	var dec txJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+
+	// Decode / verify fields according to transaction type.
+	var inner TxData
+	switch dec.Type {
+	case LegacyTxType:
+		var itx LegacyTx
+		inner = &itx
+		if err := decodeCommonFields(dec, &itx.Nonce, &itx.GasPrice, &itx.Gas, &itx.Value, &itx.Data, &itx.V, &itx.R, &itx.S); err != nil {
+			return err
+		}
+		itx.To = dec.To
+		withSignature := itx.V.Sign() != 0 || itx.R.Sign() != 0 || itx.S.Sign() != 0
+		if withSignature {
+			var V byte
+			if isProtectedV(itx.V) {
+				chainID := DeriveChainId(itx.V).Uint64()
+				V = byte(itx.V.Uint64() - 35 - 2*chainID)
+			} else {
+				V = byte(itx.V.Uint64() - 27)
+			}
+			if !crypto.ValidateSignatureValues(V, itx.R, itx.S, false) {
+				return ErrInvalidSig
+			}
+		}
+
+	case AccessListTxType:
+		var itx AccessListTx
+		inner = &itx
+		if dec.ChainID == nil {
+			return errors.New("missing required field 'chainId' in transaction")
+		}
+		if dec.AccessList == nil {
+			return errors.New("missing required field 'accessList' in transaction")
+		}
+		itx.ChainID = (*big.Int)(dec.ChainID)
+		itx.AccessList = *dec.AccessList
+		if err := decodeCommonFields(dec, &itx.Nonce, &itx.GasPrice, &itx.Gas, &itx.Value, &itx.Data, &itx.V, &itx.R, &itx.S); err != nil {
+			return err
+		}
+		itx.To = dec.To
+		withSignature := itx.V.Sign() != 0 || itx.R.Sign() != 0 || itx.S.Sign() != 0
+		if withSignature {
+			if !itx.V.IsUint64() || itx.V.Uint64() > 1 || !crypto.ValidateSignatureValues(byte(itx.V.Uint64()), itx.R, itx.S, false) {
+				return ErrInvalidSig
+			}
+		}
+
+	case ExpiringTxType:
+		var itx ExpiringTx
+		inner = &itx
+		if dec.ChainID == nil {
+			return errors.New("missing required field 'chainId' in transaction")
+		}
+		if dec.ExpiryBlock == nil && dec.ExpiryTime == nil {
+			return errors.New("missing required field 'expiryBlock' or 'expiryTime' in transaction")
+		}
+		itx.ChainID = (*big.Int)(dec.ChainID)
+		if dec.AccessList != nil {
+			itx.AccessList = *dec.AccessList
+		}
+		if dec.ExpiryBlock != nil {
+			itx.ExpiryBlock = uint64(*dec.ExpiryBlock)
+		}
+		if dec.ExpiryTime != nil {
+			itx.ExpiryTime = uint64(*dec.ExpiryTime)
+		}
+		if err := decodeCommonFields(dec, &itx.Nonce, &itx.GasPrice, &itx.Gas, &itx.Value, &itx.Data, &itx.V, &itx.R, &itx.S); err != nil {
+			return err
+		}
+		itx.To = dec.To
+		withSignature := itx.V.Sign() != 0 || itx.R.Sign() != 0 || itx.S.Sign() != 0
+		if withSignature {
+			if !itx.V.IsUint64() || itx.V.Uint64() > 1 || !crypto.ValidateSignatureValues(byte(itx.V.Uint64()), itx.R, itx.S, false) {
+				return ErrInvalidSig
+			}
+		}
+
+	default:
+		return ErrTxTypeNotSupported
+	}
+
+	// Now set the inner transaction.
+	tx.setDecoded(inner, 0)
+	return nil
+}
+
+// decodeCommonFields checks the presence of the fields shared by all transaction
+// types and copies them into the given destinations.
+func decodeCommonFields(dec txJSON, nonce *uint64, gasPrice **big.Int, gas *uint64, value **big.Int, data *[]byte, v, r, s **big.Int) error {
+	if dec.Nonce == nil {
+		return errors.New("missing required field 'nonce' in transaction")
+	}
+	*nonce = uint64(*dec.Nonce)
+	if dec.GasPrice == nil {
+		return errors.New("missing required field 'gasPrice' in transaction")
+	}
+	*gasPrice = (*big.Int)(dec.GasPrice)
+	if dec.Gas == nil {
+		return errors.New("missing required field 'gas' in transaction")
+	}
+	*gas = uint64(*dec.Gas)
+	if dec.Value == nil {
+		return errors.New("missing required field 'value' in transaction")
+	}
+	*value = (*big.Int)(dec.Value)
+	if dec.Data == nil {
+		return errors.New("missing required field 'input' in transaction")
+	}
+	*data = *dec.Data
+	if dec.V == nil {
+		return errors.New("missing required field 'v' in transaction")
+	}
+	*v = (*big.Int)(dec.V)
+	if dec.R == nil {
+		return errors.New("missing required field 'r' in transaction")
+	}
+	*r = (*big.Int)(dec.R)
+	if dec.S == nil {
+		return errors.New("missing required field 's' in transaction")
+	}
+	*s = (*big.Int)(dec.S)
+	return nil
+}
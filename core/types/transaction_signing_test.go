@@ -22,6 +22,7 @@ import (
 
 	"github.com/simplechain-org/go-simplechain/common"
 	"github.com/simplechain-org/go-simplechain/crypto"
+	"github.com/simplechain-org/go-simplechain/params"
 	"github.com/simplechain-org/go-simplechain/rlp"
 )
 
@@ -44,6 +45,96 @@ func TestEIP155Signing(t *testing.T) {
 	}
 }
 
+func TestEIP2930Signing(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+
+	signer := NewEIP2930Signer(big.NewInt(18))
+	tx, err := SignNewTx(key, signer, &AccessListTx{
+		ChainID:    big.NewInt(18),
+		To:         &addr,
+		GasPrice:   new(big.Int),
+		AccessList: AccessList{{Address: addr, StorageKeys: []common.Hash{{1}}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, err := Sender(signer, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from != addr {
+		t.Errorf("exected from and address to be equal. Got %x want %x", from, addr)
+	}
+	// Typed transactions are rejected by the older signers and by other chains
+	if _, err := Sender(NewEIP155Signer(big.NewInt(18)), tx); err != ErrTxTypeNotSupported {
+		t.Errorf("typed transaction accepted by EIP155 signer: %v", err)
+	}
+	if _, err := Sender(NewEIP2930Signer(big.NewInt(19)), tx); err != ErrInvalidChainId {
+		t.Errorf("typed transaction accepted by another chain: %v", err)
+	}
+	if _, _, _, err := NewEIP2930Signer(big.NewInt(19)).SignatureValues(tx, make([]byte, crypto.SignatureLength)); err != ErrInvalidChainId {
+		t.Errorf("typed transaction signed for another chain: %v", err)
+	}
+	// Legacy transactions are still recovered with the EIP155 rules
+	legacy, err := SignTx(NewTransaction(0, addr, new(big.Int), 0, new(big.Int), nil), signer, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from, err := Sender(NewEIP155Signer(big.NewInt(18)), legacy); err != nil || from != addr {
+		t.Errorf("legacy transaction not recovered by EIP155 signer: %x, %v", from, err)
+	}
+	// Private transactions can only be legacy ones
+	if tx.AsPrivate().IsPrivate() {
+		t.Error("typed transaction marked private")
+	}
+}
+
+func TestPrivateSigning(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+
+	tx, err := SignTx(NewTransaction(0, addr, new(big.Int), 0, new(big.Int), nil), HomesteadSigner{}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.IsPrivate() {
+		t.Fatal("homestead transaction reported private")
+	}
+	private := tx.AsPrivate()
+	if !private.IsPrivate() {
+		t.Fatal("private transaction not reported private")
+	}
+	if private.Hash() == tx.Hash() {
+		t.Error("private transaction hash matches public one")
+	}
+	// Private transactions are recovered by the signers of chains with privacy only
+	privacy := func(chainID int64, accessList bool) Signer {
+		config := &params.ChainConfig{ChainID: big.NewInt(chainID), Privacy: true}
+		if accessList {
+			config.AccessListBlock = big.NewInt(0)
+		}
+		return MakeSigner(config)
+	}
+	for _, signer := range []Signer{PrivateSigner{}, privacy(1, false), privacy(18, false), privacy(18, true)} {
+		from, err := Sender(signer, private)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if from != addr {
+			t.Errorf("exected from and address to be equal. Got %x want %x", from, addr)
+		}
+	}
+	for _, signer := range []Signer{NewEIP155Signer(big.NewInt(1)), NewEIP2930Signer(big.NewInt(18)), MakeSigner(params.TestChainConfig)} {
+		if _, err := Sender(signer, private); err != ErrInvalidChainId {
+			t.Errorf("private transaction recovered without privacy: %v", err)
+		}
+	}
+	if _, err := Sender(PrivateSigner{}, tx); err != ErrInvalidSig {
+		t.Errorf("public transaction recovered by private signer: %v", err)
+	}
+}
+
 func TestEIP155ChainId(t *testing.T) {
 	key, _ := crypto.GenerateKey()
 	addr := crypto.PubkeyToAddress(key.PublicKey)
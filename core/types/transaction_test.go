@@ -20,7 +20,9 @@ import (
 	"bytes"
 	"crypto/ecdsa"
 	"encoding/json"
+	"fmt"
 	"math/big"
+	"reflect"
 	"testing"
 
 	"github.com/simplechain-org/go-simplechain/common"
@@ -49,6 +51,23 @@ var (
 		HomesteadSigner{},
 		common.Hex2Bytes("98ff921201554726367d2be8c804a7ff89ccf285ebc57dff8ae4c44b9c19ac4a8887321be575c8095f789dd4c743dfe42c1820f9231f98a962b210e3ac2452a301"),
 	)
+
+	testAddr = common.HexToAddress("b94f5374fce5edbc8e2a8697c15331677e6ebf0b")
+
+	emptyEip2718Tx = NewTx(&AccessListTx{
+		ChainID:  big.NewInt(1),
+		Nonce:    3,
+		To:       &testAddr,
+		Value:    big.NewInt(10),
+		Gas:      25000,
+		GasPrice: big.NewInt(1),
+		Data:     common.FromHex("5544"),
+	})
+
+	signedEip2718Tx, _ = emptyEip2718Tx.WithSignature(
+		NewEIP2930Signer(big.NewInt(1)),
+		common.Hex2Bytes("c9519f4f2b30335884581971573fadf60c6204f59a911df35ee8a540456b266032f1e8e2c5dd761f9e4f88f41c8310aeaba26a8bfcdacfedfa12ec3862d3752101"),
+	)
 )
 
 func TestTransactionSigHash(t *testing.T) {
@@ -72,6 +91,41 @@ func TestTransactionEncode(t *testing.T) {
 	}
 }
 
+func TestEIP2718TransactionSigHash(t *testing.T) {
+	s := NewEIP2930Signer(big.NewInt(1))
+	if s.Hash(emptyEip2718Tx) != common.HexToHash("49b486f0ec0a60dfbbca2d30cb07c9e8ffb2a2ff41f29a1ab6737475f6ff69f3") {
+		t.Errorf("empty EIP-2718 transaction hash mismatch, got %x", s.Hash(emptyEip2718Tx))
+	}
+	if s.Hash(signedEip2718Tx) != common.HexToHash("49b486f0ec0a60dfbbca2d30cb07c9e8ffb2a2ff41f29a1ab6737475f6ff69f3") {
+		t.Errorf("signed EIP-2718 transaction hash mismatch, got %x", s.Hash(signedEip2718Tx))
+	}
+}
+
+func TestEIP2718TransactionEncode(t *testing.T) {
+	// Check the binary envelope.
+	have, err := signedEip2718Tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	want := common.FromHex("01f8630103018261a894b94f5374fce5edbc8e2a8697c15331677e6ebf0b0a825544c001a0c9519f4f2b30335884581971573fadf60c6204f59a911df35ee8a540456b2660a032f1e8e2c5dd761f9e4f88f41c8310aeaba26a8bfcdacfedfa12ec3862d37521")
+	if !bytes.Equal(have, want) {
+		t.Errorf("encoded binary mismatch, got %x", have)
+	}
+	// Inside an RLP stream, the envelope is wrapped in a string.
+	have, err = rlp.EncodeToBytes(signedEip2718Tx)
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	want, _ = rlp.EncodeToBytes(want)
+	if !bytes.Equal(have, want) {
+		t.Errorf("encoded RLP mismatch, got %x", have)
+	}
+	// The transaction hash is the hash of the envelope.
+	if signedEip2718Tx.Hash() != crypto.Keccak256Hash(common.FromHex("01f8630103018261a894b94f5374fce5edbc8e2a8697c15331677e6ebf0b0a825544c001a0c9519f4f2b30335884581971573fadf60c6204f59a911df35ee8a540456b2660a032f1e8e2c5dd761f9e4f88f41c8310aeaba26a8bfcdacfedfa12ec3862d37521")) {
+		t.Errorf("transaction hash mismatch, got %x", signedEip2718Tx.Hash())
+	}
+}
+
 func decodeTx(data []byte) (*Transaction, error) {
 	var tx Transaction
 	t, err := &tx, rlp.Decode(bytes.NewReader(data), &tx)
@@ -178,16 +232,37 @@ func TestTransactionJSON(t *testing.T) {
 	if err != nil {
 		t.Fatalf("could not generate key: %v", err)
 	}
-	signer := NewEIP155Signer(common.Big1)
+	signer := NewEIP2930Signer(common.Big1)
 
 	transactions := make([]*Transaction, 0, 50)
 	for i := uint64(0); i < 25; i++ {
 		var tx *Transaction
-		switch i % 2 {
+		switch i % 4 {
 		case 0:
 			tx = NewTransaction(i, common.Address{1}, common.Big0, 1, common.Big2, []byte("abcdef"))
 		case 1:
 			tx = NewContractCreation(i, common.Big0, 1, common.Big2, []byte("abcdef"))
+		case 2:
+			tx = NewTx(&AccessListTx{
+				ChainID:    common.Big1,
+				Nonce:      i,
+				To:         &common.Address{1},
+				Gas:        1,
+				GasPrice:   common.Big2,
+				Data:       []byte("abcdef"),
+				AccessList: AccessList{{Address: common.Address{2}, StorageKeys: []common.Hash{{3}}}},
+			})
+		case 3:
+			tx = NewTx(&ExpiringTx{
+				ChainID:     common.Big1,
+				Nonce:       i,
+				To:          &common.Address{1},
+				Gas:         1,
+				GasPrice:    common.Big2,
+				Data:        []byte("abcdef"),
+				ExpiryBlock: 100,
+				ExpiryTime:  1600000000,
+			})
 		}
 		transactions = append(transactions, tx)
 
@@ -217,5 +292,123 @@ func TestTransactionJSON(t *testing.T) {
 		if tx.ChainId().Cmp(parsedTx.ChainId()) != 0 {
 			t.Errorf("invalid chain id, want %d, got %d", tx.ChainId(), parsedTx.ChainId())
 		}
+		if tx.Type() != parsedTx.Type() {
+			t.Errorf("invalid type, want %d, got %d", tx.Type(), parsedTx.Type())
+		}
+		if tx.ExpiryBlock() != parsedTx.ExpiryBlock() || tx.ExpiryTime() != parsedTx.ExpiryTime() {
+			t.Errorf("invalid expiry, want %d/%d, got %d/%d", tx.ExpiryBlock(), tx.ExpiryTime(), parsedTx.ExpiryBlock(), parsedTx.ExpiryTime())
+		}
+	}
+}
+
+// Tests that legacy and typed transactions survive the binary and RLP codecs.
+func TestTransactionCoding(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("could not generate key: %v", err)
+	}
+	var (
+		signer    = NewEIP2930Signer(common.Big1)
+		addr      = common.HexToAddress("0x0000000000000000000000000000000000000001")
+		recipient = common.HexToAddress("095e7baea6a6c7c4c2dfeb977efac326af552d87")
+		accesses  = AccessList{{Address: addr, StorageKeys: []common.Hash{{0}}}}
+	)
+	for i := uint64(0); i < 500; i++ {
+		var txdata TxData
+		switch i % 7 {
+		case 0:
+			// Legacy tx.
+			txdata = &LegacyTx{Nonce: i, To: &recipient, Gas: 1, GasPrice: big.NewInt(2), Data: []byte("abcdef")}
+		case 1:
+			// Legacy tx contract creation.
+			txdata = &LegacyTx{Nonce: i, Gas: 1, GasPrice: big.NewInt(2), Data: []byte("abcdef")}
+		case 2:
+			// Tx with non-zero access list.
+			txdata = &AccessListTx{ChainID: big.NewInt(1), Nonce: i, To: &recipient, Gas: 123457, GasPrice: big.NewInt(10), AccessList: accesses, Data: []byte("abcdef")}
+		case 3:
+			// Tx with empty access list.
+			txdata = &AccessListTx{ChainID: big.NewInt(1), Nonce: i, To: &recipient, Gas: 123457, GasPrice: big.NewInt(10), Data: []byte("abcdef")}
+		case 4:
+			// Contract creation with access list.
+			txdata = &AccessListTx{ChainID: big.NewInt(1), Nonce: i, Gas: 123457, GasPrice: big.NewInt(10), AccessList: accesses}
+		case 5:
+			// Tx expiring at a block number.
+			txdata = &ExpiringTx{ChainID: big.NewInt(1), Nonce: i, To: &recipient, Gas: 123457, GasPrice: big.NewInt(10), ExpiryBlock: i}
+		case 6:
+			// Tx with access list expiring at a timestamp.
+			txdata = &ExpiringTx{ChainID: big.NewInt(1), Nonce: i, To: &recipient, Gas: 123457, GasPrice: big.NewInt(10), AccessList: accesses, ExpiryTime: 1600000000 + i}
+		}
+		tx, err := SignNewTx(key, signer, txdata)
+		if err != nil {
+			t.Fatalf("could not sign transaction: %v", err)
+		}
+		// Binary codec
+		data, err := tx.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary failed: %v", err)
+		}
+		parsedTx := new(Transaction)
+		if err := parsedTx.UnmarshalBinary(data); err != nil {
+			t.Fatalf("UnmarshalBinary failed: %v", err)
+		}
+		if err := assertEqual(parsedTx, tx); err != nil {
+			t.Fatal(err)
+		}
+		// RLP codec
+		data, err = rlp.EncodeToBytes(tx)
+		if err != nil {
+			t.Fatalf("RLP encoding failed: %v", err)
+		}
+		parsedTx = new(Transaction)
+		if err := rlp.DecodeBytes(data, parsedTx); err != nil {
+			t.Fatalf("RLP decoding failed: %v", err)
+		}
+		if err := assertEqual(parsedTx, tx); err != nil {
+			t.Fatal(err)
+		}
+		if from, err := Sender(signer, parsedTx); err != nil || from != crypto.PubkeyToAddress(key.PublicKey) {
+			t.Fatalf("sender mismatch: %x, %v", from, err)
+		}
+	}
+}
+
+func assertEqual(orig *Transaction, cpy *Transaction) error {
+	// compare nonce, price, gaslimit, recipient, amount, payload, V, R, S
+	if want, got := orig.Hash(), cpy.Hash(); want != got {
+		return fmt.Errorf("parsed tx differs from original tx, want %v, got %v", want, got)
+	}
+	if want, got := orig.ChainId(), cpy.ChainId(); want.Cmp(got) != 0 {
+		return fmt.Errorf("invalid chain id, want %d, got %d", want, got)
+	}
+	if orig.AccessList() != nil {
+		if !reflect.DeepEqual(orig.AccessList(), cpy.AccessList()) {
+			return fmt.Errorf("access list wrong")
+		}
+	}
+	if orig.ExpiryBlock() != cpy.ExpiryBlock() || orig.ExpiryTime() != cpy.ExpiryTime() {
+		return fmt.Errorf("expiry wrong")
+	}
+	return nil
+}
+
+// Tests the inclusion window of expiring transactions.
+func TestTransactionExpired(t *testing.T) {
+	tests := []struct {
+		tx           *Transaction
+		number, time uint64
+		expired      bool
+	}{
+		{NewTransaction(0, common.Address{}, common.Big0, 0, common.Big0, nil), 1 << 62, 1 << 62, false},
+		{NewTx(&ExpiringTx{ExpiryBlock: 10}), 10, 1 << 62, false},
+		{NewTx(&ExpiringTx{ExpiryBlock: 10}), 11, 0, true},
+		{NewTx(&ExpiringTx{ExpiryTime: 100}), 1 << 62, 100, false},
+		{NewTx(&ExpiringTx{ExpiryTime: 100}), 0, 101, true},
+		{NewTx(&ExpiringTx{ExpiryBlock: 10, ExpiryTime: 100}), 10, 101, true},
+		{NewTx(&ExpiringTx{ExpiryBlock: 10, ExpiryTime: 100}), 11, 100, true},
+	}
+	for i, tt := range tests {
+		if expired := tt.tx.Expired(tt.number, tt.time); expired != tt.expired {
+			t.Errorf("test %d: expiry mismatch at %d/%d: have %v, want %v", i, tt.number, tt.time, expired, tt.expired)
+		}
 	}
 }
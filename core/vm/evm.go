@@ -383,6 +383,10 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	if !evm.CanTransfer(evm.StateDB, caller.Address(), value) {
 		return nil, common.Address{}, gas, ErrInsufficientBalance
 	}
+	// Contracts may only be deployed on behalf of a permitted origin
+	if err := CheckAccountPermission(evm.chainConfig, evm.StateDB, evm.Origin, true); err != nil {
+		return nil, common.Address{}, gas, err
+	}
 	nonce := evm.StateDB.GetNonce(caller.Address())
 	evm.StateDB.SetNonce(caller.Address(), nonce+1)
 
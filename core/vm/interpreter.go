@@ -39,6 +39,8 @@ type Config struct {
 	EVMInterpreter   string // External EVM interpreter options
 
 	ExtraEips []int // Additional EIPS that are to be enabled
+
+	ParallelWorkers int // Number of workers speculatively executing block transactions, sequential if below 2
 }
 
 // Interpreter is used to run Ethereum based contracts and will utilise the
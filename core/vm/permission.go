@@ -0,0 +1,65 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/crypto"
+	"github.com/simplechain-org/go-simplechain/params"
+)
+
+var (
+	// ErrTxNotPermitted is returned if the sender is not permitted to send transactions.
+	ErrTxNotPermitted = errors.New("account not permitted to transact")
+
+	// ErrDeployNotPermitted is returned if the sender is not permitted to deploy contracts.
+	ErrDeployNotPermitted = errors.New("account not permitted to deploy contracts")
+)
+
+// accountRoleSlot is the storage slot of the `mapping(address => uint256) roles`
+// in the account permission contract.
+var accountRoleSlot = common.Hash{}
+
+// AccountRoles returns the roles granted to addr on a permissioned chain. Roles
+// are read from the system contract storage if one is configured, otherwise from
+// the static allowlist in genesis.
+func AccountRoles(config *params.AccountPermissionConfig, db StateDB, addr common.Address) params.AccountRole {
+	if config.Contract != nil {
+		key := crypto.Keccak256Hash(common.LeftPadBytes(addr.Bytes(), common.HashLength), accountRoleSlot.Bytes())
+		return params.AccountRole(db.GetState(*config.Contract, key).Big().Uint64())
+	}
+	return config.Accounts[addr]
+}
+
+// CheckAccountPermission checks that from is permitted to send a transaction, and
+// to deploy a contract if create is set. Chains without account permissioning
+// allow every account.
+func CheckAccountPermission(config *params.ChainConfig, db StateDB, from common.Address, create bool) error {
+	if config.AccountPermission == nil {
+		return nil
+	}
+	roles := AccountRoles(config.AccountPermission, db, from)
+	if !roles.Has(params.AccountCanTransact) {
+		return ErrTxNotPermitted
+	}
+	if create && !roles.Has(params.AccountCanDeploy) {
+		return ErrDeployNotPermitted
+	}
+	return nil
+}
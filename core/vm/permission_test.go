@@ -0,0 +1,92 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/common/hexutil"
+	"github.com/simplechain-org/go-simplechain/core/rawdb"
+	"github.com/simplechain-org/go-simplechain/core/state"
+	"github.com/simplechain-org/go-simplechain/crypto"
+	"github.com/simplechain-org/go-simplechain/params"
+)
+
+// Tests that account roles are read from the system contract storage and that
+// nested contract creations are bound to the roles of the transaction origin.
+func TestAccountPermissionContract(t *testing.T) {
+	var (
+		roles    = common.HexToAddress("0x1000")
+		factory  = common.HexToAddress("0x2000")
+		deployer = common.HexToAddress("0x3000")
+		user     = common.HexToAddress("0x4000")
+	)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	statedb.SetCode(roles, []byte{0x00})
+	setRole := func(addr common.Address, role params.AccountRole) {
+		key := crypto.Keccak256Hash(common.LeftPadBytes(addr.Bytes(), 32), common.Hash{}.Bytes())
+		statedb.SetState(roles, key, common.BigToHash(big.NewInt(int64(role))))
+	}
+	setRole(deployer, params.AccountCanTransact|params.AccountCanDeploy)
+	setRole(user, params.AccountCanTransact)
+
+	// CREATE an empty contract and store its address in slot 0
+	statedb.SetCode(factory, hexutil.MustDecode("0x600060006000f060005500"))
+
+	config := *params.TestChainConfig
+	config.AccountPermission = &params.AccountPermissionConfig{Contract: &roles}
+
+	tests := []struct {
+		from     common.Address
+		roles    params.AccountRole
+		transact error
+		deploy   error
+	}{
+		{common.HexToAddress("0x5000"), 0, ErrTxNotPermitted, ErrTxNotPermitted},
+		{user, params.AccountCanTransact, nil, ErrDeployNotPermitted},
+		{deployer, params.AccountCanTransact | params.AccountCanDeploy, nil, nil},
+	}
+	for i, tt := range tests {
+		if roles := AccountRoles(config.AccountPermission, statedb, tt.from); roles != tt.roles {
+			t.Errorf("test %d: roles mismatch: have %v, want %v", i, roles, tt.roles)
+		}
+		if err := CheckAccountPermission(&config, statedb, tt.from, false); err != tt.transact {
+			t.Errorf("test %d: transact error mismatch: have %v, want %v", i, err, tt.transact)
+		}
+		if err := CheckAccountPermission(&config, statedb, tt.from, true); err != tt.deploy {
+			t.Errorf("test %d: deploy error mismatch: have %v, want %v", i, err, tt.deploy)
+		}
+		statedb.SetState(factory, common.Hash{}, common.Hash{})
+
+		vmctx := Context{
+			CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+			Origin:      tt.from,
+			BlockNumber: big.NewInt(0),
+		}
+		vmenv := NewEVM(vmctx, statedb, &config, Config{})
+		if _, _, err := vmenv.Call(AccountRef(tt.from), factory, nil, 100000, new(big.Int)); err != nil {
+			t.Fatalf("test %d: factory call failed: %v", i, err)
+		}
+		created := statedb.GetState(factory, common.Hash{}) != (common.Hash{})
+		if created != (tt.deploy == nil) {
+			t.Errorf("test %d: nested creation mismatch: have %v, want %v", i, created, tt.deploy == nil)
+		}
+	}
+}
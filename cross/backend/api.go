@@ -17,6 +17,7 @@
 package backend
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/simplechain-org/go-simplechain/common"
@@ -24,10 +25,13 @@ import (
 	"github.com/simplechain-org/go-simplechain/log"
 	"github.com/simplechain-org/go-simplechain/rlp"
 
+	"github.com/simplechain-org/go-simplechain/cross/backend/risk"
 	cc "github.com/simplechain-org/go-simplechain/cross/core"
 	cdb "github.com/simplechain-org/go-simplechain/cross/database"
 )
 
+var errUnknownChain = errors.New("unknown chain")
+
 type PrivateCrossAdminAPI struct {
 	service *CrossService
 }
@@ -118,6 +122,98 @@ func (s *PrivateCrossAdminAPI) Remove(chainID *hexutil.Big, number hexutil.Uint6
 	return true
 }
 
+// RiskConfig returns the risk policy config of local ctxs on chainID
+func (s *PrivateCrossAdminAPI) RiskConfig(chainID *hexutil.Big) (*risk.Config, error) {
+	handler := s.service.getCrossHandler(chainID.ToInt())
+	if handler == nil {
+		return nil, errUnknownChain
+	}
+	config := handler.RiskPolicy().Config()
+	return &config, nil
+}
+
+// SetRiskConfig replaces the risk policy config of local ctxs on chainID
+func (s *PrivateCrossAdminAPI) SetRiskConfig(chainID *hexutil.Big, config risk.Config) bool {
+	handler := s.service.getCrossHandler(chainID.ToInt())
+	if handler == nil {
+		return false
+	}
+	handler.RiskPolicy().SetConfig(config)
+	log.Info("rpc SetRiskConfig", "chainID", chainID.ToInt())
+	return true
+}
+
+// PauseSigning holds every new local ctx on chainID for manual approval
+func (s *PrivateCrossAdminAPI) PauseSigning(chainID *hexutil.Big, paused bool) bool {
+	handler := s.service.getCrossHandler(chainID.ToInt())
+	if handler == nil {
+		return false
+	}
+	handler.RiskPolicy().SetPaused(paused)
+	log.Info("rpc PauseSigning", "chainID", chainID.ToInt(), "paused", paused)
+	return true
+}
+
+func (s *PrivateCrossAdminAPI) DenyAddress(chainID *hexutil.Big, address common.Address) bool {
+	handler := s.service.getCrossHandler(chainID.ToInt())
+	if handler == nil {
+		return false
+	}
+	handler.RiskPolicy().Deny(address)
+	log.Info("rpc DenyAddress", "chainID", chainID.ToInt(), "address", address)
+	return true
+}
+
+func (s *PrivateCrossAdminAPI) UndenyAddress(chainID *hexutil.Big, address common.Address) bool {
+	handler := s.service.getCrossHandler(chainID.ToInt())
+	if handler == nil {
+		return false
+	}
+	handler.RiskPolicy().Undeny(address)
+	log.Info("rpc UndenyAddress", "chainID", chainID.ToInt(), "address", address)
+	return true
+}
+
+// HeldCtx returns local ctxs waiting for manual approval on chainID
+func (s *PrivateCrossAdminAPI) HeldCtx(chainID *hexutil.Big) ([]*RPCHeldCrossTransaction, error) {
+	handler := s.service.getCrossHandler(chainID.ToInt())
+	if handler == nil {
+		return nil, errUnknownChain
+	}
+	held := handler.RiskPolicy().Held()
+	list := make([]*RPCHeldCrossTransaction, len(held))
+	for i, h := range held {
+		list[i] = newRPCHeldCrossTransaction(h)
+	}
+	return list, nil
+}
+
+// ApproveCtx signs a held ctx and broadcasts it to other anchors
+func (s *PrivateCrossAdminAPI) ApproveCtx(chainID *hexutil.Big, ctxID common.Hash) (bool, error) {
+	handler := s.service.getCrossHandler(chainID.ToInt())
+	if handler == nil {
+		return false, errUnknownChain
+	}
+	if err := handler.ApproveCtx(ctxID); err != nil {
+		return false, err
+	}
+	log.Info("rpc ApproveCtx", "chainID", chainID.ToInt(), "ctxID", ctxID.String())
+	return true, nil
+}
+
+// RejectCtx removes a held ctx without signing it
+func (s *PrivateCrossAdminAPI) RejectCtx(chainID *hexutil.Big, ctxID common.Hash) bool {
+	handler := s.service.getCrossHandler(chainID.ToInt())
+	if handler == nil {
+		return false
+	}
+	if !handler.RiskPolicy().Reject(ctxID) {
+		return false
+	}
+	log.Info("rpc RejectCtx", "chainID", chainID.ToInt(), "ctxID", ctxID.String())
+	return true
+}
+
 func (s *PrivateCrossAdminAPI) importCtx(local, remote *Handler, ctxWithSignsSArgs hexutil.Bytes) error {
 	ctx := new(cc.CrossTransactionWithSignatures)
 	if err := rlp.DecodeBytes(ctxWithSignsSArgs, ctx); err != nil {
@@ -340,6 +436,32 @@ func newRPCCrossTransaction(tx *cc.CrossTransactionWithSignatures) *RPCCrossTran
 	return result
 }
 
+type RPCHeldCrossTransaction struct {
+	CTxId            common.Hash    `json:"ctxId"`
+	TxHash           common.Hash    `json:"txHash"`
+	From             common.Address `json:"from"`
+	To               common.Address `json:"to"`
+	Value            *hexutil.Big   `json:"value"`
+	DestinationId    *hexutil.Big   `json:"destinationId"`
+	DestinationValue *hexutil.Big   `json:"destinationValue"`
+	Reason           string         `json:"reason"`
+	Time             hexutil.Uint64 `json:"time"`
+}
+
+func newRPCHeldCrossTransaction(held *risk.Held) *RPCHeldCrossTransaction {
+	return &RPCHeldCrossTransaction{
+		CTxId:            held.Tx.ID(),
+		TxHash:           held.Tx.Data.TxHash,
+		From:             held.Tx.Data.From,
+		To:               held.Tx.Data.To,
+		Value:            (*hexutil.Big)(held.Tx.Data.Value),
+		DestinationId:    (*hexutil.Big)(held.Tx.Data.DestinationId),
+		DestinationValue: (*hexutil.Big)(held.Tx.Data.DestinationValue),
+		Reason:           held.Reason,
+		Time:             hexutil.Uint64(held.Time.Unix()),
+	}
+}
+
 type RPCOwnerCrossTransaction struct {
 	Value            *hexutil.Big   `json:"value"`
 	Status           cc.CtxStatus   `json:"status"`
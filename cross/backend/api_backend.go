@@ -75,7 +75,7 @@ func (h *Handler) QueryRemoteByDestinationValueAndPage(value *big.Int, pageSize,
 	}
 	var (
 		store, _  = h.store.GetStore(h.chainID)
-		condition = []q.Matcher{q.Eq(cdb.StatusField, cc.CtxStatusWaiting), q.Gte(cdb.DestinationValue, value)}
+		condition = []q.Matcher{cdb.Eq(cdb.StatusField, cc.CtxStatusWaiting), cdb.Gte(cdb.DestinationValue, value)}
 		orderBy   = []cdb.FieldName{cdb.PriceIndex}
 		reverse   = false
 	)
@@ -92,7 +92,7 @@ func (h *Handler) QueryByPage(localSize, localPage, remoteSize, remotePage int)
 	var (
 		localStore, _  = h.store.GetStore(h.chainID)
 		remoteStore, _ = h.store.GetStore(h.remoteID)
-		condition      = []q.Matcher{q.Eq(cdb.StatusField, cc.CtxStatusWaiting)}
+		condition      = []q.Matcher{cdb.Eq(cdb.StatusField, cc.CtxStatusWaiting)}
 		orderBy        = []cdb.FieldName{cdb.PriceIndex}
 		reverse        = false
 	)
@@ -111,7 +111,7 @@ func (h *Handler) QueryLocalIllegalByPage(pageSize, startPage int) []*cc.CrossTr
 
 	var (
 		store, _  = h.store.GetStore(h.chainID)
-		condition = []q.Matcher{q.Eq(cdb.StatusField, cc.CtxStatusIllegal)}
+		condition = []q.Matcher{cdb.Eq(cdb.StatusField, cc.CtxStatusIllegal)}
 		orderBy   = []cdb.FieldName{cdb.BlockNumField}
 		reverse   = false
 	)
@@ -128,10 +128,10 @@ func (h *Handler) QueryLocalBySenderAndPage(from common.Address, pageSize, start
 		store, _  = h.store.GetStore(h.chainID)
 		condition = []q.Matcher{
 			q.Or(
-				q.Eq(cdb.StatusField, cc.CtxStatusWaiting),
-				q.Eq(cdb.StatusField, cc.CtxStatusIllegal),
+				cdb.Eq(cdb.StatusField, cc.CtxStatusWaiting),
+				cdb.Eq(cdb.StatusField, cc.CtxStatusIllegal),
 			),
-			q.Eq(cdb.FromField, from)}
+			cdb.Eq(cdb.FromField, from)}
 		orderBy = []cdb.FieldName{cdb.PriceIndex}
 		reverse = false
 	)
@@ -156,7 +156,7 @@ func (h *Handler) QueryRemoteByTakerAndPage(to common.Address, pageSize, startPa
 		return nil, 0
 	}
 	var (
-		condition = []q.Matcher{q.Eq(cdb.StatusField, cc.CtxStatusWaiting), q.Eq(cdb.ToField, to)}
+		condition = []q.Matcher{cdb.Eq(cdb.StatusField, cc.CtxStatusWaiting), cdb.Eq(cdb.ToField, to)}
 		orderBy   = []cdb.FieldName{cdb.PriceIndex}
 		store, _  = h.store.GetStore(h.remoteID)
 		reverse   = false
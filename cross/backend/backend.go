@@ -24,7 +24,6 @@ import (
 
 	"github.com/simplechain-org/go-simplechain/common"
 	"github.com/simplechain-org/go-simplechain/log"
-	"github.com/simplechain-org/go-simplechain/node"
 	"github.com/simplechain-org/go-simplechain/p2p"
 	"github.com/simplechain-org/go-simplechain/p2p/enode"
 	"github.com/simplechain-org/go-simplechain/rlp"
@@ -61,7 +60,7 @@ type crossCommons struct {
 	channel chan interface{}
 }
 
-func NewCrossService(ctx *node.ServiceContext, main, sub *cross.ServiceContext, config cross.Config) (srv *CrossService, err error) {
+func NewCrossService(ctx cdb.ServiceContext, main, sub *cross.ServiceContext, config cross.Config) (srv *CrossService, err error) {
 	srv = &CrossService{
 		config:    config,
 		peers:     newAnchorSet(),
@@ -80,7 +79,7 @@ func NewCrossService(ctx *node.ServiceContext, main, sub *cross.ServiceContext,
 		return nil, err
 	}
 
-	srv.store, err = NewCrossStore(ctx, cross.DataDir)
+	srv.store, err = NewCrossStore(ctx, cross.IndexDir)
 	if err != nil {
 		return nil, err
 	}
@@ -147,6 +146,30 @@ func (srv *CrossService) getCrossHandler(chainID *big.Int) *Handler {
 	return nil
 }
 
+// Chains returns the IDs of the main chain and the subchain exchanging ctxs.
+func (srv *CrossService) Chains() []*big.Int {
+	return []*big.Int{new(big.Int).SetUint64(srv.main.chainID), new(big.Int).SetUint64(srv.sub.chainID)}
+}
+
+// CtxStore returns the store of the ctxs made on the given chain.
+func (srv *CrossService) CtxStore(chainID *big.Int) (cdb.CtxDB, error) {
+	if srv.getCrossHandler(chainID) == nil {
+		return nil, errUnknownChain
+	}
+	return srv.store.GetStore(chainID)
+}
+
+// PoolStats returns the number of pending and queued ctxs in the pool of the
+// given chain.
+func (srv *CrossService) PoolStats(chainID *big.Int) (int, int, error) {
+	handler := srv.getCrossHandler(chainID)
+	if handler == nil {
+		return 0, 0, errUnknownChain
+	}
+	pending, queued := handler.PoolStats()
+	return pending, queued, nil
+}
+
 func (srv *CrossService) Protocols() []p2p.Protocol {
 	return []p2p.Protocol{{
 		Name:    "cross",
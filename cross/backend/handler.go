@@ -26,6 +26,7 @@ import (
 	"github.com/simplechain-org/go-simplechain/log"
 
 	"github.com/simplechain-org/go-simplechain/cross"
+	"github.com/simplechain-org/go-simplechain/cross/backend/risk"
 	"github.com/simplechain-org/go-simplechain/cross/backend/synchronise"
 	cc "github.com/simplechain-org/go-simplechain/cross/core"
 	cdb "github.com/simplechain-org/go-simplechain/cross/database"
@@ -192,10 +193,15 @@ func (h *Handler) handle(current *cc.CrossBlockEvent) {
 
 	handleReceptTransactions := func(takers []*cc.ReceptTransaction, modType cc.ModType, modStatus cc.CtxStatus) (remains []*cc.ReceptTransaction) {
 		for _, tx := range takers {
-			if err := tx.Check(h.store.Get(tx.DestinationId, tx.CTxId)); err != nil {
+			maker := h.store.Get(tx.DestinationId, tx.CTxId)
+			if err := tx.Check(maker); err != nil {
 				h.log.Warn("check taker failed", "type", modType, "status", modStatus, "error", err)
 				continue
 			}
+			if maker.IsMessage() { // message is delivered by anchors, could not be taken
+				h.log.Warn("check taker failed", "type", modType, "status", modStatus, "error", cc.ErrInvalidMessage)
+				continue
+			}
 			remote = append(remote, &cc.CrossTransactionModifier{
 				ID: tx.CTxId,
 				//TODO: update from reorg/remote wouldn't modify blockNumber
@@ -208,6 +214,22 @@ func (h *Handler) handle(current *cc.CrossBlockEvent) {
 		return remains
 	}
 
+	handleDeliveries := func(deliveries []*cc.ReceptTransaction, modType cc.ModType, modStatus cc.CtxStatus) {
+		for _, tx := range deliveries {
+			message := h.store.Get(tx.DestinationId, tx.CTxId)
+			if err := tx.Check(message); err != nil || !message.IsMessage() {
+				h.log.Warn("check delivery failed", "type", modType, "status", modStatus, "ctxID", tx.CTxId.String(), "error", err)
+				continue
+			}
+			remote = append(remote, &cc.CrossTransactionModifier{
+				ID:     tx.CTxId,
+				Type:   modType,
+				Status: modStatus,
+			})
+		}
+		h.log.Debug("handle message deliveries", "type", modType, "status", modStatus, "deliveries", len(deliveries))
+	}
+
 	// ignore early block logs
 	if height := h.store.Height(h.chainID); current.Number.Uint64()+h.retriever.ConfirmedDepth() >= height {
 
@@ -228,31 +250,15 @@ func (h *Handler) handle(current *cc.CrossBlockEvent) {
 			local = append(local, finishes...)
 		}
 
+		// reorg message delivery (remote)
+		if deliveries := current.ReorgDelivery.Deliveries; len(deliveries) > 0 {
+			handleDeliveries(deliveries, cc.Reorg, cc.CtxStatusWaiting)
+		}
+
 		// handle confirmed maker
 		if makers := current.ConfirmedMaker.Txs; len(makers) > 0 {
 			signed, commits, errs := h.pool.AddLocals(makers...)
-			for _, err := range errs {
-				logFn := h.log.Warn
-				switch err {
-				case cc.ErrDuplicateSign, cross.ErrAlreadyExistCtx:
-					logFn = h.log.Debug
-				case cross.ErrFinishedCtx:
-					logFn = h.log.Info
-				case cross.ErrReorgCtx:
-					logFn = h.log.Error
-				}
-				logFn("Add local ctx failed", "error", err)
-			}
-			// assemble signed local and add them to store with pending status
-			pendingTx := txDifferent(signed, commits)
-			cws := make([]*cc.CrossTransactionWithSignatures, len(pendingTx))
-			for i, ctx := range pendingTx {
-				cws[i] = cc.NewCrossTransactionWithSignatures(ctx, current.Number.Uint64())
-			}
-			if err := h.store.Adds(h.chainID, cws, false); err != nil {
-				h.log.Warn("Store pending ctx failed", "error", err)
-			}
-			h.service.BroadcastCrossTx(signed, true) // broad cast self signed tx to other anchors
+			h.handleLocals(signed, commits, errs, current.Number.Uint64())
 		}
 
 		// handle new taker
@@ -268,6 +274,16 @@ func (h *Handler) handle(current *cc.CrossBlockEvent) {
 			}
 		}
 
+		// handle new message delivery
+		if deliveries := current.NewDelivery.Deliveries; len(deliveries) > 0 {
+			handleDeliveries(deliveries, cc.Remote, cc.CtxStatusExecuting)
+		}
+
+		// handle confirmed message delivery, nothing to unlock in the source chain
+		if deliveries := current.ConfirmedDelivery.Deliveries; len(deliveries) > 0 {
+			handleDeliveries(deliveries, cc.Remote, cc.CtxStatusFinished)
+		}
+
 		// handle new finish
 		if finishes := current.NewFinish.Finishes; len(finishes) > 0 {
 			local = append(local, finishes...)
@@ -299,7 +315,7 @@ func (h *Handler) handleAnchorChange(number *big.Int) []*cc.CrossTransactionModi
 		h.log.Warn("handleAnchorChange failed", "error", err)
 		return nil
 	}
-	conditions := []q.Matcher{q.Eq(cdb.StatusField, cc.CtxStatusWaiting), q.Lte(cdb.BlockNumField, number.Uint64())}
+	conditions := []q.Matcher{cdb.Eq(cdb.StatusField, cc.CtxStatusWaiting), cdb.Lte(cdb.BlockNumField, number.Uint64())}
 	txm := make([]*cc.CrossTransactionModifier, 0)
 	for _, cws := range store.Query(0, 0, []cdb.FieldName{cdb.BlockNumField}, false, conditions...) {
 		for _, ctx := range cws.Resolution() { // verify each signature
@@ -384,6 +400,17 @@ func (h *Handler) readCrossMessage() {
 					ev.CallBack(commits) // call callback with signer checking results
 				}
 
+				// deliver signed messages to target contracts in this chain
+				var messages []*cc.CrossTransactionWithSignatures
+				for _, commit := range commits {
+					if commit.InvalidSigIndex == nil && commit.Tx.IsMessage() {
+						messages = append(messages, commit.Tx)
+					}
+				}
+				if len(messages) > 0 {
+					h.executor.DeliverMessages(messages)
+				}
+
 			case cc.ConfirmedTakerEvent: // taker确认消息，需要anchor发起解锁交易
 				h.executor.SubmitTransaction(ev.Txs) // submit finish transaction
 
@@ -397,6 +424,48 @@ func (h *Handler) readCrossMessage() {
 	}
 }
 
+// handleLocals stores signed local ctxs with pending status, and broadcasts them to other anchors
+func (h *Handler) handleLocals(signed []*cc.CrossTransaction, commits []*cc.CrossTransactionWithSignatures, errs []error, number uint64) {
+	for _, err := range errs {
+		logFn := h.log.Warn
+		switch err {
+		case cc.ErrDuplicateSign, cross.ErrAlreadyExistCtx:
+			logFn = h.log.Debug
+		case cross.ErrFinishedCtx, cross.ErrHeldCtx:
+			logFn = h.log.Info
+		case cross.ErrReorgCtx:
+			logFn = h.log.Error
+		}
+		logFn("Add local ctx failed", "error", err)
+	}
+	// assemble signed local and add them to store with pending status
+	pendingTx := txDifferent(signed, commits)
+	cws := make([]*cc.CrossTransactionWithSignatures, len(pendingTx))
+	for i, ctx := range pendingTx {
+		cws[i] = cc.NewCrossTransactionWithSignatures(ctx, number)
+	}
+	if err := h.store.Adds(h.chainID, cws, false); err != nil {
+		h.log.Warn("Store pending ctx failed", "error", err)
+	}
+	h.service.BroadcastCrossTx(signed, true) // broad cast self signed tx to other anchors
+}
+
+// ApproveCtx signs the ctx held by risk policy
+func (h *Handler) ApproveCtx(ctxID common.Hash) error {
+	signed, commits, errs := h.pool.Approve(ctxID)
+	if len(signed) > 0 {
+		h.handleLocals(signed, commits, nil, h.retriever.GetConfirmedTransactionNumberOnChain(signed[0]))
+	}
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+func (h *Handler) RiskPolicy() *risk.Policy {
+	return h.pool.RiskPolicy()
+}
+
 // 往pool里添加从P2P网络接收的ctx与节点签名信息
 func (h *Handler) AddRemoteCtx(ctx *cc.CrossTransaction) error {
 	if !h.retriever.CanAcceptTxs() { // wait until block synchronize completely
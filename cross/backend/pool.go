@@ -26,12 +26,12 @@ import (
 	"github.com/simplechain-org/go-simplechain/log"
 
 	"github.com/simplechain-org/go-simplechain/cross"
+	"github.com/simplechain-org/go-simplechain/cross/backend/risk"
 	cc "github.com/simplechain-org/go-simplechain/cross/core"
 	db "github.com/simplechain-org/go-simplechain/cross/database"
 	cm "github.com/simplechain-org/go-simplechain/cross/metric"
 	"github.com/simplechain-org/go-simplechain/cross/trigger"
 
-	"github.com/asdine/storm/v3/q"
 	lru "github.com/hashicorp/golang-lru"
 )
 
@@ -67,6 +67,7 @@ type CrossPool struct {
 	signer   cc.CtxSigner
 	signHash cc.SignHash
 	txLog    finishedLog
+	risk     *risk.Policy // decide whether local ctx could be signed
 
 	mu     sync.RWMutex
 	wg     sync.WaitGroup // for shutdown sync
@@ -92,6 +93,7 @@ func NewCrossPool(chainID *big.Int, config *cross.Config, store store, txLog fin
 		pendingCache: pendingCache,
 		signer:       cc.MakeCtxSigner(chainID),
 		signHash:     signHash,
+		risk:         risk.NewPolicy(config.Risk),
 		stopCh:       make(chan struct{}),
 		logger:       logger,
 	}
@@ -111,7 +113,7 @@ func (pool *CrossPool) load() error {
 	if err != nil {
 		return err
 	}
-	pending := store.Query(0, 0, []db.FieldName{db.BlockNumField}, false, q.Eq(db.StatusField, uint8(cc.CtxStatusPending)))
+	pending := store.Query(0, 0, []db.FieldName{db.BlockNumField}, false, db.Eq(db.StatusField, uint8(cc.CtxStatusPending)))
 
 	pool.logger.Info("load pending tx from store", "count", len(pending))
 
@@ -160,6 +162,31 @@ func (pool *CrossPool) Stop() {
 // @commits: ctx signed completely, commit to signedCtxCh
 // @errs: errors
 func (pool *CrossPool) AddLocals(txs ...*cc.CrossTransaction) (
+	signed []*cc.CrossTransaction, commits []*cc.CrossTransactionWithSignatures, errs []error) {
+	return pool.addLocals(txs, true)
+}
+
+// Approve signs a ctx held by risk policy, and adds it to pool as same as AddLocals.
+// The ctx stays held if it fails to be added, so that it can be approved again.
+func (pool *CrossPool) Approve(ctxID common.Hash) (
+	signed []*cc.CrossTransaction, commits []*cc.CrossTransactionWithSignatures, errs []error) {
+	ctx := pool.risk.Get(ctxID)
+	if ctx == nil {
+		return nil, nil, []error{cross.ErrNotHeldCtx}
+	}
+	signed, commits, errs = pool.addLocals([]*cc.CrossTransaction{ctx}, false)
+	if len(errs) == 0 {
+		pool.risk.Approve(ctxID)
+	}
+	return signed, commits, errs
+}
+
+// RiskPolicy returns the risk policy of local ctxs
+func (pool *CrossPool) RiskPolicy() *risk.Policy {
+	return pool.risk
+}
+
+func (pool *CrossPool) addLocals(txs []*cc.CrossTransaction, checkRisk bool) (
 	signed []*cc.CrossTransaction, commits []*cc.CrossTransactionWithSignatures, errs []error) {
 	for _, ctx := range txs {
 		if pool.txLog.IsFinish(ctx.ID()) {
@@ -176,6 +203,12 @@ func (pool *CrossPool) AddLocals(txs ...*cc.CrossTransaction) (
 			errs = append(errs, cross.ErrAlreadyExistCtx)
 			continue
 		}
+		if checkRisk {
+			if err := pool.checkRisk(ctx); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
 		// make signature first for local ctx
 		signedTx, err := pool.signTx(ctx)
 		if err != nil {
@@ -278,6 +311,19 @@ func (pool *CrossPool) addRemoteTx(ctx *cc.CrossTransaction) (signer common.Addr
 	return signer, nil
 }
 
+// checkRisk checks local ctx against risk policy before signing it
+func (pool *CrossPool) checkRisk(ctx *cc.CrossTransaction) error {
+	switch verdict, reason := pool.risk.Check(ctx); verdict {
+	case risk.Deny:
+		cm.Report(pool.chainID.Uint64(), "ctx denied by risk policy", "ctxID", ctx.ID().String(), "reason", reason)
+		return cross.ErrDeniedCtx
+	case risk.Hold:
+		pool.logger.Warn("ctx is held for manual approval", "ctxID", ctx.ID(), "reason", reason)
+		return cross.ErrHeldCtx
+	}
+	return nil
+}
+
 func (pool *CrossPool) signTx(ctx *cc.CrossTransaction) (*cc.CrossTransaction, error) {
 	ctx, err := cc.SignCtx(ctx, pool.signer, pool.signHash)
 	if err != nil {
@@ -29,6 +29,7 @@ import (
 	"github.com/simplechain-org/go-simplechain/params"
 
 	"github.com/simplechain-org/go-simplechain/cross"
+	"github.com/simplechain-org/go-simplechain/cross/backend/risk"
 	cc "github.com/simplechain-org/go-simplechain/cross/core"
 	cdb "github.com/simplechain-org/go-simplechain/cross/database"
 	"github.com/simplechain-org/go-simplechain/cross/trigger"
@@ -161,6 +162,47 @@ func TestCrossPool_Commit(t *testing.T) {
 
 }
 
+func TestCrossPool_Risk(t *testing.T) {
+	store := newTestMemoryStore()
+	p := newPoolTester(store)
+	ctx := cc.NewCrossTransaction(big.NewInt(1e18), big.NewInt(2e18), big.NewInt(19),
+		common.HexToHash("0b2aa4c82a3b0187a087e030a26b71fc1a49e74d3776ae8e03876ea9153abbca"),
+		common.HexToHash("0b2aa4c82a3b0187a087e030a26b71fc1a49e74d3776ae8e03876ea9153abbca"),
+		common.HexToHash("0b2aa4c82a3b0187a087e030a26b71fc1a49e74d3776ae8e03876ea9153abbca"),
+		crypto.PubkeyToAddress(p.localKey.PublicKey), crypto.PubkeyToAddress(p.remoteKey.PublicKey), nil)
+
+	// denied sender is never signed
+	p.RiskPolicy().Deny(ctx.Data.From)
+	_, _, errs := p.AddLocals(ctx)
+	assert.Equal(t, []error{cross.ErrDeniedCtx}, errs)
+	assert.Equal(t, 0, len(p.RiskPolicy().Held()))
+	p.RiskPolicy().Undeny(ctx.Data.From)
+
+	// ctx over threshold is held until approved
+	p.RiskPolicy().SetConfig(risk.Config{ApprovalThreshold: big.NewInt(1e18)})
+	signed, _, errs := p.AddLocals(ctx)
+	assert.Equal(t, []error{cross.ErrHeldCtx}, errs)
+	assert.Equal(t, 0, len(signed))
+	assert.Equal(t, 0, p.pending.Len())
+	assert.Equal(t, 1, len(p.RiskPolicy().Held()))
+
+	// ctx failed to be added stays held
+	store.Adds(p.chainID, []*cc.CrossTransactionWithSignatures{cc.NewCrossTransactionWithSignatures(ctx, 1)}, false)
+	_, _, errs = p.Approve(ctx.ID())
+	assert.Equal(t, []error{cross.ErrAlreadyExistCtx}, errs)
+	assert.Equal(t, 1, len(p.RiskPolicy().Held()))
+	delete(store.db, ctx.ID())
+
+	signed, _, errs = p.Approve(ctx.ID())
+	assert.Nil(t, errs)
+	assert.Equal(t, 1, len(signed))
+	assert.Equal(t, 1, p.pending.Len())
+	assert.Equal(t, 0, len(p.RiskPolicy().Held()))
+
+	_, _, errs = p.Approve(ctx.ID())
+	assert.Equal(t, []error{cross.ErrNotHeldCtx}, errs)
+}
+
 func (p *poolTester) addLocal(t *testing.T) {
 	fromAddr := crypto.PubkeyToAddress(p.localKey.PublicKey)
 	toAddr := crypto.PubkeyToAddress(p.remoteKey.PublicKey)
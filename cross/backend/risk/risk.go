@@ -0,0 +1,276 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package risk implements the anchor-side risk policy, which decides whether
+// a local anchor signs a maker ctx immediately, holds it for manual approval,
+// or refuses to sign it at all.
+package risk
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/simplechain-org/go-simplechain/common"
+
+	cc "github.com/simplechain-org/go-simplechain/cross/core"
+)
+
+const DefaultWindow = time.Hour
+
+type Config struct {
+	Window            time.Duration    `json:"window"`            // time window of value limits, DefaultWindow if zero
+	AddressLimit      *big.Int         `json:"addressLimit"`      // max value signed for one sender in a window, unlimited if nil
+	GlobalLimit       *big.Int         `json:"globalLimit"`       // max value signed for all senders in a window, unlimited if nil
+	ApprovalThreshold *big.Int         `json:"approvalThreshold"` // ctx with value not less than threshold needs manual approval
+	DenyList          []common.Address `json:"denyList"`          // ctx from or to these addresses is never signed
+	Paused            bool             `json:"paused"`            // hold every ctx for manual approval
+}
+
+type Verdict uint8
+
+const (
+	Allow Verdict = iota // sign the ctx
+	Hold                 // put the ctx into approval queue
+	Deny                 // refuse to sign the ctx
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case Allow:
+		return "allow"
+	case Hold:
+		return "hold"
+	case Deny:
+		return "deny"
+	default:
+		return "unknown"
+	}
+}
+
+// Held is a ctx waiting for manual approval
+type Held struct {
+	Tx     *cc.CrossTransaction
+	Reason string
+	Time   time.Time
+}
+
+type record struct {
+	time  time.Time
+	value *big.Int
+}
+
+// Policy checks local ctxs against the risk config, it is safe for concurrent use.
+// The approval queue lives in memory, held ctxs are dropped on restart and left to
+// the other anchors to sign.
+type Policy struct {
+	config Config
+	deny   map[common.Address]struct{}
+
+	usage  map[common.Address][]record // signed value records of every sender in window
+	global []record                    // signed value records of all senders in window
+	held   map[common.Hash]*Held
+
+	now func() time.Time
+	mu  sync.Mutex
+}
+
+func NewPolicy(config Config) *Policy {
+	p := &Policy{
+		usage: make(map[common.Address][]record),
+		held:  make(map[common.Hash]*Held),
+		now:   time.Now,
+	}
+	p.setConfig(config)
+	return p
+}
+
+func (p *Policy) setConfig(config Config) {
+	if config.Window <= 0 {
+		config.Window = DefaultWindow
+	}
+	p.deny = make(map[common.Address]struct{}, len(config.DenyList))
+	for _, addr := range config.DenyList {
+		p.deny[addr] = struct{}{}
+	}
+	config.DenyList = nil // deny list is kept in p.deny
+	p.config = config
+}
+
+// Config returns a copy of current config
+func (p *Policy) Config() Config {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	config := p.config
+	for addr := range p.deny {
+		config.DenyList = append(config.DenyList, addr)
+	}
+	sort.Slice(config.DenyList, func(i, j int) bool {
+		return bytes.Compare(config.DenyList[i].Bytes(), config.DenyList[j].Bytes()) < 0
+	})
+	return config
+}
+
+// SetConfig replaces the config, recorded values and held ctxs are kept
+func (p *Policy) SetConfig(config Config) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.setConfig(config)
+}
+
+func (p *Policy) SetPaused(paused bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.config.Paused = paused
+}
+
+func (p *Policy) Deny(addr common.Address) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.deny[addr] = struct{}{}
+}
+
+func (p *Policy) Undeny(addr common.Address) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.deny, addr)
+}
+
+// Check decides the verdict of a local ctx, the ctx is put into approval queue when it is held,
+// and its value is recorded when it is allowed.
+func (p *Policy) Check(ctx *cc.CrossTransaction) (Verdict, string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.deny[ctx.Data.From]; ok {
+		return Deny, "sender is denied"
+	}
+	if _, ok := p.deny[ctx.Data.To]; ok {
+		return Deny, "receiver is denied"
+	}
+	if _, ok := p.held[ctx.ID()]; ok {
+		return Hold, "already held"
+	}
+
+	now := p.now()
+	p.expire(now)
+
+	value := ctx.Data.Value
+	var reason string
+	switch {
+	case p.config.Paused:
+		reason = "signing is paused"
+	case p.config.ApprovalThreshold != nil && value.Cmp(p.config.ApprovalThreshold) >= 0:
+		reason = "value exceeds approval threshold"
+	case exceed(p.usage[ctx.Data.From], value, p.config.AddressLimit):
+		reason = "sender value limit exceeded"
+	case exceed(p.global, value, p.config.GlobalLimit):
+		reason = "global value limit exceeded"
+	}
+	if reason != "" {
+		p.held[ctx.ID()] = &Held{Tx: ctx, Reason: reason, Time: now}
+		return Hold, reason
+	}
+
+	p.record(ctx, now)
+	return Allow, ""
+}
+
+// Held returns the approval queue ordered by held time
+func (p *Policy) Held() []*Held {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	list := make([]*Held, 0, len(p.held))
+	for _, held := range p.held {
+		list = append(list, held)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Time.Before(list[j].Time) })
+	return list
+}
+
+// Get returns the ctx held in approval queue, nil if it is not held
+func (p *Policy) Get(ctxID common.Hash) *cc.CrossTransaction {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if held, ok := p.held[ctxID]; ok {
+		return held.Tx
+	}
+	return nil
+}
+
+// Approve removes ctx from approval queue and records its value
+func (p *Policy) Approve(ctxID common.Hash) *cc.CrossTransaction {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	held, ok := p.held[ctxID]
+	if !ok {
+		return nil
+	}
+	delete(p.held, ctxID)
+	p.record(held.Tx, p.now())
+	return held.Tx
+}
+
+// Reject removes ctx from approval queue without signing
+func (p *Policy) Reject(ctxID common.Hash) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.held[ctxID]; !ok {
+		return false
+	}
+	delete(p.held, ctxID)
+	return true
+}
+
+func (p *Policy) record(ctx *cc.CrossTransaction, now time.Time) {
+	if ctx.Data.Value.Sign() == 0 {
+		return
+	}
+	r := record{time: now, value: ctx.Data.Value}
+	p.usage[ctx.Data.From] = append(p.usage[ctx.Data.From], r)
+	p.global = append(p.global, r)
+}
+
+// expire removes records out of the window
+func (p *Policy) expire(now time.Time) {
+	start := now.Add(-p.config.Window)
+	for addr, records := range p.usage {
+		if records = trim(records, start); len(records) == 0 {
+			delete(p.usage, addr)
+		} else {
+			p.usage[addr] = records
+		}
+	}
+	p.global = trim(p.global, start)
+}
+
+func trim(records []record, start time.Time) []record {
+	i := sort.Search(len(records), func(i int) bool { return records[i].time.After(start) })
+	return records[i:]
+}
+
+func exceed(records []record, value, limit *big.Int) bool {
+	if limit == nil {
+		return false
+	}
+	sum := new(big.Int).Set(value)
+	for _, r := range records {
+		sum.Add(sum, r.value)
+	}
+	return sum.Cmp(limit) > 0
+}
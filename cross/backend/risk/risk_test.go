@@ -0,0 +1,94 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package risk
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/simplechain-org/go-simplechain/common"
+
+	cc "github.com/simplechain-org/go-simplechain/cross/core"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newCtx(id byte, from, to common.Address, value int64) *cc.CrossTransaction {
+	return cc.NewCrossTransaction(big.NewInt(value), big.NewInt(value), big.NewInt(2),
+		common.BytesToHash([]byte{id}), common.Hash{}, common.Hash{}, from, to, nil)
+}
+
+func TestPolicy_Limits(t *testing.T) {
+	var (
+		alice = common.HexToAddress("0x01")
+		bob   = common.HexToAddress("0x02")
+		now   = time.Unix(1600000000, 0)
+	)
+	p := NewPolicy(Config{
+		Window:       time.Minute,
+		AddressLimit: big.NewInt(10),
+		GlobalLimit:  big.NewInt(15),
+	})
+	p.now = func() time.Time { return now }
+
+	v, _ := p.Check(newCtx(1, alice, bob, 6))
+	assert.Equal(t, Allow, v)
+	v, _ = p.Check(newCtx(2, alice, bob, 6)) // alice: 12 > 10
+	assert.Equal(t, Hold, v)
+	v, _ = p.Check(newCtx(3, bob, alice, 9)) // global: 15
+	assert.Equal(t, Allow, v)
+	v, _ = p.Check(newCtx(4, bob, alice, 1)) // global: 16 > 15
+	assert.Equal(t, Hold, v)
+	assert.Equal(t, 2, len(p.Held()))
+
+	// records out of window are expired
+	now = now.Add(time.Minute)
+	v, _ = p.Check(newCtx(5, alice, bob, 10))
+	assert.Equal(t, Allow, v)
+
+	// approved ctx is recorded
+	assert.NotNil(t, p.Approve(common.BytesToHash([]byte{2})))
+	assert.Nil(t, p.Approve(common.BytesToHash([]byte{2})))
+	assert.True(t, p.Reject(common.BytesToHash([]byte{4})))
+	assert.Equal(t, 0, len(p.Held()))
+	v, _ = p.Check(newCtx(6, bob, alice, 1)) // global: 10+6+1 > 15
+	assert.Equal(t, Hold, v)
+}
+
+func TestPolicy_DenyAndPause(t *testing.T) {
+	var (
+		alice = common.HexToAddress("0x01")
+		bob   = common.HexToAddress("0x02")
+	)
+	p := NewPolicy(Config{DenyList: []common.Address{bob}})
+	assert.Equal(t, []common.Address{bob}, p.Config().DenyList)
+
+	v, _ := p.Check(newCtx(1, alice, bob, 1))
+	assert.Equal(t, Deny, v)
+	p.Undeny(bob)
+	v, _ = p.Check(newCtx(1, alice, bob, 1))
+	assert.Equal(t, Allow, v)
+
+	p.SetPaused(true)
+	v, _ = p.Check(newCtx(2, alice, bob, 0)) // message is held as well
+	assert.Equal(t, Hold, v)
+	v, reason := p.Check(newCtx(2, alice, bob, 0))
+	assert.Equal(t, Hold, v)
+	assert.Equal(t, "already held", reason)
+	assert.Equal(t, 1, len(p.Held()))
+}
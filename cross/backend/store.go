@@ -22,13 +22,12 @@ import (
 	"sync"
 
 	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/ethdb"
 	"github.com/simplechain-org/go-simplechain/log"
 
+	"github.com/simplechain-org/go-simplechain/cross"
 	cc "github.com/simplechain-org/go-simplechain/cross/core"
 	cdb "github.com/simplechain-org/go-simplechain/cross/database"
-
-	"github.com/asdine/storm/v3"
-	"github.com/asdine/storm/v3/q"
 )
 
 const defaultCacheSize = 4096
@@ -38,7 +37,8 @@ var ErrInvalidChainStore = errors.New("invalid chain store, chainID can not be n
 // CrossStore store cross transactions into CtxDBs
 type CrossStore struct {
 	stores map[uint64]cdb.CtxDB // chainID -> CtxDB
-	db     *storm.DB            // database to store cws
+	db     ethdb.KeyValueStore  // database to store cws
+	ctx    cdb.ServiceContext   // opens the storm database of old version for migration
 	mu     sync.Mutex
 	logger log.Logger
 }
@@ -48,11 +48,12 @@ func NewCrossStore(ctx cdb.ServiceContext, makerDb string) (*CrossStore, error)
 		logger: log.New("X-module", "store"),
 	}
 
-	db, err := cdb.OpenStormDB(ctx, makerDb)
+	db, err := cdb.OpenEtherDB(ctx, makerDb)
 	if err != nil {
 		return nil, err
 	}
 	store.db = db
+	store.ctx = ctx
 	store.stores = make(map[uint64]cdb.CtxDB)
 	return store, nil
 }
@@ -67,7 +68,19 @@ func (s *CrossStore) RegisterChain(chainID *big.Int) cdb.CtxDB {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if s.stores[chainID.Uint64()] == nil {
-		s.stores[chainID.Uint64()] = cdb.NewIndexDB(chainID, s.db, defaultCacheSize)
+		store := cdb.NewKVIndexDB(chainID, s.db, defaultCacheSize)
+		// The storm database of old version is only needed for migration, close it once done
+		if legacy, err := cdb.OpenLegacyStormDB(s.ctx, cross.DataDir); err != nil {
+			s.logger.Error("Open legacy store failed", "error", err)
+		} else if legacy != nil {
+			if _, err := cdb.MigrateIndexDB(cdb.NewIndexDB(chainID, legacy, 0), store); err != nil {
+				s.logger.Error("Migrate storm store failed", "chainID", chainID, "error", err)
+			}
+			if err := legacy.Close(); err != nil {
+				s.logger.Warn("close legacy store failed", "error", err)
+			}
+		}
+		s.stores[chainID.Uint64()] = store
 		s.logger.New("remote", chainID)
 		s.logger.Info("Register chain successfully")
 	}
@@ -150,13 +163,13 @@ func (s *CrossStore) Height(chainID *big.Int) uint64 {
 }
 
 func (s *CrossStore) Stats() map[uint64]map[cc.CtxStatus]int {
-	waiting := q.Eq(cdb.StatusField, cc.CtxStatusWaiting)
-	illegal := q.Eq(cdb.StatusField, cc.CtxStatusIllegal)
-	executing := q.Eq(cdb.StatusField, cc.CtxStatusExecuting)
-	executed := q.Eq(cdb.StatusField, cc.CtxStatusExecuted)
-	finishing := q.Eq(cdb.StatusField, cc.CtxStatusFinishing)
-	finished := q.Eq(cdb.StatusField, cc.CtxStatusFinished)
-	pending := q.Eq(cdb.StatusField, cc.CtxStatusPending)
+	waiting := cdb.Eq(cdb.StatusField, cc.CtxStatusWaiting)
+	illegal := cdb.Eq(cdb.StatusField, cc.CtxStatusIllegal)
+	executing := cdb.Eq(cdb.StatusField, cc.CtxStatusExecuting)
+	executed := cdb.Eq(cdb.StatusField, cc.CtxStatusExecuted)
+	finishing := cdb.Eq(cdb.StatusField, cc.CtxStatusFinishing)
+	finished := cdb.Eq(cdb.StatusField, cc.CtxStatusFinished)
+	pending := cdb.Eq(cdb.StatusField, cc.CtxStatusPending)
 
 	results := make(map[uint64]map[cc.CtxStatus]int, len(s.stores))
 
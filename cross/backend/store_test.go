@@ -17,14 +17,23 @@
 package backend
 
 import (
+	"io/ioutil"
 	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/simplechain-org/go-simplechain/core/rawdb"
+	"github.com/simplechain-org/go-simplechain/ethdb"
 	"github.com/simplechain-org/go-simplechain/params"
 
+	"github.com/simplechain-org/go-simplechain/cross"
+
 	cc "github.com/simplechain-org/go-simplechain/cross/core"
 	cdb "github.com/simplechain-org/go-simplechain/cross/database"
 
+	"github.com/asdine/storm/v3"
 	"github.com/asdine/storm/v3/q"
 	"github.com/stretchr/testify/assert"
 )
@@ -187,6 +196,51 @@ func TestCrossStore_UpdatesNormal(t *testing.T) {
 	}
 }
 
+type testServiceContext string
+
+func (dir testServiceContext) ResolvePath(name string) string {
+	return filepath.Join(string(dir), name)
+}
+
+func (testServiceContext) OpenDatabase(string, int, int, string) (ethdb.Database, error) {
+	return rawdb.NewMemoryDatabase(), nil
+}
+
+func TestCrossStore_MigrateLegacy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cross-store")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	ctx := testServiceContext(dir)
+
+	chainID := params.TestChainConfig.ChainID
+	legacy, err := storm.Open(ctx.ResolvePath(cross.DataDir))
+	assert.NoError(t, err)
+	ctxList := generateCtx(10, cc.CtxStatusPending)
+	assert.NoError(t, cdb.NewIndexDB(chainID, legacy, 0).Writes(ctxList, false))
+	assert.NoError(t, legacy.Close())
+
+	store, err := NewCrossStore(ctx, cross.IndexDir)
+	assert.NoError(t, err)
+	defer store.Close()
+	assert.Equal(t, len(ctxList), store.RegisterChain(chainID).Count())
+
+	// the storm database is released once migrated
+	opened := make(chan error, 1)
+	go func() {
+		db, err := storm.Open(ctx.ResolvePath(cross.DataDir))
+		if err == nil {
+			db.Close()
+		}
+		opened <- err
+	}()
+	select {
+	case err := <-opened:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("legacy store is still locked after migration")
+	}
+}
+
 func newStoreTester(chainID *big.Int) (*CrossStore, error) {
 	store, err := NewCrossStore(nil, "testing-cross-store")
 	if err != nil {
@@ -17,28 +17,49 @@
 package cross
 
 import (
+	"math/big"
+
 	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/cross/backend/risk"
 	"github.com/simplechain-org/go-simplechain/cross/backend/synchronise"
+	"github.com/simplechain-org/go-simplechain/cross/trigger"
 )
 
 const (
 	LogDir   = "crosslog"
 	TxLogDir = "crosstxlog"
-	DataDir  = "crossdata"
+	DataDir  = "crossdata" // storm db of ctxs, only opened for migration
+	IndexDir = "crossindex"
 )
 
 type Config struct {
-	MainContract common.Address       `json:"mainContract"`
-	SubContract  common.Address       `json:"subContract"`
-	Signer       common.Address       `json:"signer"`
-	Anchors      []common.Address     `json:"anchors"`
-	SyncMode     synchronise.SyncMode `json:"syncMode"`
+	MainContract common.Address          `json:"mainContract"`
+	SubContract  common.Address          `json:"subContract"`
+	Signer       common.Address          `json:"signer"`
+	Anchors      []common.Address        `json:"anchors"`
+	SyncMode     synchronise.SyncMode    `json:"syncMode"`
+	Risk         risk.Config             `json:"risk"`
+	Confirms     []trigger.ConfirmPolicy `json:"confirms"` // confirm policies of chains, default depth if absent
 }
 
 var DefaultConfig = Config{
 	SyncMode: synchronise.ALL,
 }
 
+// ConfirmPolicy returns the confirm policy of chain, or a policy with the default depth if not configured
+func (config *Config) ConfirmPolicy(chainID *big.Int, depth uint64) trigger.ConfirmPolicy {
+	for _, policy := range config.Confirms {
+		if chainID != nil && policy.ChainID == chainID.Uint64() {
+			return policy
+		}
+	}
+	var id uint64
+	if chainID != nil {
+		id = chainID.Uint64()
+	}
+	return trigger.NewConfirmPolicy(id, depth)
+}
+
 func (config *Config) Sanitize() Config {
 	cfg := Config{
 		MainContract: config.MainContract,
@@ -23,6 +23,8 @@ contract crossDemo{
         uint8 delId;
         uint reward;
         uint totalReward;
+        mapping(bytes32=>uint) messageTxs; //跨链消息 txId => 发起区块高度
+        mapping(bytes32=>uint) deliveredTxs; //已投递的跨链消息 txId => 投递区块高度
     }
 
     struct Anchor {
@@ -61,6 +63,10 @@ contract crossDemo{
     event AccumulateRewards(uint remoteChainId, address indexed anchor, uint reward);
 
     event SetAnchorStatus(uint remoteChainId);
+    //发起跨链消息
+    event MessageTx(bytes32 indexed txId, address indexed from, address target, uint remoteChainId, bytes payload);
+    //投递跨链消息 success为目标合约的执行结果
+    event MessageDelivered(bytes32 indexed txId, address indexed target, uint remoteChainId, address from, bool success);
 
     modifier onlyAnchor(uint remoteChainId) {
         require(crossChains[remoteChainId].remoteChainId > 0,"remoteChainId err");
@@ -104,18 +110,17 @@ contract crossDemo{
         address[] memory delAnchors;
 
         //初始化信息
-        crossChains[remoteChainId] = Chain({
-            remoteChainId: remoteChainId,
-            maxValue: maxValue,
-            signConfirmCount: signConfirmCount,
-            anchorsPositionBit: (temp - 1) >> (64 - _anchors.length),
-            anchorAddress:newAnchors,
-            reward:0,
-            totalReward:0,
-            delsPositionBit: (temp - 1) >> 64,
-            delsAddress:delAnchors,
-            delId:0
-            });
+        Chain storage chain = crossChains[remoteChainId];
+        chain.remoteChainId = remoteChainId;
+        chain.maxValue = maxValue;
+        chain.signConfirmCount = signConfirmCount;
+        chain.anchorsPositionBit = ~temp >> (64 - _anchors.length);
+        chain.anchorAddress = newAnchors;
+        chain.reward = 0;
+        chain.totalReward = 0;
+        chain.delsPositionBit = ~temp >> 64;
+        chain.delsAddress = delAnchors;
+        chain.delId = 0;
 
         //加入锚定矿工
         for (uint8 i=0; i<_anchors.length; i++) {
@@ -135,7 +140,7 @@ contract crossDemo{
         require (_anchors.length > 0 && _anchors.length < 64,"need _anchors");
         require ((crossChains[remoteChainId].anchorAddress.length + _anchors.length) <= 64,"_anchors err");
         uint64 temp = 0;
-        crossChains[remoteChainId].anchorsPositionBit = (temp - 1) >> (64 - crossChains[remoteChainId].anchorAddress.length - _anchors.length);
+        crossChains[remoteChainId].anchorsPositionBit = ~temp >> (64 - crossChains[remoteChainId].anchorAddress.length - _anchors.length);
         //加入锚定矿工
         for (uint8 i=0; i<_anchors.length; i++) {
             if (crossChains[remoteChainId].anchors[_anchors[i]].remoteChainId != 0) {
@@ -158,7 +163,7 @@ contract crossDemo{
         require (_anchors.length > 0,"need _anchors");
         require((crossChains[remoteChainId].anchorAddress.length - crossChains[remoteChainId].signConfirmCount) >= _anchors.length,"_anchors err");
         uint64 temp = 0;
-        crossChains[remoteChainId].anchorsPositionBit = (temp - 1) >> (64 - crossChains[remoteChainId].anchorAddress.length + _anchors.length);
+        crossChains[remoteChainId].anchorsPositionBit = ~temp >> (64 - crossChains[remoteChainId].anchorAddress.length + _anchors.length);
         for (uint8 i=0; i<_anchors.length; i++) {
             if (crossChains[remoteChainId].anchors[_anchors[i]].remoteChainId == 0) {
                 revert();
@@ -186,7 +191,7 @@ contract crossDemo{
         }
         if(crossChains[remoteChainId].delsAddress.length < 64){
             uint64 temp = 0;
-            crossChains[remoteChainId].delsPositionBit = (temp - 1) >> (64 - crossChains[remoteChainId].delsAddress.length - 1);
+            crossChains[remoteChainId].delsPositionBit = ~temp >> (64 - crossChains[remoteChainId].delsAddress.length - 1);
             crossChains[remoteChainId].delAnchors[del] = Anchor({remoteChainId:remoteChainId, position:uint8(crossChains[remoteChainId].delsAddress.length),status:false,signCount:0,finishCount:0});
             crossChains[remoteChainId].delsAddress.push(del);
 
@@ -243,6 +248,14 @@ contract crossDemo{
         return 0;
     }
 
+    function getMessageTx(bytes32 txId, uint remoteChainId) public view returns(uint){
+        return crossChains[remoteChainId].messageTxs[txId];
+    }
+
+    function getDeliveredTx(bytes32 txId, uint remoteChainId) public view returns(uint){
+        return crossChains[remoteChainId].deliveredTxs[txId];
+    }
+
     function getAnchors(uint remoteChainId) public view returns(address[] memory _anchors,uint8){
         uint8 j=0;
         for (uint8 i=0; i<crossChains[remoteChainId].anchorAddress.length; i++) {
@@ -275,13 +288,12 @@ contract crossDemo{
         require(crossChains[remoteChainId].remoteChainId > 0,"chainId err"); //是否支持的跨链
         bytes32 txId = keccak256(abi.encodePacked(msg.sender, list(), remoteChainId));
         assert(crossChains[remoteChainId].makerTxs[txId].value == 0);
-        crossChains[remoteChainId].makerTxs[txId] = MakerInfo({
-            value:(msg.value - crossChains[remoteChainId].reward),
-            signatureCount:0,
-            to:focus,
-            from:msg.sender,
-            takerHash:bytes32(0x0)
-            });
+        MakerInfo storage info = crossChains[remoteChainId].makerTxs[txId];
+        info.value = msg.value - crossChains[remoteChainId].reward;
+        info.signatureCount = 0;
+        info.to = focus;
+        info.from = payable(msg.sender);
+        info.takerHash = bytes32(0x0);
         uint total = crossChains[remoteChainId].totalReward + crossChains[remoteChainId].reward;
         assert(total >= crossChains[remoteChainId].totalReward);
         crossChains[remoteChainId].totalReward = total;
@@ -388,6 +400,40 @@ contract crossDemo{
         emit TakerTx(ctx.txId,msg.sender,remoteChainId,ctx.from,ctx.value,ctx.destinationValue);
     }
 
+    //发起跨链消息，由锚定节点签名后在目的链调用target合约
+    function messageStart(uint remoteChainId, address target, bytes memory payload) public {
+        require(crossChains[remoteChainId].remoteChainId > 0,"chainId err"); //是否支持的跨链
+        require(target != address(0x0),"target err");
+        bytes32 txId = keccak256(abi.encodePacked(msg.sender, list(), remoteChainId));
+        assert(crossChains[remoteChainId].messageTxs[txId] == 0);
+        crossChains[remoteChainId].messageTxs[txId] = block.number;
+        emit MessageTx(txId, msg.sender, target, remoteChainId, payload);
+    }
+
+    struct Message {
+        bytes32 txId;
+        bytes32 txHash;
+        address from;
+        bytes32 blockHash;
+        address target;
+        bytes payload;
+        uint[] v;
+        bytes32[] r;
+        bytes32[] s;
+    }
+
+    //锚定节点投递跨链消息，签名数据与跨链交易一致(value与destValue为0，data为target与payload)
+    function messageDeliver(Message memory m, uint remoteChainId) public onlyAnchor(remoteChainId) {
+        require(m.v.length == m.r.length,"vrs err");
+        require(m.v.length == m.s.length,"vrs err");
+        require(crossChains[remoteChainId].deliveredTxs[m.txId] == 0,"txId err");
+        require(verifySignAndCount(keccak256(abi.encodePacked(uint(0), m.txId, m.txHash, m.from, m.blockHash, chainId(), uint(0), abi.encodePacked(m.target, m.payload))), remoteChainId,m.v,m.r,m.s) >= crossChains[remoteChainId].signConfirmCount,"sign error");
+        crossChains[remoteChainId].deliveredTxs[m.txId] = block.number;
+        crossChains[remoteChainId].anchors[msg.sender].finishCount ++;
+        (bool success,) = m.target.call(abi.encodeWithSignature("onCrossMessage(uint256,bytes32,address,bytes)", remoteChainId, m.txId, m.from, m.payload));
+        emit MessageDelivered(m.txId, m.target, remoteChainId, m.from, success);
+    }
+
     function chainId() public pure returns (uint id) {
         assembly {
             id := chainid()
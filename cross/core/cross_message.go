@@ -0,0 +1,92 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/simplechain-org/go-simplechain/accounts/abi"
+	"github.com/simplechain-org/go-simplechain/common"
+)
+
+/**
+  cross-chain message is a ctx made by messageStart, it carries no value,
+  the target contract address is packed in front of the payload (Input = target|payload),
+  so that target is covered by anchor signatures as same as the swap ctx.
+
+  status path of message:
+  (pending->waiting) <-sign-- message
+  (waiting->executing) <-mod-- deliver (anchor calls target in remote chain)
+  (executing->finished) <-mod-- confirmDeliver
+*/
+
+var ErrInvalidMessage = errors.New("invalid cross message")
+
+// NewCrossMessage creates a message ctx, which will be delivered to target contract on chain networkId
+func NewCrossMessage(networkId *big.Int, id, txHash, bHash common.Hash, from, target common.Address, payload []byte) *CrossTransaction {
+	input := make([]byte, 0, common.AddressLength+len(payload))
+	input = append(append(input, target.Bytes()...), payload...)
+	return NewCrossTransaction(new(big.Int), new(big.Int), networkId, id, txHash, bHash, from, target, input)
+}
+
+// IsMessage reports whether the ctx is a cross-chain message (swap ctx always has value)
+func (tx *CrossTransaction) IsMessage() bool {
+	return tx.Data.Value.Sign() == 0
+}
+
+// IsMessage reports whether the ctx is a cross-chain message (swap ctx always has value)
+func (cws *CrossTransactionWithSignatures) IsMessage() bool {
+	return cws.Data.Value.Sign() == 0
+}
+
+// Message is the argument of messageDeliver in cross contract
+type Message struct {
+	TxId      common.Hash
+	TxHash    common.Hash
+	From      common.Address
+	BlockHash common.Hash
+	Target    common.Address
+	Payload   []byte
+	V         []*big.Int
+	R         [][32]byte
+	S         [][32]byte
+}
+
+// ConstructMessageData packs the messageDeliver call of a signed message
+func (cws *CrossTransactionWithSignatures) ConstructMessageData(crossContract abi.ABI) ([]byte, error) {
+	if !cws.IsMessage() || len(cws.Data.Input) < common.AddressLength {
+		return nil, ErrInvalidMessage
+	}
+	chainID := cws.ChainId() // source chain of the message
+	cws.lock.RLock()
+	defer cws.lock.RUnlock()
+	msg := Message{
+		TxId:      cws.Data.CTxId,
+		TxHash:    cws.Data.TxHash,
+		From:      cws.Data.From,
+		BlockHash: cws.Data.BlockHash,
+		Target:    common.BytesToAddress(cws.Data.Input[:common.AddressLength]),
+		Payload:   cws.Data.Input[common.AddressLength:],
+	}
+	for i := 0; i < cws.signaturesLength(); i++ {
+		msg.V = append(msg.V, cws.Data.V[i])
+		msg.R = append(msg.R, common.BigToHash(cws.Data.R[i]))
+		msg.S = append(msg.S, common.BigToHash(cws.Data.S[i]))
+	}
+	return crossContract.Pack("messageDeliver", msg, chainID)
+}
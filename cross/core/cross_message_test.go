@@ -0,0 +1,105 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/simplechain-org/go-simplechain/accounts/abi"
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/common/hexutil"
+	"github.com/simplechain-org/go-simplechain/crypto"
+	"github.com/simplechain-org/go-simplechain/params"
+)
+
+func crossABI(t *testing.T) abi.ABI {
+	data, err := hexutil.Decode(params.CrossDemoAbi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	crossABI, err := abi.JSON(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return crossABI
+}
+
+func TestCrossMessageABI(t *testing.T) {
+	crossABI := crossABI(t)
+	if id := crossABI.Events["MessageTx"].ID(); id != params.MessageTopic {
+		t.Errorf("MessageTx topic mismatch: have %x, want %x", id, params.MessageTopic)
+	}
+	if id := crossABI.Events["MessageDelivered"].ID(); id != params.DeliveredTopic {
+		t.Errorf("MessageDelivered topic mismatch: have %x, want %x", id, params.DeliveredTopic)
+	}
+	if id := crossABI.Methods["getMessageTx"].ID(); !bytes.Equal(id, params.GetMessageTxFn) {
+		t.Errorf("getMessageTx selector mismatch: have %x, want %x", id, params.GetMessageTxFn)
+	}
+	if id := crossABI.Methods["getDeliveredTx"].ID(); !bytes.Equal(id, params.GetDeliveredFn) {
+		t.Errorf("getDeliveredTx selector mismatch: have %x, want %x", id, params.GetDeliveredFn)
+	}
+}
+
+func TestCrossMessage(t *testing.T) {
+	var (
+		key, _  = crypto.GenerateKey()
+		target  = common.HexToAddress("0x095e7baea6a6c7c4c2dfeb977efac326af552d87")
+		payload = []byte("cross message")
+		signer  = NewEIP155CtxSigner(big.NewInt(1024))
+	)
+	msg := NewCrossMessage(big.NewInt(1025), common.HexToHash("0x01"), common.HexToHash("0x02"), common.HexToHash("0x03"),
+		common.HexToAddress("0x01"), target, payload)
+	if !msg.IsMessage() {
+		t.Fatal("message ctx is not recognized")
+	}
+	if !bytes.Equal(msg.Data.Input, append(target.Bytes(), payload...)) {
+		t.Fatalf("target is not covered by input: %x", msg.Data.Input)
+	}
+
+	signed, err := SignCtx(msg, signer, func(hash []byte) ([]byte, error) { return crypto.Sign(hash, key) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from, err := CtxSender(signer, signed); err != nil || from != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatalf("signer mismatch: have %x, err %v", from, err)
+	}
+
+	cws := NewCrossTransactionWithSignatures(signed, 1)
+	crossABI := crossABI(t)
+	data, err := cws.ConstructMessageData(crossABI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	method := crossABI.Methods["messageDeliver"]
+	if !bytes.Equal(data[:4], method.ID()) {
+		t.Fatalf("method mismatch: have %x, want %x", data[:4], method.ID())
+	}
+	args, err := method.Inputs.UnpackValues(data[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chainID := args[1].(*big.Int); chainID.Cmp(big.NewInt(1024)) != 0 {
+		t.Errorf("source chain mismatch: have %v, want 1024", chainID)
+	}
+
+	swap := NewCrossTransactionWithSignatures(rightvrsCtx, 1)
+	if _, err := swap.ConstructMessageData(crossABI); err != ErrInvalidMessage {
+		t.Errorf("swap ctx packed as message, error: %v", err)
+	}
+}
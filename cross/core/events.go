@@ -34,6 +34,14 @@ type ConfirmedTakerEvent struct {
 	Txs []*ReceptTransaction
 }
 
+type NewDeliveryEvent struct {
+	Deliveries []*ReceptTransaction
+}
+
+type ConfirmedDeliveryEvent struct {
+	Deliveries []*ReceptTransaction
+}
+
 type SignedCtxEvent struct { // pool event
 	Txs      []*CrossTransactionWithSignatures
 	CallBack func([]CommitEvent)
@@ -94,11 +102,16 @@ type CrossBlockEvent struct {
 	NewAnchor       NewAnchorEvent
 	ReorgTaker      NewTakerEvent
 	ReorgFinish     NewFinishEvent
+
+	NewDelivery       NewDeliveryEvent
+	ConfirmedDelivery ConfirmedDeliveryEvent
+	ReorgDelivery     NewDeliveryEvent
 }
 
 func (e CrossBlockEvent) IsEmpty() bool {
 	return len(e.ConfirmedMaker.Txs)|len(e.ConfirmedTaker.Txs)|
 		len(e.ConfirmedFinish.Finishes)|len(e.NewTaker.Takers)|
 		len(e.NewFinish.Finishes)|len(e.NewAnchor.ChainInfo)|
-		len(e.ReorgTaker.Takers)|len(e.ReorgFinish.Finishes) == 0
+		len(e.ReorgTaker.Takers)|len(e.ReorgFinish.Finishes)|
+		len(e.NewDelivery.Deliveries)|len(e.ConfirmedDelivery.Deliveries)|len(e.ReorgDelivery.Deliveries) == 0
 }
@@ -0,0 +1,254 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package crosstest
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"path/filepath"
+	"sync"
+
+	"github.com/simplechain-org/go-simplechain/accounts/abi"
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/core"
+	"github.com/simplechain-org/go-simplechain/core/rawdb"
+	"github.com/simplechain-org/go-simplechain/core/types"
+	"github.com/simplechain-org/go-simplechain/crypto"
+	"github.com/simplechain-org/go-simplechain/ethdb"
+	"github.com/simplechain-org/go-simplechain/log"
+	"github.com/simplechain-org/go-simplechain/node"
+	"github.com/simplechain-org/go-simplechain/rpc"
+
+	"github.com/simplechain-org/go-simplechain/cross"
+	"github.com/simplechain-org/go-simplechain/cross/backend"
+	cc "github.com/simplechain-org/go-simplechain/cross/core"
+	"github.com/simplechain-org/go-simplechain/cross/trigger/simpletrigger"
+	"github.com/simplechain-org/go-simplechain/cross/trigger/simpletrigger/retriever"
+	"github.com/simplechain-org/go-simplechain/cross/trigger/simpletrigger/subscriber"
+)
+
+// serviceContext roots the cross databases of an anchor at its own directory,
+// so that the store survives an anchor restart.
+type serviceContext struct {
+	dir string
+}
+
+func (ctx serviceContext) ResolvePath(name string) string {
+	return filepath.Join(ctx.dir, name)
+}
+
+func (ctx serviceContext) OpenDatabase(name string, cache int, handles int, namespace string) (ethdb.Database, error) {
+	return rawdb.NewLevelDBDatabase(ctx.ResolvePath(name), cache, handles, namespace)
+}
+
+// protocolManager reports that the simulated chain is always synchronised
+type protocolManager struct{}
+
+func (protocolManager) NetworkId() uint64                                       { return 0 }
+func (protocolManager) GetNonce(common.Address) uint64                          { return 0 }
+func (protocolManager) AddLocals([]*types.Transaction)                          {}
+func (protocolManager) Pending() (map[common.Address]types.Transactions, error) { return nil, nil }
+func (protocolManager) CanAcceptTxs() bool                                      { return true }
+
+// executor submits makerFinish of confirmed takers into the simulated chain
+type executor struct {
+	chain *Chain
+	key   *ecdsa.PrivateKey
+	abi   abi.ABI
+}
+
+func (exe *executor) SignHash(hash []byte) ([]byte, error) {
+	return crypto.Sign(hash, exe.key)
+}
+
+func (exe *executor) SubmitTransaction(rtxs []*cc.ReceptTransaction) {
+	for _, rtx := range rtxs {
+		data, err := rtx.ConstructData(exe.abi)
+		if err != nil {
+			log.Warn("construct makerFinish failed", "ctxID", rtx.CTxId, "error", err)
+			continue
+		}
+		exe.chain.Call(exe.key, new(big.Int), data)
+	}
+}
+
+func (exe *executor) DeliverMessages(msgs []*cc.CrossTransactionWithSignatures) {
+	for _, msg := range msgs {
+		data, err := msg.ConstructMessageData(exe.abi)
+		if err != nil {
+			log.Warn("construct messageDeliver failed", "ctxID", msg.ID(), "error", err)
+			continue
+		}
+		exe.chain.Call(exe.key, new(big.Int), data)
+	}
+}
+
+func (exe *executor) Start() {}
+func (exe *executor) Stop()  {}
+
+// anchorChain is the view of a simulated chain from an anchor
+type anchorChain struct {
+	chain      *Chain
+	bc         *core.BlockChain
+	subscriber *subscriber.SimpleSubscriber
+	api        *backend.PublicCrossChainAPI
+}
+
+func (ac *anchorChain) ChainID() *big.Int {
+	return ac.chain.ChainID()
+}
+
+func (ac *anchorChain) GenesisHash() common.Hash {
+	return ac.bc.Genesis().Hash()
+}
+
+func (ac *anchorChain) RegisterAPIs(apis []rpc.API) {
+	for _, api := range apis {
+		if public, ok := api.Service.(*backend.PublicCrossChainAPI); ok {
+			ac.api = public
+		}
+	}
+}
+
+// sync imports the canonical blocks which are missing in the anchor blockchain
+func (ac *anchorChain) sync() error {
+	blocks := ac.chain.canonicalAfter(ac.bc)
+	if len(blocks) == 0 {
+		return nil
+	}
+	_, err := ac.bc.InsertChain(blocks)
+	return err
+}
+
+func (ac *anchorChain) newContext(config cross.Config, exe *executor, journal string) *cross.ServiceContext {
+	if ac.subscriber != nil {
+		ac.subscriber.Stop() // subscriber of the crashed service
+	}
+	ac.subscriber = subscriber.NewSimpleSubscriber(ac.chain.Contract, ac.bc, journal,
+		config.ConfirmPolicy(ac.chain.Config.ChainID, uint64(simpletrigger.DefaultConfirmDepth)))
+	return &cross.ServiceContext{
+		Config:        &config,
+		ProtocolChain: ac,
+		Subscriber:    ac.subscriber,
+		Retriever:     retriever.NewSimpleRetriever(ac.bc, protocolManager{}, ac.chain.Contract, &config, ac.chain.Config),
+		Executor:      exe,
+	}
+}
+
+// Anchor is a cross-chain anchor node following both the main and the sub chain
+type Anchor struct {
+	Index   int
+	Key     *ecdsa.PrivateKey
+	Address common.Address
+
+	dir       string
+	main, sub *anchorChain
+	running   bool
+	mu        sync.Mutex
+}
+
+func newAnchor(index int, key *ecdsa.PrivateKey, dir string, main, sub *Chain) (*Anchor, error) {
+	mainBC, err := main.newBlockChain()
+	if err != nil {
+		return nil, err
+	}
+	subBC, err := sub.newBlockChain()
+	if err != nil {
+		return nil, err
+	}
+	return &Anchor{
+		Index:   index,
+		Key:     key,
+		Address: crypto.PubkeyToAddress(key.PublicKey),
+		dir:     dir,
+		main:    &anchorChain{chain: main, bc: mainBC},
+		sub:     &anchorChain{chain: sub, bc: subBC},
+	}, nil
+}
+
+func (a *Anchor) newService(anchors []common.Address, abi abi.ABI) (node.Service, error) {
+	config := cross.Config{
+		MainContract: a.main.chain.Contract,
+		SubContract:  a.sub.chain.Contract,
+		Signer:       a.Address,
+		Anchors:      anchors,
+		SyncMode:     cross.DefaultConfig.SyncMode,
+	}
+	ctx := serviceContext{dir: a.dir}
+	mainCtx := a.main.newContext(config, &executor{chain: a.main.chain, key: a.Key, abi: abi},
+		ctx.ResolvePath("mainChain_unconfirmed.rlp"))
+	subCtx := a.sub.newContext(config, &executor{chain: a.sub.chain, key: a.Key, abi: abi},
+		ctx.ResolvePath("subChain_unconfirmed.rlp"))
+	return backend.NewCrossService(ctx, mainCtx, subCtx, config)
+}
+
+// Status returns the status of the ctx stored in the anchor, which is made on the chain
+func (a *Anchor) Status(chain *Chain, ctxID common.Hash) (cc.CtxStatus, bool) {
+	ac := a.chain(chain)
+	if ac == nil || ac.api == nil {
+		return 0, false
+	}
+	tx := ac.api.CtxGet(ctxID)
+	if tx == nil {
+		return 0, false
+	}
+	return tx.Status, true
+}
+
+// Get returns the ctx stored in the anchor, which is made on the chain
+func (a *Anchor) Get(chain *Chain, ctxID common.Hash) *backend.RPCCrossTransaction {
+	if ac := a.chain(chain); ac != nil && ac.api != nil {
+		return ac.api.CtxGet(ctxID)
+	}
+	return nil
+}
+
+// Running reports whether the cross service of the anchor is running
+func (a *Anchor) Running() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.running
+}
+
+func (a *Anchor) setRunning(running bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.running = running
+}
+
+func (a *Anchor) chain(chain *Chain) *anchorChain {
+	switch chain {
+	case a.main.chain:
+		return a.main
+	case a.sub.chain:
+		return a.sub
+	}
+	return nil
+}
+
+func (a *Anchor) sync() error {
+	if err := a.main.sync(); err != nil {
+		return err
+	}
+	return a.sub.sync()
+}
+
+func (a *Anchor) close() {
+	for _, ac := range []*anchorChain{a.main, a.sub} {
+		ac.bc.Stop() // the cross subscriber is stopped by the blockchain
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package crosstest
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/common/hexutil"
+	"github.com/simplechain-org/go-simplechain/consensus/ethash"
+	"github.com/simplechain-org/go-simplechain/core"
+	"github.com/simplechain-org/go-simplechain/core/rawdb"
+	"github.com/simplechain-org/go-simplechain/core/types"
+	"github.com/simplechain-org/go-simplechain/core/vm"
+	"github.com/simplechain-org/go-simplechain/crypto"
+	"github.com/simplechain-org/go-simplechain/ethdb"
+	"github.com/simplechain-org/go-simplechain/params"
+)
+
+const (
+	callGasLimit  = 3000000
+	deployGasUsed = 6000000
+)
+
+var (
+	callGasPrice = big.NewInt(params.GWei)
+	// initial balance of every account funded in the genesis
+	fundedBalance = new(big.Int).Mul(big.NewInt(1e9), big.NewInt(params.Ether))
+
+	errNoReceipt = errors.New("transaction receipt not found")
+)
+
+// call is a contract invocation waiting to be included into the next generated block
+type call struct {
+	key   *ecdsa.PrivateKey
+	to    *common.Address
+	value *big.Int
+	data  []byte
+	gas   uint64
+}
+
+// Chain is an in-memory ethash-faker blockchain with the crossdemo contract deployed
+// at block 1. It generates canonical blocks and forks, which are imported by every
+// anchor into its own core.BlockChain instance.
+type Chain struct {
+	Config   *params.ChainConfig
+	Contract common.Address
+
+	genesis *core.Genesis
+	db      ethdb.Database // generator database holding the state of every generated block
+
+	blocks   []*types.Block // canonical blocks, blocks[0] is the genesis
+	receipts map[common.Hash]*types.Receipt
+	pending  []*call
+	mu       sync.RWMutex
+}
+
+func newChain(chainID uint64, owner *ecdsa.PrivateKey, funded []common.Address) *Chain {
+	config := &params.ChainConfig{
+		ChainID:          new(big.Int).SetUint64(chainID),
+		SingularityBlock: big.NewInt(0),
+		Ethash:           new(params.EthashConfig),
+	}
+	alloc := make(core.GenesisAlloc, len(funded)+1)
+	alloc[crypto.PubkeyToAddress(owner.PublicKey)] = core.GenesisAccount{Balance: fundedBalance}
+	for _, addr := range funded {
+		alloc[addr] = core.GenesisAccount{Balance: fundedBalance}
+	}
+	c := &Chain{
+		Config:   config,
+		Contract: crypto.CreateAddress(crypto.PubkeyToAddress(owner.PublicKey), 0),
+		genesis:  &core.Genesis{Config: config, GasLimit: 2 * deployGasUsed, Alloc: alloc},
+		db:       rawdb.NewMemoryDatabase(),
+		receipts: make(map[common.Hash]*types.Receipt),
+	}
+	c.blocks = []*types.Block{c.genesis.MustCommit(c.db)}
+
+	// deploy the cross contract at block 1
+	c.submit(&call{key: owner, value: new(big.Int), data: hexutil.MustDecode(crossDemoBin), gas: deployGasUsed})
+	c.Mine(1)
+	return c
+}
+
+// ChainID returns the chain id of the simulated chain
+func (c *Chain) ChainID() *big.Int {
+	return c.Config.ChainID
+}
+
+// Genesis returns the genesis block shared by every anchor
+func (c *Chain) Genesis() *types.Block {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.blocks[0]
+}
+
+// CurrentBlock returns the head of the canonical chain
+func (c *Chain) CurrentBlock() *types.Block {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.blocks[len(c.blocks)-1]
+}
+
+// Pending returns the number of calls waiting for the next block
+func (c *Chain) Pending() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.pending)
+}
+
+// Receipt returns the receipt of a transaction generated on the chain (include side chain)
+func (c *Chain) Receipt(txHash common.Hash) (*types.Receipt, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if receipt, ok := c.receipts[txHash]; ok {
+		return receipt, nil
+	}
+	return nil, errNoReceipt
+}
+
+// Call submits a contract invocation from key, it will be included into the next mined block
+func (c *Chain) Call(key *ecdsa.PrivateKey, value *big.Int, data []byte) {
+	to := c.Contract
+	c.submit(&call{key: key, to: &to, value: value, data: data, gas: callGasLimit})
+}
+
+func (c *Chain) submit(call *call) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending = append(c.pending, call)
+}
+
+// Mine generates n canonical blocks on the head, pending calls are packed into the first one.
+func (c *Chain) Mine(n int) []*types.Block {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pending := c.pending
+	c.pending = nil
+	blocks := c.generate(c.blocks[len(c.blocks)-1], n, pending)
+	c.blocks = append(c.blocks, blocks...)
+	return blocks
+}
+
+// Fork drops the last depth canonical blocks and replaces them with n empty blocks.
+// The transactions in the dropped blocks are discarded, n must be greater than depth
+// to make the fork heavier than the old canonical chain.
+func (c *Chain) Fork(depth, n int) ([]*types.Block, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if depth >= len(c.blocks)-1 || n <= depth {
+		return nil, errors.New("invalid fork depth")
+	}
+	ancestor := len(c.blocks) - 1 - depth
+	blocks := c.generate(c.blocks[ancestor], n, nil)
+	c.blocks = append(c.blocks[:ancestor+1], blocks...)
+	return blocks, nil
+}
+
+// canonicalAfter returns the canonical blocks after the common ancestor with bc
+func (c *Chain) canonicalAfter(bc *core.BlockChain) []*types.Block {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	number := bc.CurrentBlock().NumberU64()
+	if number > uint64(len(c.blocks)-1) {
+		number = uint64(len(c.blocks) - 1)
+	}
+	for ; number > 0; number-- {
+		if bc.GetCanonicalHash(number) == c.blocks[number].Hash() {
+			break
+		}
+	}
+	return append([]*types.Block(nil), c.blocks[number+1:]...)
+}
+
+func (c *Chain) generate(parent *types.Block, n int, calls []*call) []*types.Block {
+	signer := types.NewEIP155Signer(c.Config.ChainID)
+	blocks, receipts := core.GenerateChain(c.Config, parent, ethash.NewFaker(), c.db, n, func(i int, gen *core.BlockGen) {
+		if i > 0 {
+			return
+		}
+		for _, call := range calls {
+			from := crypto.PubkeyToAddress(call.key.PublicKey)
+			var tx *types.Transaction
+			if call.to == nil {
+				tx = types.NewContractCreation(gen.TxNonce(from), call.value, call.gas, callGasPrice, call.data)
+			} else {
+				tx = types.NewTransaction(gen.TxNonce(from), *call.to, call.value, call.gas, callGasPrice, call.data)
+			}
+			signed, err := types.SignTx(tx, signer, call.key)
+			if err != nil {
+				panic(err)
+			}
+			gen.AddTx(signed)
+		}
+	})
+	for _, rs := range receipts {
+		for _, receipt := range rs {
+			c.receipts[receipt.TxHash] = receipt
+		}
+	}
+	return blocks
+}
+
+// newBlockChain creates a blockchain instance sharing the genesis of the chain,
+// which is used by an anchor to import generated blocks.
+func (c *Chain) newBlockChain() (*core.BlockChain, error) {
+	db := rawdb.NewMemoryDatabase()
+	c.genesis.MustCommit(db)
+	return core.NewBlockChain(db, nil, c.Config, ethash.NewFaker(), vm.Config{}, nil)
+}
@@ -0,0 +1,21 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package crosstest
+
+// crossDemoBin is the compiled creation code of contract/crossdemo/crossdemo.sol,
+// kept in sync with contract/crossdemo/crossDemo.bin.
+const crossDemoBin = "0x608060405234801561001057600080fd5b50600080546001600160a01b03191633179055613f25806100326000396000f3fe6080604052600436106101b75760003560e01c80639fbb74a8116100ec578063cbff72701161008a578063eed236df11610064578063eed236df1461064f578063f7478f6a1461066f578063f7fc8e3a14610682578063f91a3ba6146106a257600080fd5b8063cbff727014610541578063cf56a58d14610601578063e2ca84621461062157600080fd5b8063ab276564116100c6578063ab276564146104a1578063b4748e47146104c1578063bdf89204146104e1578063ca90e55c1461051157600080fd5b80639fbb74a814610426578063a47bd49614610461578063a5d371e11461048157600080fd5b806360606edc116101595780638da5cb5b116101335780638da5cb5b146103705780639614171f146103a85780639624005b146103d85780639a8a05921461041357600080fd5b806360606edc146103025780637a96ac3614610322578063870f1f4a1461035d57600080fd5b80631bc3b0ff116101955780631bc3b0ff146102385780632c50336e1461029f5780632f2cbeee146102b257806347feb8f0146102e257600080fd5b80630b9e77f1146101bc5780630f560cd7146101de578063121c439d14610200575b600080fd5b3480156101c857600080fd5b506101dc6101d73660046134b4565b6106c2565b005b3480156101ea57600080fd5b50485b6040519081526020015b60405180910390f35b34801561020c57600080fd5b5061022061021b3660046134fa565b610a5e565b6040516001600160401b0390911681526020016101f7565b34801561024457600080fd5b5061028a610253366004613523565b60009182526001602090815260408084206001600160a01b0393909316845260059092019052902060028101546003909101549091565b604080519283526020830191909152016101f7565b6101dc6102ad3660046135c2565b610ac9565b3480156102be57600080fd5b506101ed6102cd366004613624565b6000908152600160205260409020600c015490565b3480156102ee57600080fd5b506101ed6102fd366004613523565b610d08565b34801561030e57600080fd5b506101ed61031d36600461363d565b610d38565b34801561032e57600080fd5b506101ed61033d366004613675565b6000908152600160209081526040808320938352600f9093019052205490565b6101dc61036b366004613697565b610d95565b34801561037c57600080fd5b50600054610390906001600160a01b031681565b6040516001600160a01b0390911681526020016101f7565b3480156103b457600080fd5b506101ed6103c3366004613624565b60009081526001602052604090206002015490565b3480156103e457600080fd5b506101ed6103f3366004613675565b600090815260016020908152604080832093835260069093019052205490565b34801561041f57600080fd5b50466101ed565b34801561043257600080fd5b506101ed610441366004613675565b6000908152600160209081526040808320938352600e9093019052205490565b34801561046d57600080fd5b506101dc61047c366004613675565b611282565b34801561048d57600080fd5b506101dc61049c36600461363d565b6112c1565b3480156104ad57600080fd5b506101dc6104bc366004613675565b611465565b3480156104cd57600080fd5b506101dc6104dc36600461377e565b611557565b3480156104ed57600080fd5b506101ed6104fc366004613624565b6000908152600160205260409020600d015490565b34801561051d57600080fd5b5061053161052c3660046138b4565b6118ab565b60405190151581526020016101f7565b34801561054d57600080fd5b506105b461055c366004613624565b6001602081905260009182526040909120805491810154600282015460038301546009840154600b850154600c860154600d9096015460ff9586169694956001600160401b0394851695939094169391909116919088565b6040805198895260ff97881660208a01528801959095526001600160401b039384166060880152919092166080860152921660a084015260c083019190915260e0820152610100016101f7565b34801561060d57600080fd5b506101dc61061c3660046134b4565b611b9d565b34801561062d57600080fd5b5061064161063c366004613624565b611ff4565b6040516101f7929190613908565b34801561065b57600080fd5b506101dc61066a36600461395f565b61221b565b6101dc61067d3660046139a6565b6123eb565b34801561068e57600080fd5b506101dc61069d366004613abf565b61286d565b3480156106ae57600080fd5b506101dc6106bd366004613b17565b6129c0565b6000546001600160a01b031633146106f55760405162461bcd60e51b81526004016106ec90613b43565b60405180910390fd5b6000828152600160205260409020546107205760405162461bcd60e51b81526004016106ec90613b66565b60008151118015610732575060408151105b61076e5760405162461bcd60e51b815260206004820152600d60248201526c6e656564205f616e63686f727360981b60448201526064016106ec565b80516000838152600160205260409081902060040154909161078f91613ba7565b11156107ad5760405162461bcd60e51b81526004016106ec90613bba565b8051600083815260016020526040808220600401549192916107ce91613be0565b6107d89190613be0565b6000848152600160205260408120600301805467ffffffffffffffff191684196001600160401b0390811690941c909316929092179091555b82518160ff161015610a2457600160008581526020019081526020016000206005016000848360ff168151811061084a5761084a613bf3565b60200260200101516001600160a01b03166001600160a01b031681526020019081526020016000206000015460001461088257600080fd5b600160008581526020019081526020016000206008016000848360ff16815181106108af576108af613bf3565b60200260200101516001600160a01b03166001600160a01b03168152602001908152602001600020600001546000146108e757600080fd5b6040805160a081018252858152600086815260016020818152848320600481015460ff908116838701529585018390526060850184905260808501849052898452919052865192936005909101928791861690811061094857610948613bf3565b6020908102919091018101516001600160a01b0316825281810192909252604090810160009081208451815584840151600180830180548887015115156101000261ffff1990911660ff9485161717905560608701516002840155608090960151600390920191909155888252939092529020845160049091019185919084169081106109d7576109d7613bf3565b60209081029190910181015182546001810184556000938452919092200180546001600160a01b0319166001600160a01b0390921691909117905580610a1c81613c09565b915050610811565b506040518381527f775ea005805a6d88c3ac83f9e24f2c5d94e2ea99e7651bebeb9067e85691b3ab906020015b60405180910390a1505050565b600080671249249249249249600284901c16610a886736db6db6db6db6db600186901c1685613c28565b610a929190613c28565b9050603f610aae671fffffffffffffff600384901c1683613c4f565b6771c71c71c71c71c716610ac29190613c6f565b9392505050565b6000848152600160205260409020600c015434118015610af9575060008481526001602052604090206002015434105b610b315760405162461bcd60e51b81526020600482015260096024820152683b30b63ab29032b93960b91b60448201526064016106ec565b600084815260016020526040902054610b7a5760405162461bcd60e51b815260206004820152600b60248201526a31b430b4b724b21032b93960a91b60448201526064016106ec565b6000334860405160609290921b6bffffffffffffffffffffffff1916602083015260348201526054810186905260740160408051601f19818403018152918152815160209283012060008881526001845282812082825260060190935291205490915015610bea57610bea613ca3565b600085815260016020818152604080842085855260068101835290842093899052919052600c0154610c1c9034613be0565b81556001808201805460ff191690556004820180546001600160a01b0387166001600160a01b0319918216179091556003830180549091163317905560006005830181905587815260209190915260408120600c810154600d90910154610c839190613ba7565b6000888152600160205260409020600d0154909150811015610ca757610ca7613ca3565b60008781526001602052604090819020600d0182905551339084907fbd637e22208593c9c2833607a782012d72bba837171215294bb84c59a0a954a290610cf79089908c9034908d908c90613d09565b60405180910390a350505050505050565b60008281526001602090815260408083206001600160a01b03851684526008019091529020600201545b92915050565b6000818152600160208181526040808420878552600701909152822001546001600160a01b03808516911603610d8b57506000818152600160209081526040808320868452600701909152902054610ac2565b5060009392505050565b6000818152600160205260409020548190610dc25760405162461bcd60e51b81526004016106ec90613b66565b60008181526001602090815260408083203384526005019091529020548114610e1b5760405162461bcd60e51b815260206004820152600b60248201526a6e6f7420616e63686f727360a81b60448201526064016106ec565b60008281526001602081815260408084203385526005019091529091200154610100900460ff16610e4b57600080fd5b60008281526001602081815260408084208751855260060182528084203385526002019091529091205460ff169003610e8357600080fd5b600082815260016020908152604080832086518452600601909152902054610eaa57600080fd5b60408084015160008481526001602090815283822087518352600601905291909120600301546001600160a01b03908116911614610f155760405162461bcd60e51b8152602060048201526008602482015267333937b69032b93960c11b60448201526064016106ec565b6000828152600160209081526040808320865184526006019091529020600401546001600160a01b03161580610f7b575060608301516000838152600160209081526040808320875184526006019091529020600401546001600160a01b039081169116145b80610fb6575060608301516000838152600160209081526040808320875184526006019091529020600301546001600160a01b039081169116145b610feb5760405162461bcd60e51b81526020600482015260066024820152653a379032b93960d11b60448201526064016106ec565b600082815260016020908152604080832086518452600601909152902060050154158061103b57506020808401516000848152600183526040808220875183526006019093529190912060050154145b6110745760405162461bcd60e51b815260206004820152600a6024820152693a3c2430b9b41032b93960b11b60448201526064016106ec565b6000828152600160208181526040808420875185526006018083528185203386526002018352818520805460ff19168517905586855283835287518552909152822001805460ff16916110c683613c09565b82546101009290920a60ff8181021990931691909216919091021790555060608301516000838152600160208181526040808420885185526006810180845282862060040180546001600160a01b0319166001600160a01b039098169790971790965582890151888652938352885185529482528084206005908101939093553384529190930190925290812060030180549161116283613d47565b9091555050600082815260016020818152604080842080840154885186526006909101909252909220015460ff91821691161061127d5760608301516000838152600160209081526040808320875184526006019091528082205490516001600160a01b039093169281156108fc0292818181858888f193505050501580156111ef573d6000803e3d6000fd5b50600082815260016020818152604080842087518552600601909152808320838155918201805460ff191690556003820180546001600160a01b0319908116909155600483018054909116905560059091018290556060850151855191516001600160a01b03909116927f8820cd26b97e4df882d1d4d25c269e58fe0f1c3eb05a864665c1d9b0cfd9e59f91a35b505050565b6000546001600160a01b031633146112ac5760405162461bcd60e51b81526004016106ec90613b43565b600091825260016020526040909120600c0155565b6000546001600160a01b031633146112eb5760405162461bcd60e51b81526004016106ec90613b43565b6000838152600160205260409020600d01548111156113395760405162461bcd60e51b815260206004820152600a6024820152693932bbb0b9321032b93960b11b60448201526064016106ec565b60008381526001602090815260408083206001600160a01b0386168452600501909152902054831461139e5760405162461bcd60e51b815260206004820152600e60248201526d34b63632b3b0b61030b731b437b960911b60448201526064016106ec565b6000838152600160205260409020600d01548111156113bf576113bf613ca3565b6000838152600160205260408120600d0180548392906113e0908490613be0565b90915550506040516001600160a01b0383169082156108fc029083906000818181858888f1935050505015801561141b573d6000803e3d6000fd5b5060408051848152602081018390526001600160a01b038416917f0e57d36b360879a87dc268845a7425bf61917c325ab8c0c15dc400a77adc1263910160405180910390a2505050565b6000546001600160a01b0316331461148f5760405162461bcd60e51b81526004016106ec90613b43565b6000828152600160205260409020546114ba5760405162461bcd60e51b81526004016106ec90613b66565b806000036114f75760405162461bcd60e51b815260206004820152600a60248201526906d617856616c756520360b41b60448201526064016106ec565b6000828152600160205260409020600c015481116115425760405162461bcd60e51b8152602060048201526008602482015267746f6f206c65737360c01b60448201526064016106ec565b60009182526001602052604090912060020155565b60008181526001602052604090205481906115845760405162461bcd60e51b81526004016106ec90613b66565b600081815260016020908152604080832033845260050190915290205481146115dd5760405162461bcd60e51b815260206004820152600b60248201526a6e6f7420616e63686f727360a81b60448201526064016106ec565b8260e00151518360c0015151146116065760405162461bcd60e51b81526004016106ec90613d60565b826101000151518360c0015151146116305760405162461bcd60e51b81526004016106ec90613d60565b600082815260016020908152604080832086518452600f01909152902054156116865760405162461bcd60e51b81526020600482015260086024820152673a3c24b21032b93960c11b60448201526064016106ec565b60008281526001602081815260408084209092015486519187015192870151606088015160ff90921694611728949093929091904660008b608001518c60a001516040516020016116d8929190613d81565b60408051601f19818403018152908290526116fc9897969594939291602001613db9565b60405160208183030381529060405280519060200120848660c001518760e00151886101000151612ac9565b60ff1610156117495760405162461bcd60e51b81526004016106ec90613e1b565b600082815260016020908152604080832086518452600f81018352818420439055338452600501909152812060030180549161178483613d47565b9190505550600083608001516001600160a01b031683856000015186604001518760a001516040516024016117bc9493929190613e3f565b60408051601f198184030181529181526020820180516001600160e01b031663c6d06c6960e01b179052516117f19190613e76565b6000604051808303816000865af19150503d806000811461182e576040519150601f19603f3d011682016040523d82523d6000602084013e611833565b606091505b5050905083608001516001600160a01b031684600001517fc80b970243315fac790585284d71b08c2abc761f9228066f73fc3116b8b4f7fa8587604001518560405161189d939291909283526001600160a01b039190911660208301521515604082015260600190565b60405180910390a350505050565b600080546001600160a01b031633146118d65760405162461bcd60e51b81526004016106ec90613b43565b600085815260016020526040902054156119025760405162461bcd60e51b81526004016106ec90613b66565b6040825111156119245760405162461bcd60e51b81526004016106ec90613bba565b600085815260016020819052604080832088815560028101889055918201805460ff191660ff881617905584516060928392909161196191613be0565b60038201805467ffffffffffffffff191686196001600160401b0390811690931c90921691909117905582516119a090600483019060208601906132e9565b506000600c8201819055600d82015560098101805467ffffffffffffffff1916905581516119d790600a83019060208501906132e9565b50600b8101805460ff1916905560005b86518160ff161015611b8d57600160008b81526020019081526020016000206005016000888360ff1681518110611a2057611a20613bf3565b60200260200101516001600160a01b03166001600160a01b0316815260200190815260200160002060000154600014611a5857600080fd5b600160008b8152602001908152602001600020600401878260ff1681518110611a8357611a83613bf3565b602090810291909101810151825460018082018555600094855283852090910180546001600160a01b0319166001600160a01b03909316929092179091556040805160a0810182528e815260ff861681850181905281830184905260608201869052608082018690528f86529290935283208a51929360059091019290918b918110611b1157611b11613bf3565b6020908102919091018101516001600160a01b0316825281810192909252604090810160002083518155918301516001830180549285015115156101000261ffff1990931660ff909216919091179190911790556060820151600282015560809091015160039091015580611b8581613c09565b9150506119e7565b5060019998505050505050505050565b6000546001600160a01b03163314611bc75760405162461bcd60e51b81526004016106ec90613b43565b600082815260016020526040902054611bf25760405162461bcd60e51b81526004016106ec90613b66565b6000815111611c335760405162461bcd60e51b815260206004820152600d60248201526c6e656564205f616e63686f727360981b60448201526064016106ec565b8051600083815260016020819052604090912090810154600490910154611c5d9160ff1690613be0565b1015611c7b5760405162461bcd60e51b81526004016106ec90613bba565b805160008381526001602052604080822060040154919291611c9c91613be0565b611ca69190613ba7565b6000848152600160205260408120600301805467ffffffffffffffff191684196001600160401b0390811690941c909316929092179091555b82518160ff161015611fc357600160008581526020019081526020016000206005016000848360ff1681518110611d1857611d18613bf3565b60200260200101516001600160a01b03166001600160a01b0316815260200190815260200160002060000154600003611d5057600080fd5b6000600160008681526020019081526020016000206005016000858460ff1681518110611d7f57611d7f613bf3565b6020908102919091018101516001600160a01b031682528181019290925260409081016000908120600190810154898352938190529190206004015460ff9092169250611dcb91613be0565b8160ff161015611f4f57600085815260016020819052604090912060040180549091611df691613be0565b81548110611e0657611e06613bf3565b60009182526020808320909101548783526001909152604090912060040180546001600160a01b039092169160ff8416908110611e4557611e45613bf3565b600091825260208083209190910180546001600160a01b0319166001600160a01b0394909416939093179092558681526001909152604081206004810180548493600590930192919060ff8516908110611ea157611ea1613bf3565b6000918252602080832091909101546001600160a01b0316835282810193909352604091820181206001908101805460ff191660ff9690961695909517909455888152929091529020600401805480611efc57611efc613e92565b6001900381819060005260206000200160006101000a8154906001600160a01b0302191690559055611f4a85858460ff1681518110611f3d57611f3d613bf3565b6020026020010151612cfe565b611fb0565b6000858152600160205260409020600401805480611f6f57611f6f613e92565b6001900381819060005260206000200160006101000a8154906001600160a01b0302191690559055611fb085858460ff1681518110611f3d57611f3d613bf3565b5080611fbb81613c09565b915050611cdf565b506040518381527ff6b9271d4e28597a384466c107af5af249a32dc61f09d9a079e1367f39a7595390602001610a51565b6060600080805b60008581526001602052604090206004015460ff8216101561209b5760008581526001602052604081206004810180546005909201929160ff851690811061204557612045613bf3565b60009182526020808320909101546001600160a01b0316835282019290925260400190206001015460ff6101009091041615612089578161208581613c09565b9250505b8061209381613c09565b915050611ffb565b508060ff166001600160401b038111156120b7576120b7613363565b6040519080825280602002602001820160405280156120e0578160200160208202803683370190505b5092506000805b60008681526001602052604090206004015460ff821610156121f85760008681526001602052604081206004810180546005909201929160ff851690811061213157612131613bf3565b60009182526020808320909101546001600160a01b0316835282019290925260400190206001015460ff61010090910416156121e6576000868152600160205260409020600401805460ff831690811061218d5761218d613bf3565b9060005260206000200160009054906101000a90046001600160a01b0316858360ff16815181106121c0576121c0613bf3565b6001600160a01b0390921660209283029190910190910152816121e281613c09565b9250505b806121f081613c09565b9150506120e7565b50505060009283525060016020819052604090922090910154909160ff90911690565b6000546001600160a01b031633146122455760405162461bcd60e51b81526004016106ec90613b43565b80612384576000805b60008581526001602052604090206004015460ff821610156122ee5760008581526001602052604081206004810180546005909201929160ff851690811061229857612298613bf3565b60009182526020808320909101546001600160a01b0316835282019290925260400190206001015460ff61010090910416156122dc57816122d881613c09565b9250505b806122e681613c09565b91505061224e565b506000848152600160208190526040909120015460ff9081169082161161231457600080fd5b60008481526001602081815260408084206001600160a01b0388168552600501825292839020909101805461ff0019166101008615150217905590518581527f21c3c2e2611672924df81517929d90190258e543f08df36d2b06c88437f08cce910160405180910390a150505050565b60008381526001602081815260408084206001600160a01b0387168552600501825292839020909101805461ff0019166101008515150217905590518481527f21c3c2e2611672924df81517929d90190258e543f08df36d2b06c88437f08cce9101610a51565b8161012001515182610100015151146124165760405162461bcd60e51b81526004016106ec90613d60565b8161014001515182610100015151146124415760405162461bcd60e51b81526004016106ec90613d60565b60808201516001600160a01b03161580612467575060808201516001600160a01b031633145b8061247e575060608201516001600160a01b031633145b6124b35760405162461bcd60e51b81526020600482015260066024820152653a379032b93960d11b60448201526064016106ec565b6000818152600160209081526040808320858301518452600701909152902054158061251157506060820151600082815260016020818152604080842082880151855260070190915290912001546001600160a01b03908116911614155b6125485760405162461bcd60e51b81526020600482015260086024820152673a3c24b21032b93960c11b60448201526064016106ec565b60608201516001600160a01b03163303612690576000818152600160208181526040928390209091015484518583015186850151606088015160a089015160c08a015160e08b0151985160ff909716986125d8986125aa984693929101613db9565b6040516020818303038152906040528051906020012083856101000151866101200151876101400151613031565b60ff1610156125f95760405162461bcd60e51b81526004016106ec90613e1b565b604080518082018252835181526060840180516001600160a01b03908116602080850191825260008781526001808352878220838b01518352600701909252868120955186559151940180549483166001600160a01b031990951694909417909355905192519216913480156108fc0292909190818181858888f1935050505015801561268a573d6000803e3d6000fd5b50612800565b8160c001513410156126d05760405162461bcd60e51b8152602060048201526009602482015268383934b1b29032b93960b91b60448201526064016106ec565b6000818152600160208181526040928390209091015484518583015186850151606088015160a089015160c08a015160e08b0151985160ff9097169861274c9861271e984693929101613db9565b6040516020818303038152906040528051906020012083856101000151866101200151876101400151612ac9565b60ff16101561276d5760405162461bcd60e51b81526004016106ec90613e1b565b604080518082018252835181526060840180516001600160a01b03908116602080850191825260008781526001808352878220838b01518352600701909252868120955186559151940180549483166001600160a01b031990951694909417909355905192519216913480156108fc0292909190818181858888f193505050501580156127fe573d6000803e3d6000fd5b505b60208201516060830151835160c08501516040513394937f3b153bbbfb2dd114d43a744204a99dc8e17db56d0d94c2ba8b82d0fa97ac6ec093612861938884526001600160a01b039290921660208401526040830152606082015260800190565b60405180910390a35050565b6000838152600160205260409020546128b65760405162461bcd60e51b815260206004820152600b60248201526a31b430b4b724b21032b93960a91b60448201526064016106ec565b6001600160a01b0382166128f95760405162461bcd60e51b815260206004820152600a6024820152693a30b933b2ba1032b93960b11b60448201526064016106ec565b6000334860405160609290921b6bffffffffffffffffffffffff1916602083015260348201526054810185905260740160408051601f198184030181529181528151602092830120600087815260018452828120828252600e019093529120549091501561296957612969613ca3565b6000848152600160209081526040808320848452600e019091529081902043905551339082907f4fc91c7049894280b09a12f12cd4fce78f4e89ad56ce08754927b560d15f4fe29061189d90879089908890613ea8565b6000546001600160a01b031633146129ea5760405162461bcd60e51b81526004016106ec90613b43565b600082815260016020526040902054612a155760405162461bcd60e51b81526004016106ec90613b66565b8060ff16600003612a525760405162461bcd60e51b81526020600482015260076024820152660636f756e7420360cc1b60448201526064016106ec565b60008281526001602052604090206004015460ff82161115612aa25760405162461bcd60e51b815260206004820152600960248201526831b7bab73a1032b93960b91b60448201526064016106ec565b6000918252600160208190526040909220909101805460ff191660ff909216919091179055565b6000806001815b8651811015612ce857612ae4886002613ed8565b878281518110612af657612af6613bf3565b60200260200101818151612b0a9190613be0565b9052508651600890889083908110612b2457612b24613bf3565b60200260200101818151612b389190613be0565b91508181525050600060018a898481518110612b5657612b56613bf3565b6020026020010151898581518110612b7057612b70613bf3565b6020026020010151898681518110612b8a57612b8a613bf3565b602002602001015160405160008152602001604052604051612bc8949392919093845260ff9290921660208401526040830152606082015260800190565b6020604051602081039080840390855afa158015612bea573d6000803e3d6000fd5b505060408051601f19015160008c8152600160209081528382206001600160a01b0384168352600501905291909120549092508a1490508015612c59575060008981526001602081815260408084206001600160a01b03861685526005019091529091200154610100900460ff165b15612cd55760008981526001602090815260408083206001600160a01b03851684526005019091528120600201805491612c9283613d47565b909155505060008981526001602081815260408084206001600160a01b038616855260050190915290912001546001600160401b03841660ff9091161b93909317925b5080612ce081613d47565b915050612ad0565b50612cf282610a5e565b98975050505050505050565b60008281526001602081815260408084206001600160a01b0386168552600581018352818520858155938401805461ffff191690556002840185905560039093018490556008909201905290205415612d5657600080fd5b60008281526001602052604090819020600a01541015612e75576000828152600160208190526040808320600a0154612d8e91613be0565b612d989190613be0565b60008481526001602081815260408084206009810180546001600160401b039819891690971c90971667ffffffffffffffff1990961695909517909555845160a081018652878152600a8501805460ff90811683850190815283890187815260608501888152608086018981526001600160a01b038d16808b526008909b0188529a89209551865591518588018054925115156101000261ffff1990931691909416171790915551600283015595516003909101558181528454918201855593825292902090910180546001600160a01b03191690911790555050565b6000828152600160205260408120600b810154600a8201805460089093019392909160ff16908110612ea957612ea9613bf3565b60009182526020808320909101546001600160a01b0316835282810193909352604091820181208181556001818101805461ffff191690556002820183905560039091018290558582529092529020600b810154600a9091018054839260ff16908110612f1857612f18613bf3565b6000918252602080832090910180546001600160a01b039485166001600160a01b03199091161790556040805160a0810182528681528684526001808452828520600b8101805460ff908116858801908152858701898152606087018a8152608088018b81529b8d168b526008909501895296892095518655518585018054975115156101000261ffff1990981691831691909117969096179095559051600284015595516003909201919091558684529091528254169190612fda83613c09565b82546101009290920a60ff81810219909316918316021790915560008481526001602052604090819020600b01549091169003905061302d576000828152600160205260409020600b01805460ff191690555b5050565b60008060018181815b88518110156132be5761304e8a6002613ed8565b89828151811061306057613060613bf3565b602002602001018181516130749190613be0565b90525088516008908a908390811061308e5761308e613bf3565b602002602001018181516130a29190613be0565b91508181525050600060018c8b84815181106130c0576130c0613bf3565b60200260200101518b85815181106130da576130da613bf3565b60200260200101518b86815181106130f4576130f4613bf3565b602002602001015160405160008152602001604052604051613132949392919093845260ff9290921660208401526040830152606082015260800190565b6020604051602081039080840390855afa158015613154573d6000803e3d6000fd5b505060408051601f19015160008e8152600160209081528382206001600160a01b0384168352600501905291909120549092508c900390506132075760008b81526001602090815260408083206001600160a01b038516845260050190915281206002018054916131c483613d47565b909155505060008b81526001602081815260408084206001600160a01b038616855260050190915290912001546001600160401b03861660ff9091161b95909517945b60008b81526001602090815260408083206001600160a01b03851684526008019091529020548b90036132ab5760008b81526001602090815260408083206001600160a01b0385168452600801909152812060020180549161326883613d47565b909155505060008b81526001602081815260408084206001600160a01b038616855260080190915290912001546001600160401b03841660ff9091161b93909317925b50806132b681613d47565b91505061303a565b506132c882610a5e565b6132d185610a5e565b6132db9190613c4f565b9a9950505050505050505050565b82805482825590600052602060002090810192821561333e579160200282015b8281111561333e57825182546001600160a01b0319166001600160a01b03909116178255602090920191600190910190613309565b5061334a92915061334e565b5090565b5b8082111561334a576000815560010161334f565b634e487b7160e01b600052604160045260246000fd5b60405161012081016001600160401b038111828210171561339c5761339c613363565b60405290565b60405161016081016001600160401b038111828210171561339c5761339c613363565b604051601f8201601f191681016001600160401b03811182821017156133ed576133ed613363565b604052919050565b60006001600160401b0382111561340e5761340e613363565b5060051b60200190565b6001600160a01b038116811461342d57600080fd5b50565b803561343b81613418565b919050565b600082601f83011261345157600080fd5b81356020613466613461836133f5565b6133c5565b82815260059290921b8401810191818101908684111561348557600080fd5b8286015b848110156134a957803561349c81613418565b8352918301918301613489565b509695505050505050565b600080604083850312156134c757600080fd5b8235915060208301356001600160401b038111156134e457600080fd5b6134f085828601613440565b9150509250929050565b60006020828403121561350c57600080fd5b81356001600160401b0381168114610ac257600080fd5b6000806040838503121561353657600080fd5b82359150602083013561354881613418565b809150509250929050565b600082601f83011261356457600080fd5b81356001600160401b0381111561357d5761357d613363565b613590601f8201601f19166020016133c5565b8181528460208386010111156135a557600080fd5b816020850160208301376000918101602001919091529392505050565b600080600080608085870312156135d857600080fd5b843593506020850135925060408501356135f181613418565b915060608501356001600160401b0381111561360c57600080fd5b61361887828801613553565b91505092959194509250565b60006020828403121561363657600080fd5b5035919050565b60008060006060848603121561365257600080fd5b83359250602084013561366481613418565b929592945050506040919091013590565b6000806040838503121561368857600080fd5b50508035926020909101359150565b60008082840360a08112156136ab57600080fd5b60808112156136b957600080fd5b50604051608081018181106001600160401b03821117156136dc576136dc613363565b8060405250833581526020840135602082015260408401356136fd81613418565b6040820152606084013561371081613418565b6060820152946080939093013593505050565b600082601f83011261373457600080fd5b81356020613744613461836133f5565b82815260059290921b8401810191818101908684111561376357600080fd5b8286015b848110156134a95780358352918301918301613767565b6000806040838503121561379157600080fd5b82356001600160401b03808211156137a857600080fd5b9084019061012082870312156137bd57600080fd5b6137c5613379565b82358152602083013560208201526137df60408401613430565b6040820152606083013560608201526137fa60808401613430565b608082015260a08301358281111561381157600080fd5b61381d88828601613553565b60a08301525060c08301358281111561383557600080fd5b61384188828601613723565b60c08301525060e08301358281111561385957600080fd5b61386588828601613723565b60e083015250610100808401358381111561387f57600080fd5b61388b89828701613723565b91830191909152509660209590950135955050505050565b803560ff8116811461343b57600080fd5b600080600080608085870312156138ca57600080fd5b84359350602085013592506138e1604086016138a3565b915060608501356001600160401b038111156138fc57600080fd5b61361887828801613440565b604080825283519082018190526000906020906060840190828701845b8281101561394a5781516001600160a01b031684529284019290840190600101613925565b50505060ff9490941692019190915250919050565b60008060006060848603121561397457600080fd5b83359250602084013561398681613418565b91506040840135801515811461399b57600080fd5b809150509250925092565b600080604083850312156139b957600080fd5b82356001600160401b03808211156139d057600080fd5b9084019061016082870312156139e557600080fd5b6139ed6133a2565b823581526020830135602082015260408301356040820152613a1160608401613430565b6060820152613a2260808401613430565b608082015260a083013560a082015260c083013560c082015260e083013582811115613a4d57600080fd5b613a5988828601613553565b60e0830152506101008084013583811115613a7357600080fd5b613a7f89828701613723565b8284015250506101208084013583811115613a9957600080fd5b613aa589828701613723565b828401525050610140808401358381111561387f57600080fd5b600080600060608486031215613ad457600080fd5b833592506020840135613ae681613418565b915060408401356001600160401b03811115613b0157600080fd5b613b0d86828701613553565b9150509250925092565b60008060408385031215613b2a57600080fd5b82359150613b3a602084016138a3565b90509250929050565b6020808252600990820152683737ba1037bbb732b960b91b604082015260600190565b6020808252601190820152703932b6b7ba32a1b430b4b724b21032b93960791b604082015260600190565b634e487b7160e01b600052601160045260246000fd5b80820180821115610d3257610d32613b91565b6020808252600c908201526b2fb0b731b437b9399032b93960a11b604082015260600190565b81810381811115610d3257610d32613b91565b634e487b7160e01b600052603260045260246000fd5b600060ff821660ff8103613c1f57613c1f613b91565b60010192915050565b6001600160401b03828116828216039080821115613c4857613c48613b91565b5092915050565b6001600160401b03818116838216019080821115613c4857613c48613b91565b60006001600160401b0380841680613c9757634e487b7160e01b600052601260045260246000fd5b92169190910692915050565b634e487b7160e01b600052600160045260246000fd5b60005b83811015613cd4578181015183820152602001613cbc565b50506000910152565b60008151808452613cf5816020860160208601613cb9565b601f01601f19169290920160200192915050565b60018060a01b038616815284602082015283604082015282606082015260a060808201526000613d3c60a0830184613cdd565b979650505050505050565b600060018201613d5957613d59613b91565b5060010190565b6020808252600790820152663b39399032b93960c91b604082015260600190565b6bffffffffffffffffffffffff198360601b16815260008251613dab816014850160208701613cb9565b919091016014019392505050565b8881528760208201528660408201526bffffffffffffffffffffffff198660601b1660608201528460748201528360948201528260b482015260008251613e078160d4850160208701613cb9565b9190910160d4019998505050505050505050565b6020808252600a908201526939b4b3b71032b93937b960b11b604082015260600190565b84815283602082015260018060a01b0383166040820152608060608201526000613e6c6080830184613cdd565b9695505050505050565b60008251613e88818460208701613cb9565b9190910192915050565b634e487b7160e01b600052603160045260246000fd5b60018060a01b0384168152826020820152606060408201526000613ecf6060830184613cdd565b95945050505050565b8082028115828204841417610d3257610d32613b9156fea2646970667358221220ad2aaeb26eedd4e1dcd45023f34a102743303c1f4f096ad1487bb32ee76321ae64736f6c63430008150033"
@@ -0,0 +1,513 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package crosstest boots several cross-chain anchors on simulated main and sub chains,
+// connected through p2p/simulations, to drive maker->taker->finish flows end to end.
+package crosstest
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/simplechain-org/go-simplechain/accounts/abi"
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/common/hexutil"
+	"github.com/simplechain-org/go-simplechain/crypto"
+	"github.com/simplechain-org/go-simplechain/node"
+	"github.com/simplechain-org/go-simplechain/p2p/enode"
+	"github.com/simplechain-org/go-simplechain/p2p/simulations"
+	"github.com/simplechain-org/go-simplechain/p2p/simulations/adapters"
+	"github.com/simplechain-org/go-simplechain/params"
+
+	cc "github.com/simplechain-org/go-simplechain/cross/core"
+	"github.com/simplechain-org/go-simplechain/cross/trigger/simpletrigger"
+)
+
+const (
+	serviceName = "cross"
+	peerTimeout = 10 * time.Second
+)
+
+var (
+	errUnknownAnchor = errors.New("unknown anchor")
+	errNotSigned     = errors.New("ctx is not signed by enough anchors")
+	errNotDelivered  = errors.New("message is not delivered")
+)
+
+// Config defines the simulated networks
+type Config struct {
+	Anchors           int    // number of anchors
+	RequireSignatures uint8  // signConfirmCount registered in contracts
+	Users             int    // number of funded user accounts
+	MainChainID       uint64 // chain id of the main chain
+	SubChainID        uint64 // chain id of the sub chain
+}
+
+var DefaultConfig = Config{
+	Anchors:           3,
+	RequireSignatures: 2,
+	Users:             2,
+	MainChainID:       1024,
+	SubChainID:        1025,
+}
+
+// Harness drives the simulated chains and the anchor network
+type Harness struct {
+	Main, Sub *Chain
+	Anchors   []*Anchor
+	Users     []*ecdsa.PrivateKey
+
+	config  Config
+	abi     abi.ABI
+	dir     string
+	owner   *ecdsa.PrivateKey
+	network *simulations.Network
+	nodes   map[enode.ID]*Anchor
+	ids     []enode.ID
+}
+
+// New creates a harness, deploys and registers the cross contract on both chains.
+func New(config Config) (_ *Harness, err error) {
+	data, err := hexutil.Decode(params.CrossDemoAbi)
+	if err != nil {
+		return nil, err
+	}
+	crossABI, err := abi.JSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	dir, err := ioutil.TempDir("", "crosstest")
+	if err != nil {
+		return nil, err
+	}
+	h := &Harness{
+		config: config,
+		abi:    crossABI,
+		dir:    dir,
+		nodes:  make(map[enode.ID]*Anchor),
+	}
+	defer func() {
+		if err != nil {
+			h.Close()
+		}
+	}()
+	h.owner, _ = crypto.GenerateKey()
+
+	var (
+		funded  []common.Address
+		keys    []*ecdsa.PrivateKey
+		anchors []common.Address
+	)
+	for i := 0; i < config.Anchors; i++ {
+		key, _ := crypto.GenerateKey()
+		keys = append(keys, key)
+		anchors = append(anchors, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	for i := 0; i < config.Users; i++ {
+		key, _ := crypto.GenerateKey()
+		h.Users = append(h.Users, key)
+		funded = append(funded, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	funded = append(funded, anchors...)
+
+	h.Main = newChain(config.MainChainID, h.owner, funded)
+	h.Sub = newChain(config.SubChainID, h.owner, funded)
+	if err := h.register(h.Main, h.Sub, anchors); err != nil {
+		return nil, err
+	}
+	if err := h.register(h.Sub, h.Main, anchors); err != nil {
+		return nil, err
+	}
+
+	h.network = simulations.NewNetwork(adapters.NewSimAdapter(adapters.Services{
+		serviceName: func(ctx *adapters.ServiceContext) (node.Service, error) {
+			anchor, ok := h.nodes[ctx.Config.ID]
+			if !ok {
+				return nil, errUnknownAnchor
+			}
+			return anchor.newService(anchors, h.abi)
+		},
+	}), &simulations.NetworkConfig{DefaultService: serviceName})
+
+	for i, key := range keys {
+		conf := adapters.RandomNodeConfig()
+		conf.Services = []string{serviceName}
+		conf.DataDir = filepath.Join(dir, fmt.Sprintf("anchor%d", i))
+		if err := os.MkdirAll(conf.DataDir, 0700); err != nil {
+			return nil, err
+		}
+		anchor, err := newAnchor(i, key, conf.DataDir, h.Main, h.Sub)
+		if err != nil {
+			return nil, err
+		}
+		h.Anchors = append(h.Anchors, anchor)
+		if _, err := h.network.NewNodeWithConfig(conf); err != nil {
+			return nil, err
+		}
+		h.nodes[conf.ID] = anchor
+		h.ids = append(h.ids, conf.ID)
+	}
+	return h, nil
+}
+
+func (h *Harness) register(chain, remote *Chain, anchors []common.Address) error {
+	data, err := h.abi.Pack("chainRegister", remote.ChainID(), new(big.Int).Mul(big.NewInt(1e6), big.NewInt(params.Ether)),
+		h.config.RequireSignatures, anchors)
+	if err != nil {
+		return err
+	}
+	chain.Call(h.owner, new(big.Int), data)
+	chain.Mine(1)
+	return nil
+}
+
+// Start boots every anchor and connects them with each other
+func (h *Harness) Start() error {
+	for i := range h.Anchors {
+		if err := h.start(i); err != nil {
+			return err
+		}
+	}
+	if err := h.network.ConnectNodesFull(h.ids); err != nil {
+		return err
+	}
+	return h.waitPeers(peerTimeout)
+}
+
+// Close stops the anchor network and removes the anchor data
+func (h *Harness) Close() {
+	if h.network != nil {
+		h.network.Shutdown()
+	}
+	for _, anchor := range h.Anchors {
+		anchor.close()
+	}
+	os.RemoveAll(h.dir)
+}
+
+// Crash stops the i-th anchor, the anchor wouldn't import any block until restart
+func (h *Harness) Crash(i int) error {
+	if i >= len(h.Anchors) {
+		return errUnknownAnchor
+	}
+	// Drop the connections first, the remote anchors would keep the stale peers
+	// until the pipe write times out and refuse the restarted anchor meanwhile
+	for j, other := range h.Anchors {
+		if j != i && other.Running() {
+			if conn := h.network.GetConn(h.ids[i], h.ids[j]); conn != nil && conn.Up {
+				if err := h.network.Disconnect(h.ids[i], h.ids[j]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	h.Anchors[i].setRunning(false)
+	return h.network.Stop(h.ids[i])
+}
+
+// Restart starts the i-th anchor, imports missed blocks and reconnects it to the running anchors
+func (h *Harness) Restart(i int) error {
+	if i >= len(h.Anchors) {
+		return errUnknownAnchor
+	}
+	if err := h.start(i); err != nil {
+		return err
+	}
+	// Dial from the restarted anchor, the others still have it in their dial history
+	client, err := h.network.GetNode(h.ids[i]).Client()
+	if err != nil {
+		return err
+	}
+	for j, other := range h.Anchors {
+		if j != i && other.Running() {
+			if err := client.Call(nil, "admin_addPeer", string(h.network.GetNode(h.ids[j]).Addr())); err != nil {
+				return err
+			}
+		}
+	}
+	return h.waitPeers(peerTimeout)
+}
+
+// waitPeers waits until every running anchor finishes the cross handshake with the others,
+// so that the signatures broadcast later reach all of them.
+func (h *Harness) waitPeers(timeout time.Duration) error {
+	return waitFor(timeout, func() error {
+		var running []int
+		for i, anchor := range h.Anchors {
+			if anchor.Running() {
+				running = append(running, i)
+			}
+		}
+		for _, i := range running {
+			node, ok := h.network.GetNode(h.ids[i]).Node.(*adapters.SimNode)
+			if !ok {
+				return errUnknownAnchor
+			}
+			var handshaked int
+			for _, peer := range node.Server().PeersInfo() {
+				if _, ok := peer.Protocols[serviceName].(string); !ok && peer.Protocols[serviceName] != nil {
+					handshaked++
+				}
+			}
+			if handshaked < len(running)-1 {
+				return fmt.Errorf("anchor %d: cross peers %d, want %d", i, handshaked, len(running)-1)
+			}
+		}
+		return nil
+	})
+}
+
+func (h *Harness) start(i int) error {
+	if err := h.network.Start(h.ids[i]); err != nil {
+		return err
+	}
+	anchor := h.Anchors[i]
+	anchor.setRunning(true)
+	return anchor.sync()
+}
+
+// Mine generates n blocks on the chain and delivers them to running anchors
+func (h *Harness) Mine(chain *Chain, n int) error {
+	chain.Mine(n)
+	return h.deliver()
+}
+
+// Reorg replaces the last depth blocks of the chain by n empty blocks, and
+// delivers the fork to running anchors
+func (h *Harness) Reorg(chain *Chain, depth, n int) error {
+	if _, err := chain.Fork(depth, n); err != nil {
+		return err
+	}
+	return h.deliver()
+}
+
+func (h *Harness) deliver() error {
+	for _, anchor := range h.Anchors {
+		if !anchor.Running() {
+			continue
+		}
+		if err := anchor.sync(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConfirmedDepth is the number of blocks to mine until logs are confirmed by anchors
+func (h *Harness) ConfirmedDepth() int {
+	return simpletrigger.DefaultConfirmDepth
+}
+
+// Maker sends a makerStart transaction on the chain and mines it, returns the ctxID
+func (h *Harness) Maker(chain *Chain, user *ecdsa.PrivateKey, value, destValue *big.Int) (common.Hash, error) {
+	remote := h.remote(chain)
+	data, err := h.abi.Pack("makerStart", remote.ChainID(), destValue, common.Address{}, []byte{})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	chain.Call(user, value, data)
+	if err := h.Mine(chain, 1); err != nil {
+		return common.Hash{}, err
+	}
+	for _, tx := range chain.CurrentBlock().Transactions() {
+		receipt, err := chain.Receipt(tx.Hash())
+		if err != nil {
+			return common.Hash{}, err
+		}
+		for _, l := range receipt.Logs {
+			if len(l.Topics) > 2 && l.Topics[0] == params.MakerTopic &&
+				common.BytesToAddress(l.Topics[2].Bytes()) == crypto.PubkeyToAddress(user.PublicKey) {
+				return l.Topics[1], nil
+			}
+		}
+	}
+	return common.Hash{}, errors.New("maker transaction failed")
+}
+
+// Message sends a messageStart transaction to the target on the remote chain and mines it, returns the ctxID
+func (h *Harness) Message(chain *Chain, user *ecdsa.PrivateKey, target common.Address, payload []byte) (common.Hash, error) {
+	remote := h.remote(chain)
+	data, err := h.abi.Pack("messageStart", remote.ChainID(), target, payload)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	chain.Call(user, new(big.Int), data)
+	if err := h.Mine(chain, 1); err != nil {
+		return common.Hash{}, err
+	}
+	for _, tx := range chain.CurrentBlock().Transactions() {
+		receipt, err := chain.Receipt(tx.Hash())
+		if err != nil {
+			return common.Hash{}, err
+		}
+		for _, l := range receipt.Logs {
+			if len(l.Topics) > 2 && l.Topics[0] == params.MessageTopic &&
+				common.BytesToAddress(l.Topics[2].Bytes()) == crypto.PubkeyToAddress(user.PublicKey) {
+				return l.Topics[1], nil
+			}
+		}
+	}
+	return common.Hash{}, errors.New("message transaction failed")
+}
+
+// Delivered looks up the messageDeliver of the ctx in the head block of the chain,
+// returns the call result of the target contract
+func (h *Harness) Delivered(chain *Chain, ctxID common.Hash) (bool, error) {
+	for _, tx := range chain.CurrentBlock().Transactions() {
+		receipt, err := chain.Receipt(tx.Hash())
+		if err != nil {
+			return false, err
+		}
+		for _, l := range receipt.Logs {
+			if len(l.Topics) > 1 && l.Topics[0] == params.DeliveredTopic && l.Topics[1] == ctxID &&
+				len(l.Data) >= common.HashLength*3 {
+				return new(big.Int).SetBytes(l.Data[common.HashLength*2:common.HashLength*3]).Sign() != 0, nil
+			}
+		}
+	}
+	return false, errNotDelivered
+}
+
+type order struct {
+	Value            *big.Int
+	TxId             common.Hash
+	TxHash           common.Hash
+	From             common.Address
+	To               common.Address
+	BlockHash        common.Hash
+	DestinationValue *big.Int
+	Data             []byte
+	V                []*big.Int
+	R                [][32]byte
+	S                [][32]byte
+}
+
+// Taker takes the ctx made on the chain by sending a taker transaction on the remote chain,
+// the signatures are fetched from any running anchor, the transaction is mined in a new block.
+func (h *Harness) Taker(chain *Chain, user *ecdsa.PrivateKey, ctxID common.Hash) error {
+	var ord *order
+	for _, anchor := range h.Anchors {
+		if !anchor.Running() {
+			continue
+		}
+		tx := anchor.Get(chain, ctxID)
+		if tx == nil || len(tx.V) < int(h.config.RequireSignatures) {
+			continue
+		}
+		ord = &order{
+			Value:            tx.Value.ToInt(),
+			TxId:             tx.CTxId,
+			TxHash:           tx.TxHash,
+			From:             tx.From,
+			To:               tx.To,
+			BlockHash:        tx.BlockHash,
+			DestinationValue: tx.DestinationValue.ToInt(),
+			Data:             tx.Input,
+		}
+		for i := range tx.V {
+			ord.V = append(ord.V, tx.V[i].ToInt())
+			ord.R = append(ord.R, common.BigToHash(tx.R[i].ToInt()))
+			ord.S = append(ord.S, common.BigToHash(tx.S[i].ToInt()))
+		}
+		break
+	}
+	if ord == nil {
+		return errNotSigned
+	}
+	data, err := h.abi.Pack("taker", *ord, chain.ChainID())
+	if err != nil {
+		return err
+	}
+	remote := h.remote(chain)
+	remote.Call(user, ord.DestinationValue, data)
+	return h.Mine(remote, 1)
+}
+
+// WaitPending waits until n calls are submitted to the chain
+func (h *Harness) WaitPending(chain *Chain, n int, timeout time.Duration) error {
+	return waitFor(timeout, func() error {
+		if pending := chain.Pending(); pending < n {
+			return fmt.Errorf("pending calls %d, want %d", pending, n)
+		}
+		return nil
+	})
+}
+
+// WaitStatus waits until every running anchor stores the ctx made on the chain with the status
+func (h *Harness) WaitStatus(chain *Chain, ctxID common.Hash, status cc.CtxStatus, timeout time.Duration) error {
+	return waitFor(timeout, func() error {
+		for _, anchor := range h.Anchors {
+			if !anchor.Running() {
+				continue
+			}
+			if current, ok := anchor.Status(chain, ctxID); !ok || current != status {
+				return fmt.Errorf("anchor %d: ctx %s status is %v(exist:%v), want %v",
+					anchor.Index, ctxID.String(), current, ok, status)
+			}
+		}
+		return nil
+	})
+}
+
+// CheckConsistency verifies that every running anchor stores the same status for the ctxs made on the chain
+func (h *Harness) CheckConsistency(chain *Chain, ctxIDs ...common.Hash) error {
+	for _, id := range ctxIDs {
+		var (
+			expect cc.CtxStatus
+			first  = true
+		)
+		for _, anchor := range h.Anchors {
+			if !anchor.Running() {
+				continue
+			}
+			status, ok := anchor.Status(chain, id)
+			if !ok {
+				return fmt.Errorf("anchor %d: ctx %s not found", anchor.Index, id.String())
+			}
+			if first {
+				expect, first = status, false
+			} else if status != expect {
+				return fmt.Errorf("anchor %d: ctx %s status is %v, others %v", anchor.Index, id.String(), status, expect)
+			}
+		}
+	}
+	return nil
+}
+
+func (h *Harness) remote(chain *Chain) *Chain {
+	if chain == h.Main {
+		return h.Sub
+	}
+	return h.Main
+}
+
+func waitFor(timeout time.Duration, check func() error) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := check()
+		if err == nil || time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package crosstest
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/crypto"
+	"github.com/simplechain-org/go-simplechain/params"
+
+	cc "github.com/simplechain-org/go-simplechain/cross/core"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const waitTimeout = 10 * time.Second
+
+var (
+	makerValue = big.NewInt(params.Ether)
+	takerValue = big.NewInt(params.Ether / 2)
+)
+
+func newHarnessTester(t *testing.T) *Harness {
+	h, err := New(DefaultConfig)
+	require.NoError(t, err)
+	require.NoError(t, h.Start())
+	return h
+}
+
+// makeSigned makes a ctx on the main chain and waits until every anchor stores it as Waiting
+func makeSigned(t *testing.T, h *Harness) common.Hash {
+	ctxID, err := h.Maker(h.Main, h.Users[0], makerValue, takerValue)
+	require.NoError(t, err)
+	require.NoError(t, h.Mine(h.Main, h.ConfirmedDepth()))
+	require.NoError(t, h.WaitStatus(h.Main, ctxID, cc.CtxStatusWaiting, waitTimeout))
+	return ctxID
+}
+
+// finish confirms the taker on the sub chain, and mines the makerFinish on the main chain
+func finish(t *testing.T, h *Harness, ctxID common.Hash) {
+	require.NoError(t, h.WaitStatus(h.Main, ctxID, cc.CtxStatusExecuting, waitTimeout))
+	require.NoError(t, h.Mine(h.Sub, h.ConfirmedDepth()))
+	require.NoError(t, h.WaitStatus(h.Main, ctxID, cc.CtxStatusExecuted, waitTimeout))
+
+	// makerFinish is submitted by every anchor, contract accepts enough signatures
+	require.NoError(t, h.WaitPending(h.Main, int(h.config.RequireSignatures), waitTimeout))
+	require.NoError(t, h.Mine(h.Main, 1))
+	require.NoError(t, h.WaitStatus(h.Main, ctxID, cc.CtxStatusFinishing, waitTimeout))
+	require.NoError(t, h.Mine(h.Main, h.ConfirmedDepth()))
+	require.NoError(t, h.WaitStatus(h.Main, ctxID, cc.CtxStatusFinished, waitTimeout))
+}
+
+func TestHarness_MakerTakerFinish(t *testing.T) {
+	h := newHarnessTester(t)
+	defer h.Close()
+
+	ctxID := makeSigned(t, h)
+	require.NoError(t, h.Taker(h.Main, h.Users[1], ctxID))
+	finish(t, h, ctxID)
+	assert.NoError(t, h.CheckConsistency(h.Main, ctxID))
+}
+
+func TestHarness_TakerReorg(t *testing.T) {
+	h := newHarnessTester(t)
+	defer h.Close()
+
+	ctxID := makeSigned(t, h)
+	require.NoError(t, h.Taker(h.Main, h.Users[1], ctxID))
+	require.NoError(t, h.WaitStatus(h.Main, ctxID, cc.CtxStatusExecuting, waitTimeout))
+
+	// the unconfirmed taker is dropped by a reorg on the sub chain
+	require.NoError(t, h.Reorg(h.Sub, 1, 2))
+	require.NoError(t, h.WaitStatus(h.Main, ctxID, cc.CtxStatusWaiting, waitTimeout))
+
+	// the ctx could be taken again
+	require.NoError(t, h.Taker(h.Main, h.Users[1], ctxID))
+	finish(t, h, ctxID)
+	assert.NoError(t, h.CheckConsistency(h.Main, ctxID))
+}
+
+func TestHarness_AnchorCrash(t *testing.T) {
+	h := newHarnessTester(t)
+	defer h.Close()
+
+	ctxID := makeSigned(t, h)
+
+	// the last anchor misses the taker and the finish
+	require.NoError(t, h.Crash(len(h.Anchors)-1))
+	require.NoError(t, h.Taker(h.Main, h.Users[1], ctxID))
+	finish(t, h, ctxID)
+
+	require.NoError(t, h.Restart(len(h.Anchors)-1))
+	require.NoError(t, h.Mine(h.Main, 1))
+	require.NoError(t, h.Mine(h.Sub, 1))
+	require.NoError(t, h.WaitStatus(h.Main, ctxID, cc.CtxStatusFinished, waitTimeout))
+	assert.NoError(t, h.CheckConsistency(h.Main, ctxID))
+}
+
+func TestHarness_MessageDelivery(t *testing.T) {
+	h := newHarnessTester(t)
+	defer h.Close()
+
+	target := crypto.PubkeyToAddress(h.Users[1].PublicKey)
+	ctxID, err := h.Message(h.Main, h.Users[0], target, []byte("ping"))
+	require.NoError(t, err)
+	require.NoError(t, h.Mine(h.Main, h.ConfirmedDepth()))
+	require.NoError(t, h.WaitStatus(h.Main, ctxID, cc.CtxStatusWaiting, waitTimeout))
+
+	// messageDeliver is submitted by every anchor, contract accepts the first one
+	require.NoError(t, h.WaitPending(h.Sub, int(h.config.RequireSignatures), waitTimeout))
+	require.NoError(t, h.Mine(h.Sub, 1))
+	success, err := h.Delivered(h.Sub, ctxID)
+	require.NoError(t, err)
+	assert.True(t, success)
+
+	require.NoError(t, h.WaitStatus(h.Main, ctxID, cc.CtxStatusExecuting, waitTimeout))
+	require.NoError(t, h.Mine(h.Sub, h.ConfirmedDepth()))
+	require.NoError(t, h.WaitStatus(h.Main, ctxID, cc.CtxStatusFinished, waitTimeout))
+	assert.NoError(t, h.CheckConsistency(h.Main, ctxID))
+}
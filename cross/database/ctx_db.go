@@ -77,6 +77,17 @@ func OpenStormDB(ctx ServiceContext, name string) (*storm.DB, error) {
 	return storm.Open(ctx.ResolvePath(name))
 }
 
+// OpenLegacyStormDB opens the storm db for migration, it returns nil if the db is not exist
+func OpenLegacyStormDB(ctx ServiceContext, name string) (*storm.DB, error) {
+	if ctx == nil || len(ctx.ResolvePath(name)) == 0 {
+		return nil, nil
+	}
+	if _, err := os.Stat(ctx.ResolvePath(name)); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return storm.Open(ctx.ResolvePath(name))
+}
+
 func OpenEtherDB(ctx ServiceContext, name string) (ethdb.Database, error) {
 	if ctx == nil {
 		return rawdb.NewMemoryDatabase(), nil
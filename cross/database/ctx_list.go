@@ -202,3 +202,7 @@ func (m *IndexDbCache) Put(index FieldName, key interface{}, ctx *CrossTransacti
 func (m *IndexDbCache) Remove(index FieldName, key interface{}) {
 	(*lru.ARCCache)(m).Remove(indexCacheKey(index, key))
 }
+
+func (m *IndexDbCache) Purge() {
+	(*lru.ARCCache)(m).Purge()
+}
@@ -115,6 +115,7 @@ func (d *indexDB) Writes(ctxList []*cc.CrossTransactionWithSignatures, replaceab
 	}
 	defer tx.Rollback()
 
+	var updated []*CrossTransactionIndexed
 	canReplace := func(old, new *CrossTransactionIndexed) bool {
 		if !replaceable {
 			return false
@@ -164,13 +165,15 @@ func (d *indexDB) Writes(ctxList []*cc.CrossTransactionWithSignatures, replaceab
 			continue
 		}
 
-		if d.cache != nil {
-			d.cache.Remove(CtxIdIndex, ctx.ID())
-			d.cache.Remove(CtxIdIndex, ctx.Data.TxHash)
-		}
+		d.invalidate(new)
+		updated = append(updated, new)
 	}
 
-	return tx.Commit()
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	d.invalidate(updated...)
+	return nil
 }
 
 func (d *indexDB) Read(ctxId common.Hash) (*cc.CrossTransactionWithSignatures, error) {
@@ -232,6 +235,7 @@ func (d *indexDB) Updates(idList []common.Hash, updaters []func(ctx *CrossTransa
 	}
 	defer tx.Rollback()
 
+	updated := make([]*CrossTransactionIndexed, 0, len(idList))
 	for i, id := range idList {
 		var ctx CrossTransactionIndexed
 		if err = tx.One(CtxIdIndex, id, &ctx); err != nil {
@@ -241,12 +245,14 @@ func (d *indexDB) Updates(idList []common.Hash, updaters []func(ctx *CrossTransa
 		if err = tx.Update(&ctx); err != nil {
 			return ErrCtxDbFailure{"transaction update failed", err}
 		}
-		if d.cache != nil {
-			d.cache.Remove(CtxIdIndex, id)
-			d.cache.Remove(TxHashIndex, ctx.TxHash)
-		}
+		d.invalidate(&ctx)
+		updated = append(updated, &ctx)
+	}
+	if err = tx.Commit(); err != nil {
+		return err
 	}
-	return tx.Commit()
+	d.invalidate(updated...)
+	return nil
 }
 
 func (d *indexDB) Deletes(idList []common.Hash) (err error) {
@@ -255,21 +261,36 @@ func (d *indexDB) Deletes(idList []common.Hash) (err error) {
 		return ErrCtxDbFailure{"begin transaction failed", err}
 	}
 	defer tx.Rollback()
+	deleted := make([]*CrossTransactionIndexed, 0, len(idList))
 	for _, id := range idList {
 		var ctx CrossTransactionIndexed
 		if err = tx.One(CtxIdIndex, id, &ctx); err != nil {
 			continue
 		}
-		if d.cache != nil {
-			d.cache.Remove(CtxIdIndex, id)
-			d.cache.Remove(TxHashIndex, ctx.TxHash)
-		}
+		d.invalidate(&ctx)
 		if err = tx.DeleteStruct(&ctx); err != nil {
 			return ErrCtxDbFailure{"transaction delete failed", err}
 		}
+		deleted = append(deleted, &ctx)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
 	}
+	d.invalidate(deleted...)
+	return nil
+}
 
-	return tx.Commit()
+// invalidate removes ctxs from cache, it is called again after the transaction committed,
+// otherwise a concurrent reader could cache the old ctx before the commit.
+func (d *indexDB) invalidate(ctxs ...*CrossTransactionIndexed) {
+	if d.cache == nil {
+		return
+	}
+	for _, ctx := range ctxs {
+		d.cache.Remove(CtxIdIndex, ctx.CtxId)
+		d.cache.Remove(TxHashIndex, ctx.TxHash)
+	}
 }
 
 func (d *indexDB) Has(id common.Hash) bool {
@@ -324,3 +324,36 @@ func TestIndexDB_Updates(t *testing.T) {
 		assert.Equal(t, cc.CtxStatusWaiting, db.One(CtxIdIndex, ctx.ID()).Status)
 	}
 }
+
+func TestIndexDB_CacheInvalidation(t *testing.T) {
+	rootDB := setupIndexDB(t)
+	defer rootDB.Close()
+
+	db := NewIndexDB(big.NewInt(1), rootDB, 20)
+	db.Clean()
+
+	ctx := generateCtx(1)[0]
+	ctx.Data.TxHash = common.HexToHash("0xff") // lookups by tx hash must not hit the ctx id entries
+	assert.NoError(t, db.Writes([]*cc.CrossTransactionWithSignatures{ctx}, false))
+
+	// cache the ctx by both indexes, then replace it
+	assert.Equal(t, cc.CtxStatusPending, db.One(CtxIdIndex, ctx.ID()).Status)
+	assert.Equal(t, cc.CtxStatusPending, db.One(TxHashIndex, ctx.Data.TxHash).Status)
+
+	replaced := &cc.CrossTransactionWithSignatures{Data: ctx.Data, Status: cc.CtxStatusWaiting, BlockNum: ctx.BlockNum}
+	assert.NoError(t, db.Writes([]*cc.CrossTransactionWithSignatures{replaced}, true))
+	assert.Equal(t, cc.CtxStatusWaiting, db.One(CtxIdIndex, ctx.ID()).Status)
+	assert.Equal(t, cc.CtxStatusWaiting, db.One(TxHashIndex, ctx.Data.TxHash).Status)
+
+	// updated ctx
+	assert.NoError(t, db.Update(ctx.ID(), func(ctx *CrossTransactionIndexed) {
+		ctx.Status = uint8(cc.CtxStatusExecuted)
+	}))
+	assert.Equal(t, cc.CtxStatusExecuted, db.One(CtxIdIndex, ctx.ID()).Status)
+	assert.Equal(t, cc.CtxStatusExecuted, db.One(TxHashIndex, ctx.Data.TxHash).Status)
+
+	// deleted ctx
+	assert.NoError(t, db.Deletes([]common.Hash{ctx.ID()}))
+	assert.Nil(t, db.One(CtxIdIndex, ctx.ID()))
+	assert.Nil(t, db.One(TxHashIndex, ctx.Data.TxHash))
+}
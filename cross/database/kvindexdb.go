@@ -0,0 +1,913 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package db
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"go/token"
+	"math/big"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/ethdb"
+	"github.com/simplechain-org/go-simplechain/log"
+	"github.com/simplechain-org/go-simplechain/rlp"
+
+	cc "github.com/simplechain-org/go-simplechain/cross/core"
+
+	"github.com/asdine/storm/v3"
+	"github.com/asdine/storm/v3/q"
+)
+
+/**
+  kvIndexDB stores ctxs of one chain in an ethdb.KeyValueStore, all keys are prefixed with "chain<id>-":
+
+  c|ctxID                    -> rlp(storedCtx)
+  h|txHash|ctxID             -> nil (TxHashIndex)
+  s|status|ctxID             -> nil (StatusField)
+  f|from|ctxID               -> nil (FromField)
+  t|to|ctxID                 -> nil (ToField)
+  v|destValue(32)|ctxID      -> nil (DestinationValue)
+  n|blockNum(8)|ctxID        -> nil (BlockNumField)
+  k                          -> last PK
+  m                          -> migrated from storm
+
+  Query uses at most one secondary index to select candidates, then every candidate is checked by the filters,
+  so results are the same as storm no matter which index is chosen.
+*/
+
+var (
+	recordPrefix    = byte('c')
+	txHashPrefix    = byte('h')
+	statusPrefix    = byte('s')
+	fromPrefix      = byte('f')
+	toPrefix        = byte('t')
+	destValuePrefix = byte('v')
+	numberPrefix    = byte('n')
+
+	lastPKKey    = []byte("k")
+	migratedKey  = []byte("m")
+	errNotExist  = errors.New("not exist")
+	valueEncSize = 32
+)
+
+// storedCtx is the rlp encoding of CrossTransactionIndexed, Price is calculated when decoding
+type storedCtx struct {
+	PK               uint64
+	CtxId            common.Hash
+	From             common.Address
+	To               common.Address
+	TxHash           common.Hash
+	BlockNum         uint64
+	Status           uint8
+	Value            *big.Int
+	BlockHash        common.Hash
+	DestinationId    *big.Int
+	DestinationValue *big.Int
+	Input            []byte
+	V                []*big.Int
+	R                []*big.Int
+	S                []*big.Int
+}
+
+type kvIndexDB struct {
+	chainID *big.Int
+	db      ethdb.KeyValueStore
+	prefix  []byte
+	cache   *IndexDbCache
+	logger  log.Logger
+
+	lastPK uint64
+	height uint64
+	dirty  bool       // height need to be recalculated
+	mu     sync.Mutex // serializes writes, like the storm transaction does
+}
+
+func NewKVIndexDB(chainID *big.Int, db ethdb.KeyValueStore, cacheSize uint64) *kvIndexDB {
+	dbName := "chain" + chainID.String()
+	log.Info("Open KVIndexDB", "dbName", dbName, "cacheSize", cacheSize)
+	d := &kvIndexDB{
+		chainID: chainID,
+		db:      db,
+		prefix:  []byte(dbName + "-"),
+		cache:   newIndexDbCache(int(cacheSize)),
+		logger:  log.New("name", dbName),
+		dirty:   true,
+	}
+	if enc, err := db.Get(d.key(lastPKKey)); err == nil && len(enc) == 8 {
+		d.lastPK = binary.BigEndian.Uint64(enc)
+	}
+	return d
+}
+
+func (d *kvIndexDB) key(parts ...[]byte) []byte {
+	key := append([]byte{}, d.prefix...)
+	for _, part := range parts {
+		key = append(key, part...)
+	}
+	return key
+}
+
+func encodeNumber(n uint64) []byte {
+	enc := make([]byte, 8)
+	binary.BigEndian.PutUint64(enc, n)
+	return enc
+}
+
+// encodeValue encodes a big value to fixed size bytes, so that it is sorted by bytes
+func encodeValue(v *big.Int) []byte {
+	if v == nil || v.Sign() < 0 {
+		return make([]byte, valueEncSize)
+	}
+	return common.LeftPadBytes(v.Bytes(), valueEncSize)
+}
+
+func (d *kvIndexDB) indexKeys(ctx *CrossTransactionIndexed) [][]byte {
+	id := ctx.CtxId.Bytes()
+	return [][]byte{
+		d.key([]byte{txHashPrefix}, ctx.TxHash.Bytes(), id),
+		d.key([]byte{statusPrefix, ctx.Status}, id),
+		d.key([]byte{fromPrefix}, ctx.From.Bytes(), id),
+		d.key([]byte{toPrefix}, ctx.To.Bytes(), id),
+		d.key([]byte{destValuePrefix}, encodeValue(ctx.DestinationValue), id),
+		d.key([]byte{numberPrefix}, encodeNumber(ctx.BlockNum), id),
+	}
+}
+
+func (d *kvIndexDB) ChainID() *big.Int {
+	return d.chainID
+}
+
+func (d *kvIndexDB) Count(filter ...q.Matcher) int {
+	count := 0
+	d.find(filter, func(*CrossTransactionIndexed) bool {
+		count++
+		return true
+	})
+	return count
+}
+
+func (d *kvIndexDB) Load() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.dirty = true
+	return nil
+}
+
+func (d *kvIndexDB) Height() uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.dirty {
+		d.height = 0
+		d.scan(numberPrefix, nil, nil, func(value []byte, _ common.Hash) bool {
+			d.height = binary.BigEndian.Uint64(value)
+			return true
+		})
+		d.dirty = false
+	}
+	return d.height
+}
+
+// Repair rebuilds all secondary indexes from records
+func (d *kvIndexDB) Repair() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	batch := d.db.NewBatch()
+	for _, prefix := range []byte{txHashPrefix, statusPrefix, fromPrefix, toPrefix, destValuePrefix, numberPrefix} {
+		if err := d.deletePrefix(batch, d.key([]byte{prefix})); err != nil {
+			return err
+		}
+	}
+	it := d.db.NewIteratorWithPrefix(d.key([]byte{recordPrefix}))
+	defer it.Release()
+	for it.Next() {
+		ctx, err := decodeIndexed(it.Value())
+		if err != nil {
+			return ErrCtxDbFailure{"decode ctx failed", err}
+		}
+		for _, key := range d.indexKeys(ctx) {
+			if err := batch.Put(key, nil); err != nil {
+				return err
+			}
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	d.dirty = true
+	return batch.Write()
+}
+
+// Clean removes all ctxs of the chain
+func (d *kvIndexDB) Clean() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	batch := d.db.NewBatch()
+	if err := d.deletePrefix(batch, d.prefix); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	if d.cache != nil {
+		d.cache.Purge()
+	}
+	d.lastPK, d.dirty = 0, true
+	return nil
+}
+
+func (d *kvIndexDB) deletePrefix(batch ethdb.Batch, prefix []byte) error {
+	it := d.db.NewIteratorWithPrefix(prefix)
+	defer it.Release()
+	for it.Next() {
+		if err := batch.Delete(common.CopyBytes(it.Key())); err != nil {
+			return err
+		}
+	}
+	return it.Error()
+}
+
+// Close does nothing, the key-value store is closed by its owner
+func (d *kvIndexDB) Close() error {
+	return nil
+}
+
+func (d *kvIndexDB) Write(ctx *cc.CrossTransactionWithSignatures) error {
+	if err := d.Writes([]*cc.CrossTransactionWithSignatures{ctx}, true); err != nil {
+		return err
+	}
+	if d.cache != nil {
+		d.cache.Put(CtxIdIndex, ctx.ID(), NewCrossTransactionIndexed(ctx))
+	}
+	return nil
+}
+
+func (d *kvIndexDB) Writes(ctxList []*cc.CrossTransactionWithSignatures, replaceable bool) error {
+	d.logger.Debug("write cross transaction", "count", len(ctxList), "replaceable", replaceable)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	canReplace := func(old, new *CrossTransactionIndexed) bool {
+		if !replaceable {
+			return false
+		}
+		if new.Status == uint8(cc.CtxStatusPending) {
+			return false
+		}
+		if new.BlockNum < old.BlockNum {
+			return false
+		}
+		if new.Status <= old.Status { //TODO:无法解决同步其他节点时，其他节点回滚的状态
+			return false
+		}
+		return true
+	}
+
+	var (
+		batch   = d.db.NewBatch()
+		written = make(map[common.Hash]*CrossTransactionIndexed, len(ctxList)) // ctxs written in this batch
+		updated []*CrossTransactionIndexed
+		lastPK  = d.lastPK
+	)
+	for _, ctx := range ctxList {
+		new := NewCrossTransactionIndexed(ctx)
+		old, ok := written[new.CtxId]
+		if !ok {
+			var err error
+			if old, err = d.read(new.CtxId); err != nil && err != errNotExist {
+				return err
+			}
+		}
+		if old == nil {
+			d.logger.Trace("add new cross transaction",
+				"id", ctx.ID().String(), "status", ctx.Status.String(), "number", ctx.BlockNum)
+			lastPK++
+			new.PK = lastPK
+
+		} else if canReplace(old, new) {
+			d.logger.Trace("replace cross transaction", "id", ctx.ID().String(),
+				"old_status", cc.CtxStatus(old.Status).String(), "new_status", ctx.Status.String(),
+				"old_height", old.BlockNum, "new_height", ctx.BlockNum)
+			new.PK = old.PK
+
+		} else {
+			d.logger.Trace("can't add or replace cross transaction", "id", ctx.ID().String(),
+				"old_status", cc.CtxStatus(old.Status).String(), "new_status", ctx.Status.String(),
+				"old_height", old.BlockNum, "new_height", ctx.BlockNum, "replaceable", replaceable)
+			continue
+		}
+
+		if err := d.put(batch, old, new); err != nil {
+			return err
+		}
+		d.invalidate(new)
+		written[new.CtxId] = new
+		updated = append(updated, new)
+	}
+	if len(updated) == 0 {
+		return nil
+	}
+	if lastPK != d.lastPK {
+		if err := batch.Put(d.key(lastPKKey), encodeNumber(lastPK)); err != nil {
+			return err
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return ErrCtxDbFailure{"write batch failed", err}
+	}
+	d.lastPK = lastPK
+	d.raiseHeight(updated...)
+	d.invalidate(updated...)
+	return nil
+}
+
+// put writes ctx and its indexes into batch, indexes of the old ctx are removed
+func (d *kvIndexDB) put(batch ethdb.Batch, old, new *CrossTransactionIndexed) error {
+	if old != nil {
+		for _, key := range d.indexKeys(old) {
+			if err := batch.Delete(key); err != nil {
+				return err
+			}
+		}
+		if old.BlockNum == d.height && new.BlockNum < old.BlockNum {
+			d.dirty = true
+		}
+	}
+	enc, err := encodeIndexed(new)
+	if err != nil {
+		return ErrCtxDbFailure{"encode ctx failed", err}
+	}
+	if err := batch.Put(d.key([]byte{recordPrefix}, new.CtxId.Bytes()), enc); err != nil {
+		return err
+	}
+	for _, key := range d.indexKeys(new) {
+		if err := batch.Put(key, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// raiseHeight updates the cached height with the written ctxs, it must be called
+// after the batch is written, so that a failed write leaves the height unchanged.
+func (d *kvIndexDB) raiseHeight(ctxs ...*CrossTransactionIndexed) {
+	for _, ctx := range ctxs {
+		if ctx.BlockNum > d.height {
+			d.height = ctx.BlockNum
+		}
+	}
+}
+
+func (d *kvIndexDB) Read(ctxId common.Hash) (*cc.CrossTransactionWithSignatures, error) {
+	ctx, err := d.get(ctxId)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.ToCrossTransaction(), nil
+}
+
+func (d *kvIndexDB) One(field FieldName, key interface{}) *cc.CrossTransactionWithSignatures {
+	if d.cache != nil {
+		ctx := d.cache.Get(field, key)
+		if ctx != nil {
+			return ctx.ToCrossTransaction()
+		}
+	}
+	var ctx *CrossTransactionIndexed
+	switch hash, isHash := key.(common.Hash); {
+	case field == CtxIdIndex && isHash:
+		ctx, _ = d.read(hash)
+	default:
+		d.find([]q.Matcher{Eq(field, key)}, func(found *CrossTransactionIndexed) bool {
+			ctx = found
+			return false
+		})
+	}
+	if ctx == nil {
+		return nil
+	}
+	if d.cache != nil {
+		d.cache.Put(field, key, ctx)
+	}
+	return ctx.ToCrossTransaction()
+}
+
+func (d *kvIndexDB) get(ctxId common.Hash) (*CrossTransactionIndexed, error) {
+	if d.cache != nil {
+		ctx := d.cache.Get(CtxIdIndex, ctxId)
+		if ctx != nil {
+			return ctx, nil
+		}
+	}
+	ctx, err := d.read(ctxId)
+	if err != nil {
+		return nil, ErrCtxDbFailure{fmt.Sprintf("get ctx:%s failed", ctxId.String()), err}
+	}
+	if d.cache != nil {
+		d.cache.Put(CtxIdIndex, ctxId, ctx)
+	}
+	return ctx, nil
+}
+
+// read loads ctx from database without cache
+func (d *kvIndexDB) read(ctxId common.Hash) (*CrossTransactionIndexed, error) {
+	enc, err := d.db.Get(d.key([]byte{recordPrefix}, ctxId.Bytes()))
+	if err != nil || len(enc) == 0 {
+		return nil, errNotExist
+	}
+	return decodeIndexed(enc)
+}
+
+func (d *kvIndexDB) Update(id common.Hash, updater func(ctx *CrossTransactionIndexed)) error {
+	return d.Updates([]common.Hash{id}, []func(ctx *CrossTransactionIndexed){updater})
+}
+
+func (d *kvIndexDB) Updates(idList []common.Hash, updaters []func(ctx *CrossTransactionIndexed)) error {
+	if len(idList) != len(updaters) {
+		return ErrCtxDbFailure{err: errors.New("invalid updates params")}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var (
+		batch   = d.db.NewBatch()
+		written = make(map[common.Hash]*CrossTransactionIndexed, len(idList))
+		updated = make([]*CrossTransactionIndexed, 0, len(idList))
+	)
+	for i, id := range idList {
+		old, ok := written[id]
+		if !ok {
+			var err error
+			if old, err = d.read(id); err != nil {
+				return ErrCtxDbFailure{"transaction want to be updated is not exist", err}
+			}
+		}
+		new := *old
+		updaters[i](&new)
+		new.PK, new.CtxId = old.PK, old.CtxId // primary keys can't be updated
+		if err := d.put(batch, old, &new); err != nil {
+			return ErrCtxDbFailure{"transaction update failed", err}
+		}
+		d.invalidate(&new)
+		written[id] = &new
+		updated = append(updated, &new)
+	}
+	if err := batch.Write(); err != nil {
+		return ErrCtxDbFailure{"write batch failed", err}
+	}
+	d.invalidate(updated...)
+	return nil
+}
+
+func (d *kvIndexDB) Deletes(idList []common.Hash) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	batch := d.db.NewBatch()
+	deleted := make([]*CrossTransactionIndexed, 0, len(idList))
+	for _, id := range idList {
+		ctx, err := d.read(id)
+		if err != nil {
+			continue
+		}
+		d.invalidate(ctx)
+		if err := batch.Delete(d.key([]byte{recordPrefix}, id.Bytes())); err != nil {
+			return ErrCtxDbFailure{"transaction delete failed", err}
+		}
+		for _, key := range d.indexKeys(ctx) {
+			if err := batch.Delete(key); err != nil {
+				return ErrCtxDbFailure{"transaction delete failed", err}
+			}
+		}
+		if ctx.BlockNum == d.height {
+			d.dirty = true
+		}
+		deleted = append(deleted, ctx)
+	}
+	if err := batch.Write(); err != nil {
+		return ErrCtxDbFailure{"write batch failed", err}
+	}
+	d.invalidate(deleted...)
+	return nil
+}
+
+// invalidate removes ctxs from cache, it is called again after the batch written,
+// otherwise a concurrent reader could cache the old ctx before the write.
+func (d *kvIndexDB) invalidate(ctxs ...*CrossTransactionIndexed) {
+	if d.cache == nil {
+		return
+	}
+	for _, ctx := range ctxs {
+		d.cache.Remove(CtxIdIndex, ctx.CtxId)
+		d.cache.Remove(TxHashIndex, ctx.TxHash)
+	}
+}
+
+func (d *kvIndexDB) Has(id common.Hash) bool {
+	_, err := d.get(id)
+	return err == nil
+}
+
+func (d *kvIndexDB) Query(pageSize int, startPage int, orderBy []FieldName, reverse bool, filter ...q.Matcher) []*cc.CrossTransactionWithSignatures {
+	if pageSize > 0 && startPage <= 0 {
+		return nil
+	}
+	var ctxs []*CrossTransactionIndexed
+	d.find(filter, func(ctx *CrossTransactionIndexed) bool {
+		ctxs = append(ctxs, ctx)
+		return true
+	})
+
+	// storm returns ctxs ordered by PK if orderBy is not specified
+	sort.Slice(ctxs, func(i, j int) bool { return ctxs[i].PK < ctxs[j].PK })
+	if len(orderBy) > 0 {
+		sort.SliceStable(ctxs, func(i, j int) bool {
+			for _, field := range orderBy {
+				if c := compareField(ctxs[i], ctxs[j], field); c != 0 {
+					return c < 0
+				}
+			}
+			return false
+		})
+	}
+	if reverse {
+		for i, j := 0, len(ctxs)-1; i < j; i, j = i+1, j-1 {
+			ctxs[i], ctxs[j] = ctxs[j], ctxs[i]
+		}
+	}
+	if pageSize > 0 {
+		skip := pageSize * (startPage - 1)
+		if skip >= len(ctxs) {
+			return []*cc.CrossTransactionWithSignatures{}
+		}
+		ctxs = ctxs[skip:]
+		if len(ctxs) > pageSize {
+			ctxs = ctxs[:pageSize]
+		}
+	}
+
+	results := make([]*cc.CrossTransactionWithSignatures, len(ctxs))
+	for i, ctx := range ctxs {
+		results[i] = ctx.ToCrossTransaction()
+	}
+	return results
+}
+
+func (d *kvIndexDB) RangeByNumber(begin, end uint64, limit int) []*cc.CrossTransactionWithSignatures {
+	var (
+		ctxs []*CrossTransactionIndexed
+		last uint64
+	)
+	d.scan(numberPrefix, encodeNumber(begin), encodeNumber(end), func(value []byte, id common.Hash) bool {
+		number := binary.BigEndian.Uint64(value)
+		//把最后一笔ctx所在高度的所有ctx取出来
+		if limit > 0 && len(ctxs) >= limit && number != last {
+			return false
+		}
+		if ctx, err := d.read(id); err == nil {
+			ctxs = append(ctxs, ctx)
+			last = number
+		}
+		return true
+	})
+	if ctxs == nil {
+		return nil
+	}
+	sort.SliceStable(ctxs, func(i, j int) bool {
+		if ctxs[i].BlockNum != ctxs[j].BlockNum {
+			return ctxs[i].BlockNum < ctxs[j].BlockNum
+		}
+		return ctxs[i].PK < ctxs[j].PK
+	})
+
+	results := make([]*cc.CrossTransactionWithSignatures, len(ctxs))
+	for i, ctx := range ctxs {
+		results[i] = ctx.ToCrossTransaction()
+	}
+	return results
+}
+
+// find calls fn with every ctx matched by filter, until fn returns false
+func (d *kvIndexDB) find(filter []q.Matcher, fn func(ctx *CrossTransactionIndexed) bool) {
+	match := func(ctx *CrossTransactionIndexed) bool {
+		for _, matcher := range filter {
+			if ok, err := matcher.Match(ctx); err != nil || !ok {
+				return false
+			}
+		}
+		return true
+	}
+	visit := func(_ []byte, id common.Hash) bool {
+		ctx, err := d.read(id)
+		if err != nil || !match(ctx) {
+			return true
+		}
+		return fn(ctx)
+	}
+
+	prefix, from, to := d.plan(filter)
+	if prefix == recordPrefix { // full scan without index
+		it := d.db.NewIteratorWithPrefix(d.key([]byte{recordPrefix}))
+		defer it.Release()
+		for it.Next() {
+			ctx, err := decodeIndexed(it.Value())
+			if err != nil || !match(ctx) {
+				continue
+			}
+			if !fn(ctx) {
+				return
+			}
+		}
+		return
+	}
+	d.scan(prefix, from, to, visit)
+}
+
+// scan iterates the index entries whose value is in range [from, to], nil means unbounded
+func (d *kvIndexDB) scan(prefix byte, from, to []byte, fn func(value []byte, id common.Hash) bool) {
+	indexPrefix := d.key([]byte{prefix})
+	it := d.db.NewIteratorWithStart(append(common.CopyBytes(indexPrefix), from...))
+	defer it.Release()
+	for it.Next() {
+		key := it.Key()
+		if !bytes.HasPrefix(key, indexPrefix) || len(key) < len(indexPrefix)+common.HashLength {
+			return
+		}
+		value := key[len(indexPrefix) : len(key)-common.HashLength]
+		if to != nil && bytes.Compare(value, to) > 0 {
+			return
+		}
+		if !fn(common.CopyBytes(value), common.BytesToHash(key[len(key)-common.HashLength:])) {
+			return
+		}
+	}
+}
+
+// plan chooses the most selective index by the top level index matchers
+func (d *kvIndexDB) plan(filter []q.Matcher) (prefix byte, from, to []byte) {
+	var (
+		best  = -1
+		rank  = map[byte]int{txHashPrefix: 0, fromPrefix: 1, toPrefix: 1, statusPrefix: 2, numberPrefix: 3, destValuePrefix: 4}
+		lower = make(map[byte][]byte)
+		upper = make(map[byte][]byte)
+	)
+	choose := func(p byte) {
+		if best < 0 || rank[p] < best {
+			best, prefix = rank[p], p
+		}
+	}
+	for _, matcher := range filter {
+		m, ok := matcher.(*indexMatcher)
+		if !ok {
+			continue
+		}
+		p, enc, ok := indexValue(m.field, m.value)
+		if !ok {
+			continue
+		}
+		switch m.tok {
+		case token.EQL:
+			lower[p], upper[p] = maxBytes(lower[p], enc), minBytes(upper[p], enc)
+		case token.GEQ:
+			lower[p] = maxBytes(lower[p], enc)
+		case token.LEQ:
+			upper[p] = minBytes(upper[p], enc)
+		default:
+			continue
+		}
+		choose(p)
+	}
+	if best < 0 {
+		return recordPrefix, nil, nil
+	}
+	return prefix, lower[prefix], upper[prefix]
+}
+
+// indexValue returns the index prefix and the encoded value of an indexed field
+func indexValue(field FieldName, value interface{}) (byte, []byte, bool) {
+	switch field {
+	case TxHashIndex:
+		if hash, ok := value.(common.Hash); ok {
+			return txHashPrefix, hash.Bytes(), true
+		}
+	case FromField, ToField:
+		if addr, ok := value.(common.Address); ok {
+			if field == FromField {
+				return fromPrefix, addr.Bytes(), true
+			}
+			return toPrefix, addr.Bytes(), true
+		}
+	case StatusField:
+		if n, ok := toUint64(value); ok && n <= 0xff {
+			return statusPrefix, []byte{byte(n)}, true
+		}
+	case BlockNumField:
+		if n, ok := toUint64(value); ok {
+			return numberPrefix, encodeNumber(n), true
+		}
+	case DestinationValue:
+		if v, ok := value.(*big.Int); ok && v != nil && v.Sign() >= 0 && v.BitLen() <= valueEncSize*8 {
+			return destValuePrefix, encodeValue(v), true
+		}
+	}
+	return 0, nil, false
+}
+
+func toUint64(value interface{}) (uint64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() >= 0 {
+			return uint64(v.Int()), true
+		}
+	}
+	return 0, false
+}
+
+func maxBytes(a, b []byte) []byte {
+	if a == nil || bytes.Compare(b, a) > 0 {
+		return b
+	}
+	return a
+}
+
+func minBytes(a, b []byte) []byte {
+	if a == nil || bytes.Compare(b, a) < 0 {
+		return b
+	}
+	return a
+}
+
+// compareField compares the field of two ctxs as the storm sorter does
+func compareField(a, b *CrossTransactionIndexed, field FieldName) int {
+	switch field {
+	case PK:
+		return compareUint64(a.PK, b.PK)
+	case BlockNumField:
+		return compareUint64(a.BlockNum, b.BlockNum)
+	case StatusField:
+		return compareUint64(uint64(a.Status), uint64(b.Status))
+	case PriceIndex:
+		return a.Price.Cmp(b.Price)
+	case DestinationValue:
+		return a.DestinationValue.Cmp(b.DestinationValue)
+	case CtxIdIndex:
+		return bytes.Compare(a.CtxId.Bytes(), b.CtxId.Bytes())
+	case TxHashIndex:
+		return bytes.Compare(a.TxHash.Bytes(), b.TxHash.Bytes())
+	case FromField:
+		return bytes.Compare(a.From.Bytes(), b.From.Bytes())
+	case ToField:
+		return bytes.Compare(a.To.Bytes(), b.To.Bytes())
+	}
+	return 0
+}
+
+func compareUint64(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func encodeIndexed(ctx *CrossTransactionIndexed) ([]byte, error) {
+	return rlp.EncodeToBytes(&storedCtx{
+		PK:               ctx.PK,
+		CtxId:            ctx.CtxId,
+		From:             ctx.From,
+		To:               ctx.To,
+		TxHash:           ctx.TxHash,
+		BlockNum:         ctx.BlockNum,
+		Status:           ctx.Status,
+		Value:            ctx.Value,
+		BlockHash:        ctx.BlockHash,
+		DestinationId:    ctx.DestinationId,
+		DestinationValue: ctx.DestinationValue,
+		Input:            ctx.Input,
+		V:                ctx.V,
+		R:                ctx.R,
+		S:                ctx.S,
+	})
+}
+
+func decodeIndexed(enc []byte) (*CrossTransactionIndexed, error) {
+	var stored storedCtx
+	if err := rlp.DecodeBytes(enc, &stored); err != nil {
+		return nil, err
+	}
+	// keep nil slices as the original ctx
+	if len(stored.Input) == 0 {
+		stored.Input = nil
+	}
+	if len(stored.V) == 0 {
+		stored.V, stored.R, stored.S = nil, nil, nil
+	}
+	ctx := &CrossTransactionIndexed{
+		PK:               stored.PK,
+		CtxId:            stored.CtxId,
+		From:             stored.From,
+		To:               stored.To,
+		TxHash:           stored.TxHash,
+		BlockNum:         stored.BlockNum,
+		Status:           stored.Status,
+		Value:            stored.Value,
+		BlockHash:        stored.BlockHash,
+		DestinationId:    stored.DestinationId,
+		DestinationValue: stored.DestinationValue,
+		Input:            stored.Input,
+		V:                stored.V,
+		R:                stored.R,
+		S:                stored.S,
+	}
+	ctx.Price = new(big.Float).SetRat(ctx.ToCrossTransaction().Price())
+	return ctx, nil
+}
+
+const migrateBatchSize = 1024
+
+// MigrateIndexDB copies all ctxs from the storm indexDB into kvIndexDB in PK order,
+// it does nothing if the chain is migrated already.
+func MigrateIndexDB(from *indexDB, to *kvIndexDB) (int, error) {
+	if migrated, _ := to.db.Has(to.key(migratedKey)); migrated {
+		return 0, nil
+	}
+	var count int
+	for skip := 0; ; skip += migrateBatchSize {
+		var ctxs []*CrossTransactionIndexed
+		if err := from.db.All(&ctxs, storm.Limit(migrateBatchSize), storm.Skip(skip)); err != nil {
+			return count, ErrCtxDbFailure{"read storm db failed", err}
+		}
+		n, err := to.writeIndexed(ctxs)
+		if err != nil {
+			return count, err
+		}
+		count += n
+		if len(ctxs) < migrateBatchSize {
+			break
+		}
+	}
+	if err := to.db.Put(to.key(migratedKey), []byte{1}); err != nil {
+		return count, err
+	}
+	to.logger.Info("Migrate storm IndexDB", "count", count)
+	return count, nil
+}
+
+// writeIndexed writes ctxs as they are, existing ctxs are skipped
+func (d *kvIndexDB) writeIndexed(ctxs []*CrossTransactionIndexed) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var (
+		batch   = d.db.NewBatch()
+		lastPK  = d.lastPK
+		written []*CrossTransactionIndexed
+	)
+	for _, ctx := range ctxs {
+		if has, _ := d.db.Has(d.key([]byte{recordPrefix}, ctx.CtxId.Bytes())); has {
+			continue
+		}
+		lastPK++
+		ctx.PK = lastPK
+		if err := d.put(batch, nil, ctx); err != nil {
+			return 0, err
+		}
+		written = append(written, ctx)
+	}
+	if len(written) == 0 {
+		return 0, nil
+	}
+	if err := batch.Put(d.key(lastPKKey), encodeNumber(lastPK)); err != nil {
+		return 0, err
+	}
+	if err := batch.Write(); err != nil {
+		return 0, ErrCtxDbFailure{"write batch failed", err}
+	}
+	d.lastPK = lastPK
+	d.raiseHeight(written...)
+	return len(written), nil
+}
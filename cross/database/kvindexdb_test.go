@@ -0,0 +1,333 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package db
+
+import (
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/core/rawdb"
+	"github.com/simplechain-org/go-simplechain/ethdb"
+	"github.com/simplechain-org/go-simplechain/ethdb/leveldb"
+
+	cc "github.com/simplechain-org/go-simplechain/cross/core"
+
+	"github.com/asdine/storm/v3/q"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKVIndexDB_ReadWrite(t *testing.T) {
+	diskdb := rawdb.NewMemoryDatabase()
+	ctxList := generateCtx(40)
+
+	db := NewKVIndexDB(big.NewInt(1), diskdb, 10)
+	assert.NoError(t, db.Write(ctxList[0]))
+	assert.Equal(t, 1, db.Count(Eq(StatusField, cc.CtxStatusPending)))
+	ctx, err := db.Read(ctxList[0].ID())
+	assert.NoError(t, err)
+	assert.Equal(t, ctxList[0], ctx)
+	assert.Equal(t, ctxList[0], db.One(TxHashIndex, ctxList[0].Data.TxHash))
+
+	// concurrent write
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 1; i < 20; i++ {
+			assert.NoError(t, db.Write(ctxList[i]))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 20; i < 40; i++ {
+			assert.NoError(t, db.Write(ctxList[i]))
+		}
+	}()
+	wg.Wait()
+	assert.Equal(t, 40, db.Count(Eq(StatusField, cc.CtxStatusPending)))
+
+	// restart db, other chains are not affected
+	db = NewKVIndexDB(big.NewInt(1), diskdb, 10)
+	assert.NoError(t, db.Load())
+	assert.Equal(t, 40, db.Count())
+	assert.EqualValues(t, 39, db.Height())
+	assert.Equal(t, 0, NewKVIndexDB(big.NewInt(11), diskdb, 10).Count())
+
+	// pk is continued after restart
+	assert.NoError(t, db.Write(generateCtx(41)[40]))
+	list := db.Query(0, 0, nil, true)
+	assert.Equal(t, generateCtx(41)[40].ID(), list[0].ID())
+}
+
+func TestKVIndexDB_Writes(t *testing.T) {
+	ctxList := generateCtx(10)
+	db := NewKVIndexDB(big.NewInt(1), rawdb.NewMemoryDatabase(), 20)
+
+	assert.NoError(t, db.Writes(ctxList, false))
+	assert.Equal(t, 10, db.Count())
+
+	// replace to waiting
+	for _, ctx := range ctxList[0:6] {
+		ctx.Status = cc.CtxStatusWaiting
+	}
+	assert.NoError(t, db.Writes(ctxList, true))
+	assert.Equal(t, 6, db.Count(Eq(StatusField, cc.CtxStatusWaiting)))
+	assert.Equal(t, 4, db.Count(Eq(StatusField, cc.CtxStatusPending)))
+
+	// replace to finishing with number++
+	for _, ctx := range ctxList[0:3] {
+		ctx.Status = cc.CtxStatusFinishing
+	}
+	// replace to finishing without number
+	for _, ctx := range ctxList[3:6] {
+		ctx.Status = cc.CtxStatusFinishing
+		ctx.BlockNum--
+	}
+	assert.NoError(t, db.Writes(ctxList, true))
+	assert.Equal(t, 3, db.Count(Eq(StatusField, cc.CtxStatusFinishing)))
+	assert.Equal(t, 3, db.Count(Eq(StatusField, cc.CtxStatusWaiting)))
+
+	for _, ctx := range ctxList[0:3] {
+		assert.Equal(t, cc.CtxStatusFinishing, db.One(CtxIdIndex, ctx.ID()).Status)
+	}
+	for _, ctx := range ctxList[3:6] {
+		assert.Equal(t, cc.CtxStatusWaiting, db.One(CtxIdIndex, ctx.ID()).Status)
+	}
+}
+
+// failingBatchDB is a database whose batches can not be written
+type failingBatchDB struct {
+	ethdb.KeyValueStore
+}
+
+func (db failingBatchDB) NewBatch() ethdb.Batch {
+	return failingBatch{db.KeyValueStore.NewBatch()}
+}
+
+type failingBatch struct {
+	ethdb.Batch
+}
+
+func (failingBatch) Write() error {
+	return errors.New("write failed")
+}
+
+func TestKVIndexDB_FailedWrite(t *testing.T) {
+	ctxList := generateCtx(10)
+	db := NewKVIndexDB(big.NewInt(1), failingBatchDB{rawdb.NewMemoryDatabase()}, 20)
+	assert.EqualValues(t, 0, db.Height())
+
+	assert.Error(t, db.Writes(ctxList, false))
+	assert.Equal(t, 0, db.Count())
+	assert.EqualValues(t, 0, db.Height())
+
+	_, err := db.writeIndexed([]*CrossTransactionIndexed{NewCrossTransactionIndexed(ctxList[9])})
+	assert.Error(t, err)
+	assert.EqualValues(t, 0, db.Height())
+}
+
+func TestKVIndexDB_UpdatesDeletes(t *testing.T) {
+	ctxList := generateCtx(10)
+	db := NewKVIndexDB(big.NewInt(1), rawdb.NewMemoryDatabase(), 20)
+	assert.NoError(t, db.Writes(ctxList, false))
+
+	var (
+		ids      []common.Hash
+		updaters []func(ctx *CrossTransactionIndexed)
+	)
+	for _, ctx := range ctxList[0:6] {
+		ids = append(ids, ctx.ID())
+		updaters = append(updaters, func(ctx *CrossTransactionIndexed) {
+			ctx.Status = uint8(cc.CtxStatusWaiting)
+			ctx.BlockNum += 100
+		})
+	}
+	assert.NoError(t, db.Updates(ids, updaters))
+	assert.Equal(t, 6, db.Count(Eq(StatusField, cc.CtxStatusWaiting)))
+	assert.Equal(t, 0, db.Count(Eq(StatusField, cc.CtxStatusWaiting), Lte(BlockNumField, 99)))
+	assert.EqualValues(t, 105, db.Height())
+	for _, ctx := range ctxList[0:6] {
+		assert.Equal(t, cc.CtxStatusWaiting, db.One(CtxIdIndex, ctx.ID()).Status)
+	}
+
+	assert.NoError(t, db.Deletes(ids))
+	assert.Equal(t, 4, db.Count())
+	assert.Equal(t, 0, db.Count(Eq(StatusField, cc.CtxStatusWaiting)))
+	assert.EqualValues(t, 9, db.Height())
+	assert.False(t, db.Has(ids[0]))
+	assert.Nil(t, db.One(TxHashIndex, ctxList[0].Data.TxHash))
+
+	assert.NoError(t, db.Repair())
+	assert.Equal(t, 4, db.Count(Eq(StatusField, cc.CtxStatusPending)))
+	assert.NoError(t, db.Clean())
+	assert.Equal(t, 0, db.Count())
+	assert.EqualValues(t, 0, db.Height())
+}
+
+// TestKVIndexDB_Query checks kvIndexDB returns the same results as storm indexDB
+func TestKVIndexDB_Query(t *testing.T) {
+	ctxList := generateCtx(100)
+	for i, ctx := range ctxList {
+		ctx.Status = cc.CtxStatus(i % 3)
+		ctx.BlockNum = uint64(i / 2) // two ctxs in a block
+		ctx.Data.To = common.BigToAddress(big.NewInt(int64(i % 7)))
+	}
+	rootDB := setupIndexDB(t)
+	defer rootDB.Close()
+	storm := NewIndexDB(big.NewInt(1), rootDB, 0)
+	storm.Clean()
+	kv := NewKVIndexDB(big.NewInt(1), rawdb.NewMemoryDatabase(), 0)
+	assert.NoError(t, storm.Writes(ctxList, false))
+	assert.NoError(t, kv.Writes(ctxList, false))
+	assert.Equal(t, storm.Height(), kv.Height())
+
+	filters := [][]q.Matcher{
+		nil,
+		{Eq(StatusField, cc.CtxStatusWaiting)},
+		{Eq(StatusField, cc.CtxStatusWaiting), Lte(BlockNumField, uint64(30))},
+		{Eq(StatusField, cc.CtxStatusPending), Gte(DestinationValue, ctxList[10].Data.DestinationValue)},
+		{q.Or(Eq(StatusField, cc.CtxStatusWaiting), Eq(StatusField, cc.CtxStatusIllegal)), Eq(FromField, common.BigToAddress(big.NewInt(10)))},
+		{Eq(StatusField, cc.CtxStatusWaiting), Eq(ToField, common.BigToAddress(big.NewInt(3)))},
+		{q.Gt(BlockNumField, uint64(10)), q.Lt(BlockNumField, uint64(20))},
+	}
+	ids := func(list []*cc.CrossTransactionWithSignatures) (ids []common.Hash) {
+		for _, ctx := range list {
+			ids = append(ids, ctx.ID())
+		}
+		return ids
+	}
+	for i, filter := range filters {
+		assert.Equal(t, storm.Count(filter...), kv.Count(filter...), "filter %d", i)
+		for _, reverse := range []bool{false, true} {
+			assert.Equal(t, ids(storm.Query(0, 0, []FieldName{BlockNumField}, reverse, filter...)),
+				ids(kv.Query(0, 0, []FieldName{BlockNumField}, reverse, filter...)), "filter %d", i)
+			assert.Equal(t, ids(storm.Query(0, 0, nil, reverse, filter...)),
+				ids(kv.Query(0, 0, nil, reverse, filter...)), "filter %d", i)
+			assert.Equal(t, ids(storm.Query(5, 2, []FieldName{PriceIndex}, reverse, filter...)),
+				ids(kv.Query(5, 2, []FieldName{PriceIndex}, reverse, filter...)), "filter %d", i)
+		}
+	}
+	assert.Equal(t, 0, len(kv.Query(50, 5, []FieldName{PriceIndex}, false)))
+
+	for _, limit := range []int{0, 1, 5, 8} {
+		assert.Equal(t, ids(storm.RangeByNumber(10, 30, limit)), ids(kv.RangeByNumber(10, 30, limit)), "limit %d", limit)
+	}
+	assert.Nil(t, kv.RangeByNumber(100, 200, 0))
+}
+
+func TestKVIndexDB_Migrate(t *testing.T) {
+	ctxList := generateCtx(2*migrateBatchSize + 10)
+	rootDB := setupIndexDB(t)
+	defer rootDB.Close()
+	storm := NewIndexDB(big.NewInt(1), rootDB, 0)
+	storm.Clean()
+	assert.NoError(t, storm.Writes(ctxList, false))
+	assert.NoError(t, storm.Update(ctxList[0].ID(), func(ctx *CrossTransactionIndexed) {
+		ctx.Status = uint8(cc.CtxStatusFinished)
+	}))
+
+	kv := NewKVIndexDB(big.NewInt(1), rawdb.NewMemoryDatabase(), 0)
+	count, err := MigrateIndexDB(storm, kv)
+	assert.NoError(t, err)
+	assert.Equal(t, len(ctxList), count)
+	assert.Equal(t, len(ctxList), kv.Count())
+	assert.Equal(t, 1, kv.Count(Eq(StatusField, cc.CtxStatusFinished)))
+	assert.Equal(t, storm.Height(), kv.Height())
+
+	// migrate only once
+	count, err = MigrateIndexDB(storm, kv)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func newBenchKVIndexDB(b *testing.B) (*kvIndexDB, func()) {
+	dir, err := ioutil.TempDir("", "cross-kvindex")
+	if err != nil {
+		b.Fatal(err)
+	}
+	diskdb, err := leveldb.New(dir, 16, 16, "")
+	if err != nil {
+		b.Fatal(err)
+	}
+	return NewKVIndexDB(big.NewInt(1), diskdb, 0), func() {
+		diskdb.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func newBenchStormIndexDB(b *testing.B) (*indexDB, func()) {
+	rootDB := setupIndexDB(b)
+	db := NewIndexDB(big.NewInt(1), rootDB, 0)
+	db.Clean()
+	return db, func() {
+		db.Clean()
+		rootDB.Close()
+	}
+}
+
+func benchmarkWrites(b *testing.B, db CtxDB, batch int) {
+	ctxList := generateCtx(b.N * batch)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := db.Writes(ctxList[i*batch:(i+1)*batch], false); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkQuery(b *testing.B, db CtxDB) {
+	ctxList := generateCtx(10000)
+	for i, ctx := range ctxList {
+		ctx.Status = cc.CtxStatus(i % 7)
+	}
+	if err := db.Writes(ctxList, false); err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		db.Query(20, 1, []FieldName{PriceIndex}, false, Eq(StatusField, cc.CtxStatusWaiting))
+	}
+}
+
+func BenchmarkKVIndexDB_Writes(b *testing.B) {
+	db, closeFn := newBenchKVIndexDB(b)
+	defer closeFn()
+	benchmarkWrites(b, db, 1000)
+}
+
+func BenchmarkStormIndexDB_Writes(b *testing.B) {
+	db, closeFn := newBenchStormIndexDB(b)
+	defer closeFn()
+	benchmarkWrites(b, db, 1000)
+}
+
+func BenchmarkKVIndexDB_Query(b *testing.B) {
+	db, closeFn := newBenchKVIndexDB(b)
+	defer closeFn()
+	benchmarkQuery(b, db)
+}
+
+func BenchmarkStormIndexDB_Query(b *testing.B) {
+	db, closeFn := newBenchStormIndexDB(b)
+	defer closeFn()
+	benchmarkQuery(b, db)
+}
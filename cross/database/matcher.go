@@ -0,0 +1,53 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package db
+
+import (
+	"go/token"
+	"reflect"
+
+	"github.com/asdine/storm/v3/q"
+)
+
+// indexMatcher is a storm matcher which exposes its field and value,
+// so that kvIndexDB can choose a secondary index to select candidates.
+type indexMatcher struct {
+	q.Matcher
+	field FieldName
+	tok   token.Token
+	value interface{}
+}
+
+// MatchValue makes indexMatcher work inside q.And, q.Or and q.Not
+func (m *indexMatcher) MatchValue(v *reflect.Value) (bool, error) {
+	return m.Matcher.(q.ValueMatcher).MatchValue(v)
+}
+
+// Eq matcher, checks if the given field is equal to the given value
+func Eq(field FieldName, v interface{}) q.Matcher {
+	return &indexMatcher{Matcher: q.Eq(field, v), field: field, tok: token.EQL, value: v}
+}
+
+// Gte matcher, checks if the given field is greater than or equal to the given value
+func Gte(field FieldName, v interface{}) q.Matcher {
+	return &indexMatcher{Matcher: q.Gte(field, v), field: field, tok: token.GEQ, value: v}
+}
+
+// Lte matcher, checks if the given field is lesser than or equal to the given value
+func Lte(field FieldName, v interface{}) q.Matcher {
+	return &indexMatcher{Matcher: q.Lte(field, v), field: field, tok: token.LEQ, value: v}
+}
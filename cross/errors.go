@@ -32,5 +32,7 @@ var (
 	ErrReorgCtx        = fmt.Errorf("[%w]: ctx is on sidechain", ErrVerifyCtx)
 	ErrInternal        = fmt.Errorf("[%w]: internal error", ErrVerifyCtx)
 	ErrRepetitionCtx   = fmt.Errorf("[%w]: repetition cross transaction", ErrVerifyCtx) // 合约重复接单
-
+	ErrDeniedCtx       = fmt.Errorf("[%w]: ctx is denied by risk policy", ErrVerifyCtx)
+	ErrHeldCtx         = fmt.Errorf("[%w]: ctx is held for manual approval", ErrVerifyCtx)
+	ErrNotHeldCtx      = fmt.Errorf("[%w]: ctx is not held for approval", ErrVerifyCtx)
 )
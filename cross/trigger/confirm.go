@@ -0,0 +1,59 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package trigger
+
+import "math/big"
+
+// ConfirmTier requires more confirmations for cross logs with large value
+type ConfirmTier struct {
+	Value *big.Int `json:"value"` // logs with value not less than it use the tier depth
+	Depth uint64   `json:"depth"`
+}
+
+// ConfirmPolicy decides how many blocks a cross log waits on a chain before it is confirmed,
+// e.g. a PoW chain requires more confirmations for large orders while a PBFT chain requires none.
+type ConfirmPolicy struct {
+	ChainID uint64        `json:"chainId"`
+	Depth   uint64        `json:"depth"` // depth of logs below every tier
+	Tiers   []ConfirmTier `json:"tiers"`
+}
+
+func NewConfirmPolicy(chainID uint64, depth uint64) ConfirmPolicy {
+	return ConfirmPolicy{ChainID: chainID, Depth: depth}
+}
+
+// DepthOf returns the confirm depth of a log with value, the deepest matched tier wins
+func (p ConfirmPolicy) DepthOf(value *big.Int) uint64 {
+	depth := p.Depth
+	for _, tier := range p.Tiers {
+		if value != nil && tier.Value != nil && value.Cmp(tier.Value) >= 0 && tier.Depth > depth {
+			depth = tier.Depth
+		}
+	}
+	return depth
+}
+
+// MaxDepth returns the depth after which every log is confirmed
+func (p ConfirmPolicy) MaxDepth() uint64 {
+	depth := p.Depth
+	for _, tier := range p.Tiers {
+		if tier.Depth > depth {
+			depth = tier.Depth
+		}
+	}
+	return depth
+}
@@ -42,6 +42,7 @@ import (
 
 const (
 	maxFinishGasLimit     = 250000
+	maxDeliverGasLimit    = 1000000 // messageDeliver calls the target contract with the payload
 	maxFinishTransactions = 256
 )
 
@@ -70,10 +71,11 @@ type SimpleExecutor struct {
 	contract    common.Address
 	contractABI abi.ABI
 
-	submitCh chan []*cc.ReceptTransaction
-	stopCh   chan struct{}
-	wg       sync.WaitGroup
-	log      log.Logger
+	submitCh  chan []*cc.ReceptTransaction
+	deliverCh chan []*cc.CrossTransactionWithSignatures
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+	log       log.Logger
 }
 
 func NewSimpleExecutor(chain simpletrigger.SimpleChain, anchor common.Address, contract common.Address, qdb queueDB) (
@@ -100,6 +102,7 @@ func NewSimpleExecutor(chain simpletrigger.SimpleChain, anchor common.Address, c
 		contract:    contract,
 		contractABI: abi,
 		submitCh:    make(chan []*cc.ReceptTransaction, 10),
+		deliverCh:   make(chan []*cc.CrossTransactionWithSignatures, 10),
 		stopCh:      make(chan struct{}),
 		log:         logger,
 	}, nil
@@ -121,6 +124,11 @@ func (exe *SimpleExecutor) loop() {
 				exe.pm.AddLocals(txs)
 			}
 
+		case msgs := <-exe.deliverCh:
+			if txs := exe.getTxForDelivery(msgs); len(txs) > 0 {
+				exe.pm.AddLocals(txs)
+			}
+
 		case <-promote.C:
 			//TODO: trigger by txpool reorg event,
 			// 可以将定时触发改成监控chainNewHead事件或者在交易池删除上链的交易后触发
@@ -205,6 +213,15 @@ func (exe *SimpleExecutor) SubmitTransaction(rtxs []*cc.ReceptTransaction) {
 	}
 }
 
+// DeliverMessages sends messageDeliver transactions of signed messages
+func (exe *SimpleExecutor) DeliverMessages(msgs []*cc.CrossTransactionWithSignatures) {
+	select {
+	case exe.deliverCh <- msgs:
+	case <-exe.stopCh:
+		exe.log.Warn("executor stopped, discard messages", "count", len(msgs))
+	}
+}
+
 func (exe *SimpleExecutor) PromoteTransaction() {
 	pending, err := exe.pm.Pending()
 	if err != nil {
@@ -311,7 +328,42 @@ func (exe *SimpleExecutor) lockout(rws *cc.ReceptTransaction, nonce uint64) *typ
 	return tx
 }
 
-func (exe *SimpleExecutor) createTransaction(rws *cc.ReceptTransaction) (*TranParam, error) {
+func (exe *SimpleExecutor) getTxForDelivery(msgs []*cc.CrossTransactionWithSignatures) []*types.Transaction {
+	nonce := exe.pm.GetNonce(exe.anchor)
+	gasPrice, err := exe.suggestPrice()
+	if err != nil {
+		exe.log.Warn("getTxForDelivery SuggestPrice", "err", err)
+		return nil
+	}
+
+	var txs []*types.Transaction
+	for _, msg := range msgs {
+		if msg.DestinationId().Uint64() != exe.pm.NetworkId() {
+			exe.log.Warn("delivering message is not matching this chain",
+				"destinationID", msg.DestinationId(), "chainID", exe.pm.NetworkId())
+			continue
+		}
+		data, err := msg.ConstructMessageData(exe.contractABI)
+		if err != nil {
+			exe.log.Warn("getTxForDelivery ConstructMessageData", "id", msg.ID(), "err", err)
+			continue
+		}
+		if ok, _ := exe.checkTransaction(exe.anchor, exe.contract, maxDeliverGasLimit, gasPrice, data); !ok {
+			exe.log.Debug("already delivered the cross message", "id", msg.ID())
+			continue
+		}
+		tx, err := newSignedTransaction(nonce, exe.contract, maxDeliverGasLimit, gasPrice, data, exe.pm.NetworkId(), exe.SignHash)
+		if err != nil {
+			exe.log.Warn("getTxForDelivery newSignedTransaction", "id", msg.ID(), "err", err)
+			continue
+		}
+		txs = append(txs, tx)
+		nonce++
+	}
+	return txs
+}
+
+func (exe *SimpleExecutor) suggestPrice() (*big.Int, error) {
 	gasPrice, err := exe.gpo.SuggestPrice(context.Background())
 	if err != nil {
 		return nil, err
@@ -321,6 +373,14 @@ func (exe *SimpleExecutor) createTransaction(rws *cc.ReceptTransaction) (*TranPa
 			"suggest", gasPrice, "minerPrice", eth.DefaultConfig.Miner.GasPrice)
 		gasPrice.Set(eth.DefaultConfig.Miner.GasPrice)
 	}
+	return gasPrice, nil
+}
+
+func (exe *SimpleExecutor) createTransaction(rws *cc.ReceptTransaction) (*TranParam, error) {
+	gasPrice, err := exe.suggestPrice()
+	if err != nil {
+		return nil, err
+	}
 	data, err := rws.ConstructData(exe.contractABI)
 	if err != nil {
 		exe.log.Error("ConstructData", "err", err)
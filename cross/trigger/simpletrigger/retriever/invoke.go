@@ -99,7 +99,7 @@ func (e EvmInvoke) CallContract(from common.Address, to *common.Address, functio
 	}
 
 	checkMsg := types.NewMessage(from, to, 0, big.NewInt(0), math.MaxUint64/2,
-		big.NewInt(params.GWei), data, false)
+		big.NewInt(params.GWei), data, nil, false)
 	var cancel context.CancelFunc
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 
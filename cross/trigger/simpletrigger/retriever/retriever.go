@@ -45,6 +45,7 @@ func (s *SimpleRetriever) CanAcceptTxs() bool {
 	return s.pm.CanAcceptTxs()
 }
 
+// ConfirmedDepth returns the max depth of the chain confirm policy, every log is confirmed after it
 func (s *SimpleRetriever) ConfirmedDepth() uint64 {
-	return uint64(simpletrigger.DefaultConfirmDepth)
+	return s.config.ConfirmPolicy(s.chainID, uint64(simpletrigger.DefaultConfirmDepth)).MaxDepth()
 }
@@ -142,6 +142,9 @@ func (v *SimpleValidator) VerifyContract(cws trigger.Transaction) error {
 	}
 	evmInvoke := NewEvmInvoke(v.chain, v.chain.CurrentBlock().Header(), stateDB, &config, vm.Config{})
 	var res []byte
+	if cws.IsMessage() {
+		return v.verifyMessageContract(evmInvoke, cws)
+	}
 	if v.IsLocalCtx(cws) {
 		res, err = evmInvoke.CallContract(common.Address{}, &v.contract, params.GetMakerTxFn, paddedCtxId, common.LeftPadBytes(cws.DestinationId().Bytes(), 32))
 		if err != nil {
@@ -165,6 +168,33 @@ func (v *SimpleValidator) VerifyContract(cws trigger.Transaction) error {
 	return nil
 }
 
+//send message to verify cross-chain message in the cross contract
+//(must exist messageTx in source-chain, do not delivered in destination-chain)
+func (v *SimpleValidator) verifyMessageContract(evmInvoke *EvmInvoke, cws trigger.Transaction) error {
+	paddedCtxId := common.LeftPadBytes(cws.ID().Bytes(), 32) //CtxId
+	if v.IsLocalCtx(cws) {
+		res, err := evmInvoke.CallContract(common.Address{}, &v.contract, params.GetMessageTxFn, paddedCtxId, common.LeftPadBytes(cws.DestinationId().Bytes(), 32))
+		if err != nil {
+			v.logger.Warn("apply getMessageTx transaction failed", "error", err)
+			return cross.ErrInternal
+		}
+		if new(big.Int).SetBytes(res).Sign() == 0 { // error if messageTx is not existed in source-chain
+			return cross.ErrRepetitionCtx
+		}
+
+	} else if v.IsRemoteCtx(cws) {
+		res, err := evmInvoke.CallContract(common.Address{}, &v.contract, params.GetDeliveredFn, paddedCtxId, common.LeftPadBytes(cws.ChainId().Bytes(), 32))
+		if err != nil {
+			v.logger.Warn("apply getDeliveredTx transaction failed", "error", err)
+			return cross.ErrInternal
+		}
+		if new(big.Int).SetBytes(res).Sign() != 0 { // error if message is already delivered in destination-chain
+			return cross.ErrRepetitionCtx
+		}
+	}
+	return nil
+}
+
 func (v *SimpleValidator) UpdateAnchors(info *cc.RemoteChainInfo) error {
 	v.mu.Lock()
 	defer v.mu.Unlock()
@@ -0,0 +1,148 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package subscriber
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/core/types"
+	"github.com/simplechain-org/go-simplechain/log"
+)
+
+// newest returns the last tracked block, unconfirmed or confirmed
+func (s *SimpleSubscriber) newest() (index uint64, hash common.Hash, ok bool) {
+	if s.blocks != nil {
+		last := s.blocks.Prev().Value.(*unconfirmedBlockLog)
+		index, hash, ok = last.index, last.hash, true
+	}
+	if n := len(s.confirmed); n > 0 && (!ok || s.confirmed[n-1].index > index) {
+		index, hash, ok = s.confirmed[n-1].index, s.confirmed[n-1].hash, true
+	}
+	return index, hash, ok
+}
+
+func (s *SimpleSubscriber) isCanonical(index uint64, hash common.Hash) bool {
+	header := s.chain.GetHeaderByNumber(index)
+	return header != nil && header.Hash() == hash
+}
+
+// rewind drops all tracked blocks which are no longer canonical, returns the dropped
+// unconfirmed blocks and the dropped confirmed blocks from newer to older.
+func (s *SimpleSubscriber) rewind() (unconfirmed []*unconfirmedBlockLog, confirmed []confirmedBlock) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for n := s.blocks.Len(); n > 0; n-- {
+		next := s.blocks.Value.(*unconfirmedBlockLog)
+		if s.isCanonical(next.index, next.hash) {
+			s.blocks = s.blocks.Next()
+			continue
+		}
+		unconfirmed = append(unconfirmed, next)
+		s.drop()
+	}
+	// ancestors of a canonical block are canonical too
+	for n := len(s.confirmed); n > 0; n-- {
+		last := s.confirmed[n-1]
+		if s.isCanonical(last.index, last.hash) {
+			break
+		}
+		confirmed = append(confirmed, last)
+		s.confirmed = s.confirmed[:n-1]
+	}
+	return unconfirmed, confirmed
+}
+
+// checkReorg detects the reorgs which are not notified by the chain, e.g. the chain is
+// rewound by setHead, or reorged while the subscriber is not running, and replays them
+// as NotifyBlockReorg from the chain, so that the cross store is not out of sync.
+func (s *SimpleSubscriber) checkReorg(number uint64) {
+	s.lock.RLock()
+	index, hash, ok := s.newest()
+	s.lock.RUnlock()
+	if !ok || s.isCanonical(index, hash) {
+		return
+	}
+
+	unconfirmed, confirmed := s.rewind()
+	dropped := make([]confirmedBlock, 0, len(unconfirmed)+len(confirmed))
+	for _, block := range unconfirmed {
+		dropped = append(dropped, confirmedBlock{index: block.index, hash: block.hash, logs: block.all})
+	}
+	dropped = append(dropped, confirmed...)
+	if len(dropped) == 0 {
+		return
+	}
+	sort.Slice(dropped, func(i, j int) bool { return dropped[i].index > dropped[j].index })
+
+	// collect deleted logs from newer to older blocks as the chain does
+	var deletedLogs, rebirthLogs [][]*types.Log
+	for _, block := range dropped {
+		deleted := s.contractLogs(block.hash)
+		if deleted == nil {
+			deleted = block.logs // receipts are deleted by rewinding, use the tracked logs
+		}
+		if len(deleted) > 0 {
+			deletedLogs = append(deletedLogs, deleted)
+		}
+	}
+
+	// collect rebirth logs from the common ancestor to the parent of new block
+	from := dropped[len(dropped)-1].index
+	s.lock.RLock()
+	if index, _, ok := s.newest(); ok && index < from {
+		from = index + 1
+	}
+	s.lock.RUnlock()
+	for n := from; n < number; n++ {
+		if header := s.chain.GetHeaderByNumber(n); header != nil {
+			if rebirth := s.contractLogs(header.Hash()); len(rebirth) > 0 {
+				rebirthLogs = append(rebirthLogs, rebirth)
+			}
+		}
+	}
+
+	log.Warn("Replay cross reorg missed by the chain", "number", number, "from", from,
+		"dropped", len(dropped), "deleted", len(deletedLogs), "rebirth", len(rebirthLogs))
+	s.reorg(new(big.Int).SetUint64(number), deletedLogs, rebirthLogs, confirmed)
+}
+
+// contractLogs retrieves the cross contract logs of block from the chain
+func (s *SimpleSubscriber) contractLogs(hash common.Hash) (logs []*types.Log) {
+	receipts := s.chain.GetReceiptsByHash(hash)
+	if receipts == nil {
+		return nil
+	}
+	logs = make([]*types.Log, 0)
+	for _, receipt := range receipts {
+		for _, l := range receipt.Logs {
+			if l.Address == s.contract {
+				logs = append(logs, l)
+			}
+		}
+	}
+	return logs
+}
+
+func (s *SimpleSubscriber) chainID() uint64 {
+	if config := s.chain.GetChainConfig(); config != nil && config.ChainID != nil {
+		return config.ChainID.Uint64()
+	}
+	return 0
+}
@@ -28,7 +28,8 @@ import (
 
 	"github.com/simplechain-org/go-simplechain/core/types"
 	cc "github.com/simplechain-org/go-simplechain/cross/core"
-	"github.com/simplechain-org/go-simplechain/cross/trigger/simpletrigger"
+	cm "github.com/simplechain-org/go-simplechain/cross/metric"
+	"github.com/simplechain-org/go-simplechain/cross/trigger"
 )
 
 type SimpleSubscriber struct {
@@ -47,12 +48,12 @@ type SimpleSubscriber struct {
 	reorgHook    func(number *big.Int, deletedLogs, rebirthLogs [][]*types.Log)
 }
 
-func NewSimpleSubscriber(contract common.Address, chain chainRetriever, journalPath string) *SimpleSubscriber {
+func NewSimpleSubscriber(contract common.Address, chain chainRetriever, journalPath string, policy trigger.ConfirmPolicy) *SimpleSubscriber {
 	s := &SimpleSubscriber{
 		contract: contract,
 		unconfirmedBlockLogs: unconfirmedBlockLogs{
-			chain: chain,
-			depth: uint(simpletrigger.DefaultConfirmDepth),
+			chain:  chain,
+			policy: policy,
 		},
 		stop: make(chan struct{}),
 	}
@@ -111,19 +112,21 @@ func (s *SimpleSubscriber) Stop() {
 }
 
 func (s *SimpleSubscriber) StoreCrossContractLog(blockNumber uint64, hash common.Hash, logs []*types.Log) {
+	s.checkReorg(blockNumber)
+
 	var unconfirmedLogs []*types.Log
 	currentEvent := cc.CrossBlockEvent{Number: new(big.Int).SetUint64(blockNumber)}
 	if s.newLogHook != nil {
 		s.newLogHook(blockNumber, hash, logs, &unconfirmedLogs, &currentEvent)
 	}
 	if logs != nil {
-		var takers []*cc.ReceptTransaction
+		var takers, deliveries []*cc.ReceptTransaction
 		var finishes []*cc.CrossTransactionModifier
 		var updates []*cc.RemoteChainInfo
 		for _, v := range logs {
 			if s.contract == v.Address && len(v.Topics) > 0 {
 				switch v.Topics[0] {
-				case params.MakerTopic:
+				case params.MakerTopic, params.MessageTopic:
 					unconfirmedLogs = append(unconfirmedLogs, v)
 
 				case params.TakerTopic:
@@ -144,6 +147,17 @@ func (s *SimpleSubscriber) StoreCrossContractLog(blockNumber uint64, hash common
 						unconfirmedLogs = append(unconfirmedLogs, v)
 					}
 
+				case params.DeliveredTopic:
+					if len(v.Topics) >= 3 && len(v.Data) >= common.HashLength*3 {
+						var target common.Address
+						copy(target[:], v.Topics[2][common.HashLength-common.AddressLength:])
+						from := common.BytesToAddress(v.Data[common.HashLength*2-common.AddressLength : common.HashLength*2])
+						deliveries = append(deliveries, cc.NewReceptTransaction(v.Topics[1], v.TxHash, from, target,
+							common.BytesToHash(v.Data[:common.HashLength]).Big(), s.chain.GetChainConfig().ChainID))
+
+						unconfirmedLogs = append(unconfirmedLogs, v)
+					}
+
 				case params.MakerFinishTopic:
 					if len(v.Topics) >= 3 {
 						finishes = append(finishes, &cc.CrossTransactionModifier{
@@ -167,6 +181,7 @@ func (s *SimpleSubscriber) StoreCrossContractLog(blockNumber uint64, hash common
 		currentEvent.NewTaker.Takers = append(currentEvent.NewTaker.Takers, takers...)
 		currentEvent.NewFinish.Finishes = append(currentEvent.NewFinish.Finishes, finishes...)
 		currentEvent.NewAnchor.ChainInfo = append(currentEvent.NewAnchor.ChainInfo, updates...)
+		currentEvent.NewDelivery.Deliveries = append(currentEvent.NewDelivery.Deliveries, deliveries...)
 	}
 
 	s.insert(blockNumber, hash, unconfirmedLogs, &currentEvent)
@@ -176,14 +191,30 @@ func (s *SimpleSubscriber) StoreCrossContractLog(blockNumber uint64, hash common
 }
 
 func (s *SimpleSubscriber) NotifyBlockReorg(number *big.Int, deletedLogs [][]*types.Log, rebirthLogs [][]*types.Log) {
+	// drop side blocks, blocks which were confirmed already mean a reorg deeper than the confirm window
+	_, confirmed := s.rewind()
+	s.reorg(number, deletedLogs, rebirthLogs, confirmed)
+}
+
+func (s *SimpleSubscriber) reorg(number *big.Int, deletedLogs [][]*types.Log, rebirthLogs [][]*types.Log, confirmed []confirmedBlock) {
 	var reorgEvent cc.CrossBlockEvent
 	if s.reorgHook != nil {
 		s.reorgHook(number, deletedLogs, rebirthLogs)
 	}
+	reorged := make(map[common.Hash]struct{}, len(confirmed))
+	for _, block := range confirmed {
+		reorged[block.hash] = struct{}{}
+	}
+	var makers []common.Hash // confirmed makers which are reorged
 	for _, deletedLog := range deletedLogs {
 		for _, l := range deletedLog {
 			if s.contract == l.Address && len(l.Topics) > 0 {
 				switch l.Topics[0] {
+				case params.MakerTopic, params.MessageTopic:
+					if _, ok := reorged[l.BlockHash]; ok && len(l.Topics) >= 2 {
+						makers = append(makers, l.Topics[1])
+					}
+
 				case params.TakerTopic: // reorg executing -> waiting
 					if len(l.Topics) >= 3 && len(l.Data) >= common.HashLength {
 						ctxId := l.Topics[1]
@@ -194,6 +225,15 @@ func (s *SimpleSubscriber) NotifyBlockReorg(number *big.Int, deletedLogs [][]*ty
 							common.BytesToHash(l.Data[:common.HashLength]).Big(), s.chain.GetChainConfig().ChainID))
 					}
 
+				case params.DeliveredTopic: // reorg message executing -> waiting
+					if len(l.Topics) >= 3 && len(l.Data) >= common.HashLength*3 {
+						var target common.Address
+						copy(target[:], l.Topics[2][common.HashLength-common.AddressLength:])
+						from := common.BytesToAddress(l.Data[common.HashLength*2-common.AddressLength : common.HashLength*2])
+						reorgEvent.ReorgDelivery.Deliveries = append(reorgEvent.ReorgDelivery.Deliveries, cc.NewReceptTransaction(l.Topics[1], l.TxHash, from, target,
+							common.BytesToHash(l.Data[:common.HashLength]).Big(), s.chain.GetChainConfig().ChainID))
+					}
+
 				case params.MakerFinishTopic: // reorg executing finishing -> executed
 					if len(l.Topics) >= 3 {
 						reorgEvent.ReorgFinish.Finishes = append(reorgEvent.ReorgFinish.Finishes, &cc.CrossTransactionModifier{
@@ -206,6 +246,13 @@ func (s *SimpleSubscriber) NotifyBlockReorg(number *big.Int, deletedLogs [][]*ty
 			}
 		}
 	}
+	if len(confirmed) > 0 {
+		// confirmed makers may be signed or taken already, they could not be rolled back and need manual check
+		log.Error("Cross reorg deeper than confirm window", "number", number, "from", confirmed[len(confirmed)-1].index,
+			"blocks", len(confirmed), "makers", len(makers))
+		cm.Report(s.chainID(), "reorg deeper than confirm window", "number", number,
+			"from", confirmed[len(confirmed)-1].index, "blocks", len(confirmed), "makers", makers)
+	}
 	if !reorgEvent.IsEmpty() {
 		reorgEvent.Number = new(big.Int).Set(number)
 		s.crossBlockSend(reorgEvent)
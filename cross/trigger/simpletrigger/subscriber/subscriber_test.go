@@ -17,6 +17,7 @@
 package subscriber
 
 import (
+	"crypto/ecdsa"
 	"math/big"
 	"testing"
 
@@ -27,7 +28,9 @@ import (
 	"github.com/simplechain-org/go-simplechain/core/types"
 	"github.com/simplechain-org/go-simplechain/core/vm"
 	cc "github.com/simplechain-org/go-simplechain/cross/core"
+	"github.com/simplechain-org/go-simplechain/cross/trigger"
 	"github.com/simplechain-org/go-simplechain/crypto"
+	"github.com/simplechain-org/go-simplechain/ethdb"
 	"github.com/simplechain-org/go-simplechain/params"
 
 	"github.com/stretchr/testify/assert"
@@ -57,8 +60,7 @@ func TestSimpleSubscriber_NotifyBlockReorg(t *testing.T) {
 	blockchain, _ := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
 	defer blockchain.Stop()
 
-	subscriber := NewSimpleSubscriber(common.Address{}, blockchain, "")
-	subscriber.depth = 4
+	subscriber := NewSimpleSubscriber(common.Address{}, blockchain, "", trigger.NewConfirmPolicy(gspec.Config.ChainID.Uint64(), 4))
 
 	chain, _ := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), db, 4, func(i int, gen *core.BlockGen) {
 		if i == 1 {
@@ -149,3 +151,164 @@ func TestSimpleSubscriber_NotifyBlockReorg(t *testing.T) {
 	assert.Equal(t, 0, len(shifts[3]))
 	assert.Equal(t, 1, len(shifts[4]))
 }
+
+func TestSimpleSubscriber_ConfirmPolicy(t *testing.T) {
+	makerLog := func(number uint64, value int64) *types.Log {
+		data := make([]byte, common.HashLength*3)
+		copy(data[common.HashLength*2:], common.BigToHash(big.NewInt(value)).Bytes())
+		return &types.Log{Topics: []common.Hash{params.MakerTopic}, Data: data, BlockNumber: number}
+	}
+
+	policy := trigger.ConfirmPolicy{Depth: 2, Tiers: []trigger.ConfirmTier{{Value: big.NewInt(100), Depth: 5}}}
+	assert.Equal(t, uint64(2), policy.DepthOf(big.NewInt(99)))
+	assert.Equal(t, uint64(5), policy.DepthOf(big.NewInt(100)))
+	assert.Equal(t, uint64(5), policy.MaxDepth())
+
+	run := func(policy trigger.ConfirmPolicy) map[uint64]int {
+		chain := newNoopChainRetriever()
+		subscriber := NewSimpleSubscriber(common.Address{}, chain, "", policy)
+		defer subscriber.Stop()
+
+		var height uint64
+		confirmed := make(map[uint64]int) // height => number of confirmed logs
+		subscriber.shiftLogHook = func(number uint64, hash common.Hash, confirmedLogs []*types.Log) {
+			confirmed[height] += len(confirmedLogs)
+		}
+		for height = 1; height <= 10; height++ {
+			header := &types.Header{Number: new(big.Int).SetUint64(height)}
+			chain.insert(header)
+			var logs []*types.Log
+			if height == 1 {
+				logs = []*types.Log{makerLog(height, 10), makerLog(height, 1000)}
+			}
+			subscriber.insert(height, header.Hash(), logs, nil)
+		}
+		subscriber.blocks.Do(func(block interface{}) {
+			assert.Empty(t, block.(*unconfirmedBlockLog).logs)
+		})
+		return confirmed
+	}
+
+	// small maker is confirmed by 2 blocks, large maker is confirmed by 5 blocks
+	confirmed := run(policy)
+	assert.Equal(t, 1, confirmed[3])
+	assert.Equal(t, 1, confirmed[6])
+
+	// logs are confirmed at once if the chain needs no confirmation
+	confirmed = run(trigger.NewConfirmPolicy(0, 0))
+	assert.Equal(t, 2, confirmed[1])
+}
+
+type reorgTestEnv struct {
+	key1, key2   *ecdsa.PrivateKey
+	addr1, addr2 common.Address
+	contract     common.Address
+	db           ethdb.Database
+	gspec        *core.Genesis
+	genesis      *types.Block
+	blockchain   *core.BlockChain
+}
+
+func newReorgTestEnv(t *testing.T) *reorgTestEnv {
+	env := new(reorgTestEnv)
+	env.key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	env.addr1 = crypto.PubkeyToAddress(env.key1.PublicKey)
+	env.key2, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+	env.addr2 = crypto.PubkeyToAddress(env.key2.PublicKey)
+	env.contract = crypto.CreateAddress(env.addr1, 0) // the contract emits logs when it is created
+	env.db = rawdb.NewMemoryDatabase()
+	env.gspec = &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: core.GenesisAlloc{
+			env.addr1: {Balance: big.NewInt(10000000000000)},
+			env.addr2: {Balance: big.NewInt(10000000000000)},
+		},
+	}
+	env.genesis = env.gspec.MustCommit(env.db)
+	blockchain, err := core.NewBlockChain(env.db, nil, env.gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	env.blockchain = blockchain
+	return env
+}
+
+// generate generates n blocks mined by coinbase, and creates the log contract at block logAt
+func (env *reorgTestEnv) generate(t *testing.T, n int, coinbase common.Address, logAt int) []*types.Block {
+	code := common.Hex2Bytes("60606040525b7f24ec1d3ff24c2f6ff210738839dbc339cd45a5294d85c79361016243157aae7b60405180905060405180910390a15b600a8060416000396000f360606040526008565b00")
+	signer := types.NewEIP155Signer(env.gspec.Config.ChainID)
+	blocks, _ := core.GenerateChain(env.gspec.Config, env.genesis, ethash.NewFaker(), env.db, n, func(i int, gen *core.BlockGen) {
+		gen.SetCoinbase(coinbase)
+		if i+1 == logAt {
+			tx, err := types.SignTx(types.NewContractCreation(gen.TxNonce(env.addr1), new(big.Int), 1000000, new(big.Int), code), signer, env.key1)
+			if err != nil {
+				t.Fatalf("failed to create tx: %v", err)
+			}
+			gen.AddTx(tx)
+		}
+	})
+	return blocks
+}
+
+func (env *reorgTestEnv) subscribe(depth uint64) (*SimpleSubscriber, *[]*types.Log) {
+	subscriber := NewSimpleSubscriber(env.contract, env.blockchain, "", trigger.NewConfirmPolicy(env.gspec.Config.ChainID.Uint64(), depth))
+	subscriber.newLogHook = func(number uint64, hash common.Hash, logs []*types.Log, unconfirmedLogs *[]*types.Log, current *cc.CrossBlockEvent) {
+		*unconfirmedLogs = append(*unconfirmedLogs, logs...)
+	}
+	deletes := make([]*types.Log, 0)
+	subscriber.reorgHook = func(number *big.Int, deletedLogs, rebirthLogs [][]*types.Log) {
+		for _, logs := range deletedLogs {
+			deletes = append(deletes, logs...)
+		}
+	}
+	return subscriber, &deletes
+}
+
+func (env *reorgTestEnv) assertCanonical(t *testing.T, subscriber *SimpleSubscriber) {
+	for _, block := range subscriber.confirmed {
+		assert.Equal(t, env.blockchain.GetHeaderByNumber(block.index).Hash(), block.hash)
+	}
+}
+
+// Tests that a chain reorg deeper than the unconfirmed ring drops the confirmed blocks
+func TestSimpleSubscriber_DeepReorg(t *testing.T) {
+	env := newReorgTestEnv(t)
+	defer env.blockchain.Stop()
+	subscriber, deletes := env.subscribe(1)
+
+	if _, err := env.blockchain.InsertChain(env.generate(t, 4, env.addr1, 2)); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	assert.Equal(t, 3, len(subscriber.confirmed))
+
+	// a longer fork replaces the confirmed blocks
+	if _, err := env.blockchain.InsertChain(env.generate(t, 6, env.addr2, 3)); err != nil {
+		t.Fatalf("failed to insert forked chain: %v", err)
+	}
+	assert.Equal(t, 1, len(*deletes))
+	assert.Equal(t, uint64(2), (*deletes)[0].BlockNumber)
+	env.assertCanonical(t, subscriber)
+}
+
+// Tests that a rewound chain which is not notified by the chain is replayed by the subscriber
+func TestSimpleSubscriber_RewindReorg(t *testing.T) {
+	env := newReorgTestEnv(t)
+	defer env.blockchain.Stop()
+	subscriber, deletes := env.subscribe(1)
+
+	if _, err := env.blockchain.InsertChain(env.generate(t, 4, env.addr1, 2)); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if err := env.blockchain.SetHead(0); err != nil {
+		t.Fatalf("failed to rewind chain: %v", err)
+	}
+	assert.Equal(t, 0, len(*deletes))
+
+	if _, err := env.blockchain.InsertChain(env.generate(t, 4, env.addr2, 3)); err != nil {
+		t.Fatalf("failed to insert rewound chain: %v", err)
+	}
+	assert.Equal(t, 1, len(*deletes))
+	assert.Equal(t, uint64(2), (*deletes)[0].BlockNumber)
+	env.assertCanonical(t, subscriber)
+	assert.Equal(t, 3, len(subscriber.confirmed))
+}
@@ -19,6 +19,7 @@ package subscriber
 import (
 	"container/ring"
 	"math/big"
+	"sort"
 	"sync"
 
 	"github.com/simplechain-org/go-simplechain/common"
@@ -33,32 +34,52 @@ import (
 type chainRetriever interface {
 	GetHeaderByNumber(number uint64) *types.Header
 	GetTransactionByTxHash(hash common.Hash) (*types.Transaction, common.Hash, uint64)
+	GetReceiptsByHash(hash common.Hash) types.Receipts
 	GetChainConfig() *params.ChainConfig
 	SetCrossSubscriber(s trigger.Subscriber)
 }
 
+// confirmedHistory is the number of confirmed blocks kept to detect reorgs deeper than the unconfirmed ring
+const confirmedHistory = 1024
+
 // unconfirmedBlockLog is a small collection of metadata about a locally mined block
 // that is placed into a trigger set for canonical chain inclusion tracking.
 type unconfirmedBlockLog struct {
+	index  uint64
+	hash   common.Hash
+	logs   []*types.Log // unconfirmed logs
+	depths []uint64     // confirm depth of every unconfirmed log
+	all    []*types.Log // all logs of block, kept to replay reorgs
+}
+
+// confirmedBlock is a block shifted out of the unconfirmed ring after all its logs are confirmed
+type confirmedBlock struct {
 	index uint64
 	hash  common.Hash
 	logs  []*types.Log
 }
 
 type unconfirmedBlockLogs struct {
-	chain  chainRetriever // Blockchain to verify canonical status through
-	depth  uint           // Depth after which to discard previous blocks
-	blocks *ring.Ring     // Block infos to allow canonical chain cross checks
-	lock   sync.RWMutex   // Protects the fields from concurrent access
+	chain     chainRetriever        // Blockchain to verify canonical status through
+	policy    trigger.ConfirmPolicy // Policy deciding the confirm depth of every log
+	blocks    *ring.Ring            // Block infos to allow canonical chain cross checks
+	confirmed []confirmedBlock      // Confirmed blocks ordered by number, to detect deep reorgs
+	lock      sync.RWMutex          // Protects the fields from concurrent access
 }
 
 func (s *SimpleSubscriber) add(index uint64, hash common.Hash, blockLogs []*types.Log) {
+	depths := make([]uint64, len(blockLogs))
+	for i, l := range blockLogs {
+		depths[i] = s.policy.DepthOf(logValue(l))
+	}
 	// Create the new item as its own ring
 	item := ring.New(1)
 	item.Value = &unconfirmedBlockLog{
-		index: index,
-		hash:  hash,
-		logs:  blockLogs,
+		index:  index,
+		hash:   hash,
+		logs:   blockLogs,
+		depths: depths,
+		all:    blockLogs,
 	}
 	// Set as the initial ring or append to the end
 	s.lock.Lock()
@@ -73,6 +94,29 @@ func (s *SimpleSubscriber) add(index uint64, hash common.Hash, blockLogs []*type
 	}
 }
 
+// logValue returns the ctx value of maker and taker logs, zero for the others
+func logValue(l *types.Log) *big.Int {
+	if len(l.Topics) > 0 && (l.Topics[0] == params.MakerTopic || l.Topics[0] == params.TakerTopic) &&
+		len(l.Data) >= common.HashLength*3 {
+		return common.BytesToHash(l.Data[common.HashLength*2 : common.HashLength*3]).Big()
+	}
+	return new(big.Int)
+}
+
+// minDepth returns the depth after which the first log of block is confirmed
+func (s *SimpleSubscriber) minDepth(block *unconfirmedBlockLog) uint64 {
+	if len(block.depths) == 0 {
+		return s.policy.Depth
+	}
+	depth := block.depths[0]
+	for _, d := range block.depths[1:] {
+		if d < depth {
+			depth = d
+		}
+	}
+	return depth
+}
+
 func (s *SimpleSubscriber) journalLog(blockLogs []*types.Log) {
 	if s.journal == nil {
 		return
@@ -86,114 +130,183 @@ func (s *SimpleSubscriber) journalLog(blockLogs []*types.Log) {
 
 // Insert adds a new block to the set of trigger ones.
 func (s *SimpleSubscriber) insert(index uint64, hash common.Hash, blockLogs []*types.Log, currentEvent *cc.CrossBlockEvent) {
-	// If a new block was mined locally, shift out any old enough blocks
-	s.shift(index, currentEvent)
-
 	// add unconfirmedBlockLog into unconfirmedBlockLogs
 	s.add(index, hash, blockLogs)
+
+	// If a new block was mined locally, shift out any old enough logs,
+	// logs of the new block are shifted at once if they need no confirmation
+	s.shift(index, currentEvent)
 }
 
-// Shift drops all trigger blocks from the set which exceed the trigger sets depth
-// allowance, checking them against the canonical chain for inclusion or staleness
-// report.
+// Shift confirms all trigger logs in the set which exceed their depth allowance,
+// checking them against the canonical chain for inclusion or staleness report,
+// blocks are dropped after all their logs are confirmed.
 func (s *SimpleSubscriber) shift(height uint64, currentEvent *cc.CrossBlockEvent) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-loop:
-	for s.blocks != nil {
-		// Retrieve the next trigger block and abort if too fresh
+	events := make(map[uint64]*cc.CrossBlockEvent)
+	for n := s.blocks.Len(); n > 0; n-- {
 		next := s.blocks.Value.(*unconfirmedBlockLog)
+		if next.index+s.minDepth(next) > height { // not confirmed yet
+			s.blocks = s.blocks.Next()
+			continue
+		}
 		// Block seems to exceed depth allowance, check for canonical status
 		header := s.chain.GetHeaderByNumber(next.index)
 		switch {
 		case header == nil:
 			log.Warn("Failed to retrieve header of mined block", "number", next.index, "hash", next.hash)
+			s.drop()
+			continue
 
 		case header.Hash() != next.hash:
 			log.Info("⑂ block became a side fork", "number", next.index, "hash", next.hash)
+			s.drop()
+			continue
+		}
 
-		case next.index+uint64(s.depth) > height: // not confirmed yet
-			break loop
-
-		default:
-			if s.shiftLogHook != nil {
-				s.shiftLogHook(next.index, next.hash, next.logs)
+		var remains, confirmed []*types.Log
+		var depths []uint64
+		for i, v := range next.logs {
+			if depth := next.depths[i]; next.index+depth > height {
+				remains, depths = append(remains, v), append(depths, depth)
+				continue
 			}
-			if next.logs != nil {
-				var ctxs []*cc.CrossTransaction
-				var rtxs []*cc.ReceptTransaction
-				var finishModifiers []*cc.CrossTransactionModifier
-				for _, v := range next.logs {
-					tx, blockHash, blockNumber := s.chain.GetTransactionByTxHash(v.TxHash)
-					if tx != nil && blockHash == v.BlockHash && blockNumber == v.BlockNumber &&
-						s.contract == v.Address && len(v.Topics) >= 3 {
-
-						switch {
-						case params.MakerTopic == v.Topics[0] && len(v.Data) >= common.HashLength*6:
-							var from common.Address
-							var to common.Address
-							copy(from[:], v.Topics[2][common.HashLength-common.AddressLength:])
-							copy(to[:], v.Data[common.HashLength-common.AddressLength:common.HashLength])
-							ctxId := v.Topics[1]
-							count := common.BytesToHash(v.Data[common.HashLength*5 : common.HashLength*6]).Big().Int64()
-							ctxs = append(ctxs,
-								cc.NewCrossTransaction(
-									common.BytesToHash(v.Data[common.HashLength*2:common.HashLength*3]).Big(),
-									common.BytesToHash(v.Data[common.HashLength*3:common.HashLength*4]).Big(),
-									common.BytesToHash(v.Data[common.HashLength:common.HashLength*2]).Big(),
-									ctxId,
-									v.TxHash,
-									v.BlockHash,
-									from,
-									to,
-									v.Data[common.HashLength*6:common.HashLength*6+count]))
-
-						case params.TakerTopic == v.Topics[0] && len(v.Data) >= common.HashLength*4:
-							var to, from common.Address
-							copy(to[:], v.Topics[2][common.HashLength-common.AddressLength:])
-							from = common.BytesToAddress(v.Data[common.HashLength*2-common.AddressLength : common.HashLength*2])
-							ctxId := v.Topics[1]
-							rtxs = append(rtxs, cc.NewReceptTransaction(ctxId, v.TxHash, from, to,
-								common.BytesToHash(v.Data[:common.HashLength]).Big(), s.chain.GetChainConfig().ChainID))
-
-						case params.MakerFinishTopic == v.Topics[0]:
-							finishModifiers = append(finishModifiers, &cc.CrossTransactionModifier{
-								ID:            v.Topics[1],
-								AtBlockNumber: v.BlockNumber + uint64(s.depth),
-								Status:        cc.CtxStatusFinished,
-							})
-						}
-					}
-				}
-
-				confirmNumber := header.Number.Uint64() + uint64(s.depth) // make a confirmed number
-
-				// add confirmed logs into current block event
-				if currentEvent != nil && currentEvent.Number.Uint64() == confirmNumber {
-					log.Debug("combine currentEvent and confirmedEvent", "number", confirmNumber)
-					currentEvent.ConfirmedMaker.Txs = append(currentEvent.ConfirmedMaker.Txs, ctxs...)
-					currentEvent.ConfirmedTaker.Txs = append(currentEvent.ConfirmedTaker.Txs, rtxs...)
-					currentEvent.ConfirmedFinish.Finishes = append(currentEvent.ConfirmedFinish.Finishes, finishModifiers...)
-
-				} else if len(ctxs)|len(rtxs)|len(finishModifiers) > 0 {
-					s.crossBlockSend(cc.CrossBlockEvent{
-						Number:          new(big.Int).SetUint64(confirmNumber),
-						ConfirmedMaker:  cc.ConfirmedMakerEvent{Txs: ctxs},
-						ConfirmedTaker:  cc.ConfirmedTakerEvent{Txs: rtxs},
-						ConfirmedFinish: cc.ConfirmedFinishEvent{Finishes: finishModifiers},
-					})
-				}
+			confirmed = append(confirmed, v)
+			confirmNumber := next.index + next.depths[i] // make a confirmed number
+			if events[confirmNumber] == nil {
+				events[confirmNumber] = &cc.CrossBlockEvent{Number: new(big.Int).SetUint64(confirmNumber)}
 			}
+			s.confirmLog(v, next.depths[i], events[confirmNumber])
+		}
+		if s.shiftLogHook != nil {
+			s.shiftLogHook(next.index, next.hash, confirmed)
+		}
+		if len(remains) > 0 {
+			next.logs, next.depths = remains, depths
+			s.blocks = s.blocks.Next()
+			continue
 		}
-		// Drop the block out of the ring
-		if s.blocks.Value == s.blocks.Next().Value {
-			s.blocks = nil
-		} else {
-			s.blocks = s.blocks.Move(-1)
-			s.blocks.Unlink(1)
-			s.blocks = s.blocks.Move(1)
+		s.record(next.index, next.hash, next.all)
+		s.drop()
+	}
+
+	numbers := make([]uint64, 0, len(events))
+	for number := range events {
+		numbers = append(numbers, number)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+	for _, number := range numbers {
+		ev := events[number]
+		// add confirmed logs into current block event
+		if currentEvent != nil && currentEvent.Number.Uint64() == number {
+			log.Debug("combine currentEvent and confirmedEvent", "number", number)
+			currentEvent.ConfirmedMaker.Txs = append(currentEvent.ConfirmedMaker.Txs, ev.ConfirmedMaker.Txs...)
+			currentEvent.ConfirmedTaker.Txs = append(currentEvent.ConfirmedTaker.Txs, ev.ConfirmedTaker.Txs...)
+			currentEvent.ConfirmedFinish.Finishes = append(currentEvent.ConfirmedFinish.Finishes, ev.ConfirmedFinish.Finishes...)
+			currentEvent.ConfirmedDelivery.Deliveries = append(currentEvent.ConfirmedDelivery.Deliveries, ev.ConfirmedDelivery.Deliveries...)
+
+		} else if !ev.IsEmpty() {
+			s.crossBlockSend(*ev)
 		}
 	}
+}
+
+// drop removes the current block out of the ring, and moves to the next one
+func (s *SimpleSubscriber) drop() {
+	if s.blocks.Value == s.blocks.Next().Value {
+		s.blocks = nil
+	} else {
+		s.blocks = s.blocks.Move(-1)
+		s.blocks.Unlink(1)
+		s.blocks = s.blocks.Move(1)
+	}
+}
+
+// record keeps the confirmed block in history ordered by number
+func (s *SimpleSubscriber) record(index uint64, hash common.Hash, logs []*types.Log) {
+	i := sort.Search(len(s.confirmed), func(i int) bool { return s.confirmed[i].index >= index })
+	if i < len(s.confirmed) && s.confirmed[i].index == index {
+		s.confirmed[i] = confirmedBlock{index: index, hash: hash, logs: logs}
+		return
+	}
+	s.confirmed = append(s.confirmed, confirmedBlock{})
+	copy(s.confirmed[i+1:], s.confirmed[i:])
+	s.confirmed[i] = confirmedBlock{index: index, hash: hash, logs: logs}
 
+	if len(s.confirmed) > confirmedHistory {
+		s.confirmed = s.confirmed[len(s.confirmed)-confirmedHistory:]
+	}
+}
+
+// confirmLog parses a confirmed log into the confirmed block event
+func (s *SimpleSubscriber) confirmLog(v *types.Log, depth uint64, ev *cc.CrossBlockEvent) {
+	tx, blockHash, blockNumber := s.chain.GetTransactionByTxHash(v.TxHash)
+	if tx == nil || blockHash != v.BlockHash || blockNumber != v.BlockNumber ||
+		s.contract != v.Address || len(v.Topics) < 3 {
+		return
+	}
+
+	switch {
+	case params.MakerTopic == v.Topics[0] && len(v.Data) >= common.HashLength*6:
+		var from common.Address
+		var to common.Address
+		copy(from[:], v.Topics[2][common.HashLength-common.AddressLength:])
+		copy(to[:], v.Data[common.HashLength-common.AddressLength:common.HashLength])
+		ctxId := v.Topics[1]
+		count := common.BytesToHash(v.Data[common.HashLength*5 : common.HashLength*6]).Big().Int64()
+		ev.ConfirmedMaker.Txs = append(ev.ConfirmedMaker.Txs,
+			cc.NewCrossTransaction(
+				common.BytesToHash(v.Data[common.HashLength*2:common.HashLength*3]).Big(),
+				common.BytesToHash(v.Data[common.HashLength*3:common.HashLength*4]).Big(),
+				common.BytesToHash(v.Data[common.HashLength:common.HashLength*2]).Big(),
+				ctxId,
+				v.TxHash,
+				v.BlockHash,
+				from,
+				to,
+				v.Data[common.HashLength*6:common.HashLength*6+count]))
+
+	case params.MessageTopic == v.Topics[0] && len(v.Data) >= common.HashLength*4:
+		var from, target common.Address
+		copy(from[:], v.Topics[2][common.HashLength-common.AddressLength:])
+		copy(target[:], v.Data[common.HashLength-common.AddressLength:common.HashLength])
+		count := common.BytesToHash(v.Data[common.HashLength*3 : common.HashLength*4]).Big().Int64()
+		if int64(len(v.Data)) < common.HashLength*4+count {
+			break
+		}
+		ev.ConfirmedMaker.Txs = append(ev.ConfirmedMaker.Txs,
+			cc.NewCrossMessage(
+				common.BytesToHash(v.Data[common.HashLength:common.HashLength*2]).Big(),
+				v.Topics[1],
+				v.TxHash,
+				v.BlockHash,
+				from,
+				target,
+				v.Data[common.HashLength*4:common.HashLength*4+count]))
+
+	case params.DeliveredTopic == v.Topics[0] && len(v.Data) >= common.HashLength*3:
+		var target common.Address
+		copy(target[:], v.Topics[2][common.HashLength-common.AddressLength:])
+		from := common.BytesToAddress(v.Data[common.HashLength*2-common.AddressLength : common.HashLength*2])
+		ev.ConfirmedDelivery.Deliveries = append(ev.ConfirmedDelivery.Deliveries, cc.NewReceptTransaction(v.Topics[1], v.TxHash, from, target,
+			common.BytesToHash(v.Data[:common.HashLength]).Big(), s.chain.GetChainConfig().ChainID))
+
+	case params.TakerTopic == v.Topics[0] && len(v.Data) >= common.HashLength*4:
+		var to, from common.Address
+		copy(to[:], v.Topics[2][common.HashLength-common.AddressLength:])
+		from = common.BytesToAddress(v.Data[common.HashLength*2-common.AddressLength : common.HashLength*2])
+		ctxId := v.Topics[1]
+		ev.ConfirmedTaker.Txs = append(ev.ConfirmedTaker.Txs, cc.NewReceptTransaction(ctxId, v.TxHash, from, to,
+			common.BytesToHash(v.Data[:common.HashLength]).Big(), s.chain.GetChainConfig().ChainID))
+
+	case params.MakerFinishTopic == v.Topics[0]:
+		ev.ConfirmedFinish.Finishes = append(ev.ConfirmedFinish.Finishes, &cc.CrossTransactionModifier{
+			ID:            v.Topics[1],
+			AtBlockNumber: v.BlockNumber + depth,
+			Status:        cc.CtxStatusFinished,
+		})
+	}
 }
@@ -46,7 +46,8 @@ func (r *noopChainRetriever) GetHeaderByNumber(number uint64) *types.Header { re
 func (r *noopChainRetriever) GetTransactionByTxHash(hash common.Hash) (*types.Transaction, common.Hash, uint64) {
 	return nil, common.Hash{}, 0
 }
-func (r *noopChainRetriever) GetChainConfig() *params.ChainConfig { return nil }
+func (r *noopChainRetriever) GetReceiptsByHash(hash common.Hash) types.Receipts { return nil }
+func (r *noopChainRetriever) GetChainConfig() *params.ChainConfig               { return nil }
 
 // Tests that inserting blocks into the unconfirmed set accumulates them until
 // the desired depth is reached, after which they begin to be dropped.
@@ -54,7 +55,7 @@ func TestUnconfirmedInsertBounds(t *testing.T) {
 	simpletrigger.DefaultConfirmDepth = 12
 	limit := simpletrigger.DefaultConfirmDepth
 
-	pool := NewSimpleSubscriber(common.Address{}, newNoopChainRetriever(), "")
+	pool := NewSimpleSubscriber(common.Address{}, newNoopChainRetriever(), "", trigger.NewConfirmPolicy(0, uint64(limit)))
 	for depth := uint64(0); depth < 2*uint64(limit); depth++ {
 		// Insert multiple blocks for the same level just to stress it
 		for i := 0; i < int(depth); i++ {
@@ -78,7 +79,7 @@ func TestUnconfirmedShifts(t *testing.T) {
 	limit, start := uint(12), uint64(25)
 
 	chain := newNoopChainRetriever()
-	pool := NewSimpleSubscriber(common.Address{}, chain, "")
+	pool := NewSimpleSubscriber(common.Address{}, chain, "", trigger.NewConfirmPolicy(0, uint64(limit)))
 	for depth := start; depth < start+uint64(limit); depth++ {
 		header := types.Header{
 			ParentHash: [32]byte{byte(depth)},
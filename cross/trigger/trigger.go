@@ -34,6 +34,7 @@ type Subscriber interface {
 type Executor interface {
 	SignHash([]byte) ([]byte, error)
 	SubmitTransaction([]*core.ReceptTransaction)
+	DeliverMessages([]*core.CrossTransactionWithSignatures)
 	Start()
 	Stop()
 }
@@ -55,6 +56,7 @@ type Transaction interface {
 	DestinationId() *big.Int
 	BlockHash() common.Hash
 	From() common.Address
+	IsMessage() bool
 }
 
 // ChainRetriever include Validator and provides blockchain retriever
@@ -0,0 +1,189 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package addrindex
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/common/hexutil"
+	"github.com/simplechain-org/go-simplechain/core"
+	"github.com/simplechain-org/go-simplechain/core/rawdb"
+	"github.com/simplechain-org/go-simplechain/ethdb"
+	"github.com/simplechain-org/go-simplechain/log"
+	"github.com/simplechain-org/go-simplechain/rlp"
+)
+
+const (
+	defaultLimit = 100  // Default number of transactions returned in a page
+	maxLimit     = 1000 // Maximum number of transactions returned in a page
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// AddressQuery holds the optional arguments of an address activity query.
+type AddressQuery struct {
+	FromBlock *hexutil.Uint64 `json:"fromBlock"` // First block of the range, genesis if nil
+	ToBlock   *hexutil.Uint64 `json:"toBlock"`   // Last block of the range, last indexed block if nil
+	Limit     *hexutil.Uint   `json:"limit"`     // Maximum number of transactions in the page
+	Cursor    hexutil.Bytes   `json:"cursor"`    // Cursor returned by the previous page
+}
+
+// AddressTransaction is a transaction touching the queried address.
+type AddressTransaction struct {
+	BlockHash        common.Hash    `json:"blockHash"`
+	BlockNumber      hexutil.Uint64 `json:"blockNumber"`
+	TransactionHash  common.Hash    `json:"transactionHash"`
+	TransactionIndex hexutil.Uint   `json:"transactionIndex"`
+	Roles            []string       `json:"roles"`
+}
+
+// AddressTransactions is a page of the transactions touching an address, ordered
+// by block number and transaction index.
+type AddressTransactions struct {
+	Transactions []*AddressTransaction `json:"transactions"`
+	Cursor       hexutil.Bytes         `json:"cursor,omitempty"` // Cursor of the next page, empty if it's the last one
+	IndexedBlock *hexutil.Uint64       `json:"indexedBlock"`     // Last block covered by the index, nil if none yet
+
+	// Blocks of the page range indexed without their internal transfers, whose
+	// transactions may be missing from the page
+	IncompleteBlocks []hexutil.Uint64 `json:"incompleteBlocks,omitempty"`
+}
+
+// PublicAddressIndexAPI provides the queries of the transactions touching an
+// address, served by the address index.
+type PublicAddressIndexAPI struct {
+	db      ethdb.Database
+	indexer *core.ChainIndexer
+}
+
+// NewPublicAddressIndexAPI creates a new API serving the address index built by
+// the given indexer.
+func NewPublicAddressIndexAPI(db ethdb.Database, indexer *core.ChainIndexer) *PublicAddressIndexAPI {
+	return &PublicAddressIndexAPI{db: db, indexer: indexer}
+}
+
+// GetTransactionsByAddress returns a page of the transactions sending to, received
+// by, creating or transferring value internally to or from the given address.
+// Only canonical blocks covered by the index are included.
+func (api *PublicAddressIndexAPI) GetTransactionsByAddress(address common.Address, query *AddressQuery) (*AddressTransactions, error) {
+	if query == nil {
+		query = new(AddressQuery)
+	}
+	limit := defaultLimit
+	if query.Limit != nil {
+		limit = int(*query.Limit)
+	}
+	if limit <= 0 || limit > maxLimit {
+		return nil, fmt.Errorf("limit must be in range [1, %d]", maxLimit)
+	}
+	result := &AddressTransactions{Transactions: []*AddressTransaction{}}
+
+	sections, _, _ := api.indexer.Sections()
+	if sections == 0 {
+		return result, nil
+	}
+	last := sections*sectionSize - 1
+	result.IndexedBlock = (*hexutil.Uint64)(&last)
+	if query.ToBlock != nil && uint64(*query.ToBlock) < last {
+		last = uint64(*query.ToBlock)
+	}
+	// Iterate the entries of the address from the cursor or the first block
+	prefix := entryKey(address, 0, 0)[:len(entryPrefix)+common.AddressLength]
+	start := entryKey(address, 0, 0)
+	switch {
+	case len(query.Cursor) > 0:
+		if len(query.Cursor) != 12 {
+			return nil, errInvalidCursor
+		}
+		copy(start[len(prefix):], query.Cursor)
+	case query.FromBlock != nil:
+		start = entryKey(address, uint64(*query.FromBlock), 0)
+	}
+	first, _ := entryPosition(start)
+
+	it := api.db.NewIteratorWithStart(start)
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if !bytes.HasPrefix(key, prefix) {
+			break
+		}
+		number, index := entryPosition(key)
+		if number > last {
+			break
+		}
+		var e entry
+		if err := rlp.DecodeBytes(it.Value(), &e); err != nil {
+			log.Error("Invalid address index entry", "address", address, "number", number, "err", err)
+			continue
+		}
+		// Skip the entries of reorged blocks not reindexed yet
+		if rawdb.ReadCanonicalHash(api.db, number) != e.BlockHash {
+			continue
+		}
+		if len(result.Transactions) == limit {
+			result.Cursor = common.CopyBytes(key[len(prefix):])
+			last = number
+			break
+		}
+		result.Transactions = append(result.Transactions, &AddressTransaction{
+			BlockHash:        e.BlockHash,
+			BlockNumber:      hexutil.Uint64(number),
+			TransactionHash:  e.TxHash,
+			TransactionIndex: hexutil.Uint(index),
+			Roles:            roles(e.Roles),
+		})
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	result.IncompleteBlocks = api.gaps(first, last)
+	return result, nil
+}
+
+// gaps returns the blocks in the given range indexed without their internal
+// transfers.
+func (api *PublicAddressIndexAPI) gaps(first, last uint64) []hexutil.Uint64 {
+	it := api.db.NewIteratorWithStart(gapKey(first))
+	defer it.Release()
+
+	var gaps []hexutil.Uint64
+	for it.Next() && bytes.HasPrefix(it.Key(), gapPrefix) {
+		number := binary.BigEndian.Uint64(it.Key()[len(gapPrefix):])
+		if number > last {
+			break
+		}
+		gaps = append(gaps, hexutil.Uint64(number))
+	}
+	return gaps
+}
+
+// roles returns the names of the roles set in the flags.
+func roles(flags uint8) []string {
+	var names []string
+	for i, name := range roleNames {
+		if flags&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,157 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package addrindex
+
+import (
+	"encoding/binary"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/core/types"
+	"github.com/simplechain-org/go-simplechain/ethdb"
+	"github.com/simplechain-org/go-simplechain/log"
+	"github.com/simplechain-org/go-simplechain/rlp"
+)
+
+// The address index is stored in the chain database under the following keys:
+//
+//	entryPrefix + address + num (uint64 big endian) + index (uint32 big endian) -> entry
+//	journalPrefix + num (uint64 big endian) -> addresses indexed in the block
+//	gapPrefix + num (uint64 big endian) -> marker of a block indexed without internal transfers
+var (
+	entryPrefix   = []byte("xa")
+	journalPrefix = []byte("xj")
+	gapPrefix     = []byte("xg")
+)
+
+// Roles an address plays in a transaction.
+const (
+	RoleFrom         = 1 << iota // Address sent the transaction
+	RoleTo                       // Address is the recipient of the transaction
+	RoleCreated                  // Address is a contract created by the transaction
+	RoleInternalFrom             // Address sent value in an internal call
+	RoleInternalTo               // Address received value in an internal call
+)
+
+var roleNames = []string{"from", "to", "created", "internalFrom", "internalTo"}
+
+// entry is the index record of a transaction touching an address.
+type entry struct {
+	BlockHash common.Hash
+	TxHash    common.Hash
+	Roles     uint8
+}
+
+// entryKey = entryPrefix + address + num (uint64 big endian) + index (uint32 big endian)
+func entryKey(addr common.Address, number uint64, index uint32) []byte {
+	key := make([]byte, len(entryPrefix)+common.AddressLength+12)
+	copy(key, entryPrefix)
+	copy(key[len(entryPrefix):], addr.Bytes())
+	binary.BigEndian.PutUint64(key[len(entryPrefix)+common.AddressLength:], number)
+	binary.BigEndian.PutUint32(key[len(entryPrefix)+common.AddressLength+8:], index)
+	return key
+}
+
+// entryPosition returns the block number and transaction index of an entry key.
+func entryPosition(key []byte) (uint64, uint32) {
+	pos := key[len(entryPrefix)+common.AddressLength:]
+	return binary.BigEndian.Uint64(pos), binary.BigEndian.Uint32(pos[8:])
+}
+
+// journalKey = journalPrefix + num (uint64 big endian)
+func journalKey(number uint64) []byte {
+	key := make([]byte, len(journalPrefix)+8)
+	copy(key, journalPrefix)
+	binary.BigEndian.PutUint64(key[len(journalPrefix):], number)
+	return key
+}
+
+// gapKey = gapPrefix + num (uint64 big endian)
+func gapKey(number uint64) []byte {
+	key := make([]byte, len(gapPrefix)+8)
+	copy(key, gapPrefix)
+	binary.BigEndian.PutUint64(key[len(gapPrefix):], number)
+	return key
+}
+
+// writeBlock stores the entries of the addresses touched by the transactions of
+// a block, along with the journal needed to delete them on reorg. Blocks whose
+// internal transfers could not be collected are marked as such.
+func writeBlock(db ethdb.KeyValueWriter, block *types.Block, txs []map[common.Address]uint8, complete bool) {
+	if !complete && len(block.Transactions()) > 0 {
+		if err := db.Put(gapKey(block.NumberU64()), []byte{1}); err != nil {
+			log.Crit("Failed to store address index gap", "err", err)
+		}
+	}
+	var (
+		seen  = make(map[common.Address]bool)
+		addrs []common.Address
+	)
+	for i, tx := range block.Transactions() {
+		for addr, roles := range txs[i] {
+			enc, err := rlp.EncodeToBytes(&entry{BlockHash: block.Hash(), TxHash: tx.Hash(), Roles: roles})
+			if err != nil {
+				log.Crit("Failed to encode address index entry", "err", err)
+			}
+			if err := db.Put(entryKey(addr, block.NumberU64(), uint32(i)), enc); err != nil {
+				log.Crit("Failed to store address index entry", "err", err)
+			}
+			if !seen[addr] {
+				seen[addr] = true
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+	if len(addrs) == 0 {
+		return
+	}
+	enc, err := rlp.EncodeToBytes(addrs)
+	if err != nil {
+		log.Crit("Failed to encode address index journal", "err", err)
+	}
+	if err := db.Put(journalKey(block.NumberU64()), enc); err != nil {
+		log.Crit("Failed to store address index journal", "err", err)
+	}
+}
+
+// deleteBlock removes all the entries indexed for the block of the given number.
+func deleteBlock(db ethdb.KeyValueStore, batch ethdb.KeyValueWriter, number uint64) {
+	if err := batch.Delete(gapKey(number)); err != nil {
+		log.Crit("Failed to delete address index gap", "err", err)
+	}
+	enc, err := db.Get(journalKey(number))
+	if err != nil || len(enc) == 0 {
+		return
+	}
+	var addrs []common.Address
+	if err := rlp.DecodeBytes(enc, &addrs); err != nil {
+		log.Error("Invalid address index journal", "number", number, "err", err)
+		return
+	}
+	for _, addr := range addrs {
+		prefix := entryKey(addr, number, 0)[:len(entryPrefix)+common.AddressLength+8]
+		it := db.NewIteratorWithPrefix(prefix)
+		for it.Next() {
+			if err := batch.Delete(common.CopyBytes(it.Key())); err != nil {
+				log.Crit("Failed to delete address index entry", "err", err)
+			}
+		}
+		it.Release()
+	}
+	if err := batch.Delete(journalKey(number)); err != nil {
+		log.Crit("Failed to delete address index journal", "err", err)
+	}
+}
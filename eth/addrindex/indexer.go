@@ -0,0 +1,186 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package addrindex implements an index of the transactions touching each
+// address, including the internal value transfers made by contracts.
+package addrindex
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/core"
+	"github.com/simplechain-org/go-simplechain/core/rawdb"
+	"github.com/simplechain-org/go-simplechain/core/types"
+	"github.com/simplechain-org/go-simplechain/core/vm"
+	"github.com/simplechain-org/go-simplechain/eth/tracers"
+	"github.com/simplechain-org/go-simplechain/ethdb"
+	"github.com/simplechain-org/go-simplechain/log"
+)
+
+const (
+	// sectionSize is the number of blocks indexed in a single section.
+	sectionSize = 16
+
+	// confirms is the number of confirmation blocks before a section is indexed.
+	confirms = 12
+
+	// throttling is the time to wait between indexing two consecutive sections.
+	throttling = 10 * time.Millisecond
+)
+
+var errMissingBlock = errors.New("missing block")
+
+// Indexer implements a core.ChainIndexer, indexing the transactions of the
+// canonical chain by the addresses they touch.
+type Indexer struct {
+	db    ethdb.Database
+	chain *core.BlockChain
+	batch ethdb.Batch
+}
+
+// NewIndexer returns a chain indexer that builds the address index of the
+// canonical chain.
+func NewIndexer(db ethdb.Database, chain *core.BlockChain) *core.ChainIndexer {
+	backend := &Indexer{
+		db:    db,
+		chain: chain,
+	}
+	table := rawdb.NewTable(db, string(rawdb.AddressIndexPrefix))
+
+	return core.NewChainIndexer(db, table, backend, sectionSize, confirms, throttling, "addrindex")
+}
+
+// Reset implements core.ChainIndexerBackend, starting a new section and dropping
+// the entries indexed by any previous run of it.
+func (idx *Indexer) Reset(ctx context.Context, section uint64, prevHead common.Hash) error {
+	idx.batch = idx.db.NewBatch()
+	for n := section * sectionSize; n < (section+1)*sectionSize; n++ {
+		deleteBlock(idx.db, idx.batch, n)
+	}
+	return nil
+}
+
+// Process implements core.ChainIndexerBackend, indexing the transactions of a
+// new block.
+func (idx *Indexer) Process(ctx context.Context, header *types.Header) error {
+	block := idx.chain.GetBlock(header.Hash(), header.Number.Uint64())
+	if block == nil {
+		return errMissingBlock
+	}
+	txs, complete := idx.collect(block)
+	writeBlock(idx.batch, block, txs, complete)
+	if idx.batch.ValueSize() > ethdb.IdealBatchSize {
+		if err := idx.batch.Write(); err != nil {
+			return err
+		}
+		idx.batch.Reset()
+	}
+	return nil
+}
+
+// Commit implements core.ChainIndexerBackend, writing out the section.
+func (idx *Indexer) Commit() error {
+	return idx.batch.Write()
+}
+
+// collect returns the roles of the addresses touched by each transaction of the
+// block, and whether its internal value transfers were included. These are only
+// found if the state of the parent is available to trace the block.
+func (idx *Indexer) collect(block *types.Block) ([]map[common.Address]uint8, bool) {
+	var (
+		config   = idx.chain.Config()
+		signer   = types.MakeSigner(config)
+		receipts = idx.chain.GetReceiptsByHash(block.Hash())
+		txs      = make([]map[common.Address]uint8, len(block.Transactions()))
+	)
+	for i, tx := range block.Transactions() {
+		roles := make(map[common.Address]uint8)
+		if from, err := types.Sender(signer, tx); err == nil {
+			roles[from] |= RoleFrom
+		}
+		if to := tx.To(); to != nil {
+			roles[*to] |= RoleTo
+		} else if i < len(receipts) && receipts[i].Status == types.ReceiptStatusSuccessful {
+			roles[receipts[i].ContractAddress] |= RoleCreated
+		}
+		txs[i] = roles
+	}
+	if len(txs) == 0 {
+		return txs, true
+	}
+	parent := idx.chain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return txs, false
+	}
+	statedb, err := idx.chain.StateAt(parent.Root())
+	if err != nil {
+		log.Warn("Indexing block without internal transfers, state unavailable", "number", block.NumberU64(), "err", err)
+		return txs, false
+	}
+	for i, tx := range block.Transactions() {
+		msg, err := tx.AsMessage(signer)
+		if err != nil {
+			return txs, false
+		}
+		tracer := tracers.NewCallTracer()
+		vmctx := core.NewEVMContext(msg, block.Header(), idx.chain, nil)
+		vmenv := vm.NewEVM(vmctx, statedb, config, vm.Config{Debug: true, Tracer: tracer})
+
+		statedb.Prepare(tx.Hash(), block.Hash(), i)
+		if _, _, _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas())); err != nil {
+			log.Warn("Failed to trace transaction for the address index", "hash", tx.Hash(), "err", err)
+			return txs, false
+		}
+		statedb.Finalise(true)
+
+		if root := tracer.Result(); root.Error == "" {
+			for _, call := range root.Calls {
+				collectInternal(txs[i], call)
+			}
+		}
+	}
+	return txs, true
+}
+
+// collectInternal adds the value transfers of a successful internal call and of
+// its own internal calls to the roles.
+func collectInternal(roles map[common.Address]uint8, call *tracers.CallFrame) {
+	if call.Error != "" {
+		return
+	}
+	switch call.Type {
+	case "CREATE", "CREATE2":
+		if call.To != nil {
+			roles[*call.To] |= RoleCreated
+		}
+	case "CALLCODE", "DELEGATECALL", "STATICCALL":
+		// Calls running in the context of the caller don't transfer value
+		for _, inner := range call.Calls {
+			collectInternal(roles, inner)
+		}
+		return
+	}
+	if call.Value != nil && call.Value.ToInt().Sign() > 0 && call.From != nil && call.To != nil {
+		roles[*call.From] |= RoleInternalFrom
+		roles[*call.To] |= RoleInternalTo
+	}
+	for _, inner := range call.Calls {
+		collectInternal(roles, inner)
+	}
+}
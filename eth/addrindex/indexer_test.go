@@ -0,0 +1,164 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package addrindex
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/common/hexutil"
+	"github.com/simplechain-org/go-simplechain/consensus/ethash"
+	"github.com/simplechain-org/go-simplechain/core"
+	"github.com/simplechain-org/go-simplechain/core/rawdb"
+	"github.com/simplechain-org/go-simplechain/core/types"
+	"github.com/simplechain-org/go-simplechain/core/vm"
+	"github.com/simplechain-org/go-simplechain/crypto"
+	"github.com/simplechain-org/go-simplechain/params"
+)
+
+var (
+	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddress = crypto.PubkeyToAddress(testKey.PublicKey)
+
+	testRecipient = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	testForwarder = common.HexToAddress("0x2000000000000000000000000000000000000002")
+	testTarget    = common.HexToAddress("0x3000000000000000000000000000000000000003")
+)
+
+// forwarderCode sends 1 wei to the test target on every call.
+var forwarderCode = append(append(common.FromHex("0x6000600060006000600173"), testTarget.Bytes()...), common.FromHex("0x5af100")...)
+
+// Tests that the index tracks the senders, recipients and internal value
+// transfers of the transactions, and that queries page through them.
+func TestGetTransactionsByAddress(t *testing.T) {
+	var (
+		db    = rawdb.NewMemoryDatabase()
+		gspec = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				testAddress:   {Balance: big.NewInt(params.Ether)},
+				testForwarder: {Balance: big.NewInt(params.Ether), Code: forwarderCode},
+			},
+		}
+		genesis = gspec.MustCommit(db)
+		signer  = types.MakeSigner(gspec.Config)
+	)
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 2*sectionSize+confirms, func(i int, block *core.BlockGen) {
+		to := testRecipient
+		if i%2 == 1 {
+			to = testForwarder
+		}
+		tx, err := types.SignTx(types.NewTransaction(block.TxNonce(testAddress), to, big.NewInt(1000), 100000, nil, nil), signer, testKey)
+		if err != nil {
+			t.Fatalf("failed to sign transaction: %v", err)
+		}
+		block.AddTx(tx)
+	})
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	indexer := NewIndexer(db, chain)
+	indexer.Start(chain)
+	defer indexer.Close()
+
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if sections, _, _ := indexer.Sections(); sections == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("indexer didn't finish the sections in time")
+		}
+	}
+	api := NewPublicAddressIndexAPI(db, indexer)
+
+	// The internal transfers of the forwarder are found by tracing the blocks
+	res, err := api.GetTransactionsByAddress(testTarget, nil)
+	if err != nil {
+		t.Fatalf("failed to query target: %v", err)
+	}
+	if len(res.Transactions) != sectionSize-1 {
+		t.Fatalf("target transaction count mismatch: have %d, want %d", len(res.Transactions), sectionSize-1)
+	}
+	if res.IndexedBlock == nil || uint64(*res.IndexedBlock) != 2*sectionSize-1 {
+		t.Fatalf("indexed block mismatch: have %v, want %d", res.IndexedBlock, 2*sectionSize-1)
+	}
+	if len(res.IncompleteBlocks) != 0 {
+		t.Fatalf("incomplete blocks reported for traced chain: %v", res.IncompleteBlocks)
+	}
+	if want := []string{"internalTo"}; !reflect.DeepEqual(res.Transactions[0].Roles, want) {
+		t.Fatalf("target roles mismatch: have %v, want %v", res.Transactions[0].Roles, want)
+	}
+	res, err = api.GetTransactionsByAddress(testForwarder, nil)
+	if err != nil {
+		t.Fatalf("failed to query forwarder: %v", err)
+	}
+	if want := []string{"to", "internalFrom"}; !reflect.DeepEqual(res.Transactions[0].Roles, want) {
+		t.Fatalf("forwarder roles mismatch: have %v, want %v", res.Transactions[0].Roles, want)
+	}
+	// Page through the transactions of the sender
+	var (
+		limit = hexutil.Uint(5)
+		query = &AddressQuery{Limit: &limit}
+		seen  []common.Hash
+	)
+	for {
+		res, err := api.GetTransactionsByAddress(testAddress, query)
+		if err != nil {
+			t.Fatalf("failed to query sender: %v", err)
+		}
+		for _, tx := range res.Transactions {
+			if tx.TransactionHash != blocks[tx.BlockNumber-1].Transactions()[0].Hash() {
+				t.Fatalf("transaction mismatch in block %d", tx.BlockNumber)
+			}
+			seen = append(seen, tx.TransactionHash)
+		}
+		if len(res.Cursor) == 0 {
+			break
+		}
+		query.Cursor = res.Cursor
+	}
+	// All blocks of the indexed sections but the genesis have a transaction
+	if len(seen) != 2*sectionSize-1 {
+		t.Fatalf("sender transaction count mismatch: have %d, want %d", len(seen), 2*sectionSize-1)
+	}
+	// Ranges limit the blocks returned
+	from, to := hexutil.Uint64(3), hexutil.Uint64(6)
+	res, err = api.GetTransactionsByAddress(testRecipient, &AddressQuery{FromBlock: &from, ToBlock: &to})
+	if err != nil {
+		t.Fatalf("failed to query recipient: %v", err)
+	}
+	if len(res.Transactions) != 2 || res.Transactions[0].BlockNumber != 3 || res.Transactions[1].BlockNumber != 5 {
+		t.Fatalf("recipient range mismatch: have %d transactions", len(res.Transactions))
+	}
+	// Blocks indexed without their internal transfers must be reported
+	writeBlock(db, blocks[3], []map[common.Address]uint8{{testAddress: RoleFrom, testRecipient: RoleTo}}, false)
+	res, err = api.GetTransactionsByAddress(testRecipient, &AddressQuery{FromBlock: &from, ToBlock: &to})
+	if err != nil {
+		t.Fatalf("failed to query recipient: %v", err)
+	}
+	if want := []hexutil.Uint64{4}; !reflect.DeepEqual(res.IncompleteBlocks, want) {
+		t.Fatalf("incomplete blocks mismatch: have %v, want %v", res.IncompleteBlocks, want)
+	}
+}
@@ -155,6 +155,47 @@ func (api *PrivateMinerAPI) GetHashrate() uint64 {
 	return api.e.miner.HashRate()
 }
 
+// GetTemplate returns the block being built by the miner, with the gas and fees
+// of its transactions and the reasons the other pool transactions were left out.
+func (api *PrivateMinerAPI) GetTemplate() (*miner.Template, error) {
+	template := api.e.Miner().Template()
+	if template == nil {
+		return nil, errors.New("no block template yet")
+	}
+	return template, nil
+}
+
+// NewTemplates creates a subscription that fires with the template of each new
+// work committed by the miner.
+func (api *PrivateMinerAPI) NewTemplates(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		templates := make(chan *miner.Template, 16)
+		sub := api.e.Miner().SubscribeTemplates(templates)
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case template := <-templates:
+				notifier.Notify(rpcSub.ID, template)
+			case <-sub.Err():
+				return
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 // PrivateAdminAPI is the collection of Ethereum full node-related APIs
 // exposed over the private admin endpoint.
 type PrivateAdminAPI struct {
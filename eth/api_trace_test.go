@@ -0,0 +1,89 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/simplechain-org/go-simplechain/common"
+)
+
+// Tests that call tracer results are flattened in depth first order with the
+// correct trace addresses.
+func TestFlattenTrace(t *testing.T) {
+	result := json.RawMessage(`{
+		"type": "CALL", "from": "0x0000000000000000000000000000000000000001", "to": "0x0000000000000000000000000000000000000002",
+		"value": "0x0", "gas": "0x10000", "gasUsed": "0x5000", "input": "0x", "output": "0x",
+		"calls": [
+			{
+				"type": "CREATE", "from": "0x0000000000000000000000000000000000000002", "to": "0x0000000000000000000000000000000000000003",
+				"value": "0x1", "gas": "0x1000", "gasUsed": "0x500", "input": "0x00", "output": "0x",
+				"calls": [
+					{"type": "SELFDESTRUCT", "from": "0x0000000000000000000000000000000000000003", "to": "0x0000000000000000000000000000000000000004", "value": "0x1"}
+				]
+			},
+			{
+				"type": "STATICCALL", "from": "0x0000000000000000000000000000000000000002", "to": "0x0000000000000000000000000000000000000005",
+				"input": "0x", "error": "execution reverted"
+			}
+		]
+	}`)
+	traces, err := flattenTrace(result, common.Hash{0x01}, 1, common.Hash{0x02}, 3)
+	if err != nil {
+		t.Fatalf("failed to flatten trace: %v", err)
+	}
+	want := []struct {
+		kind      string
+		address   []int
+		subtraces int
+		sender    common.Address
+		recipient common.Address
+	}{
+		{"call", []int{}, 2, common.Address{19: 1}, common.Address{19: 2}},
+		{"create", []int{0}, 1, common.Address{19: 2}, common.Address{19: 3}},
+		{"suicide", []int{0, 0}, 0, common.Address{19: 3}, common.Address{19: 4}},
+		{"call", []int{1}, 0, common.Address{19: 2}, common.Address{19: 5}},
+	}
+	if len(traces) != len(want) {
+		t.Fatalf("trace count mismatch: have %d, want %d", len(traces), len(want))
+	}
+	for i, trace := range traces {
+		if trace.Type != want[i].kind {
+			t.Errorf("trace %d: type mismatch: have %s, want %s", i, trace.Type, want[i].kind)
+		}
+		if !reflect.DeepEqual(trace.TraceAddress, want[i].address) {
+			t.Errorf("trace %d: address mismatch: have %v, want %v", i, trace.TraceAddress, want[i].address)
+		}
+		if trace.Subtraces != want[i].subtraces {
+			t.Errorf("trace %d: subtraces mismatch: have %d, want %d", i, trace.Subtraces, want[i].subtraces)
+		}
+		if sender, recipient := trace.parties(); sender != want[i].sender || recipient != want[i].recipient {
+			t.Errorf("trace %d: parties mismatch: have %x->%x, want %x->%x", i, sender, recipient, want[i].sender, want[i].recipient)
+		}
+		if trace.TransactionPosition != 3 || trace.BlockNumber != 1 {
+			t.Errorf("trace %d: position mismatch: have %d/%d", i, trace.BlockNumber, trace.TransactionPosition)
+		}
+	}
+	if traces[3].Error != "execution reverted" || traces[3].Result != nil {
+		t.Errorf("failed call reported as successful: %+v", traces[3])
+	}
+	if traces[3].Action.CallType != "staticcall" {
+		t.Errorf("call type mismatch: have %s, want staticcall", traces[3].Action.CallType)
+	}
+}
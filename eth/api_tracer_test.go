@@ -0,0 +1,90 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/common/hexutil"
+	"github.com/simplechain-org/go-simplechain/consensus/ethash"
+	"github.com/simplechain-org/go-simplechain/core"
+	"github.com/simplechain-org/go-simplechain/core/rawdb"
+	"github.com/simplechain-org/go-simplechain/core/vm"
+	"github.com/simplechain-org/go-simplechain/eth/tracers"
+	"github.com/simplechain-org/go-simplechain/internal/ethapi"
+	"github.com/simplechain-org/go-simplechain/params"
+	"github.com/simplechain-org/go-simplechain/rpc"
+)
+
+// Tests that unsent calls can be traced on top of a block, with the state and
+// the block context overridden.
+func TestTraceCall(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		genesis = (&core.Genesis{Config: params.TestChainConfig}).MustCommit(db)
+		target  = common.Address{0xaa}
+		miner   = common.Address{0xbb}
+	)
+	chain, err := core.NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	blocks, _ := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), db, 1, nil)
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	eth := &Ethereum{config: &DefaultConfig, chainDb: db, blockchain: chain}
+	eth.APIBackend = &EthAPIBackend{eth: eth}
+	api := NewPrivateDebugAPI(eth)
+
+	// COINBASE PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+	code := hexutil.Bytes(common.FromHex("0x4160005260206000f3"))
+	config := &TraceCallConfig{
+		TraceConfig:    TraceConfig{Tracer: &callTracerName},
+		StateOverrides: &ethapi.StateOverride{target: {Code: &code}},
+		BlockOverrides: &ethapi.BlockOverrides{Coinbase: &miner},
+	}
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+
+	res, err := api.TraceCall(context.Background(), ethapi.CallArgs{To: &target}, latest, config)
+	if err != nil {
+		t.Fatalf("failed to trace call: %v", err)
+	}
+	frame := new(tracers.CallFrame)
+	if err := json.Unmarshal(res.(json.RawMessage), frame); err != nil {
+		t.Fatalf("failed to decode trace: %v", err)
+	}
+	if frame.Error != "" {
+		t.Fatalf("call failed: %v", frame.Error)
+	}
+	if frame.Output == nil || common.BytesToAddress(*frame.Output) != miner {
+		t.Fatalf("coinbase mismatch: have %v, want %x", frame.Output, miner)
+	}
+	// Without a tracer the struct logs are returned, without overrides the code is missing
+	res, err = api.TraceCall(context.Background(), ethapi.CallArgs{To: &target}, latest, nil)
+	if err != nil {
+		t.Fatalf("failed to trace call: %v", err)
+	}
+	if result := res.(*ethapi.ExecutionResult); len(result.StructLogs) != 0 || result.Failed {
+		t.Fatalf("unexpected execution without code: %+v", result)
+	}
+}
@@ -39,15 +39,18 @@ import (
 	istanbulBackend "github.com/simplechain-org/go-simplechain/consensus/istanbul/backend"
 	"github.com/simplechain-org/go-simplechain/consensus/raft"
 	"github.com/simplechain-org/go-simplechain/consensus/scrypt"
+	"github.com/simplechain-org/go-simplechain/contracts/permission"
 	"github.com/simplechain-org/go-simplechain/core"
 	"github.com/simplechain-org/go-simplechain/core/bloombits"
 	"github.com/simplechain-org/go-simplechain/core/rawdb"
 	"github.com/simplechain-org/go-simplechain/core/state"
 	"github.com/simplechain-org/go-simplechain/core/types"
 	"github.com/simplechain-org/go-simplechain/core/vm"
+	"github.com/simplechain-org/go-simplechain/eth/addrindex"
 	"github.com/simplechain-org/go-simplechain/eth/downloader"
 	"github.com/simplechain-org/go-simplechain/eth/filters"
 	"github.com/simplechain-org/go-simplechain/eth/gasprice"
+	"github.com/simplechain-org/go-simplechain/eth/tokenindex"
 	"github.com/simplechain-org/go-simplechain/ethdb"
 	"github.com/simplechain-org/go-simplechain/event"
 	"github.com/simplechain-org/go-simplechain/internal/ethapi"
@@ -57,6 +60,7 @@ import (
 	"github.com/simplechain-org/go-simplechain/p2p"
 	"github.com/simplechain-org/go-simplechain/p2p/enr"
 	"github.com/simplechain-org/go-simplechain/params"
+	"github.com/simplechain-org/go-simplechain/private"
 	"github.com/simplechain-org/go-simplechain/rlp"
 	"github.com/simplechain-org/go-simplechain/rpc"
 
@@ -74,8 +78,10 @@ type LesServer interface {
 
 // Ethereum implements the Ethereum full node service.
 type Ethereum struct {
-	config      *Config
-	chainConfig *params.ChainConfig
+	config         *Config
+	chainConfig    *params.ChainConfig
+	permission     *permission.Permission
+	privateManager private.TransactionManager
 
 	// Channel for shutting down the service
 	shutdownChan chan bool
@@ -95,6 +101,8 @@ type Ethereum struct {
 
 	bloomRequests chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
 	bloomIndexer  *core.ChainIndexer             // Bloom indexer operating during block imports
+	addrIndexer   *core.ChainIndexer             // Address indexer, nil if the address index is disabled
+	tokenIndexer  *core.ChainIndexer             // Token indexer, nil if the token index is disabled
 
 	APIBackend *EthAPIBackend
 
@@ -201,6 +209,7 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 			EnablePreimageRecording: config.EnablePreimageRecording,
 			EWASMInterpreter:        config.EWASMInterpreter,
 			EVMInterpreter:          config.EVMInterpreter,
+			ParallelWorkers:         config.ParallelWorkers,
 		}
 		cacheConfig = &core.CacheConfig{
 			TrieCleanLimit:      config.TrieCleanCache,
@@ -208,6 +217,7 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 			TrieDirtyLimit:      config.TrieDirtyCache,
 			TrieDirtyDisabled:   config.NoPruning,
 			TrieTimeLimit:       config.TrieTimeout,
+			SnapshotLimit:       config.SnapshotCache,
 		}
 	)
 	eth.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, chainConfig, eth.engine, vmConfig, eth.shouldPreserve)
@@ -215,6 +225,15 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 		return nil, err
 
 	}
+	if config.PrivateManager != "" {
+		if !chainConfig.Privacy {
+			return nil, errors.New("private transaction manager configured on a chain without privacy")
+		}
+		if eth.privateManager, err = private.New(config.PrivateManager, config.PrivateIdentity); err != nil {
+			return nil, err
+		}
+		eth.blockchain.SetPrivateManager(eth.privateManager)
+	}
 	// Rewind the chain in case of an incompatible config upgrade.
 	if compat, ok := genesisErr.(*params.ConfigCompatError); ok {
 		log.Warn("Rewinding chain to upgrade configuration", "err", compat)
@@ -222,6 +241,17 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 		rawdb.WriteChainConfig(chainDb, genesisHash, chainConfig)
 	}
 	eth.bloomIndexer.Start(eth.blockchain)
+	if config.AddressIndex {
+		if !config.NoPruning {
+			log.Warn("Address index enabled without archive mode, internal transfers of pruned blocks will be missing")
+		}
+		eth.addrIndexer = addrindex.NewIndexer(chainDb, eth.blockchain)
+		eth.addrIndexer.Start(eth.blockchain)
+	}
+	if config.TokenIndex {
+		eth.tokenIndexer = tokenindex.NewIndexer(chainDb, eth.blockchain)
+		eth.tokenIndexer.Start(eth.blockchain)
+	}
 
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = ctx.ResolvePath(config.TxPool.Journal)
@@ -366,6 +396,25 @@ func (s *Ethereum) APIs() []rpc.API {
 	// Append Register APIs
 	apis = append(apis, s.apis...)
 
+	// Append the address index queries if it is enabled
+	if s.addrIndexer != nil {
+		apis = append(apis, rpc.API{
+			Namespace: "eth",
+			Version:   "1.0",
+			Service:   addrindex.NewPublicAddressIndexAPI(s.chainDb, s.addrIndexer),
+			Public:    true,
+		})
+	}
+	// Append the token index queries if it is enabled
+	if s.tokenIndexer != nil {
+		apis = append(apis, rpc.API{
+			Namespace: "eth",
+			Version:   "1.0",
+			Service:   s.TokenIndex(),
+			Public:    true,
+		})
+	}
+
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
@@ -406,6 +455,10 @@ func (s *Ethereum) APIs() []rpc.API {
 			Namespace: "debug",
 			Version:   "1.0",
 			Service:   NewPrivateDebugAPI(s),
+		}, {
+			Namespace: "trace",
+			Version:   "1.0",
+			Service:   NewPrivateTraceAPI(s),
 		}, {
 			Namespace: "net",
 			Version:   "1.0",
@@ -599,6 +652,14 @@ func (s *Ethereum) GasOracle() *gasprice.Oracle {
 	return s.APIBackend.gpo
 }
 
+// TokenIndex returns the queries of the token index, nil if it is disabled.
+func (s *Ethereum) TokenIndex() *tokenindex.PublicTokenIndexAPI {
+	if s.tokenIndexer == nil {
+		return nil
+	}
+	return tokenindex.NewPublicTokenIndexAPI(s.chainDb, s.tokenIndexer)
+}
+
 // Protocols implements node.Service, returning all the currently configured
 // network protocols to start.
 func (s *Ethereum) Protocols() []p2p.Protocol {
@@ -637,13 +698,36 @@ func (s *Ethereum) Start(srvr *p2p.Server) error {
 	if s.lesServer != nil {
 		s.lesServer.Start(srvr)
 	}
+	// Enforce the node permissioning for every consensus engine
+	if s.chainConfig.Permission != nil {
+		perm, err := permission.NewPermission(s.blockchain, s.chainConfig.Permission.Contract)
+		if err != nil {
+			return err
+		}
+		// Only the peers of this chain are restricted on a shared server
+		var protocols []string
+		for _, proto := range s.Protocols() {
+			protocols = append(protocols, proto.Name)
+		}
+		s.permission = perm
+		s.permission.Start(srvr, protocols...)
+	}
 	return nil
 }
 
 // Stop implements node.Service, terminating all internal goroutines used by the
 // Ethereum protocol.
 func (s *Ethereum) Stop() error {
+	if s.permission != nil {
+		s.permission.Stop()
+	}
 	s.bloomIndexer.Close()
+	if s.addrIndexer != nil {
+		s.addrIndexer.Close()
+	}
+	if s.tokenIndexer != nil {
+		s.tokenIndexer.Close()
+	}
 	s.blockchain.Stop()
 	s.engine.Close()
 	//s.ctxStore.Stop()
@@ -52,6 +52,7 @@ var DefaultConfig = Config{
 	TrieCleanCache:     256,
 	TrieDirtyCache:     256,
 	TrieTimeout:        60 * time.Minute,
+	SnapshotCache:      102,
 	Miner: miner.Config{
 		GasFloor: 8000000,
 		GasCeil:  8000000,
@@ -102,6 +103,9 @@ type Config struct {
 	NoPruning  bool // Whether to disable pruning and flush everything to disk
 	NoPrefetch bool // Whether to disable prefetching and only load state on demand
 
+	AddressIndex bool // Whether to index the transactions touching each address
+	TokenIndex   bool // Whether to index the token balances and transfers of each address
+
 	// Whitelist of required block number -> hash values to accept
 	Whitelist map[uint64]common.Hash `toml:"-"`
 
@@ -125,6 +129,7 @@ type Config struct {
 	TrieCleanCache int
 	TrieDirtyCache int
 	TrieTimeout    time.Duration
+	SnapshotCache  int
 
 	// Mining options
 	Miner miner.Config
@@ -153,6 +158,9 @@ type Config struct {
 	// Type of the EVM interpreter ("" for default)
 	EVMInterpreter string
 
+	// Number of workers speculatively executing block transactions (sequential if below 2)
+	ParallelWorkers int
+
 	// RPCGasCap is the global gas cap for eth-call variants.
 	RPCGasCap *big.Int `toml:",omitempty"`
 
@@ -162,6 +170,11 @@ type Config struct {
 	// CheckpointOracle is the configuration for checkpoint oracle.
 	CheckpointOracle *params.CheckpointOracleConfig `toml:",omitempty"`
 
+	// PrivateManager is the private transaction manager, the directory of a local
+	// manager or the URL of a remote one. Empty disables private transactions.
+	PrivateManager  string `toml:",omitempty"`
+	PrivateIdentity string `toml:",omitempty"` // Identity of the node in a local manager
+
 	// Singularity block override (TODO: remove after the fork)
 	OverrideSingularity *big.Int
 
@@ -338,6 +338,9 @@ func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([
 		if crit.ToBlock != nil {
 			end = crit.ToBlock.Int64()
 		}
+		if err := api.checkRange(ctx, begin, end); err != nil {
+			return nil, err
+		}
 		// Construct the range filter
 		filter = NewRangeFilter(api.backend, begin, end, crit.Addresses, crit.Topics)
 	}
@@ -349,6 +352,26 @@ func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([
 	return returnLogs(logs), err
 }
 
+// checkRange enforces the block range cap of the API key of the caller on a log
+// query, the latest and pending blocks being resolved to the current head.
+func (api *PublicFilterAPI) checkRange(ctx context.Context, begin, end int64) error {
+	if begin >= 0 && end >= 0 {
+		return rpc.CheckBlockRange(ctx, uint64(begin), uint64(end))
+	}
+	head, err := api.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil || head == nil {
+		return err
+	}
+	from, to := head.Number.Uint64(), head.Number.Uint64()
+	if begin >= 0 {
+		from = uint64(begin)
+	}
+	if end >= 0 {
+		to = uint64(end)
+	}
+	return rpc.CheckBlockRange(ctx, from, to)
+}
+
 // UninstallFilter removes the filter with the given filter id.
 //
 // https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_uninstallfilter
@@ -393,6 +416,9 @@ func (api *PublicFilterAPI) GetFilterLogs(ctx context.Context, id rpc.ID) ([]*ty
 		if f.crit.ToBlock != nil {
 			end = f.crit.ToBlock.Int64()
 		}
+		if err := api.checkRange(ctx, begin, end); err != nil {
+			return nil, err
+		}
 		// Construct the range filter
 		filter = NewRangeFilter(api.backend, begin, end, f.crit.Addresses, f.crit.Topics)
 	}
@@ -0,0 +1,226 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package tokenindex
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/common/hexutil"
+	"github.com/simplechain-org/go-simplechain/core"
+	"github.com/simplechain-org/go-simplechain/core/rawdb"
+	"github.com/simplechain-org/go-simplechain/ethdb"
+	"github.com/simplechain-org/go-simplechain/log"
+	"github.com/simplechain-org/go-simplechain/rlp"
+)
+
+const (
+	defaultLimit = 100  // Default number of events returned in a page
+	maxLimit     = 1000 // Maximum number of events returned in a page
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+var (
+	standardNames = map[uint8]string{ERC20: "ERC20", ERC721: "ERC721"}
+	kindNames     = map[uint8]string{KindTransfer: "Transfer", KindApproval: "Approval"}
+)
+
+// TokenBalance is the balance of a token held by an account.
+type TokenBalance struct {
+	Token    common.Address `json:"token"`
+	Standard string         `json:"standard"`
+	Balance  *hexutil.Big   `json:"balance"` // Amount of ERC-20 tokens, or number of ERC-721 tokens
+}
+
+// TokenBalances are the non-zero token balances of an account.
+type TokenBalances struct {
+	Balances     []*TokenBalance `json:"balances"`
+	IndexedBlock *hexutil.Uint64 `json:"indexedBlock"` // Last block covered by the index, nil if none yet
+}
+
+// TransferQuery holds the optional arguments of a token history query.
+type TransferQuery struct {
+	Token     *common.Address `json:"token"`     // Token to restrict the events to
+	Approvals bool            `json:"approvals"` // Whether to include the Approval events
+	FromBlock *hexutil.Uint64 `json:"fromBlock"` // First block of the range, genesis if nil
+	ToBlock   *hexutil.Uint64 `json:"toBlock"`   // Last block of the range, last indexed block if nil
+	Limit     *hexutil.Uint   `json:"limit"`     // Maximum number of events in the page
+	Cursor    hexutil.Bytes   `json:"cursor"`    // Cursor returned by the previous page
+}
+
+// TokenTransfer is a Transfer or Approval event involving the queried account.
+// For approvals From is the owner and To the spender.
+type TokenTransfer struct {
+	Event           string         `json:"event"`
+	Standard        string         `json:"standard"`
+	Token           common.Address `json:"token"`
+	From            common.Address `json:"from"`
+	To              common.Address `json:"to"`
+	Value           *hexutil.Big   `json:"value,omitempty"`   // Amount of ERC-20 tokens
+	TokenID         *hexutil.Big   `json:"tokenId,omitempty"` // Id of the ERC-721 token
+	BlockHash       common.Hash    `json:"blockHash"`
+	BlockNumber     hexutil.Uint64 `json:"blockNumber"`
+	TransactionHash common.Hash    `json:"transactionHash"`
+	LogIndex        hexutil.Uint   `json:"logIndex"`
+}
+
+// TokenTransfers is a page of the token events involving an account, ordered by
+// block number and log index.
+type TokenTransfers struct {
+	Transfers    []*TokenTransfer `json:"transfers"`
+	Cursor       hexutil.Bytes    `json:"cursor,omitempty"` // Cursor of the next page, empty if it's the last one
+	IndexedBlock *hexutil.Uint64  `json:"indexedBlock"`     // Last block covered by the index, nil if none yet
+}
+
+// PublicTokenIndexAPI provides the token balances and histories of accounts,
+// served by the token index.
+type PublicTokenIndexAPI struct {
+	db      ethdb.Database
+	indexer *core.ChainIndexer
+}
+
+// NewPublicTokenIndexAPI creates a new API serving the token index built by the
+// given indexer.
+func NewPublicTokenIndexAPI(db ethdb.Database, indexer *core.ChainIndexer) *PublicTokenIndexAPI {
+	return &PublicTokenIndexAPI{db: db, indexer: indexer}
+}
+
+// indexedBlock returns the last block covered by the index, nil if none yet.
+func (api *PublicTokenIndexAPI) indexedBlock() *hexutil.Uint64 {
+	sections, _, _ := api.indexer.Sections()
+	if sections == 0 {
+		return nil
+	}
+	last := hexutil.Uint64(sections*sectionSize - 1)
+	return &last
+}
+
+// GetTokenBalances returns the ERC-20 and ERC-721 tokens held by the account.
+func (api *PublicTokenIndexAPI) GetTokenBalances(holder common.Address) (*TokenBalances, error) {
+	result := &TokenBalances{
+		Balances:     []*TokenBalance{},
+		IndexedBlock: api.indexedBlock(),
+	}
+	prefix := balanceKey(holder, common.Address{})[:len(balancePrefix)+common.AddressLength]
+	it := api.db.NewIteratorWithPrefix(prefix)
+	defer it.Release()
+
+	for it.Next() {
+		var b balance
+		if err := rlp.DecodeBytes(it.Value(), &b); err != nil {
+			log.Error("Invalid token index balance", "holder", holder, "err", err)
+			continue
+		}
+		result.Balances = append(result.Balances, &TokenBalance{
+			Token:    common.BytesToAddress(it.Key()[len(prefix):]),
+			Standard: standardNames[b.Standard],
+			Balance:  (*hexutil.Big)(b.Balance),
+		})
+	}
+	return result, it.Error()
+}
+
+// GetTokenTransfers returns a page of the token events sent or received by the
+// account. Only canonical blocks covered by the index are included.
+func (api *PublicTokenIndexAPI) GetTokenTransfers(holder common.Address, query *TransferQuery) (*TokenTransfers, error) {
+	if query == nil {
+		query = new(TransferQuery)
+	}
+	limit := defaultLimit
+	if query.Limit != nil {
+		limit = int(*query.Limit)
+	}
+	if limit <= 0 || limit > maxLimit {
+		return nil, fmt.Errorf("limit must be in range [1, %d]", maxLimit)
+	}
+	result := &TokenTransfers{
+		Transfers:    []*TokenTransfer{},
+		IndexedBlock: api.indexedBlock(),
+	}
+	if result.IndexedBlock == nil {
+		return result, nil
+	}
+	last := uint64(*result.IndexedBlock)
+	if query.ToBlock != nil && uint64(*query.ToBlock) < last {
+		last = uint64(*query.ToBlock)
+	}
+	// Iterate the events of the holder from the cursor or the first block
+	prefix := eventKey(holder, 0, 0)[:len(eventPrefix)+common.AddressLength]
+	start := eventKey(holder, 0, 0)
+	switch {
+	case len(query.Cursor) > 0:
+		if len(query.Cursor) != 12 {
+			return nil, errInvalidCursor
+		}
+		copy(start[len(prefix):], query.Cursor)
+	case query.FromBlock != nil:
+		start = eventKey(holder, uint64(*query.FromBlock), 0)
+	}
+	it := api.db.NewIteratorWithStart(start)
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if !bytes.HasPrefix(key, prefix) {
+			break
+		}
+		number, index := eventPosition(key)
+		if number > last {
+			break
+		}
+		var ev event
+		if err := rlp.DecodeBytes(it.Value(), &ev); err != nil {
+			log.Error("Invalid token index event", "holder", holder, "number", number, "err", err)
+			continue
+		}
+		if ev.Kind == KindApproval && !query.Approvals {
+			continue
+		}
+		if query.Token != nil && ev.Token != *query.Token {
+			continue
+		}
+		// Skip the events of reorged blocks not reindexed yet
+		if rawdb.ReadCanonicalHash(api.db, number) != ev.BlockHash {
+			continue
+		}
+		if len(result.Transfers) == limit {
+			result.Cursor = common.CopyBytes(key[len(prefix):])
+			break
+		}
+		transfer := &TokenTransfer{
+			Event:           kindNames[ev.Kind],
+			Standard:        standardNames[ev.Standard],
+			Token:           ev.Token,
+			From:            ev.From,
+			To:              ev.To,
+			BlockHash:       ev.BlockHash,
+			BlockNumber:     hexutil.Uint64(number),
+			TransactionHash: ev.TxHash,
+			LogIndex:        hexutil.Uint(index),
+		}
+		if ev.Standard == ERC721 {
+			transfer.TokenID = (*hexutil.Big)(ev.Value)
+		} else {
+			transfer.Value = (*hexutil.Big)(ev.Value)
+		}
+		result.Transfers = append(result.Transfers, transfer)
+	}
+	return result, it.Error()
+}
@@ -0,0 +1,209 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package tokenindex
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/ethdb"
+	"github.com/simplechain-org/go-simplechain/log"
+	"github.com/simplechain-org/go-simplechain/rlp"
+)
+
+// The token index is stored in the chain database under the following keys:
+//
+//	eventPrefix + holder + num (uint64 big endian) + log index (uint32 big endian) -> event
+//	balancePrefix + holder + token -> balance
+//	journalPrefix + num (uint64 big endian) -> changes made by the block
+//	headKey -> number of blocks indexed (uint64 big endian)
+var (
+	eventPrefix   = []byte("xt")
+	balancePrefix = []byte("xb")
+	journalPrefix = []byte("xk")
+	headKey       = []byte("xh")
+)
+
+// Token standards of the indexed events.
+const (
+	ERC20  = 1
+	ERC721 = 2
+)
+
+// Kinds of the indexed events.
+const (
+	KindTransfer = iota
+	KindApproval
+)
+
+// event is the index record of a Transfer or Approval event. For approvals From
+// is the owner and To the spender. Value is the amount of ERC-20 tokens, or the
+// id of the ERC-721 token.
+type event struct {
+	Kind      uint8
+	Standard  uint8
+	Token     common.Address
+	From      common.Address
+	To        common.Address
+	Value     *big.Int
+	BlockHash common.Hash
+	TxHash    common.Hash
+}
+
+// balance is the amount of ERC-20 tokens or the number of ERC-721 tokens held.
+type balance struct {
+	Standard uint8
+	Balance  *big.Int
+}
+
+// balanceChange is the journal record of the balance held before a block.
+type balanceChange struct {
+	Holder  common.Address
+	Token   common.Address
+	Existed bool
+	Prev    balance
+}
+
+// journal holds the changes made by a block, needed to revert it on reorg.
+type journal struct {
+	Holders  []common.Address
+	Balances []balanceChange
+}
+
+// eventKey = eventPrefix + holder + num (uint64 big endian) + log index (uint32 big endian)
+func eventKey(holder common.Address, number uint64, index uint32) []byte {
+	key := make([]byte, len(eventPrefix)+common.AddressLength+12)
+	copy(key, eventPrefix)
+	copy(key[len(eventPrefix):], holder.Bytes())
+	binary.BigEndian.PutUint64(key[len(eventPrefix)+common.AddressLength:], number)
+	binary.BigEndian.PutUint32(key[len(eventPrefix)+common.AddressLength+8:], index)
+	return key
+}
+
+// eventPosition returns the block number and log index of an event key.
+func eventPosition(key []byte) (uint64, uint32) {
+	pos := key[len(eventPrefix)+common.AddressLength:]
+	return binary.BigEndian.Uint64(pos), binary.BigEndian.Uint32(pos[8:])
+}
+
+// balanceKey = balancePrefix + holder + token
+func balanceKey(holder, token common.Address) []byte {
+	key := make([]byte, len(balancePrefix)+2*common.AddressLength)
+	copy(key, balancePrefix)
+	copy(key[len(balancePrefix):], holder.Bytes())
+	copy(key[len(balancePrefix)+common.AddressLength:], token.Bytes())
+	return key
+}
+
+// journalKey = journalPrefix + num (uint64 big endian)
+func journalKey(number uint64) []byte {
+	key := make([]byte, len(journalPrefix)+8)
+	copy(key, journalPrefix)
+	binary.BigEndian.PutUint64(key[len(journalPrefix):], number)
+	return key
+}
+
+// readHead retrieves the number of blocks indexed.
+func readHead(db ethdb.KeyValueReader) uint64 {
+	data, _ := db.Get(headKey)
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+// writeHead stores the number of blocks indexed.
+func writeHead(db ethdb.KeyValueWriter, head uint64) {
+	if err := db.Put(headKey, encodeNumber(head)); err != nil {
+		log.Crit("Failed to store token index head", "err", err)
+	}
+}
+
+// readBalance retrieves the balance of a token held, nil if none.
+func readBalance(db ethdb.KeyValueReader, holder, token common.Address) *balance {
+	data, _ := db.Get(balanceKey(holder, token))
+	if len(data) == 0 {
+		return nil
+	}
+	b := new(balance)
+	if err := rlp.DecodeBytes(data, b); err != nil {
+		log.Error("Invalid token index balance", "holder", holder, "token", token, "err", err)
+		return nil
+	}
+	return b
+}
+
+// writeBalance stores the balance of a token held, deleting it if nil.
+func writeBalance(db ethdb.KeyValueWriter, holder, token common.Address, b *balance) {
+	if b == nil {
+		if err := db.Delete(balanceKey(holder, token)); err != nil {
+			log.Crit("Failed to delete token index balance", "err", err)
+		}
+		return
+	}
+	data, err := rlp.EncodeToBytes(b)
+	if err != nil {
+		log.Crit("Failed to encode token index balance", "err", err)
+	}
+	if err := db.Put(balanceKey(holder, token), data); err != nil {
+		log.Crit("Failed to store token index balance", "err", err)
+	}
+}
+
+// writeEvent stores an event in the history of a holder.
+func writeEvent(db ethdb.KeyValueWriter, holder common.Address, number uint64, index uint32, ev *event) {
+	data, err := rlp.EncodeToBytes(ev)
+	if err != nil {
+		log.Crit("Failed to encode token index event", "err", err)
+	}
+	if err := db.Put(eventKey(holder, number, index), data); err != nil {
+		log.Crit("Failed to store token index event", "err", err)
+	}
+}
+
+// readJournal retrieves the changes made by a block, nil if none.
+func readJournal(db ethdb.KeyValueReader, number uint64) *journal {
+	data, _ := db.Get(journalKey(number))
+	if len(data) == 0 {
+		return nil
+	}
+	j := new(journal)
+	if err := rlp.DecodeBytes(data, j); err != nil {
+		log.Error("Invalid token index journal", "number", number, "err", err)
+		return nil
+	}
+	return j
+}
+
+// writeJournal stores the changes made by a block.
+func writeJournal(db ethdb.KeyValueWriter, number uint64, j *journal) {
+	data, err := rlp.EncodeToBytes(j)
+	if err != nil {
+		log.Crit("Failed to encode token index journal", "err", err)
+	}
+	if err := db.Put(journalKey(number), data); err != nil {
+		log.Crit("Failed to store token index journal", "err", err)
+	}
+}
+
+// encodeNumber encodes a number as big endian uint64.
+func encodeNumber(number uint64) []byte {
+	enc := make([]byte, 8)
+	binary.BigEndian.PutUint64(enc, number)
+	return enc
+}
@@ -0,0 +1,264 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package tokenindex implements an index of the ERC-20 and ERC-721 Transfer and
+// Approval events, tracking the token balances and history of each holder.
+package tokenindex
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/core"
+	"github.com/simplechain-org/go-simplechain/core/rawdb"
+	"github.com/simplechain-org/go-simplechain/core/types"
+	"github.com/simplechain-org/go-simplechain/crypto"
+	"github.com/simplechain-org/go-simplechain/ethdb"
+	"github.com/simplechain-org/go-simplechain/log"
+)
+
+const (
+	// sectionSize is the number of blocks indexed in a single section.
+	sectionSize = 16
+
+	// confirms is the number of confirmation blocks before a section is indexed.
+	confirms = 12
+
+	// throttling is the time to wait between indexing two consecutive sections.
+	throttling = 10 * time.Millisecond
+)
+
+var (
+	// transferTopic is the signature of the Transfer(address,address,uint256) event.
+	transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+	// approvalTopic is the signature of the Approval(address,address,uint256) event.
+	approvalTopic = crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+)
+
+var errMissingReceipts = errors.New("missing receipts")
+
+// Indexer implements a core.ChainIndexer, indexing the token events of the
+// canonical chain. Blocks are applied to the balances in order, and reverted
+// through their journals when a section is reindexed.
+type Indexer struct {
+	db       ethdb.Database
+	chain    *core.BlockChain
+	batch    ethdb.Batch
+	balances map[[2]common.Address]*balance // Balances modified by the section, nil if deleted
+}
+
+// NewIndexer returns a chain indexer that builds the token index of the
+// canonical chain.
+func NewIndexer(db ethdb.Database, chain *core.BlockChain) *core.ChainIndexer {
+	backend := &Indexer{
+		db:    db,
+		chain: chain,
+	}
+	table := rawdb.NewTable(db, string(rawdb.TokenIndexPrefix))
+
+	return core.NewChainIndexer(db, table, backend, sectionSize, confirms, throttling, "tokenindex")
+}
+
+// Reset implements core.ChainIndexerBackend, starting a new section and reverting
+// the blocks indexed from its start onwards by any previous run.
+func (idx *Indexer) Reset(ctx context.Context, section uint64, prevHead common.Hash) error {
+	idx.batch = idx.db.NewBatch()
+	idx.balances = make(map[[2]common.Address]*balance)
+
+	start := section * sectionSize
+	head := readHead(idx.db)
+	for n := head; n > start; n-- {
+		idx.revert(n - 1)
+	}
+	if head > start {
+		writeHead(idx.batch, start)
+	}
+	return nil
+}
+
+// Process implements core.ChainIndexerBackend, indexing the token events of a
+// new block.
+func (idx *Indexer) Process(ctx context.Context, header *types.Header) error {
+	var (
+		number = header.Number.Uint64()
+		hash   = header.Hash()
+		j      = new(journal)
+	)
+	if types.BloomLookup(header.Bloom, transferTopic) || types.BloomLookup(header.Bloom, approvalTopic) {
+		receipts := idx.chain.GetReceiptsByHash(hash)
+		if receipts == nil {
+			return errMissingReceipts
+		}
+		var (
+			holders = make(map[common.Address]bool)
+			touched = make(map[[2]common.Address]bool)
+		)
+		for _, receipt := range receipts {
+			for _, l := range receipt.Logs {
+				ev := decode(l)
+				if ev == nil {
+					continue
+				}
+				ev.BlockHash, ev.TxHash = hash, l.TxHash
+
+				for _, holder := range []common.Address{ev.From, ev.To} {
+					if holder == (common.Address{}) {
+						continue
+					}
+					writeEvent(idx.batch, holder, number, uint32(l.Index), ev)
+					if !holders[holder] {
+						holders[holder] = true
+						j.Holders = append(j.Holders, holder)
+					}
+				}
+				if ev.Kind != KindTransfer {
+					continue
+				}
+				amount := ev.Value
+				if ev.Standard == ERC721 {
+					amount = big.NewInt(1)
+				}
+				if ev.From != (common.Address{}) {
+					idx.update(j, touched, ev.From, ev.Token, ev.Standard, new(big.Int).Neg(amount))
+				}
+				if ev.To != (common.Address{}) {
+					idx.update(j, touched, ev.To, ev.Token, ev.Standard, amount)
+				}
+			}
+		}
+	}
+	if len(j.Holders) > 0 {
+		writeJournal(idx.batch, number, j)
+	}
+	writeHead(idx.batch, number+1)
+
+	if idx.batch.ValueSize() > ethdb.IdealBatchSize {
+		if err := idx.batch.Write(); err != nil {
+			return err
+		}
+		idx.batch.Reset()
+	}
+	return nil
+}
+
+// Commit implements core.ChainIndexerBackend, writing out the section.
+func (idx *Indexer) Commit() error {
+	return idx.batch.Write()
+}
+
+// balance retrieves the current balance of a token held, nil if none.
+func (idx *Indexer) balance(holder, token common.Address) *balance {
+	if b, ok := idx.balances[[2]common.Address{holder, token}]; ok {
+		return b
+	}
+	return readBalance(idx.db, holder, token)
+}
+
+// setBalance updates the balance of a token held, deleting it if nil.
+func (idx *Indexer) setBalance(holder, token common.Address, b *balance) {
+	idx.balances[[2]common.Address{holder, token}] = b
+	writeBalance(idx.batch, holder, token, b)
+}
+
+// update adds the delta to the balance of a token held, journaling the balance
+// held before the block when it's first touched. Balances of non-conforming
+// tokens never go below zero.
+func (idx *Indexer) update(j *journal, touched map[[2]common.Address]bool, holder, token common.Address, standard uint8, delta *big.Int) {
+	prev := idx.balance(holder, token)
+	if key := [2]common.Address{holder, token}; !touched[key] {
+		touched[key] = true
+
+		change := balanceChange{Holder: holder, Token: token, Existed: prev != nil}
+		if prev != nil {
+			change.Prev = *prev
+		}
+		j.Balances = append(j.Balances, change)
+	}
+	amount := new(big.Int).Set(delta)
+	if prev != nil {
+		amount.Add(amount, prev.Balance)
+	}
+	if amount.Sign() <= 0 {
+		idx.setBalance(holder, token, nil)
+		return
+	}
+	idx.setBalance(holder, token, &balance{Standard: standard, Balance: amount})
+}
+
+// revert undoes the changes made by an indexed block.
+func (idx *Indexer) revert(number uint64) {
+	j := readJournal(idx.db, number)
+	if j == nil {
+		return
+	}
+	for i := len(j.Balances) - 1; i >= 0; i-- {
+		change := j.Balances[i]
+		if change.Existed {
+			prev := change.Prev
+			idx.setBalance(change.Holder, change.Token, &prev)
+		} else {
+			idx.setBalance(change.Holder, change.Token, nil)
+		}
+	}
+	for _, holder := range j.Holders {
+		prefix := eventKey(holder, number, 0)[:len(eventPrefix)+common.AddressLength+8]
+		it := idx.db.NewIteratorWithPrefix(prefix)
+		for it.Next() {
+			if err := idx.batch.Delete(common.CopyBytes(it.Key())); err != nil {
+				log.Crit("Failed to delete token index event", "err", err)
+			}
+		}
+		it.Release()
+	}
+	if err := idx.batch.Delete(journalKey(number)); err != nil {
+		log.Crit("Failed to delete token index journal", "err", err)
+	}
+}
+
+// decode returns the index record of a standard Transfer or Approval event, nil
+// if the log isn't one. ERC-20 events carry the amount in the data, ERC-721 ones
+// the indexed token id.
+func decode(l *types.Log) *event {
+	if len(l.Topics) < 3 {
+		return nil
+	}
+	ev := &event{
+		Token: l.Address,
+		From:  common.BytesToAddress(l.Topics[1].Bytes()),
+		To:    common.BytesToAddress(l.Topics[2].Bytes()),
+	}
+	switch l.Topics[0] {
+	case transferTopic:
+		ev.Kind = KindTransfer
+	case approvalTopic:
+		ev.Kind = KindApproval
+	default:
+		return nil
+	}
+	switch {
+	case len(l.Topics) == 3 && len(l.Data) == 32:
+		ev.Standard, ev.Value = ERC20, new(big.Int).SetBytes(l.Data)
+	case len(l.Topics) == 4 && len(l.Data) == 0:
+		ev.Standard, ev.Value = ERC721, l.Topics[3].Big()
+	default:
+		return nil
+	}
+	return ev
+}
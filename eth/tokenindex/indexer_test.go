@@ -0,0 +1,167 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package tokenindex
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/consensus/ethash"
+	"github.com/simplechain-org/go-simplechain/core"
+	"github.com/simplechain-org/go-simplechain/core/rawdb"
+	"github.com/simplechain-org/go-simplechain/core/types"
+	"github.com/simplechain-org/go-simplechain/core/vm"
+	"github.com/simplechain-org/go-simplechain/crypto"
+	"github.com/simplechain-org/go-simplechain/params"
+)
+
+var (
+	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddress = crypto.PubkeyToAddress(testKey.PublicKey)
+
+	holderA = common.HexToAddress("0xa000000000000000000000000000000000000001")
+	holderB = common.HexToAddress("0xb000000000000000000000000000000000000002")
+	holderC = common.HexToAddress("0xc000000000000000000000000000000000000003")
+
+	// The test tokens emit the event of calldata topic + from + to + value
+	erc20      = common.HexToAddress("0x2000000000000000000000000000000000000020")
+	erc20Code  = common.FromHex("0x6020604060003760203560003560603560206000a300")
+	erc721     = common.HexToAddress("0x7000000000000000000000000000000000000721")
+	erc721Code = common.FromHex("0x60403560203560003560603560006000a400")
+)
+
+// emit returns a transaction calling a test token to emit an event.
+func emit(block *core.BlockGen, token common.Address, topic common.Hash, from, to common.Address, value int64) *types.Transaction {
+	data := append(common.LeftPadBytes(from.Bytes(), 32), common.LeftPadBytes(to.Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(big.NewInt(value).Bytes(), 32)...)
+	data = append(data, topic.Bytes()...)
+
+	tx, err := types.SignTx(types.NewTransaction(block.TxNonce(testAddress), token, new(big.Int), 100000, nil, data), types.MakeSigner(params.TestChainConfig), testKey)
+	if err != nil {
+		panic(err)
+	}
+	return tx
+}
+
+// checkBalances waits for the indexed balances of the holder to match the wanted
+// ones, formatted as token:standard:balance.
+func checkBalances(t *testing.T, api *PublicTokenIndexAPI, holder common.Address, want ...string) {
+	var have []string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		res, err := api.GetTokenBalances(holder)
+		if err != nil {
+			t.Fatalf("failed to query balances: %v", err)
+		}
+		have = have[:0]
+		for _, b := range res.Balances {
+			have = append(have, fmt.Sprintf("%x:%s:%d", b.Token, b.Standard, b.Balance.ToInt()))
+		}
+		if fmt.Sprint(have) == fmt.Sprint(want) && res.IndexedBlock != nil {
+			return
+		}
+	}
+	t.Fatalf("balances of %x mismatch: have %v, want %v", holder, have, want)
+}
+
+// Tests that the index tracks the token balances and transfers of the holders,
+// and that reorgs revert them.
+func TestTokenIndex(t *testing.T) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		gendb  = rawdb.NewMemoryDatabase()
+		engine = ethash.NewFaker()
+		gspec  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				testAddress: {Balance: big.NewInt(params.Ether)},
+				erc20:       {Balance: new(big.Int), Code: erc20Code},
+				erc721:      {Balance: new(big.Int), Code: erc721Code},
+			},
+		}
+		genesis = gspec.MustCommit(gendb)
+	)
+	gspec.MustCommit(db)
+
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, engine, gendb, 2*sectionSize+confirms, func(i int, block *core.BlockGen) {
+		switch i {
+		case 0:
+			block.AddTx(emit(block, erc20, transferTopic, common.Address{}, holderA, 1000))
+		case 1:
+			block.AddTx(emit(block, erc20, transferTopic, holderA, holderB, 300))
+		case 2:
+			block.AddTx(emit(block, erc20, approvalTopic, holderA, holderB, 50))
+		case 3:
+			block.AddTx(emit(block, erc721, transferTopic, common.Address{}, holderA, 7))
+		case 4:
+			block.AddTx(emit(block, erc721, transferTopic, holderA, holderB, 7))
+		}
+	})
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, engine, vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	indexer := NewIndexer(db, chain)
+	indexer.Start(chain)
+	defer indexer.Close()
+
+	api := NewPublicTokenIndexAPI(db, indexer)
+	checkBalances(t, api, holderA, fmt.Sprintf("%x:ERC20:700", erc20))
+	checkBalances(t, api, holderB, fmt.Sprintf("%x:ERC20:300", erc20), fmt.Sprintf("%x:ERC721:1", erc721))
+
+	// Check the history of the holders with and without the approvals
+	res, err := api.GetTokenTransfers(holderA, nil)
+	if err != nil {
+		t.Fatalf("failed to query transfers: %v", err)
+	}
+	if len(res.Transfers) != 4 {
+		t.Fatalf("transfer count mismatch: have %d, want 4", len(res.Transfers))
+	}
+	if tr := res.Transfers[3]; tr.Standard != "ERC721" || tr.TokenID == nil || tr.TokenID.ToInt().Int64() != 7 || tr.Value != nil {
+		t.Fatalf("ERC-721 transfer mismatch: %+v", tr)
+	}
+	token := erc20
+	res, err = api.GetTokenTransfers(holderB, &TransferQuery{Token: &token, Approvals: true})
+	if err != nil {
+		t.Fatalf("failed to query transfers: %v", err)
+	}
+	if len(res.Transfers) != 2 || res.Transfers[1].Event != "Approval" || res.Transfers[1].Value.ToInt().Int64() != 50 {
+		t.Fatalf("approval mismatch: have %d events", len(res.Transfers))
+	}
+	// Reorg the chain, replacing the transfer to B with a transfer to C
+	fork, _ := core.GenerateChain(gspec.Config, blocks[0], engine, gendb, 2*sectionSize+confirms, func(i int, block *core.BlockGen) {
+		if i == 0 {
+			block.AddTx(emit(block, erc20, transferTopic, holderA, holderC, 100))
+		}
+	})
+	if _, err := chain.InsertChain(fork); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	checkBalances(t, api, holderA, fmt.Sprintf("%x:ERC20:900", erc20))
+	checkBalances(t, api, holderB)
+	checkBalances(t, api, holderC, fmt.Sprintf("%x:ERC20:100", erc20))
+
+	if res, err := api.GetTokenTransfers(holderB, &TransferQuery{Approvals: true}); err != nil || len(res.Transfers) != 0 {
+		t.Fatalf("reorged transfers returned: %v, %v", res, err)
+	}
+}
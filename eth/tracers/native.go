@@ -0,0 +1,69 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"sync/atomic"
+
+	"github.com/simplechain-org/go-simplechain/core/vm"
+)
+
+// TxTracer is a transaction tracer producing a JSON result, implemented either
+// in JavaScript or natively in Go.
+type TxTracer interface {
+	vm.Tracer
+
+	// GetResult returns the outcome of the trace, or any error that occurred.
+	GetResult() (json.RawMessage, error)
+
+	// Stop terminates the tracing, failing it with the given error.
+	Stop(err error)
+}
+
+// natives contains the tracers implemented in Go by name. They take precedence
+// over the JavaScript tracers of the same name.
+var natives = map[string]func() TxTracer{
+	"callTracer":     func() TxTracer { return NewCallTracer() },
+	"prestateTracer": func() TxTracer { return NewPrestateTracer() },
+}
+
+// NewTxTracer creates the native tracer of the given name if there is one, or
+// a JavaScript tracer from the given name or code otherwise.
+func NewTxTracer(code string) (TxTracer, error) {
+	if ctor, ok := natives[code]; ok {
+		return ctor(), nil
+	}
+	return New(code)
+}
+
+// interrupter implements the Stop method of the native tracers.
+type interrupter struct {
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (i *interrupter) Stop(err error) {
+	i.reason = err
+	atomic.StoreUint32(&i.interrupt, 1)
+}
+
+// stopped reports whether the tracer was stopped.
+func (i *interrupter) stopped() bool {
+	return atomic.LoadUint32(&i.interrupt) > 0
+}
@@ -121,6 +121,14 @@ type callTracerTest struct {
 }
 
 func TestPrestateTracerCreate2(t *testing.T) {
+	testPrestateTracerCreate2(t, func() (TxTracer, error) { return New("prestateTracer") })
+}
+
+func TestNativePrestateTracerCreate2(t *testing.T) {
+	testPrestateTracerCreate2(t, func() (TxTracer, error) { return NewPrestateTracer(), nil })
+}
+
+func testPrestateTracerCreate2(t *testing.T, newTracer func() (TxTracer, error)) {
 	unsignedTx := types.NewTransaction(1, common.HexToAddress("0x00000000000000000000000000000000deadbeef"),
 		new(big.Int), 5000000, big.NewInt(1), []byte{})
 
@@ -171,7 +179,7 @@ func TestPrestateTracerCreate2(t *testing.T) {
 	statedb := tests.MakePreState(rawdb.NewMemoryDatabase(), alloc)
 
 	// Create the tracer, the EVM environment and run it
-	tracer, err := New("prestateTracer")
+	tracer, err := newTracer()
 	if err != nil {
 		t.Fatalf("failed to create call tracer: %v", err)
 	}
@@ -202,6 +210,16 @@ func TestPrestateTracerCreate2(t *testing.T) {
 // Iterates over all the input-output datasets in the tracer test harness and
 // runs the JavaScript tracers against them.
 func TestCallTracer(t *testing.T) {
+	testCallTracer(t, func() (TxTracer, error) { return New("callTracer") })
+}
+
+// Iterates over all the input-output datasets in the tracer test harness and
+// runs the native tracers against them.
+func TestNativeCallTracer(t *testing.T) {
+	testCallTracer(t, func() (TxTracer, error) { return NewCallTracer(), nil })
+}
+
+func testCallTracer(t *testing.T, newTracer func() (TxTracer, error)) {
 	files, err := ioutil.ReadDir("testdata")
 	if err != nil {
 		t.Fatalf("failed to retrieve tracer test suite: %v", err)
@@ -245,7 +263,7 @@ func TestCallTracer(t *testing.T) {
 			statedb := tests.MakePreState(rawdb.NewMemoryDatabase(), test.Genesis.Alloc)
 
 			// Create the tracer, the EVM environment and run it
-			tracer, err := New("callTracer")
+			tracer, err := newTracer()
 			if err != nil {
 				t.Fatalf("failed to create call tracer: %v", err)
 			}
@@ -512,7 +512,7 @@ func (ec *Client) EstimateGas(ctx context.Context, msg simplechain.CallMsg) (uin
 // If the transaction was a contract creation use the TransactionReceipt method to get the
 // contract address after the transaction has been mined.
 func (ec *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
-	data, err := rlp.EncodeToBytes(tx)
+	data, err := tx.MarshalBinary()
 	if err != nil {
 		return err
 	}
@@ -539,6 +539,9 @@ func toCallArg(msg simplechain.CallMsg) interface{} {
 	if msg.Nonce != 0 {
 		arg["gasPrice"] = hexutil.Uint64(msg.Nonce)
 	}
+	if msg.AccessList != nil {
+		arg["accessList"] = msg.AccessList
+	}
 	return arg
 }
 
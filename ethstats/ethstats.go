@@ -34,14 +34,19 @@ import (
 	"github.com/simplechain-org/go-simplechain/common"
 	"github.com/simplechain-org/go-simplechain/common/mclock"
 	"github.com/simplechain-org/go-simplechain/consensus"
+	raftBackend "github.com/simplechain-org/go-simplechain/consensus/raft/backend"
 	"github.com/simplechain-org/go-simplechain/core"
 	"github.com/simplechain-org/go-simplechain/core/types"
 	"github.com/simplechain-org/go-simplechain/eth"
+	"github.com/simplechain-org/go-simplechain/eth/downloader"
 	"github.com/simplechain-org/go-simplechain/event"
+	"github.com/simplechain-org/go-simplechain/internal/ethapi"
 	"github.com/simplechain-org/go-simplechain/les"
 	"github.com/simplechain-org/go-simplechain/log"
+	"github.com/simplechain-org/go-simplechain/miner"
 	"github.com/simplechain-org/go-simplechain/p2p"
 	"github.com/simplechain-org/go-simplechain/rpc"
+	"github.com/simplechain-org/go-simplechain/sub"
 )
 
 const (
@@ -66,17 +71,30 @@ type blockChain interface {
 	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
 }
 
+// fullNodeBackend is the full node service being monitored, either the Ethereum
+// service of the main chain or the one of a subchain.
+type fullNodeBackend interface {
+	BlockChain() *core.BlockChain
+	TxPool() *core.TxPool
+	Miner() *miner.Miner
+	Downloader() *downloader.Downloader
+}
+
 // Service implements an Ethereum netstats reporting daemon that pushes local
 // chain statistics up to a monitoring server.
 type Service struct {
-	server *p2p.Server        // Peer-to-peer server to retrieve networking infos
-	eth    *eth.Ethereum      // Full Ethereum service if monitoring a full node
-	les    *les.LightEthereum // Light Ethereum service if monitoring a light node
-	engine consensus.Engine   // Consensus engine to retrieve variadic block fields
+	server *p2p.Server              // Peer-to-peer server to retrieve networking infos
+	eth    fullNodeBackend          // Full Ethereum service if monitoring a full node
+	api    ethapi.Backend           // API backend of the full node, to suggest gas prices
+	les    *les.LightEthereum       // Light Ethereum service if monitoring a light node
+	raft   *raftBackend.RaftService // Raft service if the monitored chain runs raft
+	engine consensus.Engine         // Consensus engine to retrieve variadic block fields
+	cross  CrossPool                // Cross chain service to report the ctx pool of (v2 only)
 
 	node string // Name of the node to display on the monitoring page
 	pass string // Password to authorize access to the monitoring page
 	host string // Remote address of the monitoring service
+	v2   bool   // Whether to report using the v2 protocol
 
 	pongCh chan struct{} // Pong notifications are fed into this channel
 	histCh chan []uint64 // History request block numbers are fed into this channel
@@ -84,23 +102,39 @@ type Service struct {
 
 // New returns a monitoring service ready for stats reporting.
 func New(url string, ethServ *eth.Ethereum, lesServ *les.LightEthereum) (*Service, error) {
-	// Parse the netstats connection url
+	s, err := newService(url)
+	if err != nil {
+		return nil, err
+	}
+	if ethServ != nil {
+		s.eth, s.api, s.engine = ethServ, ethServ.APIBackend, ethServ.Engine()
+	} else {
+		s.les, s.engine = lesServ, lesServ.Engine()
+	}
+	return s, nil
+}
+
+// NewSub returns a monitoring service ready for reporting the stats of a subchain,
+// along with the role of the node in its raft cluster if raftServ is not nil.
+func NewSub(url string, subServ *sub.Ethereum, raftServ *raftBackend.RaftService) (*Service, error) {
+	s, err := newService(url)
+	if err != nil {
+		return nil, err
+	}
+	s.eth, s.api, s.engine = subServ, subServ.APIBackend, subServ.Engine()
+	s.raft = raftServ
+	return s, nil
+}
+
+// newService parses the netstats connection url and creates a stats service
+// without any chain to monitor.
+func newService(url string) (*Service, error) {
 	re := regexp.MustCompile("([^:@]*)(:([^@]*))?@(.+)")
 	parts := re.FindStringSubmatch(url)
 	if len(parts) != 5 {
 		return nil, fmt.Errorf("invalid netstats url: \"%s\", should be nodename:secret@host:port", url)
 	}
-	// Assemble and return the stats service
-	var engine consensus.Engine
-	if ethServ != nil {
-		engine = ethServ.Engine()
-	} else {
-		engine = lesServ.Engine()
-	}
 	return &Service{
-		eth:    ethServ,
-		les:    lesServ,
-		engine: engine,
 		node:   parts[1],
 		pass:   parts[3],
 		host:   parts[4],
@@ -198,6 +232,9 @@ func (s *Service) loop() {
 	for {
 		// Resolve the URL, defaulting to TLS, but falling back to none too
 		path := fmt.Sprintf("%s/api", s.host)
+		if s.v2 {
+			path = fmt.Sprintf("%s/v2", s.host)
+		}
 		urls := []string{path}
 
 		// url.Parse and url.IsAbs is unsuitable (https://github.com/golang/go/issues/19779)
@@ -224,7 +261,12 @@ func (s *Service) loop() {
 			continue
 		}
 		// Authenticate the client with the server
-		if err = s.login(conn); err != nil {
+		if s.v2 {
+			err = s.loginV2(conn)
+		} else {
+			err = s.login(conn)
+		}
+		if err != nil {
 			log.Warn("Stats login failed", "err", err)
 			conn.Close()
 			time.Sleep(10 * time.Second)
@@ -262,6 +304,11 @@ func (s *Service) loop() {
 				if err = s.reportPending(conn); err != nil {
 					log.Warn("Post-block transaction stats report failed", "err", err)
 				}
+				if s.v2 && err == nil {
+					if err = s.reportConsensus(conn); err != nil {
+						log.Warn("Consensus stats report failed", "err", err)
+					}
+				}
 			case <-txCh:
 				if err = s.reportPending(conn); err != nil {
 					log.Warn("Transaction stats report failed", "err", err)
@@ -367,9 +414,8 @@ type authMsg struct {
 	Secret string   `json:"secret"`
 }
 
-// login tries to authorize the client at the remote server.
-func (s *Service) login(conn *websocket.Conn) error {
-	// Construct and send the login authentication
+// nodeInfo assembles the metainformation about the node.
+func (s *Service) nodeInfo() nodeInfo {
 	infos := s.server.NodeInfo()
 
 	var network, protocol string
@@ -380,20 +426,26 @@ func (s *Service) login(conn *websocket.Conn) error {
 		network = fmt.Sprintf("%d", infos.Protocols["les"].(*les.NodeInfo).Network)
 		protocol = fmt.Sprintf("les/%d", les.ClientProtocolVersions[0])
 	}
+	return nodeInfo{
+		Name:     s.node,
+		Node:     infos.Name,
+		Port:     infos.Ports.Listener,
+		Network:  network,
+		Protocol: protocol,
+		API:      "No",
+		Os:       runtime.GOOS,
+		OsVer:    runtime.GOARCH,
+		Client:   "0.1.1",
+		History:  true,
+	}
+}
+
+// login tries to authorize the client at the remote server.
+func (s *Service) login(conn *websocket.Conn) error {
+	// Construct and send the login authentication
 	auth := &authMsg{
-		ID: s.node,
-		Info: nodeInfo{
-			Name:     s.node,
-			Node:     infos.Name,
-			Port:     infos.Ports.Listener,
-			Network:  network,
-			Protocol: protocol,
-			API:      "No",
-			Os:       runtime.GOOS,
-			OsVer:    runtime.GOARCH,
-			Client:   "0.1.1",
-			History:  true,
-		},
+		ID:     s.node,
+		Info:   s.nodeInfo(),
 		Secret: s.pass,
 	}
 	login := map[string][]interface{}{
@@ -426,6 +478,14 @@ func (s *Service) report(conn *websocket.Conn) error {
 	if err := s.reportStats(conn); err != nil {
 		return err
 	}
+	if s.v2 {
+		if err := s.reportConsensus(conn); err != nil {
+			return err
+		}
+		if err := s.reportCross(conn); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -481,6 +541,9 @@ type blockStats struct {
 	TxHash     common.Hash    `json:"transactionsRoot"`
 	Root       common.Hash    `json:"stateRoot"`
 	Uncles     uncleStats     `json:"uncles"`
+
+	Validators []common.Address `json:"validators,omitempty"` // Istanbul validator set
+	Committers []common.Address `json:"committers,omitempty"` // Istanbul validators that committed the block
 }
 
 // txStats is the information to report about individual transactions.
@@ -550,10 +613,11 @@ func (s *Service) assembleBlockStats(block *types.Block) *blockStats {
 		td = s.les.BlockChain().GetTd(header.Hash(), header.Number.Uint64())
 		txs = []txStats{}
 	}
-	// Assemble and return the block stats
+	// Assemble and return the block stats, the author being the signer of the
+	// signature based engines
 	author, _ := s.engine.Author(header)
 
-	return &blockStats{
+	stats := &blockStats{
 		Number:     header.Number,
 		Hash:       header.Hash(),
 		ParentHash: header.ParentHash,
@@ -568,6 +632,13 @@ func (s *Service) assembleBlockStats(block *types.Block) *blockStats {
 		Root:       header.Root,
 		Uncles:     uncles,
 	}
+	if istanbul, ok := s.engine.(consensus.Istanbul); ok {
+		if extra, err := types.ExtractIstanbulExtra(header); err == nil {
+			stats.Validators = extra.Validators
+		}
+		stats.Committers, _ = istanbul.Signers(header)
+	}
+	return stats
 }
 
 // reportHistory retrieves the most recent batch of blocks and reports it to the
@@ -670,6 +741,9 @@ type nodeStats struct {
 	Peers    int  `json:"peers"`
 	GasPrice int  `json:"gasPrice"`
 	Uptime   int  `json:"uptime"`
+
+	Round    *big.Int `json:"round,omitempty"`    // Current Istanbul round
+	RaftRole string   `json:"raftRole,omitempty"` // Role in the raft cluster
 }
 
 // reportPending retrieves various stats about the node at the networking and
@@ -689,12 +763,23 @@ func (s *Service) reportStats(conn *websocket.Conn) error {
 		sync := s.eth.Downloader().Progress()
 		syncing = s.eth.BlockChain().CurrentHeader().Number.Uint64() >= sync.HighestBlock
 
-		price, _ := s.eth.APIBackend.SuggestPrice(context.Background())
+		price, _ := s.api.SuggestPrice(context.Background())
 		gasprice = int(price.Uint64())
 	} else {
 		sync := s.les.Downloader().Progress()
 		syncing = s.les.BlockChain().CurrentHeader().Number.Uint64() >= sync.HighestBlock
 	}
+	// Gather the state of the consensus
+	var (
+		round    *big.Int
+		raftRole string
+	)
+	if istanbul, ok := s.engine.(consensus.Istanbul); ok {
+		round = istanbul.CurrentRound()
+	}
+	if s.raft != nil {
+		raftRole = s.raft.Role()
+	}
 	// Assemble the node stats and send it to the server
 	log.Trace("Sending node details to ethstats")
 
@@ -708,6 +793,8 @@ func (s *Service) reportStats(conn *websocket.Conn) error {
 			GasPrice: gasprice,
 			Syncing:  syncing,
 			Uptime:   100,
+			Round:    round,
+			RaftRole: raftRole,
 		},
 	}
 	report := map[string][]interface{}{
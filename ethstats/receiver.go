@@ -0,0 +1,178 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethstats
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/simplechain-org/go-simplechain/common/hexutil"
+	"github.com/simplechain-org/go-simplechain/crypto"
+	"github.com/simplechain-org/go-simplechain/log"
+	"github.com/simplechain-org/go-simplechain/p2p/enode"
+)
+
+// NodeReport is the latest state reported by a node to a Receiver.
+type NodeReport struct {
+	NodeID  enode.ID                   `json:"nodeId"`
+	Info    json.RawMessage            `json:"info"`
+	Reports map[string]json.RawMessage `json:"reports"` // Latest report of each kind, by emit command
+	Updated time.Time                  `json:"updated"`
+}
+
+// Receiver is a minimal ethstats v2 server, authenticating the nodes by their
+// node key and keeping their latest reports. It's meant for local monitoring and
+// testing: websocket requests are served as v2 connections, plain HTTP requests
+// get the collected reports as JSON.
+type Receiver struct {
+	authorized map[enode.ID]bool // Nodes allowed to report, nil to allow any
+	upgrader   websocket.Upgrader
+
+	nodes map[string]*NodeReport
+	lock  sync.RWMutex
+}
+
+// NewReceiver creates a v2 stats server accepting the reports of the given nodes,
+// or of any node able to sign the challenge if none is given.
+func NewReceiver(authorized []enode.ID) *Receiver {
+	r := &Receiver{nodes: make(map[string]*NodeReport)}
+	if len(authorized) > 0 {
+		r.authorized = make(map[enode.ID]bool, len(authorized))
+		for _, id := range authorized {
+			r.authorized[id] = true
+		}
+	}
+	return r
+}
+
+// Nodes returns a copy of the latest reports of the nodes, by node name.
+func (r *Receiver) Nodes() map[string]*NodeReport {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	nodes := make(map[string]*NodeReport, len(r.nodes))
+	for name, node := range r.nodes {
+		cpy := *node
+		cpy.Reports = make(map[string]json.RawMessage, len(node.Reports))
+		for kind, report := range node.Reports {
+			cpy.Reports[kind] = report
+		}
+		nodes[name] = &cpy
+	}
+	return nodes
+}
+
+// ServeHTTP implements http.Handler.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if !websocket.IsWebSocketUpgrade(req) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(r.Nodes())
+		return
+	}
+	conn, err := r.upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		log.Debug("Failed to upgrade stats connection", "err", err)
+		return
+	}
+	defer conn.Close()
+
+	name, err := r.login(conn, req.Host)
+	if err != nil {
+		log.Debug("Stats node login failed", "addr", req.RemoteAddr, "err", err)
+		return
+	}
+	for {
+		command, args, err := readEmit(conn)
+		if err != nil {
+			log.Debug("Stats node disconnected", "name", name, "err", err)
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+		// Answer the pings for the latency measurements, store anything else
+		if command == "node-ping" {
+			pong := map[string][]interface{}{"emit": {"node-pong", args[0]}}
+			if err := conn.WriteJSON(pong); err != nil {
+				return
+			}
+			continue
+		}
+		r.lock.Lock()
+		r.nodes[name].Reports[command] = args[0]
+		r.nodes[name].Updated = time.Now()
+		r.lock.Unlock()
+	}
+}
+
+// login challenges the node to sign a nonce for the host it dialled with its key,
+// returning its name. A name already reported under a different key is rejected.
+func (r *Receiver) login(conn *websocket.Conn, host string) (string, error) {
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	challenge := map[string][]interface{}{"emit": {"challenge", &challengeMsg{Nonce: nonce}}}
+	if err := conn.WriteJSON(challenge); err != nil {
+		return "", err
+	}
+	command, args, err := readEmit(conn)
+	if err != nil {
+		return "", err
+	}
+	var hello struct {
+		ID        string          `json:"id"`
+		NodeID    enode.ID        `json:"nodeId"`
+		Info      json.RawMessage `json:"info"`
+		Signature hexutil.Bytes   `json:"signature"`
+	}
+	if command != "hello" || len(args) != 1 {
+		return "", errUnauthorized
+	}
+	if err := json.Unmarshal(args[0], &hello); err != nil {
+		return "", err
+	}
+	pubkey, err := crypto.SigToPub(challengeHash(host, hello.ID, nonce), hello.Signature)
+	if err != nil {
+		return "", err
+	}
+	// A signature for another host or name recovers to another key
+	id := enode.PubkeyToIDV4(pubkey)
+	if id != hello.NodeID || (r.authorized != nil && !r.authorized[id]) {
+		return "", errUnauthorized
+	}
+	// Names are chosen by the nodes, keep them bound to the first key using them
+	r.lock.Lock()
+	if node, ok := r.nodes[hello.ID]; ok && node.NodeID != id {
+		r.lock.Unlock()
+		return "", errNameTaken
+	}
+	r.nodes[hello.ID] = &NodeReport{
+		NodeID:  id,
+		Info:    hello.Info,
+		Reports: make(map[string]json.RawMessage),
+		Updated: time.Now(),
+	}
+	r.lock.Unlock()
+
+	log.Info("Stats node logged in", "name", hello.ID, "id", id)
+	return hello.ID, conn.WriteJSON(map[string][]interface{}{"emit": {"ready"}})
+}
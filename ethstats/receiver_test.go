@@ -0,0 +1,142 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethstats
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/simplechain-org/go-simplechain/crypto"
+	"github.com/simplechain-org/go-simplechain/p2p/enode"
+)
+
+// dialReceiver connects to the receiver and logs in as the given node, signing
+// the challenge for host with the key.
+func dialReceiver(t *testing.T, url, host, name string, key *ecdsa.PrivateKey) (*websocket.Conn, error) {
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(url, "http")+"/v2", nil)
+	if err != nil {
+		t.Fatalf("failed to dial receiver: %v", err)
+	}
+	command, args, err := readEmit(conn)
+	if err != nil || command != "challenge" || len(args) != 1 {
+		t.Fatalf("challenge mismatch: %s, %v", command, err)
+	}
+	var challenge challengeMsg
+	if err := json.Unmarshal(args[0], &challenge); err != nil {
+		t.Fatalf("invalid challenge: %v", err)
+	}
+	sig, _ := crypto.Sign(challengeHash(host, name, challenge.Nonce), key)
+	hello := map[string][]interface{}{"emit": {"hello", &helloMsg{ID: name, NodeID: enode.PubkeyToIDV4(&key.PublicKey), Info: nodeInfo{Name: name}, Signature: sig}}}
+	if err := conn.WriteJSON(hello); err != nil {
+		t.Fatalf("failed to send hello: %v", err)
+	}
+	if command, _, err := readEmit(conn); err != nil || command != "ready" {
+		conn.Close()
+		return nil, errUnauthorized
+	}
+	return conn, nil
+}
+
+// Tests that the receiver only accepts the authorized node keys, and keeps the
+// latest reports of the nodes.
+func TestReceiver(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	rival, _ := crypto.GenerateKey()
+
+	receiver := NewReceiver([]enode.ID{enode.PubkeyToIDV4(&key.PublicKey), enode.PubkeyToIDV4(&rival.PublicKey)})
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	// Nodes with unauthorized keys must be rejected
+	if _, err := dialReceiver(t, server.URL, statsHost(server.URL), "other", other); err == nil {
+		t.Fatalf("unauthorized node logged in")
+	}
+	conn, err := dialReceiver(t, server.URL, statsHost(server.URL), "node", key)
+	if err != nil {
+		t.Fatalf("authorized node rejected: %v", err)
+	}
+	defer conn.Close()
+
+	// Authorized nodes must not take over the name of another one
+	if _, err := dialReceiver(t, server.URL, statsHost(server.URL), "node", rival); err == nil {
+		t.Fatalf("node name taken over by another key")
+	}
+	// Pings must be answered, reports stored
+	ping := map[string][]interface{}{"emit": {"node-ping", map[string]string{"id": "node"}}}
+	if err := conn.WriteJSON(ping); err != nil {
+		t.Fatalf("failed to send ping: %v", err)
+	}
+	if command, _, err := readEmit(conn); err != nil || command != "node-pong" {
+		t.Fatalf("pong mismatch: %s, %v", command, err)
+	}
+	report := map[string][]interface{}{"emit": {"consensus", map[string]interface{}{
+		"id":        "node",
+		"consensus": &consensusStats{Engine: "istanbul", Istanbul: &istanbulStats{RoundChanges: 3}},
+	}}}
+	if err := conn.WriteJSON(report); err != nil {
+		t.Fatalf("failed to send report: %v", err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		node := receiver.Nodes()["node"]
+		if node == nil || node.Reports["consensus"] == nil {
+			continue
+		}
+		if node.NodeID != enode.PubkeyToIDV4(&key.PublicKey) {
+			t.Fatalf("node id mismatch: have %x", node.NodeID)
+		}
+		var stats struct {
+			Consensus consensusStats `json:"consensus"`
+		}
+		if err := json.Unmarshal(node.Reports["consensus"], &stats); err != nil {
+			t.Fatalf("invalid stored report: %v", err)
+		}
+		if stats.Consensus.Engine != "istanbul" || stats.Consensus.Istanbul.RoundChanges != 3 {
+			t.Fatalf("stored report mismatch: %+v", stats.Consensus)
+		}
+		if len(receiver.Nodes()) != 1 {
+			t.Fatalf("node count mismatch: have %d, want 1", len(receiver.Nodes()))
+		}
+		return
+	}
+	t.Fatalf("report not stored")
+}
+
+// Tests that a challenge signed for one server is rejected by another one, even
+// if it accepts any node.
+func TestReceiverRelayedChallenge(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+
+	serverA := httptest.NewServer(NewReceiver(nil))
+	defer serverA.Close()
+	serverB := httptest.NewServer(NewReceiver(nil))
+	defer serverB.Close()
+
+	if _, err := dialReceiver(t, serverB.URL, statsHost(serverA.URL), "node", key); err == nil {
+		t.Fatalf("challenge signed for another server accepted")
+	}
+	conn, err := dialReceiver(t, serverB.URL, statsHost(serverB.URL), "node", key)
+	if err != nil {
+		t.Fatalf("challenge signed for the server rejected: %v", err)
+	}
+	conn.Close()
+}
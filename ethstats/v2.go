@@ -0,0 +1,263 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethstats
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/gorilla/websocket"
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/common/hexutil"
+	"github.com/simplechain-org/go-simplechain/consensus"
+	"github.com/simplechain-org/go-simplechain/crypto"
+	"github.com/simplechain-org/go-simplechain/log"
+	"github.com/simplechain-org/go-simplechain/p2p/enode"
+)
+
+// The v2 protocol authenticates the nodes by their p2p node key instead of a
+// shared secret, and reports the state of the consensus engine and of the cross
+// chain pool on top of the legacy stats. The login goes as:
+//
+//	server: {"emit": ["challenge", {"nonce": <hex>}]}
+//	client: {"emit": ["hello", {"id": <name>, "nodeId": <hex>, "info": <node info>, "signature": <hex>}]}
+//	server: {"emit": ["ready"]}
+//
+// where the signature is made with the node key over challengeHash(host, id, nonce),
+// host being the address dialled by the node, so that a challenge relayed from
+// another server is of no use there.
+
+var (
+	errUnauthorized = errors.New("unauthorized")
+	errNameTaken    = errors.New("node name taken by another key")
+)
+
+// CrossPool is the cross chain service reporting the size of the ctx pools.
+type CrossPool interface {
+	PoolStats(chainID *big.Int) (pending int, queued int, err error)
+}
+
+// UseV2 switches the service to the v2 reporting protocol. It must be called
+// before the service is started.
+func (s *Service) UseV2() {
+	s.v2 = true
+}
+
+// SetCrossPool sets the cross chain service whose pool of the monitored chain
+// is reported by the v2 protocol.
+func (s *Service) SetCrossPool(pool CrossPool) {
+	s.cross = pool
+}
+
+// challengeHash returns the digest a node signs to login under the given name
+// with the nonce of the server reached at host.
+func challengeHash(host string, id string, nonce []byte) []byte {
+	return crypto.Keccak256([]byte("ethstats-v2"), []byte(host), []byte{0}, []byte(id), []byte{0}, nonce)
+}
+
+// statsHost returns the host of a stats server address, as sent in the Host
+// header of the websocket requests.
+func statsHost(address string) string {
+	if i := strings.Index(address, "://"); i >= 0 {
+		address = address[i+3:]
+	}
+	if i := strings.Index(address, "/"); i >= 0 {
+		address = address[:i]
+	}
+	return address
+}
+
+// challengeMsg is the login challenge sent by a v2 server.
+type challengeMsg struct {
+	Nonce hexutil.Bytes `json:"nonce"`
+}
+
+// helloMsg is the login message of a v2 client, signed with the node key.
+type helloMsg struct {
+	ID        string        `json:"id"`
+	NodeID    enode.ID      `json:"nodeId"`
+	Info      nodeInfo      `json:"info"`
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// readEmit reads a message from the connection, returning its command and the
+// raw arguments.
+func readEmit(conn *websocket.Conn) (string, []json.RawMessage, error) {
+	var msg map[string][]json.RawMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		return "", nil, err
+	}
+	if len(msg["emit"]) == 0 {
+		return "", nil, errors.New("non-broadcast message")
+	}
+	var command string
+	if err := json.Unmarshal(msg["emit"][0], &command); err != nil {
+		return "", nil, err
+	}
+	return command, msg["emit"][1:], nil
+}
+
+// loginV2 answers the challenge of a v2 server with the signature of the node.
+func (s *Service) loginV2(conn *websocket.Conn) error {
+	command, args, err := readEmit(conn)
+	if err != nil {
+		return err
+	}
+	var challenge challengeMsg
+	if command != "challenge" || len(args) != 1 {
+		return errors.New("missing challenge")
+	}
+	if err := json.Unmarshal(args[0], &challenge); err != nil || len(challenge.Nonce) == 0 {
+		return errors.New("invalid challenge")
+	}
+	sig, err := crypto.Sign(challengeHash(statsHost(s.host), s.node, challenge.Nonce), s.server.PrivateKey)
+	if err != nil {
+		return err
+	}
+	hello := map[string][]interface{}{
+		"emit": {"hello", &helloMsg{ID: s.node, NodeID: enode.PubkeyToIDV4(&s.server.PrivateKey.PublicKey), Info: s.nodeInfo(), Signature: sig}},
+	}
+	if err := conn.WriteJSON(hello); err != nil {
+		return err
+	}
+	// Retrieve the remote ack or connection termination
+	if command, _, err := readEmit(conn); err != nil || command != "ready" {
+		return errUnauthorized
+	}
+	return nil
+}
+
+// consensusStats is the state of the consensus engine reported by the v2
+// protocol, only the section of the running engine being filled.
+type consensusStats struct {
+	Engine   string         `json:"engine"`
+	DPoS     *dposStats     `json:"dpos,omitempty"`
+	Istanbul *istanbulStats `json:"istanbul,omitempty"`
+	Raft     *raftStats     `json:"raft,omitempty"`
+}
+
+// dposStats is the state of the DPoS signers at the head.
+type dposStats struct {
+	SignerQueue   []common.Address          `json:"signerQueue"`
+	MissedSigners []common.Address          `json:"missedSigners"` // Signers that missed their slot before the head
+	Punishments   map[common.Address]uint64 `json:"punishments"`
+}
+
+// istanbulStats is the state of the running Istanbul consensus.
+type istanbulStats struct {
+	Round        *big.Int       `json:"round"`
+	Proposer     common.Address `json:"proposer"`
+	RoundChanges uint64         `json:"roundChanges"`
+}
+
+// raftStats is the state of the node in its raft cluster.
+type raftStats struct {
+	Role         string `json:"role"`
+	Leader       uint16 `json:"leader"`
+	Term         uint64 `json:"term"`
+	AppliedIndex uint64 `json:"appliedIndex"`
+}
+
+// crossStats is the size of the cross chain pool of the monitored chain.
+type crossStats struct {
+	Pending int `json:"pending"`
+	Queued  int `json:"queued"`
+}
+
+// assembleConsensusStats gathers the state of the consensus engine at the head.
+func (s *Service) assembleConsensusStats() *consensusStats {
+	if s.raft != nil {
+		leader, term, applied := s.raft.Status()
+		return &consensusStats{
+			Engine: "raft",
+			Raft:   &raftStats{Role: s.raft.Role(), Leader: leader, Term: term, AppliedIndex: applied},
+		}
+	}
+	switch engine := s.engine.(type) {
+	case consensus.DPoS:
+		stats := &dposStats{SignerQueue: []common.Address{}, MissedSigners: []common.Address{}, Punishments: map[common.Address]uint64{}}
+		if s.eth != nil {
+			chain := s.eth.BlockChain()
+			head := chain.CurrentHeader()
+			if queue, err := engine.SignerQueue(chain, head); err == nil {
+				stats.SignerQueue = queue
+			}
+			if missed, err := engine.MissedSigners(head); err == nil && missed != nil {
+				stats.MissedSigners = missed
+			}
+			if punished, err := engine.Punishments(chain, head); err == nil {
+				stats.Punishments = punished
+			}
+		}
+		return &consensusStats{Engine: "dpos", DPoS: stats}
+
+	case consensus.Istanbul:
+		return &consensusStats{
+			Engine: "istanbul",
+			Istanbul: &istanbulStats{
+				Round:        engine.CurrentRound(),
+				Proposer:     engine.CurrentProposer(),
+				RoundChanges: engine.RoundChanges(),
+			},
+		}
+	case consensus.PoW:
+		return &consensusStats{Engine: "pow"}
+	default:
+		return &consensusStats{Engine: "unknown"}
+	}
+}
+
+// reportConsensus sends the state of the consensus engine to the stats server.
+func (s *Service) reportConsensus(conn *websocket.Conn) error {
+	details := s.assembleConsensusStats()
+
+	log.Trace("Sending consensus stats to ethstats", "engine", details.Engine)
+
+	stats := map[string]interface{}{
+		"id":        s.node,
+		"consensus": details,
+	}
+	report := map[string][]interface{}{
+		"emit": {"consensus", stats},
+	}
+	return conn.WriteJSON(report)
+}
+
+// reportCross sends the size of the cross chain pool of the monitored chain to
+// the stats server, if the node runs the cross chain service.
+func (s *Service) reportCross(conn *websocket.Conn) error {
+	if s.cross == nil || s.eth == nil {
+		return nil
+	}
+	pending, queued, err := s.cross.PoolStats(s.eth.BlockChain().Config().ChainID)
+	if err != nil {
+		log.Debug("Cross pool not available for ethstats", "err", err)
+		return nil
+	}
+	log.Trace("Sending cross pool stats to ethstats", "pending", pending, "queued", queued)
+
+	stats := map[string]interface{}{
+		"id":    s.node,
+		"cross": &crossStats{Pending: pending, Queued: queued},
+	}
+	report := map[string][]interface{}{
+		"emit": {"cross", stats},
+	}
+	return conn.WriteJSON(report)
+}
@@ -20,25 +20,38 @@ package graphql
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math/big"
 	"time"
 
 	"github.com/simplechain-org/go-simplechain"
 	"github.com/simplechain-org/go-simplechain/common"
 	"github.com/simplechain-org/go-simplechain/common/hexutil"
+	"github.com/simplechain-org/go-simplechain/consensus"
 	"github.com/simplechain-org/go-simplechain/core/rawdb"
 	"github.com/simplechain-org/go-simplechain/core/state"
 	"github.com/simplechain-org/go-simplechain/core/types"
 	"github.com/simplechain-org/go-simplechain/core/vm"
+	cc "github.com/simplechain-org/go-simplechain/cross/core"
+	cdb "github.com/simplechain-org/go-simplechain/cross/database"
 	"github.com/simplechain-org/go-simplechain/eth/filters"
+	"github.com/simplechain-org/go-simplechain/eth/tokenindex"
 	"github.com/simplechain-org/go-simplechain/internal/ethapi"
-	"github.com/simplechain-org/go-simplechain/rlp"
+	"github.com/simplechain-org/go-simplechain/params"
 	"github.com/simplechain-org/go-simplechain/rpc"
+
+	"github.com/asdine/storm/v3/q"
 )
 
 var (
 	errBlockInvariant = errors.New("block objects must be instantiated with at least one of num or hash")
 )
 
+const (
+	defaultCrossPageSize = 100  // Default number of cross-chain transactions per page
+	maxCrossPageSize     = 1000 // Maximum number of cross-chain transactions per page
+)
+
 // Account represents an Ethereum account at a particular block.
 type Account struct {
 	backend       ethapi.Backend
@@ -123,6 +136,7 @@ func (l *Log) Data(ctx context.Context) hexutil.Bytes {
 // backend and hash are mandatory; all others will be fetched when required.
 type Transaction struct {
 	backend ethapi.Backend
+	cross   CrossBackend
 	hash    common.Hash
 	tx      *types.Transaction
 	block   *Block
@@ -138,6 +152,7 @@ func (t *Transaction) resolve(ctx context.Context) (*types.Transaction, error) {
 			blockNrOrHash := rpc.BlockNumberOrHashWithHash(blockHash, false)
 			t.block = &Block{
 				backend:      t.backend,
+				cross:        t.cross,
 				numberOrHash: &blockNrOrHash,
 			}
 			t.index = index
@@ -321,6 +336,7 @@ type BlockType int
 // when required.
 type Block struct {
 	backend      ethapi.Backend
+	cross        CrossBackend
 	numberOrHash *rpc.BlockNumberOrHash
 	hash         common.Hash
 	header       *types.Header
@@ -435,6 +451,7 @@ func (b *Block) Parent(ctx context.Context) (*Block, error) {
 		num := rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(b.header.Number.Uint64() - 1))
 		return &Block{
 			backend:      b.backend,
+			cross:        b.cross,
 			numberOrHash: &num,
 			hash:         b.header.ParentHash,
 		}, nil
@@ -525,6 +542,7 @@ func (b *Block) Ommers(ctx context.Context) (*[]*Block, error) {
 		blockNumberOrHash := rpc.BlockNumberOrHashWithHash(uncle.Hash(), false)
 		ret = append(ret, &Block{
 			backend:      b.backend,
+			cross:        b.cross,
 			numberOrHash: &blockNumberOrHash,
 			header:       uncle,
 		})
@@ -596,6 +614,102 @@ func (b *Block) Miner(ctx context.Context, args BlockNumberArgs) (*Account, erro
 	}, nil
 }
 
+func (b *Block) Signer(ctx context.Context) (*common.Address, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := b.backend.Engine().Author(header)
+	if err != nil {
+		return nil, nil
+	}
+	return &signer, nil
+}
+
+func (b *Block) Validators(ctx context.Context) (*[]common.Address, error) {
+	if _, ok := b.backend.Engine().(consensus.Istanbul); !ok {
+		return nil, nil
+	}
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	extra, err := types.ExtractIstanbulExtra(header)
+	if err != nil {
+		return nil, err
+	}
+	return &extra.Validators, nil
+}
+
+func (b *Block) Committers(ctx context.Context) (*[]common.Address, error) {
+	istanbul, ok := b.backend.Engine().(consensus.Istanbul)
+	if !ok {
+		return nil, nil
+	}
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	committers, err := istanbul.Signers(header)
+	if err != nil {
+		return nil, err
+	}
+	return &committers, nil
+}
+
+func (b *Block) SignerQueue(ctx context.Context) (*[]common.Address, error) {
+	dpos, ok := b.backend.Engine().(consensus.DPoS)
+	if !ok {
+		return nil, nil
+	}
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	queue, err := dpos.SignerQueue(&chainReader{ctx, b.backend}, header)
+	if err != nil {
+		return nil, err
+	}
+	return &queue, nil
+}
+
+func (b *Block) Confirmations(ctx context.Context) (*[]common.Address, error) {
+	dpos, ok := b.backend.Engine().(consensus.DPoS)
+	if !ok {
+		return nil, nil
+	}
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	confirmations, err := dpos.Confirmations(&chainReader{ctx, b.backend}, b.backend.CurrentBlock().Header(), header.Number.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	return &confirmations, nil
+}
+
+func (b *Block) CrossTransactions(ctx context.Context) (*[]*CrossTransaction, error) {
+	if b.cross == nil {
+		return nil, nil
+	}
+	store, err := b.cross.CtxStore(b.backend.ChainConfig().ChainID)
+	if err != nil {
+		return nil, err
+	}
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	ret := make([]*CrossTransaction, 0)
+	for _, tx := range block.Transactions() {
+		for _, cws := range store.Query(0, 0, nil, false, q.Eq(cdb.TxHashIndex, tx.Hash())) {
+			ret = append(ret, &CrossTransaction{cws})
+		}
+	}
+	return &ret, nil
+}
+
 func (b *Block) TransactionCount(ctx context.Context) (*int32, error) {
 	block, err := b.resolve(ctx)
 	if err != nil || block == nil {
@@ -614,6 +728,7 @@ func (b *Block) Transactions(ctx context.Context) (*[]*Transaction, error) {
 	for i, tx := range block.Transactions() {
 		ret = append(ret, &Transaction{
 			backend: b.backend,
+			cross:   b.cross,
 			hash:    tx.Hash(),
 			tx:      tx,
 			block:   b,
@@ -635,6 +750,7 @@ func (b *Block) TransactionAt(ctx context.Context, args struct{ Index int32 }) (
 	tx := txs[args.Index]
 	return &Transaction{
 		backend: b.backend,
+		cross:   b.cross,
 		hash:    tx.Hash(),
 		tx:      tx,
 		block:   b,
@@ -655,6 +771,7 @@ func (b *Block) OmmerAt(ctx context.Context, args struct{ Index int32 }) (*Block
 	blockNumberOrHash := rpc.BlockNumberOrHashWithHash(uncle.Hash(), false)
 	return &Block{
 		backend:      b.backend,
+		cross:        b.cross,
 		numberOrHash: &blockNumberOrHash,
 		header:       uncle,
 	}, nil
@@ -681,7 +798,7 @@ type BlockFilterCriteria struct {
 
 // runFilter accepts a filter and executes it, returning all its results as
 // `Log` objects.
-func runFilter(ctx context.Context, be ethapi.Backend, filter *filters.Filter) ([]*Log, error) {
+func runFilter(ctx context.Context, be ethapi.Backend, cross CrossBackend, filter *filters.Filter) ([]*Log, error) {
 	logs, err := filter.Logs(ctx)
 	if err != nil || logs == nil {
 		return nil, err
@@ -690,7 +807,7 @@ func runFilter(ctx context.Context, be ethapi.Backend, filter *filters.Filter) (
 	for _, log := range logs {
 		ret = append(ret, &Log{
 			backend:     be,
-			transaction: &Transaction{backend: be, hash: log.TxHash},
+			transaction: &Transaction{backend: be, cross: cross, hash: log.TxHash},
 			log:         log,
 		})
 	}
@@ -718,7 +835,7 @@ func (b *Block) Logs(ctx context.Context, args struct{ Filter BlockFilterCriteri
 	filter := filters.NewBlockFilter(b.backend, hash, addresses, topics)
 
 	// Run the filter and return all the logs
-	return runFilter(ctx, b.backend, filter)
+	return runFilter(ctx, b.backend, b.cross, filter)
 }
 
 func (b *Block) Account(ctx context.Context, args struct {
@@ -861,9 +978,53 @@ func (p *Pending) EstimateGas(ctx context.Context, args struct {
 	return ethapi.DoEstimateGas(ctx, p.backend, args.Data, pendingBlockNr, p.backend.RPCGasCap())
 }
 
+// chainReader adapts the backend to the chain reader used by the consensus
+// engines to retrieve their snapshots.
+type chainReader struct {
+	ctx     context.Context
+	backend ethapi.Backend
+}
+
+func (c *chainReader) Config() *params.ChainConfig {
+	return c.backend.ChainConfig()
+}
+
+func (c *chainReader) CurrentHeader() *types.Header {
+	return c.backend.CurrentBlock().Header()
+}
+
+func (c *chainReader) GetHeader(hash common.Hash, number uint64) *types.Header {
+	header, _ := c.backend.HeaderByHash(c.ctx, hash)
+	if header == nil || header.Number.Uint64() != number {
+		return nil
+	}
+	return header
+}
+
+func (c *chainReader) GetHeaderByNumber(number uint64) *types.Header {
+	header, _ := c.backend.HeaderByNumber(c.ctx, rpc.BlockNumber(number))
+	return header
+}
+
+func (c *chainReader) GetHeaderByHash(hash common.Hash) *types.Header {
+	header, _ := c.backend.HeaderByHash(c.ctx, hash)
+	return header
+}
+
+func (c *chainReader) GetBlock(hash common.Hash, number uint64) *types.Block {
+	block, _ := c.backend.BlockByHash(c.ctx, hash)
+	if block == nil || block.NumberU64() != number {
+		return nil
+	}
+	return block
+}
+
 // Resolver is the top-level object in the GraphQL hierarchy.
 type Resolver struct {
 	backend ethapi.Backend
+	raft    RaftNode     // Raft service of the chain, nil if it doesn't run raft
+	cross   CrossBackend // Cross service of the anchor, nil if the node isn't an anchor
+	tokens  TokenBackend // Token index of the chain, nil if it's disabled
 }
 
 func (r *Resolver) Block(ctx context.Context, args struct {
@@ -876,18 +1037,21 @@ func (r *Resolver) Block(ctx context.Context, args struct {
 		numberOrHash := rpc.BlockNumberOrHashWithNumber(number)
 		block = &Block{
 			backend:      r.backend,
+			cross:        r.cross,
 			numberOrHash: &numberOrHash,
 		}
 	} else if args.Hash != nil {
 		numberOrHash := rpc.BlockNumberOrHashWithHash(*args.Hash, false)
 		block = &Block{
 			backend:      r.backend,
+			cross:        r.cross,
 			numberOrHash: &numberOrHash,
 		}
 	} else {
 		numberOrHash := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
 		block = &Block{
 			backend:      r.backend,
+			cross:        r.cross,
 			numberOrHash: &numberOrHash,
 		}
 	}
@@ -923,6 +1087,7 @@ func (r *Resolver) Blocks(ctx context.Context, args struct {
 		numberOrHash := rpc.BlockNumberOrHashWithNumber(i)
 		ret = append(ret, &Block{
 			backend:      r.backend,
+			cross:        r.cross,
 			numberOrHash: &numberOrHash,
 		})
 	}
@@ -936,6 +1101,7 @@ func (r *Resolver) Pending(ctx context.Context) *Pending {
 func (r *Resolver) Transaction(ctx context.Context, args struct{ Hash common.Hash }) (*Transaction, error) {
 	tx := &Transaction{
 		backend: r.backend,
+		cross:   r.cross,
 		hash:    args.Hash,
 	}
 	// Resolve the transaction; if it doesn't exist, return nil.
@@ -950,7 +1116,7 @@ func (r *Resolver) Transaction(ctx context.Context, args struct{ Hash common.Has
 
 func (r *Resolver) SendRawTransaction(ctx context.Context, args struct{ Data hexutil.Bytes }) (common.Hash, error) {
 	tx := new(types.Transaction)
-	if err := rlp.DecodeBytes(args.Data, tx); err != nil {
+	if err := tx.UnmarshalBinary(args.Data); err != nil {
 		return common.Hash{}, err
 	}
 	hash, err := ethapi.SubmitTransaction(ctx, r.backend, tx)
@@ -997,7 +1163,7 @@ func (r *Resolver) Logs(ctx context.Context, args struct{ Filter FilterCriteria
 	}
 	// Construct the range filter
 	filter := filters.NewRangeFilter(filters.Backend(r.backend), begin, end, addresses, topics)
-	return runFilter(ctx, r.backend, filter)
+	return runFilter(ctx, r.backend, r.cross, filter)
 }
 
 func (r *Resolver) GasPrice(ctx context.Context) (hexutil.Big, error) {
@@ -1053,3 +1219,355 @@ func (r *Resolver) Syncing() (*SyncState, error) {
 	// Otherwise gather the block sync stats
 	return &SyncState{progress}, nil
 }
+
+// ConsensusState represents the state of the consensus engine returned from the
+// `consensus` accessor.
+type ConsensusState struct {
+	engine consensus.Engine
+	raft   RaftNode
+}
+
+func (s *ConsensusState) Round() *hexutil.Uint64 {
+	istanbul, ok := s.engine.(consensus.Istanbul)
+	if !ok {
+		return nil
+	}
+	round := istanbul.CurrentRound()
+	if round == nil {
+		return nil
+	}
+	r := hexutil.Uint64(round.Uint64())
+	return &r
+}
+
+func (s *ConsensusState) RaftRole() *string {
+	if s.raft == nil {
+		return nil
+	}
+	role := s.raft.Role()
+	return &role
+}
+
+func (r *Resolver) Consensus() *ConsensusState {
+	return &ConsensusState{engine: r.backend.Engine(), raft: r.raft}
+}
+
+// CrossTransaction represents a cross-chain transaction indexed by the cross
+// service of an anchor node.
+type CrossTransaction struct {
+	ctx *cc.CrossTransactionWithSignatures
+}
+
+func (t *CrossTransaction) ID() common.Hash {
+	return t.ctx.ID()
+}
+
+func (t *CrossTransaction) Status() string {
+	return t.ctx.Status.String()
+}
+
+func (t *CrossTransaction) ChainID() hexutil.Big {
+	return hexutil.Big(*t.ctx.ChainId())
+}
+
+func (t *CrossTransaction) DestinationID() hexutil.Big {
+	return hexutil.Big(*t.ctx.DestinationId())
+}
+
+func (t *CrossTransaction) Maker() common.Address {
+	return t.ctx.Data.From
+}
+
+func (t *CrossTransaction) Taker() common.Address {
+	return t.ctx.Data.To
+}
+
+func (t *CrossTransaction) Value() hexutil.Big {
+	return hexutil.Big(*t.ctx.Data.Value)
+}
+
+func (t *CrossTransaction) DestinationValue() hexutil.Big {
+	return hexutil.Big(*t.ctx.Data.DestinationValue)
+}
+
+func (t *CrossTransaction) Data() hexutil.Bytes {
+	return t.ctx.Data.Input
+}
+
+func (t *CrossTransaction) TransactionHash() common.Hash {
+	return t.ctx.Data.TxHash
+}
+
+func (t *CrossTransaction) BlockHash() common.Hash {
+	return t.ctx.Data.BlockHash
+}
+
+func (t *CrossTransaction) BlockNumber() hexutil.Uint64 {
+	return hexutil.Uint64(t.ctx.BlockNum)
+}
+
+func (t *CrossTransaction) Signatures() []*CrossSignature {
+	signer := cc.MakeCtxSigner(t.ctx.ChainId())
+	resolution := t.ctx.Resolution()
+	ret := make([]*CrossSignature, 0, len(resolution))
+	for _, ctx := range resolution {
+		ret = append(ret, &CrossSignature{signer, ctx})
+	}
+	return ret
+}
+
+// CrossSignature represents the signature of an anchor on a cross-chain
+// transaction.
+type CrossSignature struct {
+	signer cc.CtxSigner
+	ctx    *cc.CrossTransaction
+}
+
+func (s *CrossSignature) V() hexutil.Big {
+	return hexutil.Big(*s.ctx.Data.V)
+}
+
+func (s *CrossSignature) R() hexutil.Big {
+	return hexutil.Big(*s.ctx.Data.R)
+}
+
+func (s *CrossSignature) S() hexutil.Big {
+	return hexutil.Big(*s.ctx.Data.S)
+}
+
+func (s *CrossSignature) Signer() *common.Address {
+	signer, err := cc.CtxSender(s.signer, s.ctx)
+	if err != nil {
+		return nil
+	}
+	return &signer
+}
+
+func (r *Resolver) CrossTransaction(ctx context.Context, args struct{ ID common.Hash }) (*CrossTransaction, error) {
+	if r.cross == nil {
+		return nil, nil
+	}
+	for _, chainID := range r.cross.Chains() {
+		store, err := r.cross.CtxStore(chainID)
+		if err != nil {
+			return nil, err
+		}
+		if cws, err := store.Read(args.ID); err == nil {
+			return &CrossTransaction{cws}, nil
+		}
+	}
+	return nil, nil
+}
+
+// CrossTransactionFilterCriteria encapsulates the arguments to `crossTransactions`
+// on the root resolver object.
+type CrossTransactionFilterCriteria struct {
+	ChainID   *hexutil.Big    // chain the transactions were made on, nil means this chain
+	Status    *string         // restricts matches to a status
+	Maker     *common.Address // restricts matches to a maker
+	Taker     *common.Address // restricts matches to a taker
+	FromBlock *hexutil.Uint64 // beginning of the queried range of status update blocks
+	ToBlock   *hexutil.Uint64 // end of the queried range of status update blocks
+}
+
+func (r *Resolver) CrossTransactions(ctx context.Context, args struct {
+	Filter   CrossTransactionFilterCriteria
+	PageSize *int32
+	Page     *int32
+}) (*[]*CrossTransaction, error) {
+	if r.cross == nil {
+		return nil, nil
+	}
+	var chainID *big.Int
+	if args.Filter.ChainID != nil {
+		chainID = args.Filter.ChainID.ToInt()
+	} else {
+		chainID = r.backend.ChainConfig().ChainID
+	}
+	store, err := r.cross.CtxStore(chainID)
+	if err != nil {
+		return nil, err
+	}
+	var matchers []q.Matcher
+	if args.Filter.Status != nil {
+		var status cc.CtxStatus
+		if err := status.UnmarshalText([]byte(*args.Filter.Status)); err != nil {
+			return nil, fmt.Errorf("invalid cross transaction status %q", *args.Filter.Status)
+		}
+		matchers = append(matchers, q.Eq(cdb.StatusField, uint8(status)))
+	}
+	if args.Filter.Maker != nil {
+		matchers = append(matchers, q.Eq(cdb.FromField, *args.Filter.Maker))
+	}
+	if args.Filter.Taker != nil {
+		matchers = append(matchers, q.Eq(cdb.ToField, *args.Filter.Taker))
+	}
+	if args.Filter.FromBlock != nil {
+		matchers = append(matchers, q.Gte(cdb.BlockNumField, uint64(*args.Filter.FromBlock)))
+	}
+	if args.Filter.ToBlock != nil {
+		matchers = append(matchers, q.Lte(cdb.BlockNumField, uint64(*args.Filter.ToBlock)))
+	}
+	pageSize, page := defaultCrossPageSize, 1
+	if args.PageSize != nil {
+		pageSize = int(*args.PageSize)
+	}
+	if args.Page != nil {
+		page = int(*args.Page)
+	}
+	if pageSize <= 0 || pageSize > maxCrossPageSize {
+		return nil, fmt.Errorf("page size must be in range [1, %d]", maxCrossPageSize)
+	}
+	ret := make([]*CrossTransaction, 0, pageSize)
+	for _, cws := range store.Query(pageSize, page, []cdb.FieldName{cdb.BlockNumField}, false, matchers...) {
+		ret = append(ret, &CrossTransaction{cws})
+	}
+	return &ret, nil
+}
+
+// TokenBalance represents the balance of a token held by an account.
+type TokenBalance struct {
+	b *tokenindex.TokenBalance
+}
+
+func (b *TokenBalance) Token() common.Address {
+	return b.b.Token
+}
+
+func (b *TokenBalance) Standard() string {
+	return b.b.Standard
+}
+
+func (b *TokenBalance) Balance() hexutil.Big {
+	return *b.b.Balance
+}
+
+func (r *Resolver) TokenBalances(ctx context.Context, args struct{ Holder common.Address }) (*[]*TokenBalance, error) {
+	if r.tokens == nil {
+		return nil, nil
+	}
+	balances, err := r.tokens.GetTokenBalances(args.Holder)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*TokenBalance, 0, len(balances.Balances))
+	for _, b := range balances.Balances {
+		ret = append(ret, &TokenBalance{b})
+	}
+	return &ret, nil
+}
+
+// TokenTransfer represents a Transfer or Approval event of a token.
+type TokenTransfer struct {
+	t *tokenindex.TokenTransfer
+}
+
+func (t *TokenTransfer) Event() string {
+	return t.t.Event
+}
+
+func (t *TokenTransfer) Standard() string {
+	return t.t.Standard
+}
+
+func (t *TokenTransfer) Token() common.Address {
+	return t.t.Token
+}
+
+func (t *TokenTransfer) From() common.Address {
+	return t.t.From
+}
+
+func (t *TokenTransfer) To() common.Address {
+	return t.t.To
+}
+
+func (t *TokenTransfer) Value() *hexutil.Big {
+	return t.t.Value
+}
+
+func (t *TokenTransfer) TokenID() *hexutil.Big {
+	return t.t.TokenID
+}
+
+func (t *TokenTransfer) BlockHash() common.Hash {
+	return t.t.BlockHash
+}
+
+func (t *TokenTransfer) BlockNumber() hexutil.Uint64 {
+	return t.t.BlockNumber
+}
+
+func (t *TokenTransfer) TransactionHash() common.Hash {
+	return t.t.TransactionHash
+}
+
+func (t *TokenTransfer) LogIndex() int32 {
+	return int32(t.t.LogIndex)
+}
+
+// TokenTransferPage represents a page of the token events involving an account.
+type TokenTransferPage struct {
+	p *tokenindex.TokenTransfers
+}
+
+func (p *TokenTransferPage) Transfers() []*TokenTransfer {
+	ret := make([]*TokenTransfer, 0, len(p.p.Transfers))
+	for _, t := range p.p.Transfers {
+		ret = append(ret, &TokenTransfer{t})
+	}
+	return ret
+}
+
+func (p *TokenTransferPage) Cursor() *hexutil.Bytes {
+	if len(p.p.Cursor) == 0 {
+		return nil
+	}
+	return &p.p.Cursor
+}
+
+func (p *TokenTransferPage) IndexedBlock() *hexutil.Uint64 {
+	return p.p.IndexedBlock
+}
+
+// TokenTransferFilterCriteria encapsulates the arguments to `tokenTransfers` on
+// the root resolver object.
+type TokenTransferFilterCriteria struct {
+	Token     *common.Address // restricts matches to a token
+	Approvals *bool           // whether to include the Approval events
+	FromBlock *hexutil.Uint64 // beginning of the queried range of blocks
+	ToBlock   *hexutil.Uint64 // end of the queried range of blocks
+}
+
+func (r *Resolver) TokenTransfers(ctx context.Context, args struct {
+	Holder common.Address
+	Filter *TokenTransferFilterCriteria
+	Limit  *int32
+	Cursor *hexutil.Bytes
+}) (*TokenTransferPage, error) {
+	if r.tokens == nil {
+		return nil, nil
+	}
+	query := new(tokenindex.TransferQuery)
+	if args.Filter != nil {
+		query.Token = args.Filter.Token
+		query.Approvals = args.Filter.Approvals != nil && *args.Filter.Approvals
+		query.FromBlock = args.Filter.FromBlock
+		query.ToBlock = args.Filter.ToBlock
+	}
+	if args.Limit != nil {
+		if *args.Limit <= 0 {
+			return nil, errors.New("limit must be positive")
+		}
+		limit := hexutil.Uint(*args.Limit)
+		query.Limit = &limit
+	}
+	if args.Cursor != nil {
+		query.Cursor = *args.Cursor
+	}
+	transfers, err := r.tokens.GetTokenTransfers(args.Holder, query)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenTransferPage{transfers}, nil
+}
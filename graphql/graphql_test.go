@@ -17,12 +17,117 @@
 package graphql
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
 	"testing"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/core/rawdb"
+	cc "github.com/simplechain-org/go-simplechain/cross/core"
+	cdb "github.com/simplechain-org/go-simplechain/cross/database"
+	"github.com/simplechain-org/go-simplechain/crypto"
 )
 
 func TestBuildSchema(t *testing.T) {
 	// Make sure the schema can be parsed and matched up to the object model.
-	if _, err := newHandler(nil); err != nil {
+	if _, err := newHandler(&Resolver{}); err != nil {
 		t.Errorf("Could not construct GraphQL handler: %v", err)
 	}
 }
+
+// testCrossBackend serves the ctx stores of the chains from memory.
+type testCrossBackend map[uint64]cdb.CtxDB
+
+func (b testCrossBackend) Chains() []*big.Int {
+	var ids []*big.Int
+	for id := range b {
+		ids = append(ids, new(big.Int).SetUint64(id))
+	}
+	return ids
+}
+
+func (b testCrossBackend) CtxStore(chainID *big.Int) (cdb.CtxDB, error) {
+	store, ok := b[chainID.Uint64()]
+	if !ok {
+		return nil, errors.New("unknown chain")
+	}
+	return store, nil
+}
+
+func TestCrossTransactions(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	anchor := crypto.PubkeyToAddress(key.PublicKey)
+
+	var (
+		chainID = big.NewInt(1)
+		signer  = cc.MakeCtxSigner(chainID)
+		store   = cdb.NewKVIndexDB(chainID, rawdb.NewMemoryDatabase(), 10)
+		makers  = []common.Address{{0x01}, {0x02}, {0x01}}
+	)
+	for i, maker := range makers {
+		tx := cc.NewCrossTransaction(big.NewInt(100), big.NewInt(10), big.NewInt(2), common.Hash{byte(i + 1)},
+			common.Hash{0xaa, byte(i)}, common.Hash{0xbb}, maker, common.Address{0x03}, nil)
+		tx, err := cc.SignCtx(tx, signer, func(hash []byte) ([]byte, error) { return crypto.Sign(hash, key) })
+		if err != nil {
+			t.Fatalf("failed to sign ctx: %v", err)
+		}
+		cws := cc.NewCrossTransactionWithSignatures(tx, uint64(i+1))
+		if i == 2 {
+			cws.SetStatus(cc.CtxStatusFinished)
+		}
+		if err := store.Write(cws); err != nil {
+			t.Fatalf("failed to write ctx: %v", err)
+		}
+	}
+	s, err := graphql.ParseSchema(schema, &Resolver{cross: testCrossBackend{1: store}})
+	if err != nil {
+		t.Fatalf("failed to parse schema: %v", err)
+	}
+	query := func(q string) map[string]interface{} {
+		res := s.Exec(context.Background(), q, "", nil)
+		if len(res.Errors) > 0 {
+			t.Fatalf("query %s failed: %v", q, res.Errors)
+		}
+		var data map[string]interface{}
+		if err := json.Unmarshal(res.Data, &data); err != nil {
+			t.Fatalf("failed to decode result: %v", err)
+		}
+		return data
+	}
+	// Filter by maker and status
+	data := query(`{ crossTransactions(filter: {chainId: 1, maker: "0x0100000000000000000000000000000000000000", status: "waiting"}) { id blockNumber } }`)
+	list := data["crossTransactions"].([]interface{})
+	if len(list) != 0 {
+		t.Fatalf("waiting ctxs mismatch: have %d, want 0", len(list))
+	}
+	data = query(`{ crossTransactions(filter: {chainId: 1, maker: "0x0100000000000000000000000000000000000000"}) { id status } }`)
+	list = data["crossTransactions"].([]interface{})
+	if len(list) != 2 {
+		t.Fatalf("maker ctxs mismatch: have %d, want 2", len(list))
+	}
+	if status := list[1].(map[string]interface{})["status"]; status != "finished" {
+		t.Fatalf("ctx status mismatch: have %v, want finished", status)
+	}
+	// Retrieve a single ctx with its signatures
+	data = query(`{ crossTransaction(id: "0x0200000000000000000000000000000000000000000000000000000000000000") { maker taker chainId destinationId signatures { signer } } }`)
+	ctx := data["crossTransaction"].(map[string]interface{})
+	if ctx["maker"] != "0x0200000000000000000000000000000000000000" {
+		t.Fatalf("maker mismatch: have %v", ctx["maker"])
+	}
+	if ctx["chainId"] != "0x1" || ctx["destinationId"] != "0x2" {
+		t.Fatalf("chain ids mismatch: have %v -> %v, want 0x1 -> 0x2", ctx["chainId"], ctx["destinationId"])
+	}
+	sigs := ctx["signatures"].([]interface{})
+	if len(sigs) != 1 || sigs[0].(map[string]interface{})["signer"] != strings.ToLower(anchor.Hex()) {
+		t.Fatalf("signers mismatch: have %v, want %v", sigs, anchor)
+	}
+	// Unknown ctxs resolve to null
+	data = query(`{ crossTransaction(id: "0xff00000000000000000000000000000000000000000000000000000000000000") { id } }`)
+	if data["crossTransaction"] != nil {
+		t.Fatalf("unknown ctx resolved: %v", data["crossTransaction"])
+	}
+}
@@ -158,6 +158,22 @@ const schema string = `
         receiptsRoot: Bytes32!
         # Miner is the account that mined this block.
         miner(block: Long): Account!
+        # Signer is the account that sealed this block, as recovered by the
+        # consensus engine, e.g. the DPoS signer or the Istanbul proposer.
+        signer: Address
+        # Validators is the Istanbul validator set of this block. For other
+        # consensus engines, this field will be null.
+        validators: [Address!]
+        # Committers are the Istanbul validators whose committed seals are in
+        # this block. For other consensus engines, this field will be null.
+        committers: [Address!]
+        # SignerQueue is the DPoS signer queue in turn at this block. For other
+        # consensus engines, this field will be null.
+        signerQueue: [Address!]
+        # Confirmations are the DPoS signers that confirmed this block, as known
+        # at the current head. For other consensus engines, this field will be
+        # null.
+        confirmations: [Address!]
         # ExtraData is an arbitrary data field supplied by the miner.
         extraData: Bytes!
         # GasLimit is the maximum amount of gas that was available to transactions in this block.
@@ -206,6 +222,10 @@ const schema string = `
         # EstimateGas estimates the amount of gas that will be required for
         # successful execution of a transaction at the current block's state.
         estimateGas(data: CallData!): Long!
+        # CrossTransactions is a list of cross-chain transactions made in this
+        # block. If the node doesn't index cross-chain transactions, this field
+        # will be null.
+        crossTransactions: [CrossTransaction!]
     }
 
     # CallData represents the data associated with a local contract call.
@@ -291,6 +311,137 @@ const schema string = `
       estimateGas(data: CallData!): Long!
     }
 
+    # CrossTransaction is a cross-chain transaction, made on one chain by a maker
+    # and taken on the destination chain by a taker.
+    type CrossTransaction {
+        # ID is the identifier of the cross-chain transaction.
+        id: Bytes32!
+        # Status is the status of the cross-chain transaction, one of pending,
+        # waiting, illegal, executing, executed, finishing and finished.
+        status: String!
+        # ChainID is the ID of the chain the transaction was made on.
+        chainId: BigInt!
+        # DestinationID is the ID of the chain the transaction is taken on.
+        destinationId: BigInt!
+        # Maker is the account that made the transaction.
+        maker: Address!
+        # Taker is the account the transaction is made for on the destination
+        # chain.
+        taker: Address!
+        # Value is the value, in wei, offered by the maker.
+        value: BigInt!
+        # DestinationValue is the value, in wei, asked on the destination chain.
+        destinationValue: BigInt!
+        # Data is the data supplied by the maker.
+        data: Bytes!
+        # TransactionHash is the hash of the transaction that made it.
+        transactionHash: Bytes32!
+        # BlockHash is the hash of the block the transaction was made in.
+        blockHash: Bytes32!
+        # BlockNumber is the number of the block the status was last updated in.
+        blockNumber: Long!
+        # Signatures are the signatures of the anchors on the transaction.
+        signatures: [CrossSignature!]!
+    }
+
+    # CrossSignature is the signature of an anchor on a cross-chain transaction.
+    type CrossSignature {
+        v: BigInt!
+        r: BigInt!
+        s: BigInt!
+        # Signer is the anchor recovered from the signature. If the signature
+        # is invalid, this field will be null.
+        signer: Address
+    }
+
+    # CrossTransactionFilterCriteria encapsulates criteria for searching
+    # cross-chain transactions.
+    input CrossTransactionFilterCriteria {
+        # ChainID is the chain the transactions were made on. If not supplied,
+        # defaults to this chain.
+        chainId: BigInt
+        # Status restricts matches to transactions with this status.
+        status: String
+        # Maker restricts matches to transactions made by this account.
+        maker: Address
+        # Taker restricts matches to transactions made for this account.
+        taker: Address
+        # FromBlock and ToBlock restrict matches to transactions whose status
+        # was last updated in this range of blocks.
+        fromBlock: Long
+        toBlock: Long
+    }
+
+    # TokenBalance is the balance of an ERC-20 or ERC-721 token held by an
+    # account.
+    type TokenBalance {
+        # Token is the address of the token contract.
+        token: Address!
+        # Standard is the token standard, ERC20 or ERC721.
+        standard: String!
+        # Balance is the amount of ERC-20 tokens, or the number of ERC-721
+        # tokens held.
+        balance: BigInt!
+    }
+
+    # TokenTransfer is a Transfer or Approval event of a token.
+    type TokenTransfer {
+        # Event is the name of the event, Transfer or Approval.
+        event: String!
+        # Standard is the token standard, ERC20 or ERC721.
+        standard: String!
+        # Token is the address of the token contract.
+        token: Address!
+        # From is the sender, or the owner for approvals.
+        from: Address!
+        # To is the recipient, or the spender for approvals.
+        to: Address!
+        # Value is the amount of ERC-20 tokens. For ERC-721 tokens, this field
+        # will be null.
+        value: BigInt
+        # TokenID is the id of the ERC-721 token. For ERC-20 tokens, this field
+        # will be null.
+        tokenId: BigInt
+        blockHash: Bytes32!
+        blockNumber: Long!
+        transactionHash: Bytes32!
+        # LogIndex is the index of the event log in the block.
+        logIndex: Int!
+    }
+
+    # TokenTransferPage is a page of the token events involving an account.
+    type TokenTransferPage {
+        transfers: [TokenTransfer!]!
+        # Cursor is the cursor of the next page. If this is the last page, this
+        # field will be null.
+        cursor: Bytes
+        # IndexedBlock is the last block covered by the token index. If no
+        # block is indexed yet, this field will be null.
+        indexedBlock: Long
+    }
+
+    # TokenTransferFilterCriteria encapsulates criteria for searching token
+    # events.
+    input TokenTransferFilterCriteria {
+        # Token restricts matches to events of this token contract.
+        token: Address
+        # Approvals includes the Approval events in the matches.
+        approvals: Boolean
+        # FromBlock and ToBlock restrict matches to this range of blocks.
+        fromBlock: Long
+        toBlock: Long
+    }
+
+    # ConsensusState is the state of the consensus engine of the node.
+    type ConsensusState {
+        # Round is the current Istanbul round. For other consensus engines, or
+        # if the engine is not running, this field will be null.
+        round: Long
+        # RaftRole is the role of the node in the raft cluster of the chain. If
+        # the chain doesn't run raft, this field will be null.
+        raftRole: String
+    }
+
     type Query {
         # Block fetches an Ethereum block by number or by hash. If neither is
         # supplied, the most recent known block is returned.
@@ -311,6 +462,22 @@ const schema string = `
         protocolVersion: Int!
         # Syncing returns information on the current synchronisation state.
         syncing: SyncState
+        # Consensus returns the state of the consensus engine of the node.
+        consensus: ConsensusState!
+        # CrossTransaction returns a cross-chain transaction by its ID. If the
+        # node doesn't index cross-chain transactions, this field will be null.
+        crossTransaction(id: Bytes32!): CrossTransaction
+        # CrossTransactions returns a page of cross-chain transactions matching
+        # the provided filter, ordered by block number. If the node doesn't
+        # index cross-chain transactions, this field will be null.
+        crossTransactions(filter: CrossTransactionFilterCriteria!, pageSize: Int, page: Int): [CrossTransaction!]
+        # TokenBalances returns the tokens held by an account. If the node
+        # doesn't index tokens, this field will be null.
+        tokenBalances(holder: Address!): [TokenBalance!]
+        # TokenTransfers returns a page of the token events involving an
+        # account, ordered by block number. If the node doesn't index tokens,
+        # this field will be null.
+        tokenTransfers(holder: Address!, filter: TokenTransferFilterCriteria, limit: Int, cursor: Bytes): TokenTransferPage
     }
 
     type Mutation {
@@ -18,17 +18,49 @@ package graphql
 
 import (
 	"fmt"
+	"math/big"
 	"net"
 	"net/http"
 
 	"github.com/graph-gophers/graphql-go"
 	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/simplechain-org/go-simplechain/common"
+	cdb "github.com/simplechain-org/go-simplechain/cross/database"
+	"github.com/simplechain-org/go-simplechain/eth/tokenindex"
 	"github.com/simplechain-org/go-simplechain/internal/ethapi"
 	"github.com/simplechain-org/go-simplechain/log"
 	"github.com/simplechain-org/go-simplechain/p2p"
 	"github.com/simplechain-org/go-simplechain/rpc"
 )
 
+// RaftNode is a member of the raft cluster of a chain.
+type RaftNode interface {
+	// Role returns the role of the node in the cluster, or an empty string if it
+	// isn't part of it.
+	Role() string
+}
+
+// CrossBackend gives access to the cross-chain transactions indexed by the cross
+// service of an anchor node.
+type CrossBackend interface {
+	// Chains returns the IDs of the chains exchanging cross-chain transactions.
+	Chains() []*big.Int
+
+	// CtxStore returns the store of the cross-chain transactions made on the
+	// given chain.
+	CtxStore(chainID *big.Int) (cdb.CtxDB, error)
+}
+
+// TokenBackend gives access to the token balances and transfers indexed by the
+// token index of a chain.
+type TokenBackend interface {
+	// GetTokenBalances returns the tokens held by the account.
+	GetTokenBalances(holder common.Address) (*tokenindex.TokenBalances, error)
+
+	// GetTokenTransfers returns a page of the token events involving the account.
+	GetTokenTransfers(holder common.Address, query *tokenindex.TransferQuery) (*tokenindex.TokenTransfers, error)
+}
+
 // Service encapsulates a GraphQL service.
 type Service struct {
 	endpoint string           // The host:port endpoint for this service.
@@ -36,6 +68,9 @@ type Service struct {
 	vhosts   []string         // Recognised vhosts
 	timeouts rpc.HTTPTimeouts // Timeout settings for HTTP requests.
 	backend  ethapi.Backend   // The backend that queries will operate onn.
+	raft     RaftNode         // The raft service of the chain, if it runs raft.
+	cross    CrossBackend     // The cross service of the node, if it is an anchor.
+	tokens   TokenBackend     // The token index of the chain, if it is enabled.
 	handler  http.Handler     // The `http.Handler` used to answer queries.
 	listener net.Listener     // The listening socket.
 }
@@ -51,6 +86,29 @@ func New(backend ethapi.Backend, endpoint string, cors, vhosts []string, timeout
 	}, nil
 }
 
+// NewSub constructs a new GraphQL service instance for a subchain, reporting the
+// role of the node in its raft cluster if raft is not nil.
+func NewSub(backend ethapi.Backend, raft RaftNode, endpoint string, cors, vhosts []string, timeouts rpc.HTTPTimeouts) (*Service, error) {
+	service, err := New(backend, endpoint, cors, vhosts, timeouts)
+	if err != nil {
+		return nil, err
+	}
+	service.raft = raft
+	return service, nil
+}
+
+// SetCrossBackend makes the cross-chain transactions of an anchor node queryable.
+// It must be called before the service is started.
+func (s *Service) SetCrossBackend(cross CrossBackend) {
+	s.cross = cross
+}
+
+// SetTokenBackend makes the token balances and transfers of the chain queryable.
+// It must be called before the service is started.
+func (s *Service) SetTokenBackend(tokens TokenBackend) {
+	s.tokens = tokens
+}
+
 // Protocols returns the list of protocols exported by this service.
 func (s *Service) Protocols() []p2p.Protocol { return nil }
 
@@ -61,7 +119,7 @@ func (s *Service) APIs() []rpc.API { return nil }
 // layer was also initialized to spawn any goroutines required by the service.
 func (s *Service) Start(server *p2p.Server) error {
 	var err error
-	s.handler, err = newHandler(s.backend)
+	s.handler, err = newHandler(&Resolver{backend: s.backend, raft: s.raft, cross: s.cross, tokens: s.tokens})
 	if err != nil {
 		return err
 	}
@@ -75,10 +133,8 @@ func (s *Service) Start(server *p2p.Server) error {
 
 // newHandler returns a new `http.Handler` that will answer GraphQL queries.
 // It additionally exports an interactive query browser on the / endpoint.
-func newHandler(backend ethapi.Backend) (http.Handler, error) {
-	q := Resolver{backend}
-
-	s, err := graphql.ParseSchema(schema, &q)
+func newHandler(q *Resolver) (http.Handler, error) {
+	s, err := graphql.ParseSchema(schema, q)
 	if err != nil {
 		return nil, err
 	}
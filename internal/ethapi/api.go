@@ -35,6 +35,7 @@ import (
 	"github.com/simplechain-org/go-simplechain/consensus/ethash"
 	"github.com/simplechain-org/go-simplechain/core"
 	"github.com/simplechain-org/go-simplechain/core/rawdb"
+	"github.com/simplechain-org/go-simplechain/core/state"
 	"github.com/simplechain-org/go-simplechain/core/types"
 	"github.com/simplechain-org/go-simplechain/core/vm"
 	"github.com/simplechain-org/go-simplechain/crypto"
@@ -354,9 +355,9 @@ func (s *PrivateAccountAPI) signTransaction(ctx context.Context, args *SendTxArg
 		return nil, err
 	}
 	// Assemble the transaction and sign with the wallet
-	tx := args.toTransaction()
-
-	return wallet.SignTxWithPassphrase(account, passwd, tx, s.b.ChainConfig().ChainID)
+	return args.signTx(s.b, func(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+		return wallet.SignTxWithPassphrase(account, passwd, tx, chainID)
+	})
 }
 
 // SendTransaction will create a transaction from the given arguments and
@@ -398,7 +399,7 @@ func (s *PrivateAccountAPI) SignTransaction(ctx context.Context, args SendTxArgs
 		log.Warn("Failed transaction sign attempt", "from", args.From, "to", args.To, "value", args.Value.ToInt(), "err", err)
 		return nil, err
 	}
-	data, err := rlp.EncodeToBytes(signed)
+	data, err := signed.MarshalBinary()
 	if err != nil {
 		return nil, err
 	}
@@ -743,15 +744,73 @@ type CallArgs struct {
 	Value    *hexutil.Big    `json:"value"`
 	Data     *hexutil.Bytes  `json:"data"`
 	Nonce    *hexutil.Uint64 `json:"nonce"`
+
+	AccessList *types.AccessList `json:"accessList"`
 }
 
-// account indicates the overriding fields of account during the execution of
-// a message call.
+// ToMessage converts the call arguments into a message, filling in the defaults
+// of the missing fields. The gas allowance is capped by the global gas cap.
+func (args *CallArgs) ToMessage(globalGasCap *big.Int) types.Message {
+	// Use the zero address if no sender was specified
+	var addr common.Address
+	if args.From != nil {
+		addr = *args.From
+	}
+	// Set default gas & gas price if none were set
+	gas := uint64(math.MaxUint64 / 2)
+	if args.Gas != nil {
+		gas = uint64(*args.Gas)
+	}
+	if globalGasCap != nil && globalGasCap.Uint64() < gas {
+		log.Warn("Caller gas above allowance, capping", "requested", gas, "cap", globalGasCap)
+		gas = globalGasCap.Uint64()
+	}
+	gasPrice := new(big.Int).SetUint64(defaultGasPrice)
+	if args.GasPrice != nil {
+		gasPrice = args.GasPrice.ToInt()
+	}
+
+	value := new(big.Int)
+	if args.Value != nil {
+		value = args.Value.ToInt()
+	}
+
+	var data []byte
+	if args.Data != nil {
+		data = []byte(*args.Data)
+	}
+	var nonce uint64
+	if args.Nonce != nil {
+		nonce = uint64(*args.Nonce)
+	}
+
+	var accessList types.AccessList
+	if args.AccessList != nil {
+		accessList = *args.AccessList
+	}
+	return types.NewMessage(addr, args.To, nonce, value, gas, gasPrice, data, accessList, false)
+}
+
+// setDefaultSender sets the sender of the call to the first local account if
+// none was specified.
+func (args *CallArgs) setDefaultSender(b Backend) {
+	if args.From != nil {
+		return
+	}
+	if wallets := b.AccountManager().Wallets(); len(wallets) > 0 {
+		if accounts := wallets[0].Accounts(); len(accounts) > 0 {
+			args.From = &accounts[0].Address
+		}
+	}
+}
+
+// OverrideAccount indicates the overriding fields of account during the execution
+// of a message call.
 // Note, state and stateDiff can't be specified at the same time. If state is
 // set, message execution will only use the data in the given state. Otherwise
 // if statDiff is set, all diff will be applied first and then execute the call
 // message.
-type account struct {
+type OverrideAccount struct {
 	Nonce     *hexutil.Uint64              `json:"nonce"`
 	Code      *hexutil.Bytes               `json:"code"`
 	Balance   **hexutil.Big                `json:"balance"`
@@ -759,26 +818,12 @@ type account struct {
 	StateDiff *map[common.Hash]common.Hash `json:"stateDiff"`
 }
 
-func DoCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides map[common.Address]account, vmCfg vm.Config, timeout time.Duration, globalGasCap *big.Int) ([]byte, uint64, bool, error) {
-	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
+// StateOverride is the collection of overridden accounts.
+type StateOverride map[common.Address]OverrideAccount
 
-	state, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
-	if state == nil || err != nil {
-		return nil, 0, false, err
-	}
-	// Set sender address or use a default if none specified
-	var addr common.Address
-	if args.From == nil {
-		if wallets := b.AccountManager().Wallets(); len(wallets) > 0 {
-			if accounts := wallets[0].Accounts(); len(accounts) > 0 {
-				addr = accounts[0].Address
-			}
-		}
-	} else {
-		addr = *args.From
-	}
-	// Override the fields of specified contracts before execution.
-	for addr, account := range overrides {
+// Apply overrides the fields of specified accounts into the given state.
+func (diff StateOverride) Apply(state *state.StateDB) error {
+	for addr, account := range diff {
 		// Override account nonce.
 		if account.Nonce != nil {
 			state.SetNonce(addr, uint64(*account.Nonce))
@@ -792,7 +837,7 @@ func DoCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.Blo
 			state.SetBalance(addr, (*big.Int)(*account.Balance))
 		}
 		if account.State != nil && account.StateDiff != nil {
-			return nil, 0, false, fmt.Errorf("account %s has both 'state' and 'stateDiff'", addr.Hex())
+			return fmt.Errorf("account %s has both 'state' and 'stateDiff'", addr.Hex())
 		}
 		// Replace entire state if caller requires.
 		if account.State != nil {
@@ -805,37 +850,53 @@ func DoCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.Blo
 			}
 		}
 	}
-	// Set default gas & gas price if none were set
-	gas := uint64(math.MaxUint64 / 2)
-	if args.Gas != nil {
-		gas = uint64(*args.Gas)
+	return nil
+}
+
+// BlockOverrides is the set of block context fields to override during the
+// execution of a message call. Note, the fork rules stay those of the block the
+// call is executed on.
+type BlockOverrides struct {
+	Number     *hexutil.Big    `json:"number"`
+	Difficulty *hexutil.Big    `json:"difficulty"`
+	Time       *hexutil.Uint64 `json:"time"`
+	GasLimit   *hexutil.Uint64 `json:"gasLimit"`
+	Coinbase   *common.Address `json:"coinbase"`
+}
+
+// Apply overrides the given block context fields.
+func (diff *BlockOverrides) Apply(context *vm.Context) {
+	if diff == nil {
+		return
 	}
-	if globalGasCap != nil && globalGasCap.Uint64() < gas {
-		log.Warn("Caller gas above allowance, capping", "requested", gas, "cap", globalGasCap)
-		gas = globalGasCap.Uint64()
+	if diff.Number != nil {
+		context.BlockNumber = new(big.Int).Set(diff.Number.ToInt())
 	}
-	gasPrice := new(big.Int).SetUint64(defaultGasPrice)
-	if args.GasPrice != nil {
-		gasPrice = args.GasPrice.ToInt()
+	if diff.Difficulty != nil {
+		context.Difficulty = new(big.Int).Set(diff.Difficulty.ToInt())
 	}
-
-	value := new(big.Int)
-	if args.Value != nil {
-		value = args.Value.ToInt()
+	if diff.Time != nil {
+		context.Time = new(big.Int).SetUint64(uint64(*diff.Time))
 	}
-
-	var data []byte
-	if args.Data != nil {
-		data = []byte(*args.Data)
+	if diff.GasLimit != nil {
+		context.GasLimit = uint64(*diff.GasLimit)
 	}
-	var nonce uint64
-	if args.Nonce != nil {
-		nonce = uint64(*args.Nonce)
+	if diff.Coinbase != nil {
+		context.Coinbase = *diff.Coinbase
 	}
+}
 
-	// Create new call message
-	msg := types.NewMessage(addr, args.To, nonce, value, gas, gasPrice, data, false)
+func DoCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides StateOverride, vmCfg vm.Config, timeout time.Duration, globalGasCap *big.Int) ([]byte, uint64, bool, error) {
+	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
 
+	state, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, 0, false, err
+	}
+	// Override the fields of specified contracts before execution.
+	if err := overrides.Apply(state); err != nil {
+		return nil, 0, false, err
+	}
 	// Setup context so it may be cancelled the call has completed
 	// or, in case of unmetered gas, setup a context with a timeout.
 	var cancel context.CancelFunc
@@ -848,11 +909,21 @@ func DoCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.Blo
 	// this makes sure resources are cleaned up.
 	defer cancel()
 
+	// Set sender address or use a default if none specified
+	args.setDefaultSender(b)
+	return applyCall(ctx, b, args.ToMessage(globalGasCap), state, header, nil, timeout)
+}
+
+// applyCall executes the given message on top of the given state, aborting the
+// execution when the context is cancelled.
+func applyCall(ctx context.Context, b Backend, msg types.Message, state *state.StateDB, header *types.Header, blockOverrides *BlockOverrides, timeout time.Duration) ([]byte, uint64, bool, error) {
 	// Get a new instance of the EVM.
 	evm, vmError, err := b.GetEVM(ctx, msg, state, header)
 	if err != nil {
 		return nil, 0, false, err
 	}
+	blockOverrides.Apply(&evm.Context)
+
 	// Wait for the context to be done and cancel the evm. Even if the
 	// EVM has finished, cancelling may be done (repeatedly)
 	go func() {
@@ -880,8 +951,8 @@ func DoCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.Blo
 //
 // Note, this function doesn't make and changes in the state/blockchain and is
 // useful to execute and retrieve values.
-func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *map[common.Address]account) (hexutil.Bytes, error) {
-	var accounts map[common.Address]account
+func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride) (hexutil.Bytes, error) {
+	var accounts StateOverride
 	if overrides != nil {
 		accounts = *overrides
 	}
@@ -889,6 +960,63 @@ func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNrOr
 	return (hexutil.Bytes)(result), err
 }
 
+// CallResult is the outcome of a single call of a simulated bundle.
+type CallResult struct {
+	ReturnData hexutil.Bytes  `json:"returnData"`
+	GasUsed    hexutil.Uint64 `json:"gasUsed"`
+	Failed     bool           `json:"failed"`
+	Logs       []*types.Log   `json:"logs"`
+}
+
+// CallMany executes the given calls in sequence on the state for the given
+// block number, each call seeing the state changes of the previous ones. The
+// state and block context of the simulation may be overridden.
+//
+// Note, this function doesn't make and changes in the state/blockchain and is
+// useful to simulate a bundle of dependent transactions.
+func (s *PublicBlockChainAPI) CallMany(ctx context.Context, calls []CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride, blockOverrides *BlockOverrides) ([]*CallResult, error) {
+	defer func(start time.Time) { log.Debug("Executing EVM call bundle finished", "runtime", time.Since(start)) }(time.Now())
+
+	state, header, err := s.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	if overrides != nil {
+		if err := overrides.Apply(state); err != nil {
+			return nil, err
+		}
+	}
+	// The whole bundle shares the timeout of a single call
+	timeout := 5 * time.Second
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	results := make([]*CallResult, len(calls))
+	for i, args := range calls {
+		args.setDefaultSender(s.b)
+
+		// Calls have no hash, key their logs by index in the bundle
+		state.Prepare(common.BigToHash(big.NewInt(int64(i))), common.Hash{}, i)
+
+		res, gas, failed, err := applyCall(ctx, s.b, args.ToMessage(s.b.RPCGasCap()), state, header, blockOverrides, timeout)
+		if err != nil {
+			return nil, fmt.Errorf("call %d: %v", i, err)
+		}
+		logs := state.GetLogs(common.BigToHash(big.NewInt(int64(i))))
+		for _, log := range logs {
+			log.TxHash = common.Hash{}
+		}
+		results[i] = &CallResult{
+			ReturnData: res,
+			GasUsed:    hexutil.Uint64(gas),
+			Failed:     failed,
+			Logs:       logs,
+		}
+		state.Finalise(true)
+	}
+	return results, nil
+}
+
 func DoEstimateGas(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, gasCap *big.Int) (hexutil.Uint64, error) {
 	// Binary search the gas requirement, as it may be higher than the amount used
 	var (
@@ -1100,20 +1228,25 @@ func (s *PublicBlockChainAPI) rpcMarshalBlock(b *types.Block, inclTx bool, fullT
 
 // RPCTransaction represents a transaction that will serialize to the RPC representation of a transaction
 type RPCTransaction struct {
-	BlockHash        *common.Hash    `json:"blockHash"`
-	BlockNumber      *hexutil.Big    `json:"blockNumber"`
-	From             common.Address  `json:"from"`
-	Gas              hexutil.Uint64  `json:"gas"`
-	GasPrice         *hexutil.Big    `json:"gasPrice"`
-	Hash             common.Hash     `json:"hash"`
-	Input            hexutil.Bytes   `json:"input"`
-	Nonce            hexutil.Uint64  `json:"nonce"`
-	To               *common.Address `json:"to"`
-	TransactionIndex *hexutil.Uint64 `json:"transactionIndex"`
-	Value            *hexutil.Big    `json:"value"`
-	V                *hexutil.Big    `json:"v"`
-	R                *hexutil.Big    `json:"r"`
-	S                *hexutil.Big    `json:"s"`
+	BlockHash        *common.Hash      `json:"blockHash"`
+	BlockNumber      *hexutil.Big      `json:"blockNumber"`
+	From             common.Address    `json:"from"`
+	Gas              hexutil.Uint64    `json:"gas"`
+	GasPrice         *hexutil.Big      `json:"gasPrice"`
+	Hash             common.Hash       `json:"hash"`
+	Input            hexutil.Bytes     `json:"input"`
+	Nonce            hexutil.Uint64    `json:"nonce"`
+	To               *common.Address   `json:"to"`
+	TransactionIndex *hexutil.Uint64   `json:"transactionIndex"`
+	Value            *hexutil.Big      `json:"value"`
+	Type             hexutil.Uint64    `json:"type"`
+	Accesses         *types.AccessList `json:"accessList,omitempty"`
+	ChainID          *hexutil.Big      `json:"chainId,omitempty"`
+	ExpiryBlock      *hexutil.Uint64   `json:"expiryBlock,omitempty"`
+	ExpiryTime       *hexutil.Uint64   `json:"expiryTime,omitempty"`
+	V                *hexutil.Big      `json:"v"`
+	R                *hexutil.Big      `json:"r"`
+	S                *hexutil.Big      `json:"s"`
 }
 
 // newRPCTransaction returns a transaction that will serialize to the RPC
@@ -1121,12 +1254,13 @@ type RPCTransaction struct {
 func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber uint64, index uint64) *RPCTransaction {
 	var signer types.Signer = types.FrontierSigner{}
 	if tx.Protected() {
-		signer = types.NewEIP155Signer(tx.ChainId())
+		signer = types.NewEIP2930Signer(tx.ChainId())
 	}
 	from, _ := types.Sender(signer, tx)
 	v, r, s := tx.RawSignatureValues()
 
 	result := &RPCTransaction{
+		Type:     hexutil.Uint64(tx.Type()),
 		From:     from,
 		Gas:      hexutil.Uint64(tx.Gas()),
 		GasPrice: (*hexutil.Big)(tx.GasPrice()),
@@ -1139,6 +1273,19 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 		R:        (*hexutil.Big)(r),
 		S:        (*hexutil.Big)(s),
 	}
+	switch tx.Type() {
+	case types.AccessListTxType:
+		al := tx.AccessList()
+		result.Accesses = &al
+		result.ChainID = (*hexutil.Big)(tx.ChainId())
+	case types.ExpiringTxType:
+		al := tx.AccessList()
+		expiryBlock, expiryTime := hexutil.Uint64(tx.ExpiryBlock()), hexutil.Uint64(tx.ExpiryTime())
+		result.Accesses = &al
+		result.ChainID = (*hexutil.Big)(tx.ChainId())
+		result.ExpiryBlock = &expiryBlock
+		result.ExpiryTime = &expiryTime
+	}
 	if blockHash != (common.Hash{}) {
 		result.BlockHash = &blockHash
 		result.BlockNumber = (*hexutil.Big)(new(big.Int).SetUint64(blockNumber))
@@ -1167,7 +1314,7 @@ func newRPCRawTransactionFromBlockIndex(b *types.Block, index uint64) hexutil.By
 	if index >= uint64(len(txs)) {
 		return nil
 	}
-	blob, _ := rlp.EncodeToBytes(txs[index])
+	blob, _ := txs[index].MarshalBinary()
 	return blob
 }
 
@@ -1293,8 +1440,8 @@ func (s *PublicTransactionPoolAPI) GetRawTransactionByHash(ctx context.Context,
 			return nil, nil
 		}
 	}
-	// Serialize to RLP and return
-	return rlp.EncodeToBytes(tx)
+	// Serialize to binary and return
+	return tx.MarshalBinary()
 }
 
 // GetTransactionReceipt returns the transaction receipt for the given transaction hash.
@@ -1314,11 +1461,29 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 
 	var signer types.Signer = types.FrontierSigner{}
 	if tx.Protected() {
-		signer = types.NewEIP155Signer(tx.ChainId())
+		signer = types.NewEIP2930Signer(tx.ChainId())
 	}
 	from, _ := types.Sender(signer, tx)
 
+	// Participants of a private transaction see the outcome of its payload
+	if tx.IsPrivate() {
+		privateReceipt, err := s.b.GetPrivateReceipt(ctx, blockHash, hash)
+		if err != nil {
+			return nil, err
+		}
+		if privateReceipt != nil {
+			for i, l := range privateReceipt.Logs {
+				l.BlockNumber, l.BlockHash = blockNumber, blockHash
+				l.TxHash, l.TxIndex, l.Index = hash, uint(index), uint(i)
+			}
+			privateReceipt.GasUsed, privateReceipt.CumulativeGasUsed = receipt.GasUsed, receipt.CumulativeGasUsed
+			privateReceipt.ContractAddress = receipt.ContractAddress
+			receipt = privateReceipt
+		}
+	}
+
 	fields := map[string]interface{}{
+		"type":              hexutil.Uint(tx.Type()),
 		"blockHash":         blockHash,
 		"blockNumber":       hexutil.Uint64(blockNumber),
 		"transactionHash":   hash,
@@ -1373,6 +1538,19 @@ type SendTxArgs struct {
 	// newer name and should be preferred by clients.
 	Data  *hexutil.Bytes `json:"data"`
 	Input *hexutil.Bytes `json:"input"`
+
+	// For access list transactions, the chain id defaults to the one of the node.
+	AccessList *types.AccessList `json:"accessList,omitempty"`
+	ChainID    *hexutil.Big      `json:"chainId,omitempty"`
+
+	// For expiring transactions, the last block number and/or timestamp the
+	// transaction may be included at.
+	ExpiryBlock *hexutil.Uint64 `json:"expiryBlock,omitempty"`
+	ExpiryTime  *hexutil.Uint64 `json:"expiryTime,omitempty"`
+
+	// Private transaction participants, the payload is only revealed to them.
+	PrivateFrom string   `json:"privateFrom"`
+	PrivateFor  []string `json:"privateFor"`
 }
 
 // setDefaults is a helper function that fills in default values for unspecified tx fields.
@@ -1418,11 +1596,12 @@ func (args *SendTxArgs) setDefaults(ctx context.Context, b Backend) error {
 			input = args.Data
 		}
 		callArgs := CallArgs{
-			From:     &args.From, // From shouldn't be nil
-			To:       args.To,
-			GasPrice: args.GasPrice,
-			Value:    args.Value,
-			Data:     input,
+			From:       &args.From, // From shouldn't be nil
+			To:         args.To,
+			GasPrice:   args.GasPrice,
+			Value:      args.Value,
+			Data:       input,
+			AccessList: args.AccessList,
 		}
 		pendingBlockNr := rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber)
 		estimated, err := DoEstimateGas(ctx, b, callArgs, pendingBlockNr, b.RPCGasCap())
@@ -1432,9 +1611,71 @@ func (args *SendTxArgs) setDefaults(ctx context.Context, b Backend) error {
 		args.Gas = &estimated
 		log.Trace("Estimate gas usage automatically", "gas", args.Gas)
 	}
+	if (args.AccessList != nil || args.isExpiring()) && args.ChainID == nil {
+		args.ChainID = (*hexutil.Big)(b.ChainConfig().ChainID)
+	}
+	return nil
+}
+
+// isExpiring returns whether the transaction carries an expiry limit.
+func (args *SendTxArgs) isExpiring() bool {
+	return args.ExpiryBlock != nil || args.ExpiryTime != nil
+}
+
+// isPrivate returns whether the transaction is to be sent privately.
+func (args *SendTxArgs) isPrivate() bool {
+	return args.PrivateFor != nil
+}
+
+// setPrivatePayload hands the payload over to the private transaction manager and
+// replaces it with the hash of the encrypted payload.
+func (args *SendTxArgs) setPrivatePayload(b Backend) error {
+	manager := b.PrivateManager()
+	if manager == nil {
+		return errors.New("private transactions are not enabled")
+	}
+	if args.Value.ToInt().Sign() != 0 {
+		return errors.New("private transactions can't transfer value")
+	}
+	if args.AccessList != nil {
+		return errors.New("private transactions can't carry an access list")
+	}
+	if args.isExpiring() {
+		return errors.New("private transactions can't expire")
+	}
+	input := args.Input
+	if input == nil {
+		input = args.Data
+	}
+	if input == nil || len(*input) == 0 {
+		return errors.New("private transaction without payload")
+	}
+	hash, err := manager.Send(*input, args.PrivateFrom, args.PrivateFor)
+	if err != nil {
+		return err
+	}
+	data := hexutil.Bytes(hash.Bytes())
+	args.Data, args.Input = &data, nil
 	return nil
 }
 
+// signTx signs the transaction assembled from the arguments with the wallet,
+// marking it private if it has private participants.
+func (args *SendTxArgs) signTx(b Backend, sign func(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)) (*types.Transaction, error) {
+	if !args.isPrivate() {
+		return sign(args.toTransaction(), b.ChainConfig().ChainID)
+	}
+	if err := args.setPrivatePayload(b); err != nil {
+		return nil, err
+	}
+	// Private transactions are signed with the homestead rules
+	signed, err := sign(args.toTransaction(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return signed.AsPrivate(), nil
+}
+
 func (args *SendTxArgs) toTransaction() *types.Transaction {
 	var input []byte
 	if args.Input != nil {
@@ -1442,10 +1683,50 @@ func (args *SendTxArgs) toTransaction() *types.Transaction {
 	} else if args.Data != nil {
 		input = *args.Data
 	}
-	if args.To == nil {
-		return types.NewContractCreation(uint64(*args.Nonce), (*big.Int)(args.Value), uint64(*args.Gas), (*big.Int)(args.GasPrice), input)
+	var data types.TxData
+	switch {
+	case args.isExpiring():
+		itx := &types.ExpiringTx{
+			To:       args.To,
+			ChainID:  (*big.Int)(args.ChainID),
+			Nonce:    uint64(*args.Nonce),
+			Gas:      uint64(*args.Gas),
+			GasPrice: (*big.Int)(args.GasPrice),
+			Value:    (*big.Int)(args.Value),
+			Data:     input,
+		}
+		if args.AccessList != nil {
+			itx.AccessList = *args.AccessList
+		}
+		if args.ExpiryBlock != nil {
+			itx.ExpiryBlock = uint64(*args.ExpiryBlock)
+		}
+		if args.ExpiryTime != nil {
+			itx.ExpiryTime = uint64(*args.ExpiryTime)
+		}
+		data = itx
+	case args.AccessList == nil:
+		data = &types.LegacyTx{
+			To:       args.To,
+			Nonce:    uint64(*args.Nonce),
+			Gas:      uint64(*args.Gas),
+			GasPrice: (*big.Int)(args.GasPrice),
+			Value:    (*big.Int)(args.Value),
+			Data:     input,
+		}
+	default:
+		data = &types.AccessListTx{
+			To:         args.To,
+			ChainID:    (*big.Int)(args.ChainID),
+			Nonce:      uint64(*args.Nonce),
+			Gas:        uint64(*args.Gas),
+			GasPrice:   (*big.Int)(args.GasPrice),
+			Value:      (*big.Int)(args.Value),
+			Data:       input,
+			AccessList: *args.AccessList,
+		}
 	}
-	return types.NewTransaction(uint64(*args.Nonce), *args.To, (*big.Int)(args.Value), uint64(*args.Gas), (*big.Int)(args.GasPrice), input)
+	return types.NewTx(data)
 }
 
 // SubmitTransaction is a helper function that submits tx to txPool and logs a message.
@@ -1490,9 +1771,9 @@ func (s *PublicTransactionPoolAPI) SendTransaction(ctx context.Context, args Sen
 		return common.Hash{}, err
 	}
 	// Assemble the transaction and sign with the wallet
-	tx := args.toTransaction()
-
-	signed, err := wallet.SignTx(account, tx, s.b.ChainConfig().ChainID)
+	signed, err := args.signTx(s.b, func(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+		return wallet.SignTx(account, tx, chainID)
+	})
 	if err != nil {
 		return common.Hash{}, err
 	}
@@ -1506,9 +1787,9 @@ func (s *PublicTransactionPoolAPI) FillTransaction(ctx context.Context, args Sen
 	if err := args.setDefaults(ctx, s.b); err != nil {
 		return nil, err
 	}
-	// Assemble the transaction and obtain rlp
+	// Assemble the transaction and obtain its binary encoding
 	tx := args.toTransaction()
-	data, err := rlp.EncodeToBytes(tx)
+	data, err := tx.MarshalBinary()
 	if err != nil {
 		return nil, err
 	}
@@ -1519,7 +1800,7 @@ func (s *PublicTransactionPoolAPI) FillTransaction(ctx context.Context, args Sen
 // The sender is responsible for signing the transaction and using the correct nonce.
 func (s *PublicTransactionPoolAPI) SendRawTransaction(ctx context.Context, encodedTx hexutil.Bytes) (common.Hash, error) {
 	tx := new(types.Transaction)
-	if err := rlp.DecodeBytes(encodedTx, tx); err != nil {
+	if err := tx.UnmarshalBinary(encodedTx); err != nil {
 		return common.Hash{}, err
 	}
 	return SubmitTransaction(ctx, s.b, tx)
@@ -1576,7 +1857,7 @@ func (s *PublicTransactionPoolAPI) SignTransaction(ctx context.Context, args Sen
 	if err != nil {
 		return nil, err
 	}
-	data, err := rlp.EncodeToBytes(tx)
+	data, err := tx.MarshalBinary()
 	if err != nil {
 		return nil, err
 	}
@@ -1600,7 +1881,7 @@ func (s *PublicTransactionPoolAPI) PendingTransactions() ([]*RPCTransaction, err
 	for _, tx := range pending {
 		var signer types.Signer = types.HomesteadSigner{}
 		if tx.Protected() {
-			signer = types.NewEIP155Signer(tx.ChainId())
+			signer = types.NewEIP2930Signer(tx.ChainId())
 		}
 		from, _ := types.Sender(signer, tx)
 		if _, exists := accounts[from]; exists {
@@ -1628,7 +1909,7 @@ func (s *PublicTransactionPoolAPI) Resend(ctx context.Context, sendArgs SendTxAr
 	for _, p := range pending {
 		var signer types.Signer = types.HomesteadSigner{}
 		if p.Protected() {
-			signer = types.NewEIP155Signer(p.ChainId())
+			signer = types.NewEIP2930Signer(p.ChainId())
 		}
 		wantSigHash := signer.Hash(matchTx)
 
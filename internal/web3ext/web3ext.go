@@ -35,6 +35,7 @@ var Modules = map[string]string{
 	"rpc":        RpcJs,
 	"shh":        ShhJs,
 	"swarmfs":    SwarmfsJs,
+	"trace":      TraceJs,
 	"txpool":     TxpoolJs,
 	"les":        LESJs,
 }
@@ -113,6 +114,46 @@ web3._extend({
 			call: 'cross_syncStore',
 			params: 0,
 		}),
+		new web3._extend.Method({
+			name: 'riskConfig',
+			call: 'cross_riskConfig',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'setRiskConfig',
+			call: 'cross_setRiskConfig',
+			params: 2,
+		}),
+		new web3._extend.Method({
+			name: 'pauseSigning',
+			call: 'cross_pauseSigning',
+			params: 2,
+		}),
+		new web3._extend.Method({
+			name: 'denyAddress',
+			call: 'cross_denyAddress',
+			params: 2,
+		}),
+		new web3._extend.Method({
+			name: 'undenyAddress',
+			call: 'cross_undenyAddress',
+			params: 2,
+		}),
+		new web3._extend.Method({
+			name: 'heldCtx',
+			call: 'cross_heldCtx',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'approveCtx',
+			call: 'cross_approveCtx',
+			params: 2,
+		}),
+		new web3._extend.Method({
+			name: 'rejectCtx',
+			call: 'cross_rejectCtx',
+			params: 2,
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -664,6 +705,12 @@ web3._extend({
 			params: 2,
 			inputFormatter: [null, null]
 		}),
+		new web3._extend.Method({
+			name: 'traceCall',
+			call: 'debug_traceCall',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
 		new web3._extend.Method({
 			name: 'preimage',
 			call: 'debug_preimage',
@@ -766,6 +813,12 @@ web3._extend({
 			call: 'eth_getRawTransactionByHash',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'callMany',
+			call: 'eth_callMany',
+			params: 4,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter, null, null]
+		}),
 		new web3._extend.Method({
 			name: 'getRawTransactionFromBlock',
 			call: function(args) {
@@ -780,6 +833,24 @@ web3._extend({
 			params: 3,
 			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, web3._extend.formatters.inputBlockNumberFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'getTransactionsByAddress',
+			call: 'eth_getTransactionsByAddress',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'getTokenBalances',
+			call: 'eth_getTokenBalances',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getTokenTransfers',
+			call: 'eth_getTokenTransfers',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null]
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -838,6 +909,10 @@ web3._extend({
 			name: 'getHashrate',
 			call: 'miner_getHashrate'
 		}),
+		new web3._extend.Method({
+			name: 'getTemplate',
+			call: 'miner_getTemplate'
+		}),
 	],
 	properties: []
 });
@@ -969,6 +1044,30 @@ web3._extend({
 });
 `
 
+const TraceJs = `
+web3._extend({
+	property: 'trace',
+	methods: [
+		new web3._extend.Method({
+			name: 'block',
+			call: 'trace_block',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'transaction',
+			call: 'trace_transaction',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'filter',
+			call: 'trace_filter',
+			params: 1
+		}),
+	]
+});
+`
+
 const TxpoolJs = `
 web3._extend({
 	property: 'txpool',
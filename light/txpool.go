@@ -69,6 +69,8 @@ type TxPool struct {
 	clearIdx     uint64                               // earliest block nr that can contain mined tx info
 
 	singularity bool // Fork indicator whether we are in the singularity stage.
+	accessList  bool // Fork indicator whether typed transactions are accepted.
+	txExpiry    bool // Fork indicator whether expiring transactions are accepted.
 }
 
 // TxRelayBackend provides an interface to the mechanism that forwards transacions
@@ -90,7 +92,7 @@ type TxRelayBackend interface {
 func NewTxPool(config *params.ChainConfig, chain *LightChain, relay TxRelayBackend) *TxPool {
 	pool := &TxPool{
 		config:      config,
-		signer:      types.NewEIP155Signer(config.ChainID),
+		signer:      types.MakeSigner(config),
 		nonce:       make(map[common.Address]uint64),
 		pending:     make(map[common.Hash]*types.Transaction),
 		mined:       make(map[common.Hash][]*types.Transaction),
@@ -314,6 +316,8 @@ func (pool *TxPool) setNewHead(head *types.Header) {
 	// Update fork indicator by next pending block number
 	next := new(big.Int).Add(head.Number, big.NewInt(1))
 	pool.singularity = pool.config.IsSingularity(next)
+	pool.accessList = pool.config.IsAccessList(next)
+	pool.txExpiry = pool.config.IsTxExpiry(next)
 }
 
 // Stop stops the light transaction pool
@@ -343,6 +347,13 @@ func (pool *TxPool) Stats() (pending int) {
 
 // validateTx checks whether a transaction is valid according to the consensus rules.
 func (pool *TxPool) validateTx(ctx context.Context, tx *types.Transaction) error {
+	// Accept only legacy transactions until the access list fork is enabled
+	if !pool.accessList && tx.Type() != types.LegacyTxType {
+		return core.ErrTxTypeNotSupported
+	}
+	if !pool.txExpiry && tx.Type() == types.ExpiringTxType {
+		return core.ErrTxTypeNotSupported
+	}
 	// Validate sender
 	var (
 		from common.Address
@@ -366,6 +377,10 @@ func (pool *TxPool) validateTx(ctx context.Context, tx *types.Transaction) error
 	if header.GasLimit < tx.Gas() {
 		return core.ErrGasLimit
 	}
+	// Reject transactions which can't make it into the next block anymore
+	if tx.Expired(header.Number.Uint64()+1, header.Time+1) {
+		return core.ErrTxExpired
+	}
 
 	// Transactions can't be negative. This may never happen
 	// using RLP decoded transactions but may occur if you create
@@ -381,7 +396,7 @@ func (pool *TxPool) validateTx(ctx context.Context, tx *types.Transaction) error
 	}
 
 	// Should supply enough intrinsic gas
-	gas, err := core.IntrinsicGas(tx.Data(), tx.To() == nil, pool.singularity)
+	gas, err := core.IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, pool.singularity)
 	if err != nil {
 		return err
 	}
@@ -180,6 +180,17 @@ func (miner *Miner) PendingBlock() *types.Block {
 	return miner.worker.pendingBlock()
 }
 
+// Template returns the template of the block being built, with the pool
+// transactions left out of it, or nil if no work was committed yet.
+func (miner *Miner) Template() *Template {
+	return miner.worker.currentTemplate()
+}
+
+// SubscribeTemplates subscribes to the templates of the new work committed.
+func (miner *Miner) SubscribeTemplates(ch chan<- *Template) event.Subscription {
+	return miner.worker.subscribeTemplates(ch)
+}
+
 func (miner *Miner) SetEtherbase(addr common.Address) {
 	miner.coinbase = addr
 	miner.worker.setEtherbase(addr)
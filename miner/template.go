@@ -0,0 +1,264 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"math/big"
+	"sync/atomic"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/common/hexutil"
+	"github.com/simplechain-org/go-simplechain/core/types"
+	"github.com/simplechain-org/go-simplechain/event"
+)
+
+// maxTemplateExcluded is the maximum number of excluded transactions reported
+// by a block template.
+const maxTemplateExcluded = 1024
+
+// Reasons for a pool transaction to be left out of the block template.
+const (
+	ExcludeNonceGap     = "nonceGap"        // Queued behind a missing or excluded nonce of the account
+	ExcludeNonceTooLow  = "nonceTooLow"     // Nonce already used in the parent state
+	ExcludeNonceTooHigh = "nonceTooHigh"    // Nonce ahead of the parent state
+	ExcludeGasLimit     = "gasLimit"        // Gas limit above the gas left in the block
+	ExcludeUnderpriced  = "underpriced"     // Block filled with better paying transactions
+	ExcludePrioritized  = "prioritized"     // Block filled with the transactions of a priority lane
+	ExcludeUnsupported  = "unsupportedType" // Transaction type not supported by the chain
+	ExcludeExpired      = "expired"         // Transaction past its expiry block
+	ExcludeInterrupted  = "interrupted"     // Block building interrupted by a resubmit
+	ExcludeFailed       = "failed"          // Transaction failed to apply
+)
+
+// TemplateTx is a transaction included in the block template.
+type TemplateTx struct {
+	Hash     common.Hash    `json:"hash"`
+	From     common.Address `json:"from"`
+	Nonce    hexutil.Uint64 `json:"nonce"`
+	Gas      hexutil.Uint64 `json:"gas"`
+	GasUsed  hexutil.Uint64 `json:"gasUsed"`
+	GasPrice *hexutil.Big   `json:"gasPrice"`
+	Fee      *hexutil.Big   `json:"fee"`            // Gas used times gas price
+	Lane     string         `json:"lane,omitempty"` // Priority lane the transaction belongs to
+}
+
+// ExcludedTx is a pool transaction left out of the block template.
+type ExcludedTx struct {
+	Hash     common.Hash    `json:"hash"`
+	From     common.Address `json:"from"`
+	Nonce    hexutil.Uint64 `json:"nonce"`
+	Gas      hexutil.Uint64 `json:"gas"`
+	GasPrice *hexutil.Big   `json:"gasPrice"`
+	Reason   string         `json:"reason"`
+	Detail   string         `json:"detail,omitempty"` // Prioritized lane or failure
+}
+
+// Template is the block the miner is currently building, along with the pool
+// transactions it left out.
+type Template struct {
+	Number       hexutil.Uint64 `json:"number"`
+	ParentHash   common.Hash    `json:"parentHash"`
+	Coinbase     common.Address `json:"miner"`
+	Timestamp    hexutil.Uint64 `json:"timestamp"`
+	GasLimit     hexutil.Uint64 `json:"gasLimit"`
+	GasUsed      hexutil.Uint64 `json:"gasUsed"`
+	Fees         *hexutil.Big   `json:"fees"`
+	Transactions []*TemplateTx  `json:"transactions"`
+	Excluded     []*ExcludedTx  `json:"excluded"`
+	Truncated    bool           `json:"truncated"` // Whether excluded transactions were left out of the report
+}
+
+// exclude records the reason a transaction was skipped by the current work.
+func (w *worker) exclude(tx *types.Transaction, from common.Address, reason string, detail string) {
+	w.current.excluded[tx.Hash()] = newExcludedTx(tx, from, reason, detail)
+}
+
+// newExcludedTx creates the template record of an excluded transaction.
+func newExcludedTx(tx *types.Transaction, from common.Address, reason string, detail string) *ExcludedTx {
+	return &ExcludedTx{
+		Hash:     tx.Hash(),
+		From:     from,
+		Nonce:    hexutil.Uint64(tx.Nonce()),
+		Gas:      hexutil.Uint64(tx.Gas()),
+		GasPrice: (*hexutil.Big)(tx.GasPrice()),
+		Reason:   reason,
+		Detail:   detail,
+	}
+}
+
+// laneName returns the name of the priority lane of a transaction, empty if
+// it's a regular one.
+func (w *worker) laneName(tx *types.Transaction) string {
+	pool := w.eth.TxPool()
+	if pool.Lanes() == 0 {
+		return ""
+	}
+	return pool.LaneName(pool.Lane(tx))
+}
+
+// templateWork is the snapshot of a committed work its template is built from.
+type templateWork struct {
+	header      *types.Header
+	signer      types.Signer
+	txs         []*types.Transaction
+	receipts    []*types.Receipt
+	excluded    map[common.Hash]*ExcludedTx
+	filledLane  string
+	interrupted bool
+	pool        map[common.Address]types.Transactions // Executable transactions the work was built from
+}
+
+// updateTemplate records the current work as the one to build the template of.
+// As scanning the pool is expensive, the template is only assembled right away
+// if there are subscribers, otherwise on the first request. The pool holds the
+// executable transactions the work was built from.
+func (w *worker) updateTemplate(pool map[common.Address]types.Transactions) {
+	env := w.current
+	work := &templateWork{
+		header:      types.CopyHeader(env.header),
+		signer:      env.signer,
+		txs:         env.txs[:len(env.txs):len(env.txs)],
+		receipts:    env.receipts[:len(env.receipts):len(env.receipts)],
+		excluded:    make(map[common.Hash]*ExcludedTx, len(env.excluded)),
+		filledLane:  env.filledLane,
+		interrupted: env.interrupted,
+		pool:        pool,
+	}
+	for hash, tx := range env.excluded {
+		work.excluded[hash] = tx
+	}
+	if atomic.LoadInt32(&w.templateSubs) == 0 {
+		w.templateMu.Lock()
+		w.template, w.templateWork = nil, work
+		w.templateMu.Unlock()
+		return
+	}
+	template := w.buildTemplate(work)
+
+	w.templateMu.Lock()
+	w.template, w.templateWork = template, nil
+	w.templateMu.Unlock()
+
+	w.templateFeed.Send(template)
+}
+
+// buildTemplate assembles the template of a committed work, the queued
+// transactions of the pool being reported as nonce gaps.
+func (w *worker) buildTemplate(env *templateWork) *Template {
+	template := &Template{
+		Number:       hexutil.Uint64(env.header.Number.Uint64()),
+		ParentHash:   env.header.ParentHash,
+		Coinbase:     env.header.Coinbase,
+		Timestamp:    hexutil.Uint64(env.header.Time),
+		GasLimit:     hexutil.Uint64(env.header.GasLimit),
+		GasUsed:      hexutil.Uint64(env.header.GasUsed),
+		Transactions: make([]*TemplateTx, 0, len(env.txs)),
+		Excluded:     []*ExcludedTx{},
+	}
+	fees := new(big.Int)
+	included := make(map[common.Hash]bool, len(env.txs))
+	for i, tx := range env.txs {
+		from, _ := types.Sender(env.signer, tx)
+		fee := new(big.Int).Mul(new(big.Int).SetUint64(env.receipts[i].GasUsed), tx.GasPrice())
+		fees.Add(fees, fee)
+
+		template.Transactions = append(template.Transactions, &TemplateTx{
+			Hash:     tx.Hash(),
+			From:     from,
+			Nonce:    hexutil.Uint64(tx.Nonce()),
+			Gas:      hexutil.Uint64(tx.Gas()),
+			GasUsed:  hexutil.Uint64(env.receipts[i].GasUsed),
+			GasPrice: (*hexutil.Big)(tx.GasPrice()),
+			Fee:      (*hexutil.Big)(fee),
+			Lane:     w.laneName(tx),
+		})
+		included[tx.Hash()] = true
+	}
+	template.Fees = (*hexutil.Big)(fees)
+
+	add := func(tx *ExcludedTx) {
+		if len(template.Excluded) == maxTemplateExcluded {
+			template.Truncated = true
+			return
+		}
+		template.Excluded = append(template.Excluded, tx)
+	}
+	// Explain the executable transactions left out, the ones never reached being
+	// either outbid or pushed out by a priority lane
+	for from, txs := range env.pool {
+		blocked := false
+		for _, tx := range txs {
+			if included[tx.Hash()] {
+				continue
+			}
+			if excluded := env.excluded[tx.Hash()]; excluded != nil {
+				add(excluded)
+				blocked = blocked || (excluded.Reason != ExcludeNonceTooLow && excluded.Reason != ExcludeFailed)
+				continue
+			}
+			switch {
+			case blocked:
+				add(newExcludedTx(tx, from, ExcludeNonceGap, ""))
+			case env.interrupted:
+				add(newExcludedTx(tx, from, ExcludeInterrupted, ""))
+			case env.filledLane != "" && env.filledLane != w.laneName(tx):
+				add(newExcludedTx(tx, from, ExcludePrioritized, env.filledLane))
+			default:
+				add(newExcludedTx(tx, from, ExcludeUnderpriced, ""))
+			}
+			blocked = true
+		}
+	}
+	// Report the non-executable transactions of the pool as nonce gaps
+	_, queued := w.eth.TxPool().Content()
+	for from, txs := range queued {
+		for _, tx := range txs {
+			add(newExcludedTx(tx, from, ExcludeNonceGap, ""))
+		}
+	}
+	return template
+}
+
+// currentTemplate returns the template of the last committed work, nil if none.
+func (w *worker) currentTemplate() *Template {
+	w.templateMu.Lock()
+	defer w.templateMu.Unlock()
+
+	if w.template == nil && w.templateWork != nil {
+		w.template, w.templateWork = w.buildTemplate(w.templateWork), nil
+	}
+	return w.template
+}
+
+// subscribeTemplates subscribes to the templates of the new work committed,
+// which are only assembled while there are subscribers.
+func (w *worker) subscribeTemplates(ch chan<- *Template) event.Subscription {
+	sub := w.templateFeed.Subscribe(ch)
+	atomic.AddInt32(&w.templateSubs, 1)
+
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer atomic.AddInt32(&w.templateSubs, -1)
+		defer sub.Unsubscribe()
+
+		select {
+		case err := <-sub.Err():
+			return err
+		case <-quit:
+			return nil
+		}
+	})
+}
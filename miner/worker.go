@@ -60,6 +60,10 @@ const (
 	// miningLogAtDepth is the number of confirmations before logging successful mining.
 	miningLogAtDepth = 7
 
+	// maxSpeculatedTxs is the maximum number of transactions speculatively executed
+	// ahead when parallel execution is enabled.
+	maxSpeculatedTxs = 4096
+
 	// minRecommitInterval is the minimal time interval to recreate the mining block with
 	// any newly arrived transactions.
 	minRecommitInterval     = 1 * time.Second
@@ -95,6 +99,11 @@ type environment struct {
 	header   *types.Header
 	txs      []*types.Transaction
 	receipts []*types.Receipt
+
+	lane        string                      // Priority lane being committed, empty for regular transactions
+	excluded    map[common.Hash]*ExcludedTx // Transactions skipped while committing, with the reason
+	filledLane  string                      // Priority lane being committed when the block ran out of gas
+	interrupted bool                        // Whether committing was interrupted by a resubmit
 }
 
 // task contains all information for consensus engine sealing and result submitting.
@@ -167,6 +176,12 @@ type worker struct {
 	snapshotBlock *types.Block
 	snapshotState *state.StateDB
 
+	templateMu   sync.Mutex    // The lock used to protect the block template
+	template     *Template     // Template of the last work, nil until requested if no subscribers
+	templateWork *templateWork // Last work whose template is not built yet
+	templateFeed event.Feed
+	templateSubs int32 // Number of template subscribers (atomic)
+
 	// atomic status counters
 	running int32 // The indicator whether the consensus engine is running or not.
 	newTxs  int32 // New arrival transaction count since last sealing work submitting.
@@ -688,12 +703,13 @@ func (w *worker) makeCurrent(parent *types.Block, header *types.Header) error {
 		return err
 	}
 	env := &environment{
-		signer:    types.NewEIP155Signer(w.chainConfig.ChainID),
+		signer:    types.MakeSigner(w.chainConfig),
 		state:     state,
 		ancestors: mapset.NewSet(),
 		family:    mapset.NewSet(),
 		uncles:    mapset.NewSet(),
 		header:    header,
+		excluded:  make(map[common.Hash]*ExcludedTx),
 	}
 
 	// when 08 is processed ancestors contain 07 (quick block)
@@ -763,10 +779,18 @@ func (w *worker) updateSnapshot() {
 	w.snapshotState = w.current.state.Copy()
 }
 
-func (w *worker) commitTransaction(tx *types.Transaction, coinbase common.Address) ([]*types.Log, error) {
+func (w *worker) commitTransaction(tx *types.Transaction, coinbase common.Address, executor *core.ParallelExecutor) ([]*types.Log, error) {
 	snap := w.current.state.Snapshot()
 
-	receipt, err := core.ApplyTransaction(w.chainConfig, w.chain, &coinbase, w.current.gasPool, w.current.state, w.current.header, tx, &w.current.header.GasUsed, *w.chain.GetVMConfig())
+	var (
+		receipt *types.Receipt
+		err     error
+	)
+	if executor != nil {
+		receipt, err = executor.ApplyTransaction(w.current.gasPool, w.current.state, tx, &w.current.header.GasUsed)
+	} else {
+		receipt, err = core.ApplyTransaction(w.chainConfig, w.chain, &coinbase, w.current.gasPool, w.current.state, w.current.header, tx, &w.current.header.GasUsed, *w.chain.GetVMConfig())
+	}
 	if err != nil {
 		w.current.state.RevertToSnapshot(snap)
 		return nil, err
@@ -788,6 +812,14 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 		w.current.gasPool = new(core.GasPool).AddGas(w.current.header.GasLimit)
 	}
 
+	// Speculate the transactions in their expected order if parallel execution is enabled
+	var executor *core.ParallelExecutor
+	if vmConfig := *w.chain.GetVMConfig(); vmConfig.ParallelWorkers > 1 {
+		preview := txs.Preview(w.current.gasPool.Gas(), maxSpeculatedTxs)
+		executor = core.NewParallelExecutor(w.chainConfig, w.chain, &coinbase, w.current.state, w.current.header, preview, vmConfig)
+		defer executor.Stop()
+	}
+
 	var coalescedLogs []*types.Log
 	//Loop:
 	for {
@@ -800,6 +832,7 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 		if interrupt != nil && atomic.LoadInt32(interrupt) != commitInterruptNone {
 			// Notify resubmit loop to increase resubmitting interval due to too frequent commits.
 			if atomic.LoadInt32(interrupt) == commitInterruptResubmit {
+				w.current.interrupted = true
 				ratio := float64(w.current.header.GasLimit-w.current.gasPool.Gas()) / float64(w.current.header.GasLimit)
 				if ratio < 0.1 {
 					ratio = 0.1
@@ -814,6 +847,9 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 		// If we don't have enough gas for any further transactions then we're done
 		if w.current.gasPool.Gas() < params.TxGas {
 			log.Trace("Not enough gas for further transactions", "have", w.current.gasPool, "want", params.TxGas)
+			if w.current.filledLane == "" {
+				w.current.filledLane = w.current.lane
+			}
 			break
 		}
 		// Retrieve the next transaction and abort if all done
@@ -829,21 +865,36 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 
 		// Start executing the transaction
 		w.current.state.Prepare(tx.Hash(), common.Hash{}, w.current.tcount)
-		logs, err := w.commitTransaction(tx, coinbase)
+		logs, err := w.commitTransaction(tx, coinbase, executor)
 		switch err {
 		case core.ErrGasLimitReached:
 			// Pop the current out-of-gas transaction without shifting in the next from the account
 			log.Trace("Gas limit exceeded for current block", "sender", from)
+			w.exclude(tx, from, ExcludeGasLimit, "")
 			txs.Pop()
 
 		case core.ErrNonceTooLow:
 			// New head notification data race between the transaction pool and miner, shift
 			log.Trace("Skipping transaction with low nonce", "sender", from, "nonce", tx.Nonce())
+			w.exclude(tx, from, ExcludeNonceTooLow, "")
 			txs.Shift()
 
 		case core.ErrNonceTooHigh:
 			// Reorg notification data race between the transaction pool and miner, skip account =
 			log.Trace("Skipping account with hight nonce", "sender", from, "nonce", tx.Nonce())
+			w.exclude(tx, from, ExcludeNonceTooHigh, "")
+			txs.Pop()
+
+		case core.ErrTxTypeNotSupported:
+			// Pop the unsupported transaction without shifting in the next from the account
+			log.Trace("Skipping unsupported transaction type", "sender", from, "type", tx.Type())
+			w.exclude(tx, from, ExcludeUnsupported, "")
+			txs.Pop()
+
+		case core.ErrTxExpired:
+			// Pop the expired transaction without shifting in the next from the account
+			log.Trace("Skipping expired transaction", "sender", from, "hash", tx.Hash())
+			w.exclude(tx, from, ExcludeExpired, "")
 			txs.Pop()
 
 		case nil:
@@ -856,6 +907,7 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 			// Strange error, discard the transaction and get the next in line (note, the
 			// nonce-too-high clause will prevent us from executing in vain).
 			log.Debug("Transaction failed, account skipped", "hash", tx.Hash(), "err", err)
+			w.exclude(tx, from, ExcludeFailed, err.Error())
 			txs.Shift()
 		}
 	}
@@ -968,8 +1020,49 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 	// Short circuit if there is no available pending transactions
 	if len(pending) == 0 {
 		w.updateSnapshot()
+		w.updateTemplate(pending)
 		return
 	}
+	pool := make(map[common.Address]types.Transactions, len(pending))
+	for account, txs := range pending {
+		pool[account] = txs
+	}
+	// Commit the accounts led by priority lane transactions first, in lane order
+	if lanes := w.eth.TxPool().Lanes(); lanes > 0 {
+		laneTxs := make([]map[common.Address]types.Transactions, lanes)
+		for account, txs := range pending {
+			lane := w.eth.TxPool().Lane(txs[0])
+			if lane < 0 {
+				continue
+			}
+			// Only the leading run of the lane is prioritized, the account is split
+			// at its first transaction from elsewhere
+			n := 1
+			for n < len(txs) && w.eth.TxPool().Lane(txs[n]) == lane {
+				n++
+			}
+			if laneTxs[lane] == nil {
+				laneTxs[lane] = make(map[common.Address]types.Transactions)
+			}
+			laneTxs[lane][account] = txs[:n]
+			if n < len(txs) {
+				pending[account] = txs[n:]
+			} else {
+				delete(pending, account)
+			}
+		}
+		for lane, accounts := range laneTxs {
+			if len(accounts) == 0 {
+				continue
+			}
+			w.current.lane = w.eth.TxPool().LaneName(lane)
+			txs := types.NewTransactionsByPriceAndNonce(w.current.signer, accounts)
+			if w.commitTransactions(txs, w.coinbase, interrupt) {
+				return
+			}
+		}
+		w.current.lane = ""
+	}
 	// Split the pending transactions into locals and remotes
 	localTxs, remoteTxs := make(map[common.Address]types.Transactions), pending
 	for _, account := range w.eth.TxPool().Locals() {
@@ -991,6 +1084,7 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 		}
 	}
 	w.commit(uncles, w.fullTaskHook, true, tstart)
+	w.updateTemplate(pool)
 }
 
 // commit runs any post-transaction state modifications, assembles the final block
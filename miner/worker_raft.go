@@ -158,6 +158,8 @@ func (w *worker) commitRaftWork() {
 		return
 	}
 
+	w.updateTemplate(txs)
+
 	if w.current.tcount == 0 {
 		log.Info("Not minting a new block since there are no pending transactions")
 		return
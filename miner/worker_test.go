@@ -517,3 +517,106 @@ func testAdjustInterval(t *testing.T, chainConfig *params.ChainConfig, engine co
 		t.Error("interval reset timeout")
 	}
 }
+
+// Tests that the worker publishes the template of its work, explaining the pool
+// transactions left out.
+func TestBlockTemplate(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	w, b := newTestWorker(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	defer w.close()
+
+	gapped, _ := types.SignTx(types.NewTransaction(5, testUserAddress, big.NewInt(1000), params.TxGas, nil, nil), types.HomesteadSigner{}, testBankKey)
+	b.txPool.AddLocal(gapped)
+
+	templates := make(chan *Template, 16)
+	sub := w.subscribeTemplates(templates)
+	defer sub.Unsubscribe()
+
+	w.skipSealHook = func(task *task) bool { return true }
+	w.start()
+
+	timeout := time.NewTimer(3 * time.Second)
+	defer timeout.Stop()
+	for {
+		select {
+		case template := <-templates:
+			if len(template.Transactions) != 1 {
+				continue
+			}
+			if tx := template.Transactions[0]; tx.Hash != pendingTxs[0].Hash() || uint64(tx.GasUsed) != params.TxGas || tx.From != testBankAddress {
+				t.Fatalf("included transaction mismatch: %+v", tx)
+			}
+			if len(template.Excluded) != 1 {
+				t.Fatalf("excluded transaction count mismatch: have %d, want 1", len(template.Excluded))
+			}
+			if tx := template.Excluded[0]; tx.Hash != gapped.Hash() || tx.Reason != ExcludeNonceGap {
+				t.Fatalf("excluded transaction mismatch: %+v", tx)
+			}
+			if w.currentTemplate() == nil {
+				t.Fatalf("template not retained")
+			}
+			return
+		case <-timeout.C:
+			t.Fatalf("template timeout")
+		}
+	}
+}
+
+func TestLaneAccountSplit(t *testing.T) {
+	engine := ethash.NewFaker()
+	defer engine.Close()
+
+	// Fund the user account, so that both accounts may have pending transactions
+	b := newTestWorkerBackend(t, ethashChainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	blocks, _ := core.GenerateChain(ethashChainConfig, b.chain.Genesis(), engine, b.db, 1, func(i int, gen *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(0, testUserAddress, big.NewInt(params.Ether/10), params.TxGas, nil, nil), types.HomesteadSigner{}, testBankKey)
+		gen.AddTx(tx)
+	})
+	if _, err := b.chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert funding block: %v", err)
+	}
+	config := testTxPoolConfig
+	config.Lanes = []core.TxLane{{Name: "events", Prefixes: []string{"event:"}, Slots: 16}}
+	b.txPool.Stop()
+	b.txPool = core.NewTxPool(config, ethashChainConfig, b.chain)
+
+	// The regular transaction of the bank must not ride along its lane transaction
+	lane, _ := types.SignTx(types.NewTransaction(1, testUserAddress, big.NewInt(0), 50000, big.NewInt(1), []byte("event:vote")), types.HomesteadSigner{}, testBankKey)
+	regular, _ := types.SignTx(types.NewTransaction(2, testUserAddress, big.NewInt(0), params.TxGas, big.NewInt(1), nil), types.HomesteadSigner{}, testBankKey)
+	expensive, _ := types.SignTx(types.NewTransaction(0, testBankAddress, big.NewInt(0), params.TxGas, big.NewInt(10), nil), types.HomesteadSigner{}, testUserKey)
+	for _, err := range b.txPool.AddLocals([]*types.Transaction{lane, regular, expensive}) {
+		if err != nil {
+			t.Fatalf("failed to add transaction: %v", err)
+		}
+	}
+	w := newWorker(testConfig, ethashChainConfig, engine, b, new(event.TypeMux), nil, false)
+	w.setEtherbase(testBankAddress)
+	defer w.close()
+
+	taskCh := make(chan types.Transactions, 16)
+	w.newTaskHook = func(task *task) { taskCh <- task.block.Transactions() }
+	w.skipSealHook = func(task *task) bool { return true }
+	w.start()
+
+	want := []common.Hash{lane.Hash(), expensive.Hash(), regular.Hash()}
+	timeout := time.NewTimer(3 * time.Second)
+	defer timeout.Stop()
+	for {
+		select {
+		case txs := <-taskCh:
+			if len(txs) != len(want) {
+				continue
+			}
+			for i, tx := range txs {
+				if tx.Hash() != want[i] {
+					t.Fatalf("transaction %d mismatch: have %x, want %x", i, tx.Hash(), want[i])
+				}
+			}
+			return
+		case <-timeout.C:
+			t.Fatalf("new task timeout")
+		}
+	}
+}
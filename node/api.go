@@ -31,7 +31,8 @@ import (
 // PrivateAdminAPI is the collection of administrative API methods exposed only
 // over a secure RPC channel.
 type PrivateAdminAPI struct {
-	node *Node // Node interfaced by this API
+	node  *Node  // Node interfaced by this API
+	chain string // Hosted chain interfaced by this API, empty for the node itself
 }
 
 // NewPrivateAdminAPI creates a new API definition for the private admin methods
@@ -44,7 +45,7 @@ func NewPrivateAdminAPI(node *Node) *PrivateAdminAPI {
 // connection at all times, even reconnecting if it is lost.
 func (api *PrivateAdminAPI) AddPeer(url string) (bool, error) {
 	// Make sure the server is running, fail otherwise
-	server := api.node.Server()
+	server := api.server()
 	if server == nil {
 		return false, ErrNodeStopped
 	}
@@ -60,7 +61,7 @@ func (api *PrivateAdminAPI) AddPeer(url string) (bool, error) {
 // RemovePeer disconnects from a remote node if the connection exists
 func (api *PrivateAdminAPI) RemovePeer(url string) (bool, error) {
 	// Make sure the server is running, fail otherwise
-	server := api.node.Server()
+	server := api.server()
 	if server == nil {
 		return false, ErrNodeStopped
 	}
@@ -76,7 +77,7 @@ func (api *PrivateAdminAPI) RemovePeer(url string) (bool, error) {
 // AddTrustedPeer allows a remote node to always connect, even if slots are full
 func (api *PrivateAdminAPI) AddTrustedPeer(url string) (bool, error) {
 	// Make sure the server is running, fail otherwise
-	server := api.node.Server()
+	server := api.server()
 	if server == nil {
 		return false, ErrNodeStopped
 	}
@@ -92,7 +93,7 @@ func (api *PrivateAdminAPI) AddTrustedPeer(url string) (bool, error) {
 // does not disconnect it automatically.
 func (api *PrivateAdminAPI) RemoveTrustedPeer(url string) (bool, error) {
 	// Make sure the server is running, fail otherwise
-	server := api.node.Server()
+	server := api.server()
 	if server == nil {
 		return false, ErrNodeStopped
 	}
@@ -108,7 +109,7 @@ func (api *PrivateAdminAPI) RemoveTrustedPeer(url string) (bool, error) {
 // node's p2p.Server
 func (api *PrivateAdminAPI) PeerEvents(ctx context.Context) (*rpc.Subscription, error) {
 	// Make sure the server is running, fail otherwise
-	server := api.node.Server()
+	server := api.server()
 	if server == nil {
 		return nil, ErrNodeStopped
 	}
@@ -142,121 +143,86 @@ func (api *PrivateAdminAPI) PeerEvents(ctx context.Context) (*rpc.Subscription,
 	return rpcSub, nil
 }
 
+// server returns the p2p server of the interfaced chain.
+func (api *PrivateAdminAPI) server() *p2p.Server {
+	if api.chain != "" {
+		return api.node.ChainServer(api.chain)
+	}
+	return api.node.Server()
+}
+
+// endpoints returns the RPC endpoint group of the interfaced chain along with
+// its configuration.
+func (api *PrivateAdminAPI) endpoints() (*rpcEndpoints, *ChainConfig) {
+	if api.chain != "" {
+		if c := api.node.chain(api.chain); c != nil {
+			return &c.rpc, c.config
+		}
+	}
+	return &api.node.rpcEndpoints, api.node.config.rpcConfig()
+}
+
 // StartRPC starts the HTTP RPC API server.
 func (api *PrivateAdminAPI) StartRPC(host *string, port *int, cors *string, apis *string, vhosts *string) (bool, error) {
 	api.node.lock.Lock()
 	defer api.node.lock.Unlock()
 
-	if api.node.IsMainChain() {
-
-		if api.node.httpHandler != nil {
-			return false, fmt.Errorf("HTTP RPC already running on %s", api.node.httpEndpoint)
-		}
-
-		if host == nil {
-			h := DefaultHTTPHost
-			if api.node.config.HTTPHost != "" {
-				h = api.node.config.HTTPHost
-			}
-			host = &h
-		}
-		if port == nil {
-			port = &api.node.config.HTTPPort
-		}
-
-		allowedOrigins := api.node.config.HTTPCors
-		if cors != nil {
-			allowedOrigins = nil
-			for _, origin := range strings.Split(*cors, ",") {
-				allowedOrigins = append(allowedOrigins, strings.TrimSpace(origin))
-			}
-		}
-
-		allowedVHosts := api.node.config.HTTPVirtualHosts
-		if vhosts != nil {
-			allowedVHosts = nil
-			for _, vhost := range strings.Split(*host, ",") {
-				allowedVHosts = append(allowedVHosts, strings.TrimSpace(vhost))
-			}
-		}
-
-		modules := api.node.httpWhitelist
-		if apis != nil {
-			modules = nil
-			for _, m := range strings.Split(*apis, ",") {
-				modules = append(modules, strings.TrimSpace(m))
-			}
-		}
-
-		if err := api.node.startHTTP(fmt.Sprintf("%s:%d", *host, *port), api.node.rpcAPIs, modules, allowedOrigins, allowedVHosts, api.node.config.HTTPTimeouts); err != nil {
-			return false, err
-		}
-		return true, nil
-	} else if api.node.IsSubChain() {
-		if api.node.subHttpHandler != nil {
-			return false, fmt.Errorf("HTTP RPC already running on %v", api.node.subHttpHandler)
-		}
+	endpoints, config := api.endpoints()
+	if endpoints.httpHandler != nil {
+		return false, fmt.Errorf("HTTP RPC already running on %s", endpoints.httpEndpoint)
+	}
 
-		if host == nil {
-			h := DefaultSubHTTPHost
-			if api.node.config.SubHTTPHost != "" {
-				h = api.node.config.SubHTTPHost
-			}
-			host = &h
-		}
-		if port == nil {
-			port = &api.node.config.SubHTTPPort
+	if host == nil {
+		h := DefaultHTTPHost
+		if config.HTTPHost != "" {
+			h = config.HTTPHost
 		}
+		host = &h
+	}
+	if port == nil {
+		port = &config.HTTPPort
+	}
 
-		allowedOrigins := api.node.config.SubHTTPCors
-		if cors != nil {
-			allowedOrigins = nil
-			for _, origin := range strings.Split(*cors, ",") {
-				allowedOrigins = append(allowedOrigins, strings.TrimSpace(origin))
-			}
+	allowedOrigins := config.HTTPCors
+	if cors != nil {
+		allowedOrigins = nil
+		for _, origin := range strings.Split(*cors, ",") {
+			allowedOrigins = append(allowedOrigins, strings.TrimSpace(origin))
 		}
+	}
 
-		allowedVHosts := api.node.config.SubHTTPVirtualHosts
-		if vhosts != nil {
-			allowedVHosts = nil
-			for _, vhost := range strings.Split(*host, ",") {
-				allowedVHosts = append(allowedVHosts, strings.TrimSpace(vhost))
-			}
+	allowedVHosts := config.HTTPVirtualHosts
+	if vhosts != nil {
+		allowedVHosts = nil
+		for _, vhost := range strings.Split(*host, ",") {
+			allowedVHosts = append(allowedVHosts, strings.TrimSpace(vhost))
 		}
+	}
 
-		modules := api.node.httpWhitelist
-		if apis != nil {
-			modules = nil
-			for _, m := range strings.Split(*apis, ",") {
-				modules = append(modules, strings.TrimSpace(m))
-			}
+	modules := endpoints.httpWhitelist
+	if apis != nil {
+		modules = nil
+		for _, m := range strings.Split(*apis, ",") {
+			modules = append(modules, strings.TrimSpace(m))
 		}
+	}
 
-		if err := api.node.startSubHTTP(fmt.Sprintf("%s:%d", *host, *port), api.node.subRpcAPIs, modules, allowedOrigins, allowedVHosts, api.node.config.HTTPTimeouts); err != nil {
-			return false, err
-		}
-		return true, nil
+	if err := endpoints.startHTTP(fmt.Sprintf("%s:%d", *host, *port), endpoints.rpcAPIs, modules, allowedOrigins, allowedVHosts, api.node.config.HTTPTimeouts, config.APIKeys, config.HTTPAuth); err != nil {
+		return false, err
 	}
-	return false, nil
+	return true, nil
 }
 
 // StopRPC terminates an already running HTTP RPC API endpoint.
 func (api *PrivateAdminAPI) StopRPC() (bool, error) {
 	api.node.lock.Lock()
 	defer api.node.lock.Unlock()
-	if api.node.IsMainChain() {
-		if api.node.httpHandler == nil {
-			return false, fmt.Errorf("HTTP RPC not running")
-		}
-		api.node.stopHTTP()
-	} else if api.node.IsSubChain() {
-		if api.node.subHttpHandler == nil {
-			return false, fmt.Errorf("HTTP RPC not running")
-		}
-		api.node.stopSubHTTP()
-	} else if api.node.IsAnchor() {
-		return false, nil
+
+	endpoints, _ := api.endpoints()
+	if endpoints.httpHandler == nil {
+		return false, fmt.Errorf("HTTP RPC not running")
 	}
+	endpoints.stopHTTP()
 	return true, nil
 }
 
@@ -264,107 +230,63 @@ func (api *PrivateAdminAPI) StopRPC() (bool, error) {
 func (api *PrivateAdminAPI) StartWS(host *string, port *int, allowedOrigins *string, apis *string) (bool, error) {
 	api.node.lock.Lock()
 	defer api.node.lock.Unlock()
-	if api.node.IsMainChain() {
-		if api.node.wsHandler != nil {
-			return false, fmt.Errorf("WebSocket RPC already running on %s", api.node.wsEndpoint)
-		}
-
-		if host == nil {
-			h := DefaultWSHost
-			if api.node.config.WSHost != "" {
-				h = api.node.config.WSHost
-			}
-			host = &h
-		}
-		if port == nil {
-			port = &api.node.config.WSPort
-		}
-
-		origins := api.node.config.WSOrigins
-		if allowedOrigins != nil {
-			origins = nil
-			for _, origin := range strings.Split(*allowedOrigins, ",") {
-				origins = append(origins, strings.TrimSpace(origin))
-			}
-		}
-
-		modules := api.node.config.WSModules
-		if apis != nil {
-			modules = nil
-			for _, m := range strings.Split(*apis, ",") {
-				modules = append(modules, strings.TrimSpace(m))
-			}
-		}
-
-		if err := api.node.startWS(fmt.Sprintf("%s:%d", *host, *port), api.node.rpcAPIs, modules, origins, api.node.config.WSExposeAll); err != nil {
-			return false, err
-		}
-		return true, nil
-	} else if api.node.IsSubChain() {
-		if api.node.subWsHandler != nil {
-			return false, fmt.Errorf("WebSocket RPC already running on %v", api.node.subWsHandler)
-		}
 
-		if host == nil {
-			h := DefaultSubWSHost
-			if api.node.config.SubWSHost != "" {
-				h = api.node.config.SubWSHost
-			}
-			host = &h
-		}
-		if port == nil {
-			port = &api.node.config.SubWSPort
-		}
+	endpoints, config := api.endpoints()
+	if endpoints.wsHandler != nil {
+		return false, fmt.Errorf("WebSocket RPC already running on %s", endpoints.wsEndpoint)
+	}
 
-		origins := api.node.config.SubWSOrigins
-		if allowedOrigins != nil {
-			origins = nil
-			for _, origin := range strings.Split(*allowedOrigins, ",") {
-				origins = append(origins, strings.TrimSpace(origin))
-			}
+	if host == nil {
+		h := DefaultWSHost
+		if config.WSHost != "" {
+			h = config.WSHost
 		}
+		host = &h
+	}
+	if port == nil {
+		port = &config.WSPort
+	}
 
-		modules := api.node.config.SubWSModules
-		if apis != nil {
-			modules = nil
-			for _, m := range strings.Split(*apis, ",") {
-				modules = append(modules, strings.TrimSpace(m))
-			}
+	origins := config.WSOrigins
+	if allowedOrigins != nil {
+		origins = nil
+		for _, origin := range strings.Split(*allowedOrigins, ",") {
+			origins = append(origins, strings.TrimSpace(origin))
 		}
+	}
 
-		if err := api.node.startSubWS(fmt.Sprintf("%s:%d", *host, *port), api.node.subRpcAPIs, modules, origins, api.node.config.SubWSExposeAll); err != nil {
-			return false, err
+	modules := config.WSModules
+	if apis != nil {
+		modules = nil
+		for _, m := range strings.Split(*apis, ",") {
+			modules = append(modules, strings.TrimSpace(m))
 		}
-		return true, nil
 	}
 
-	return false, nil
+	if err := endpoints.startWS(fmt.Sprintf("%s:%d", *host, *port), endpoints.rpcAPIs, modules, origins, config.WSExposeAll, config.APIKeys, config.WSAuth); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 // StopWS terminates an already running websocket RPC API endpoint.
 func (api *PrivateAdminAPI) StopWS() (bool, error) {
 	api.node.lock.Lock()
 	defer api.node.lock.Unlock()
-	if api.node.IsMainChain() {
-		if api.node.wsHandler == nil {
-			return false, fmt.Errorf("WebSocket RPC not running")
-		}
-		api.node.stopWS()
-	} else if api.node.IsSubChain() {
-		if api.node.subWsHandler == nil {
-			return false, fmt.Errorf("WebSocket RPC not running")
-		}
-		api.node.stopSubWS()
-	} else {
-		return false, nil
+
+	endpoints, _ := api.endpoints()
+	if endpoints.wsHandler == nil {
+		return false, fmt.Errorf("WebSocket RPC not running")
 	}
+	endpoints.stopWS()
 	return true, nil
 }
 
 // PublicAdminAPI is the collection of administrative API methods exposed over
 // both secure and unsecure RPC channels.
 type PublicAdminAPI struct {
-	node *Node // Node interfaced by this API
+	node  *Node  // Node interfaced by this API
+	chain string // Hosted chain interfaced by this API, empty for the node itself
 }
 
 // NewPublicAdminAPI creates a new API definition for the public admin methods
@@ -373,10 +295,18 @@ func NewPublicAdminAPI(node *Node) *PublicAdminAPI {
 	return &PublicAdminAPI{node: node}
 }
 
+// server returns the p2p server of the interfaced chain.
+func (api *PublicAdminAPI) server() *p2p.Server {
+	if api.chain != "" {
+		return api.node.ChainServer(api.chain)
+	}
+	return api.node.Server()
+}
+
 // Peers retrieves all the information we know about each individual peer at the
 // protocol granularity.
 func (api *PublicAdminAPI) Peers() ([]*p2p.PeerInfo, error) {
-	server := api.node.Server()
+	server := api.server()
 	if server == nil {
 		return nil, ErrNodeStopped
 	}
@@ -386,7 +316,7 @@ func (api *PublicAdminAPI) Peers() ([]*p2p.PeerInfo, error) {
 // NodeInfo retrieves all the information we know about the host node at the
 // protocol granularity.
 func (api *PublicAdminAPI) NodeInfo() (*p2p.NodeInfo, error) {
-	server := api.node.Server()
+	server := api.server()
 	if server == nil {
 		return nil, ErrNodeStopped
 	}
@@ -0,0 +1,153 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+
+	"github.com/simplechain-org/go-simplechain/p2p"
+	"github.com/simplechain-org/go-simplechain/rpc"
+)
+
+// ChainConfig is the config section of a chain hosted by the node. The services
+// registered to a chain store their data in the chain namespace of the instance
+// directory, are served by the RPC endpoint group of the chain, and run their
+// protocols on the p2p server of the chain.
+type ChainConfig struct {
+	// Name identifies the chain within the node, it must be unique and must not
+	// contain the / character.
+	Name string
+
+	// DataDir is the namespace of the chain within the instance directory, defaults
+	// to the chain name.
+	DataDir string `toml:",omitempty"`
+
+	// P2P is the networking config of the chain. If nil, the chain protocols run on
+	// the p2p server of the node, in which case they must not collide with the
+	// protocols of the node or of other chains sharing it.
+	P2P *p2p.Config `toml:",omitempty"`
+
+	// IPCPath is the requested location to place the IPC endpoint of the chain,
+	// resolved the same way as Config.IPCPath. An empty path disables IPC.
+	IPCPath string `toml:",omitempty"`
+
+	// HTTPHost is the host interface on which to start the HTTP RPC server of the
+	// chain. If this field is empty, no HTTP API endpoint will be started.
+	HTTPHost         string          `toml:",omitempty"`
+	HTTPPort         int             `toml:",omitempty"`
+	HTTPCors         []string        `toml:",omitempty"`
+	HTTPVirtualHosts []string        `toml:",omitempty"`
+	HTTPModules      []string        `toml:",omitempty"`
+	HTTPAuth         *rpc.AuthConfig `toml:",omitempty"`
+
+	// WSHost is the host interface on which to start the websocket RPC server of
+	// the chain. If this field is empty, no websocket API endpoint will be started.
+	WSHost      string          `toml:",omitempty"`
+	WSPort      int             `toml:",omitempty"`
+	WSOrigins   []string        `toml:",omitempty"`
+	WSModules   []string        `toml:",omitempty"`
+	WSExposeAll bool            `toml:",omitempty"`
+	WSAuth      *rpc.AuthConfig `toml:",omitempty"`
+
+	// APIKeys is the list of keys required by the HTTP and websocket RPC servers
+	// of the chain, as Config.APIKeys.
+	APIKeys []rpc.APIKey `toml:",omitempty"`
+
+	// GraphQLHost is the host interface on which to start the GraphQL server of
+	// the chain. If this field is empty, no GraphQL endpoint will be started.
+	GraphQLHost         string   `toml:",omitempty"`
+	GraphQLPort         int      `toml:",omitempty"`
+	GraphQLCors         []string `toml:",omitempty"`
+	GraphQLVirtualHosts []string `toml:",omitempty"`
+}
+
+// HTTPEndpoint resolves the HTTP endpoint of the chain.
+func (c *ChainConfig) HTTPEndpoint() string {
+	if c.HTTPHost == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", c.HTTPHost, c.HTTPPort)
+}
+
+// WSEndpoint resolves the websocket endpoint of the chain.
+func (c *ChainConfig) WSEndpoint() string {
+	if c.WSHost == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", c.WSHost, c.WSPort)
+}
+
+// GraphQLEndpoint resolves the GraphQL endpoint of the chain.
+func (c *ChainConfig) GraphQLEndpoint() string {
+	if c.GraphQLHost == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", c.GraphQLHost, c.GraphQLPort)
+}
+
+func (c *ChainConfig) dataDir() string {
+	if c.DataDir == "" {
+		return c.Name
+	}
+	return c.DataDir
+}
+
+// rpcConfig returns the RPC settings of the node endpoints in the shape of a
+// chain config.
+func (c *Config) rpcConfig() *ChainConfig {
+	return &ChainConfig{
+		IPCPath:          c.IPCPath,
+		HTTPHost:         c.HTTPHost,
+		HTTPPort:         c.HTTPPort,
+		HTTPCors:         c.HTTPCors,
+		HTTPVirtualHosts: c.HTTPVirtualHosts,
+		HTTPModules:      c.HTTPModules,
+		HTTPAuth:         c.HTTPAuth,
+		WSHost:           c.WSHost,
+		WSPort:           c.WSPort,
+		WSOrigins:        c.WSOrigins,
+		WSModules:        c.WSModules,
+		WSExposeAll:      c.WSExposeAll,
+		WSAuth:           c.WSAuth,
+		APIKeys:          c.APIKeys,
+	}
+}
+
+// resolveChainPath resolves path in the namespace of the given chain, or in the
+// instance directory if chain is nil.
+func (c *Config) resolveChainPath(chain *ChainConfig, path string) string {
+	if chain == nil {
+		return c.ResolvePath(path)
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	if c.DataDir == "" {
+		return ""
+	}
+	return filepath.Join(c.instanceDir(), chain.dataDir(), path)
+}
+
+// chain is a chain hosted by the node, along with its running services.
+type chain struct {
+	config   *ChainConfig
+	services map[reflect.Type]Service // Currently running services of the chain
+	server   *p2p.Server              // P2P server of the chain, nil if the node server is shared
+	rpc      rpcEndpoints             // RPC endpoint group of the chain
+}
@@ -0,0 +1,149 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/simplechain-org/go-simplechain/p2p"
+)
+
+// Tests that invalid or duplicate chain names are rejected.
+func TestChainConfigValidation(t *testing.T) {
+	for i, chains := range [][]ChainConfig{
+		{{Name: ""}},
+		{{Name: "a/b"}},
+		{{Name: "a"}, {Name: "a"}},
+	} {
+		config := testNodeConfig()
+		config.Chains = chains
+		if _, err := New(config); err == nil {
+			t.Errorf("test %d: invalid chains accepted: %v", i, chains)
+		}
+	}
+}
+
+// Tests that services registered to a hosted chain are kept apart from the node
+// services and store their data in the chain namespace.
+func TestChainServices(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary data directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := testNodeConfig()
+	config.DataDir = dir
+	config.Chains = []ChainConfig{{Name: "sub"}}
+
+	stack, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to create protocol stack: %v", err)
+	}
+	defer stack.Close()
+
+	if err := stack.RegisterChain("unknown", NewNoopServiceB); err != ErrChainUnknown {
+		t.Fatalf("unknown chain registration mismatch: have %v, want %v", err, ErrChainUnknown)
+	}
+	if err := stack.Register(NewNoopServiceA); err != nil {
+		t.Fatalf("failed to register node service: %v", err)
+	}
+	var (
+		chainName string
+		chainPath string
+	)
+	constructor := func(ctx *ServiceContext) (Service, error) {
+		var global *NoopServiceA
+		if err := ctx.Service(&global); err != nil {
+			t.Errorf("node service not visible to chain: %v", err)
+		}
+		chainName, chainPath = ctx.Chain(), ctx.ResolvePath("chaindata")
+		return new(NoopServiceB), nil
+	}
+	if err := stack.RegisterChain("sub", constructor); err != nil {
+		t.Fatalf("failed to register chain service: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start protocol stack: %v", err)
+	}
+	defer stack.Stop()
+
+	if chainName != "sub" {
+		t.Errorf("chain name mismatch: have %q, want %q", chainName, "sub")
+	}
+	if want := filepath.Join(dir, config.name(), "sub", "chaindata"); chainPath != want {
+		t.Errorf("chain path mismatch: have %s, want %s", chainPath, want)
+	}
+	var service *NoopServiceB
+	if err := stack.ChainService("sub", &service); err != nil || service == nil {
+		t.Errorf("chain service not retrievable: %v", err)
+	}
+	if err := stack.Service(&service); err != ErrServiceUnknown {
+		t.Errorf("chain service leaked to node: have %v, want %v", err, ErrServiceUnknown)
+	}
+	if server := stack.ChainServer("sub"); server != stack.Server() {
+		t.Errorf("chain without p2p config not sharing the node server")
+	}
+	client, err := stack.AttachChain("sub")
+	if err != nil {
+		t.Fatalf("failed to attach to chain: %v", err)
+	}
+	defer client.Close()
+
+	var modules map[string]string
+	if err := client.Call(&modules, "rpc_modules"); err != nil {
+		t.Fatalf("failed to query chain modules: %v", err)
+	}
+	if _, ok := modules["admin"]; !ok {
+		t.Errorf("admin module missing from chain endpoint: %v", modules)
+	}
+}
+
+// Tests that hosted chains sharing the node p2p server can't run the same
+// protocol versions, while chains with their own server can.
+func TestChainProtocolCollision(t *testing.T) {
+	protocol := func(ctx *ServiceContext) (Service, error) {
+		return &InstrumentedService{protocols: []p2p.Protocol{{Name: "sub", Version: 1}}}, nil
+	}
+	for i, dedicated := range []bool{false, true} {
+		config := testNodeConfig()
+		config.Chains = []ChainConfig{{Name: "a"}, {Name: "b"}}
+		if dedicated {
+			config.Chains[1].P2P = &p2p.Config{MaxPeers: 1, ListenAddr: "127.0.0.1:0", NoDiscovery: true}
+		}
+		stack, err := New(config)
+		if err != nil {
+			t.Fatalf("test %d: failed to create protocol stack: %v", i, err)
+		}
+		for _, chain := range []string{"a", "b"} {
+			if err := stack.RegisterChain(chain, protocol); err != nil {
+				t.Fatalf("test %d: failed to register chain service: %v", i, err)
+			}
+		}
+		err = stack.Start()
+		if _, ok := err.(*DuplicateProtocolError); ok == dedicated {
+			t.Errorf("test %d: start error mismatch: %v", i, err)
+		}
+		if err == nil {
+			stack.Stop()
+		}
+		stack.Close()
+	}
+}
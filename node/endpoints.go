@@ -0,0 +1,198 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/simplechain-org/go-simplechain/log"
+	"github.com/simplechain-org/go-simplechain/rpc"
+)
+
+// rpcEndpoints is a group of RPC endpoints serving the APIs of a chain.
+type rpcEndpoints struct {
+	rpcAPIs       []rpc.API   // List of APIs currently provided by the endpoints
+	inprocHandler *rpc.Server // In-process RPC request handler to process the API requests
+
+	ipcEndpoint string       // IPC endpoint to listen at (empty = IPC disabled)
+	ipcListener net.Listener // IPC RPC listener socket to serve API requests
+	ipcHandler  *rpc.Server  // IPC RPC request handler to process the API requests
+
+	httpEndpoint  string       // HTTP endpoint (interface + port) to listen at (empty = HTTP disabled)
+	httpWhitelist []string     // HTTP RPC modules to allow through this endpoint
+	httpListener  net.Listener // HTTP RPC listener socket to server API requests
+	httpHandler   *rpc.Server  // HTTP RPC request handler to process the API requests
+
+	wsEndpoint string       // Websocket endpoint (interface + port) to listen at (empty = websocket disabled)
+	wsListener net.Listener // Websocket RPC listener socket to server API requests
+	wsHandler  *rpc.Server  // Websocket RPC request handler to process the API requests
+
+	logger log.Logger
+}
+
+// start starts all the configured endpoints of the group, terminating all in
+// case of errors.
+func (r *rpcEndpoints) start(apis []rpc.API, config *ChainConfig, timeouts rpc.HTTPTimeouts) error {
+	if err := r.startInProc(apis); err != nil {
+		return err
+	}
+	if err := r.startIPC(apis); err != nil {
+		r.stopInProc()
+		return err
+	}
+	if err := r.startHTTP(r.httpEndpoint, apis, config.HTTPModules, config.HTTPCors, config.HTTPVirtualHosts, timeouts, config.APIKeys, config.HTTPAuth); err != nil {
+		r.stopIPC()
+		r.stopInProc()
+		return err
+	}
+	if err := r.startWS(r.wsEndpoint, apis, config.WSModules, config.WSOrigins, config.WSExposeAll, config.APIKeys, config.WSAuth); err != nil {
+		r.stopHTTP()
+		r.stopIPC()
+		r.stopInProc()
+		return err
+	}
+	// All API endpoints started successfully
+	r.rpcAPIs = apis
+	return nil
+}
+
+// stop terminates the network endpoints of the group.
+func (r *rpcEndpoints) stop() {
+	r.stopWS()
+	r.stopHTTP()
+	r.stopIPC()
+	r.rpcAPIs = nil
+}
+
+// startInProc initializes an in-process RPC endpoint.
+func (r *rpcEndpoints) startInProc(apis []rpc.API) error {
+	// Register all the APIs exposed by the services
+	handler := rpc.NewServer()
+	for _, api := range apis {
+		if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
+			return err
+		}
+		r.logger.Debug("InProc registered", "namespace", api.Namespace)
+	}
+	r.inprocHandler = handler
+	return nil
+}
+
+// stopInProc terminates the in-process RPC endpoint.
+func (r *rpcEndpoints) stopInProc() {
+	if r.inprocHandler != nil {
+		r.inprocHandler.Stop()
+		r.inprocHandler = nil
+	}
+}
+
+// startIPC initializes and starts the IPC RPC endpoint.
+func (r *rpcEndpoints) startIPC(apis []rpc.API) error {
+	if r.ipcEndpoint == "" {
+		return nil // IPC disabled.
+	}
+	listener, handler, err := rpc.StartIPCEndpoint(r.ipcEndpoint, apis)
+	if err != nil {
+		return err
+	}
+	r.ipcListener = listener
+	r.ipcHandler = handler
+	r.logger.Info("IPC endpoint opened", "url", r.ipcEndpoint)
+	return nil
+}
+
+// stopIPC terminates the IPC RPC endpoint.
+func (r *rpcEndpoints) stopIPC() {
+	if r.ipcListener != nil {
+		r.ipcListener.Close()
+		r.ipcListener = nil
+
+		r.logger.Info("IPC endpoint closed", "url", r.ipcEndpoint)
+	}
+	if r.ipcHandler != nil {
+		r.ipcHandler.Stop()
+		r.ipcHandler = nil
+	}
+}
+
+// startHTTP initializes and starts the HTTP RPC endpoint.
+func (r *rpcEndpoints) startHTTP(endpoint string, apis []rpc.API, modules []string, cors []string, vhosts []string, timeouts rpc.HTTPTimeouts, keys []rpc.APIKey, auth *rpc.AuthConfig) error {
+	// Short circuit if the HTTP endpoint isn't being exposed
+	if endpoint == "" {
+		return nil
+	}
+	listener, handler, err := rpc.StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts, timeouts, keys, auth)
+	if err != nil {
+		return err
+	}
+	r.logger.Info("HTTP endpoint opened", "url", fmt.Sprintf("%s://%s", auth.Scheme("http"), endpoint), "cors", strings.Join(cors, ","), "vhosts", strings.Join(vhosts, ","), "apikeys", len(keys))
+	// All listeners booted successfully
+	r.httpEndpoint = endpoint
+	r.httpListener = listener
+	r.httpHandler = handler
+
+	return nil
+}
+
+// stopHTTP terminates the HTTP RPC endpoint.
+func (r *rpcEndpoints) stopHTTP() {
+	if r.httpListener != nil {
+		r.httpListener.Close()
+		r.httpListener = nil
+
+		r.logger.Info("HTTP endpoint closed", "url", fmt.Sprintf("http://%s", r.httpEndpoint))
+	}
+	if r.httpHandler != nil {
+		r.httpHandler.Stop()
+		r.httpHandler = nil
+	}
+}
+
+// startWS initializes and starts the websocket RPC endpoint.
+func (r *rpcEndpoints) startWS(endpoint string, apis []rpc.API, modules []string, wsOrigins []string, exposeAll bool, keys []rpc.APIKey, auth *rpc.AuthConfig) error {
+	// Short circuit if the WS endpoint isn't being exposed
+	if endpoint == "" {
+		return nil
+	}
+	listener, handler, err := rpc.StartWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll, keys, auth)
+	if err != nil {
+		return err
+	}
+	r.logger.Info("WebSocket endpoint opened", "url", fmt.Sprintf("%s://%s", auth.Scheme("ws"), listener.Addr()))
+	// All listeners booted successfully
+	r.wsEndpoint = endpoint
+	r.wsListener = listener
+	r.wsHandler = handler
+
+	return nil
+}
+
+// stopWS terminates the websocket RPC endpoint.
+func (r *rpcEndpoints) stopWS() {
+	if r.wsListener != nil {
+		r.wsListener.Close()
+		r.wsListener = nil
+
+		r.logger.Info("WebSocket endpoint closed", "url", fmt.Sprintf("ws://%s", r.wsEndpoint))
+	}
+	if r.wsHandler != nil {
+		r.wsHandler.Stop()
+		r.wsHandler = nil
+	}
+}
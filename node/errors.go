@@ -28,6 +28,7 @@ var (
 	ErrNodeStopped    = errors.New("node not started")
 	ErrNodeRunning    = errors.New("node already running")
 	ErrServiceUnknown = errors.New("unknown service")
+	ErrChainUnknown   = errors.New("unknown chain")
 
 	datadirInUseErrnos = map[uint]bool{11: true, 32: true, 35: true}
 )
@@ -50,14 +51,31 @@ func (e *DuplicateServiceError) Error() string {
 	return fmt.Sprintf("duplicate service: %v", e.Kind)
 }
 
+// DuplicateProtocolError is returned during Node startup if the services sharing
+// a p2p server run the same protocol version, e.g. two hosted chains without
+// dedicated networking.
+type DuplicateProtocolError struct {
+	Name    string
+	Version uint
+}
+
+// Error generates a textual representation of the duplicate protocol error.
+func (e *DuplicateProtocolError) Error() string {
+	return fmt.Sprintf("duplicate protocol: %s/%d", e.Name, e.Version)
+}
+
 // StopError is returned if a Node fails to stop either any of its registered
 // services or itself.
 type StopError struct {
 	Server   error
 	Services map[reflect.Type]error
+	Chains   map[string]map[reflect.Type]error // Failures of the services of hosted chains
 }
 
 // Error generates a textual representation of the stop error.
 func (e *StopError) Error() string {
+	if len(e.Chains) > 0 {
+		return fmt.Sprintf("server: %v, services: %v, chains: %v", e.Server, e.Services, e.Chains)
+	}
 	return fmt.Sprintf("server: %v, services: %v", e.Server, e.Services)
 }
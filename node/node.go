@@ -19,7 +19,6 @@ package node
 import (
 	"errors"
 	"fmt"
-	"net"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -49,46 +48,24 @@ type Node struct {
 	serverConfig p2p.Config
 	server       *p2p.Server // Currently running P2P networking layer
 
-	serviceFuncs []ServiceConstructor     // Service constructors (in dependency order)
+	serviceFuncs []serviceFunc            // Service constructors (in dependency order)
 	services     map[reflect.Type]Service // Currently running services
 
-	rpcAPIs       []rpc.API   // List of APIs currently provided by the node
-	inprocHandler *rpc.Server // In-process RPC request handler to process the API requests
+	rpcEndpoints // RPC endpoints serving the APIs of the node services
 
-	ipcEndpoint string       // IPC endpoint to listen at (empty = IPC disabled)
-	ipcListener net.Listener // IPC RPC listener socket to serve API requests
-	ipcHandler  *rpc.Server  // IPC RPC request handler to process the API requests
-
-	httpEndpoint  string       // HTTP endpoint (interface + port) to listen at (empty = HTTP disabled)
-	httpWhitelist []string     // HTTP RPC modules to allow through this endpoint
-	httpListener  net.Listener // HTTP RPC listener socket to server API requests
-	httpHandler   *rpc.Server  // HTTP RPC request handler to process the API requests
-
-	wsEndpoint string       // Websocket endpoint (interface + port) to listen at (empty = websocket disabled)
-	wsListener net.Listener // Websocket RPC listener socket to server API requests
-	wsHandler  *rpc.Server  // Websocket RPC request handler to process the API requests
+	chains []*chain // Chains hosted by the node
 
 	stop chan struct{} // Channel to wait for termination notifications
 	lock sync.RWMutex
 
 	log log.Logger
+}
 
-	//for subchain
-	subRpcAPIs       []rpc.API   // List of APIs currently provided by the node
-	subInprocHandler *rpc.Server // In-process RPC request handler to process the API requests
-
-	subIpcEndpoint string       // IPC endpoint to listen at (empty = IPC disabled)
-	subIpcListener net.Listener // IPC RPC listener socket to serve API requests
-	subIpcHandler  *rpc.Server  // IPC RPC request handler to process the API requests
-
-	subHttpEndpoint  string       // HTTP endpoint (interface + port) to listen at (empty = HTTP disabled)
-	subHttpWhitelist []string     // HTTP RPC modules to allow through this endpoint
-	subHttpListener  net.Listener // HTTP RPC listener socket to server API requests
-	subHttpHandler   *rpc.Server  // HTTP RPC request handler to process the API requests
-
-	subWsEndpoint string       // Websocket endpoint (interface + port) to listen at (empty = websocket disabled)
-	subWsListener net.Listener // Websocket RPC listener socket to server API requests
-	subWsHandler  *rpc.Server  // Websocket RPC request handler to process the API requests
+// serviceFunc is a registered service constructor, along with the chain the
+// service belongs to (empty = the node itself).
+type serviceFunc struct {
+	chain       string
+	constructor ServiceConstructor
 }
 
 // New creates a new P2P node, ready for protocol registration.
@@ -97,6 +74,7 @@ func New(conf *Config) (*Node, error) {
 	// working directory don't affect the node.
 	confCopy := *conf
 	conf = &confCopy
+	conf.Chains = append([]ChainConfig(nil), conf.Chains...)
 	if conf.DataDir != "" {
 		absdatadir, err := filepath.Abs(conf.DataDir)
 		if err != nil {
@@ -124,21 +102,44 @@ func New(conf *Config) (*Node, error) {
 	if conf.Logger == nil {
 		conf.Logger = log.New()
 	}
+	// Assemble the hosted chains, each with its own namespace
+	chains := make([]*chain, 0, len(conf.Chains))
+	for i := range conf.Chains {
+		config := &conf.Chains[i]
+		if config.Name == "" || strings.ContainsAny(config.Name, `/\`) {
+			return nil, fmt.Errorf("invalid chain name %q", config.Name)
+		}
+		for _, c := range chains {
+			if c.config.Name == config.Name {
+				return nil, fmt.Errorf("duplicate chain %q", config.Name)
+			}
+		}
+		chains = append(chains, &chain{
+			config: config,
+			rpc: rpcEndpoints{
+				ipcEndpoint:  conf.ipcEndpoint(config.IPCPath),
+				httpEndpoint: config.HTTPEndpoint(),
+				wsEndpoint:   config.WSEndpoint(),
+				logger:       conf.Logger.New("chain", config.Name),
+			},
+		})
+	}
 	// Note: any interaction with Config that would create/touch files
 	// in the data directory or instance directory is delayed until Start.
 	return &Node{
 		accman:            am,
 		ephemeralKeystore: ephemeralKeystore,
 		config:            conf,
-		serviceFuncs:      []ServiceConstructor{},
-		ipcEndpoint:       conf.IPCEndpoint(),
-		httpEndpoint:      conf.HTTPEndpoint(),
-		wsEndpoint:        conf.WSEndpoint(),
-		eventmux:          new(event.TypeMux),
-		log:               conf.Logger,
-		subIpcEndpoint:    conf.SubIPCEndpoint(),
-		subHttpEndpoint:   conf.SubHTTPEndpoint(),
-		subWsEndpoint:     conf.SubWSEndpoint(),
+		serviceFuncs:      []serviceFunc{},
+		rpcEndpoints: rpcEndpoints{
+			ipcEndpoint:  conf.IPCEndpoint(),
+			httpEndpoint: conf.HTTPEndpoint(),
+			wsEndpoint:   conf.WSEndpoint(),
+			logger:       conf.Logger,
+		},
+		chains:   chains,
+		eventmux: new(event.TypeMux),
+		log:      conf.Logger,
 	}, nil
 }
 
@@ -174,7 +175,23 @@ func (n *Node) Register(constructor ServiceConstructor) error {
 	if n.server != nil {
 		return ErrNodeRunning
 	}
-	n.serviceFuncs = append(n.serviceFuncs, constructor)
+	n.serviceFuncs = append(n.serviceFuncs, serviceFunc{constructor: constructor})
+	return nil
+}
+
+// RegisterChain injects a new service into the stack of a hosted chain. The service
+// created by the passed constructor must be unique in its type within the chain.
+func (n *Node) RegisterChain(name string, constructor ServiceConstructor) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.server != nil {
+		return ErrNodeRunning
+	}
+	if n.chain(name) == nil {
+		return ErrChainUnknown
+	}
+	n.serviceFuncs = append(n.serviceFuncs, serviceFunc{chain: name, constructor: constructor})
 	return nil
 }
 
@@ -209,9 +226,17 @@ func (n *Node) Start() error {
 	running := &p2p.Server{Config: n.serverConfig}
 	n.log.Info("Starting peer-to-peer node", "instance", n.serverConfig.Name)
 
+	// Hosted chains with their own networking get a dedicated p2p server
+	for _, c := range n.chains {
+		c.services = make(map[reflect.Type]Service)
+		c.server = nil
+		if c.config.P2P != nil {
+			c.server = &p2p.Server{Config: n.chainServerConfig(c.config)}
+		}
+	}
 	// Otherwise copy and specialize the P2P configuration
 	services := make(map[reflect.Type]Service)
-	for _, constructor := range n.serviceFuncs {
+	for _, sf := range n.serviceFuncs {
 		// Create a new context for the particular service
 		ctx := &ServiceContext{
 			config:         n.config,
@@ -222,69 +247,94 @@ func (n *Node) Start() error {
 		for kind, s := range services { // copy needed for threaded access
 			ctx.services[kind] = s
 		}
+		owner := services
+		if sf.chain != "" {
+			c := n.chain(sf.chain)
+			for kind, s := range c.services { // services of the chain shadow the node ones
+				ctx.services[kind] = s
+			}
+			ctx.chain, owner = c.config, c.services
+		}
 		// Construct and save the service
-		service, err := constructor(ctx)
+		service, err := sf.constructor(ctx)
 		if err != nil {
 			return err
 		}
 		kind := reflect.TypeOf(service)
-		if _, exists := services[kind]; exists {
+		if _, exists := owner[kind]; exists {
 			return &DuplicateServiceError{Kind: kind}
 		}
-		services[kind] = service
+		owner[kind] = service
 	}
-	// Gather the protocols and start the freshly assembled P2P server
+	// Gather the protocols and start the freshly assembled P2P servers
 	for _, service := range services {
 		running.Protocols = append(running.Protocols, service.Protocols()...)
 	}
+	for _, c := range n.chains {
+		server := c.p2pServer(running)
+		for _, service := range c.services {
+			server.Protocols = append(server.Protocols, service.Protocols()...)
+		}
+	}
+	if err := checkProtocols(running); err != nil {
+		return err
+	}
+	for _, c := range n.chains {
+		if c.server != nil {
+			if err := checkProtocols(c.server); err != nil {
+				return err
+			}
+		}
+	}
 	if err := running.Start(); err != nil {
 		return convertFileLockError(err)
 	}
+	for _, c := range n.chains {
+		if c.server == nil {
+			continue
+		}
+		if err := c.server.Start(); err != nil {
+			n.stopServers(running)
+			return convertFileLockError(err)
+		}
+		n.log.Info("Started chain peer-to-peer server", "chain", c.config.Name)
+	}
 	// Start each of the services
-	var started []reflect.Type
-	for kind, service := range services {
+	var started []Service
+	for _, service := range services {
 		// Start the next service, stopping all previous upon failure
 		if err := service.Start(running); err != nil {
-			for _, kind := range started {
-				services[kind].Stop()
-			}
-			running.Stop()
-
+			stopServices(started)
+			n.stopServers(running)
 			return err
 		}
 		// Mark the service started for potential cleanup
-		started = append(started, kind)
-	}
-	// Lastly start the configured RPC interfaces
-	if n.config.Role.IsMainChain() {
-		if err := n.startRPC(services); err != nil {
-			for _, service := range services {
-				service.Stop()
-			}
-			running.Stop()
-			return err
-		}
-	} else if n.config.Role.IsSubChain() {
-		if err := n.startSubRPC(services); err != nil {
-			for _, service := range services {
-				service.Stop()
-			}
-			running.Stop()
-			return err
-		}
-	} else if n.config.Role.IsAnchor() {
-		if err := n.startRPC(services); err != nil {
-			for _, service := range services {
-				service.Stop()
+		started = append(started, service)
+	}
+	for _, c := range n.chains {
+		for _, service := range c.services {
+			if err := service.Start(c.p2pServer(running)); err != nil {
+				stopServices(started)
+				n.stopServers(running)
+				return err
 			}
-			running.Stop()
-			return err
+			started = append(started, service)
 		}
-		if err := n.startSubRPC(services); err != nil {
-			for _, service := range services {
-				service.Stop()
+	}
+	// Lastly start the configured RPC interfaces
+	if err := n.startRPC(services); err != nil {
+		stopServices(started)
+		n.stopServers(running)
+		return err
+	}
+	for i, c := range n.chains {
+		if err := n.startChainRPC(c); err != nil {
+			for _, c := range n.chains[:i] {
+				c.rpc.stop()
 			}
-			running.Stop()
+			n.rpcEndpoints.stop()
+			stopServices(started)
+			n.stopServers(running)
 			return err
 		}
 	}
@@ -295,180 +345,113 @@ func (n *Node) Start() error {
 	return nil
 }
 
-// Config returns the configuration of node.
-func (n *Node) Config() *Config {
-	return n.config
+// chainServerConfig assembles the p2p config of a hosted chain, the chain shares
+// the node key and name with the node server.
+func (n *Node) chainServerConfig(config *ChainConfig) p2p.Config {
+	server := *config.P2P
+	server.PrivateKey = n.serverConfig.PrivateKey
+	server.Name = n.serverConfig.Name
+	server.Logger = n.log.New("chain", config.Name)
+	if server.NodeDatabase == "" && n.config.DataDir != "" {
+		server.NodeDatabase = n.config.resolveChainPath(config, datadirNodeDatabase)
+	}
+	return server
 }
 
-func (n *Node) openDataDir() error {
-	if n.config.DataDir == "" {
-		return nil // ephemeral
+// p2pServer returns the p2p server running the protocols of the chain.
+func (c *chain) p2pServer(node *p2p.Server) *p2p.Server {
+	if c.server != nil {
+		return c.server
 	}
-
-	instdir := filepath.Join(n.config.DataDir, n.config.name())
-	if err := os.MkdirAll(instdir, 0700); err != nil {
-		return err
-	}
-	// Lock the instance directory to prevent concurrent use by another instance as well as
-	// accidental use of the instance directory as a database.
-	release, _, err := fileutil.Flock(filepath.Join(instdir, "LOCK"))
-	if err != nil {
-		return convertFileLockError(err)
-	}
-	n.instanceDirLock = release
-	return nil
+	return node
 }
 
-// startRPC is a helper method to start all the various RPC endpoint during node
-// startup. It's not meant to be called at any time afterwards as it makes certain
-// assumptions about the state of the node.
-func (n *Node) startRPC(services map[reflect.Type]Service) error {
-	// Gather all the possible APIs to surface
-	apis := n.apis()
-	for key, service := range services {
-		if key.String() != "*sub.Ethereum" {
-			apis = append(apis, service.APIs()...)
+// checkProtocols ensures no two services of a p2p server run the same protocol
+// version, as peers could not tell them apart.
+func checkProtocols(server *p2p.Server) error {
+	seen := make(map[string]bool)
+	for _, proto := range server.Protocols {
+		id := fmt.Sprintf("%s/%d", proto.Name, proto.Version)
+		if seen[id] {
+			return &DuplicateProtocolError{Name: proto.Name, Version: proto.Version}
 		}
+		seen[id] = true
 	}
-	// Start the various API endpoints, terminating all in case of errors
-	if err := n.startInProc(apis); err != nil {
-		return err
-	}
-	if err := n.startIPC(apis); err != nil {
-		n.stopInProc()
-		return err
-	}
-	if err := n.startHTTP(n.httpEndpoint, apis, n.config.HTTPModules, n.config.HTTPCors, n.config.HTTPVirtualHosts, n.config.HTTPTimeouts); err != nil {
-		n.stopIPC()
-		n.stopInProc()
-		return err
-	}
-	if err := n.startWS(n.wsEndpoint, apis, n.config.WSModules, n.config.WSOrigins, n.config.WSExposeAll); err != nil {
-		n.stopHTTP()
-		n.stopIPC()
-		n.stopInProc()
-		return err
-	}
-	// All API endpoints started successfully
-	n.rpcAPIs = apis
 	return nil
 }
 
-// startInProc initializes an in-process RPC endpoint.
-func (n *Node) startInProc(apis []rpc.API) error {
-	// Register all the APIs exposed by the services
-	handler := rpc.NewServer()
-	for _, api := range apis {
-		if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
-			return err
+// stopServers terminates the node p2p server along with the ones of the chains.
+func (n *Node) stopServers(running *p2p.Server) {
+	for _, c := range n.chains {
+		if c.server != nil {
+			c.server.Stop()
 		}
-		n.log.Debug("InProc registered", "namespace", api.Namespace)
 	}
-	n.inprocHandler = handler
-	return nil
+	running.Stop()
 }
 
-// stopInProc terminates the in-process RPC endpoint.
-func (n *Node) stopInProc() {
-	if n.inprocHandler != nil {
-		n.inprocHandler.Stop()
-		n.inprocHandler = nil
+// stopServices terminates the given services, ignoring any errors.
+func stopServices(services []Service) {
+	for _, service := range services {
+		service.Stop()
 	}
 }
 
-// startIPC initializes and starts the IPC RPC endpoint.
-func (n *Node) startIPC(apis []rpc.API) error {
-	if n.ipcEndpoint == "" {
-		return nil // IPC disabled.
-	}
-	listener, handler, err := rpc.StartIPCEndpoint(n.ipcEndpoint, apis)
-	if err != nil {
-		return err
+// chain retrieves a hosted chain by name.
+func (n *Node) chain(name string) *chain {
+	for _, c := range n.chains {
+		if c.config.Name == name {
+			return c
+		}
 	}
-	n.ipcListener = listener
-	n.ipcHandler = handler
-	n.log.Info("IPC endpoint opened", "url", n.ipcEndpoint)
 	return nil
 }
 
-// stopIPC terminates the IPC RPC endpoint.
-func (n *Node) stopIPC() {
-	if n.ipcListener != nil {
-		n.ipcListener.Close()
-		n.ipcListener = nil
+// Config returns the configuration of node.
+func (n *Node) Config() *Config {
+	return n.config
+}
 
-		n.log.Info("IPC endpoint closed", "url", n.ipcEndpoint)
-	}
-	if n.ipcHandler != nil {
-		n.ipcHandler.Stop()
-		n.ipcHandler = nil
+func (n *Node) openDataDir() error {
+	if n.config.DataDir == "" {
+		return nil // ephemeral
 	}
-}
 
-// startHTTP initializes and starts the HTTP RPC endpoint.
-func (n *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors []string, vhosts []string, timeouts rpc.HTTPTimeouts) error {
-	// Short circuit if the HTTP endpoint isn't being exposed
-	if endpoint == "" {
-		return nil
+	instdir := filepath.Join(n.config.DataDir, n.config.name())
+	if err := os.MkdirAll(instdir, 0700); err != nil {
+		return err
 	}
-	listener, handler, err := rpc.StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts, timeouts)
+	// Lock the instance directory to prevent concurrent use by another instance as well as
+	// accidental use of the instance directory as a database.
+	release, _, err := fileutil.Flock(filepath.Join(instdir, "LOCK"))
 	if err != nil {
-		return err
+		return convertFileLockError(err)
 	}
-	n.log.Info("HTTP endpoint opened", "url", fmt.Sprintf("http://%s", endpoint), "cors", strings.Join(cors, ","), "vhosts", strings.Join(vhosts, ","))
-	// All listeners booted successfully
-	n.httpEndpoint = endpoint
-	n.httpListener = listener
-	n.httpHandler = handler
-
+	n.instanceDirLock = release
 	return nil
 }
 
-// stopHTTP terminates the HTTP RPC endpoint.
-func (n *Node) stopHTTP() {
-	if n.httpListener != nil {
-		n.httpListener.Close()
-		n.httpListener = nil
-
-		n.log.Info("HTTP endpoint closed", "url", fmt.Sprintf("http://%s", n.httpEndpoint))
-	}
-	if n.httpHandler != nil {
-		n.httpHandler.Stop()
-		n.httpHandler = nil
-	}
-}
-
-// startWS initializes and starts the websocket RPC endpoint.
-func (n *Node) startWS(endpoint string, apis []rpc.API, modules []string, wsOrigins []string, exposeAll bool) error {
-	// Short circuit if the WS endpoint isn't being exposed
-	if endpoint == "" {
-		return nil
-	}
-	listener, handler, err := rpc.StartWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll)
-	if err != nil {
-		return err
+// startRPC is a helper method to start all the various RPC endpoint during node
+// startup. It's not meant to be called at any time afterwards as it makes certain
+// assumptions about the state of the node.
+func (n *Node) startRPC(services map[reflect.Type]Service) error {
+	// Gather all the possible APIs to surface
+	apis := n.apis("")
+	for _, service := range services {
+		apis = append(apis, service.APIs()...)
 	}
-	n.log.Info("WebSocket endpoint opened", "url", fmt.Sprintf("ws://%s", listener.Addr()))
-	// All listeners booted successfully
-	n.wsEndpoint = endpoint
-	n.wsListener = listener
-	n.wsHandler = handler
-
-	return nil
+	// Start the various API endpoints, terminating all in case of errors
+	return n.rpcEndpoints.start(apis, n.config.rpcConfig(), n.config.HTTPTimeouts)
 }
 
-// stopWS terminates the websocket RPC endpoint.
-func (n *Node) stopWS() {
-	if n.wsListener != nil {
-		n.wsListener.Close()
-		n.wsListener = nil
-
-		n.log.Info("WebSocket endpoint closed", "url", fmt.Sprintf("ws://%s", n.wsEndpoint))
-	}
-	if n.wsHandler != nil {
-		n.wsHandler.Stop()
-		n.wsHandler = nil
+// startChainRPC starts the RPC endpoint group of a hosted chain, serving the APIs
+// of the chain services.
+func (n *Node) startChainRPC(c *chain) error {
+	apis := n.apis(c.config.Name)
+	for _, service := range c.services {
+		apis = append(apis, service.APIs()...)
 	}
+	return c.rpc.start(apis, c.config, n.config.HTTPTimeouts)
 }
 
 // Stop terminates a running node along with all it's services. In the node was
@@ -483,25 +466,31 @@ func (n *Node) Stop() error {
 	}
 
 	// Terminate the API, services and the p2p server.
-	n.stopWS()
-	n.stopHTTP()
-	n.stopIPC()
-	n.rpcAPIs = nil
-
-	n.stopSubWS()
-	n.stopSubHTTP()
-	n.stopSubIPC()
-	n.subRpcAPIs = nil
-
+	n.rpcEndpoints.stop()
+	for _, c := range n.chains {
+		c.rpc.stop()
+	}
 	failure := &StopError{
 		Services: make(map[reflect.Type]error),
+		Chains:   make(map[string]map[reflect.Type]error),
+	}
+	for _, c := range n.chains {
+		for kind, service := range c.services {
+			if err := service.Stop(); err != nil {
+				if failure.Chains[c.config.Name] == nil {
+					failure.Chains[c.config.Name] = make(map[reflect.Type]error)
+				}
+				failure.Chains[c.config.Name][kind] = err
+			}
+		}
+		c.services = nil
 	}
 	for kind, service := range n.services {
 		if err := service.Stop(); err != nil {
 			failure.Services[kind] = err
 		}
 	}
-	n.server.Stop()
+	n.stopServers(n.server)
 	n.services = nil
 	n.server = nil
 
@@ -522,7 +511,7 @@ func (n *Node) Stop() error {
 		keystoreErr = os.RemoveAll(n.ephemeralKeystore)
 	}
 
-	if len(failure.Services) > 0 {
+	if len(failure.Services) > 0 || len(failure.Chains) > 0 {
 		return failure
 	}
 	if keystoreErr != nil {
@@ -565,13 +554,23 @@ func (n *Node) Attach() (*rpc.Client, error) {
 	if n.server == nil {
 		return nil, ErrNodeStopped
 	}
-	if n.inprocHandler != nil {
-		return rpc.DialInProc(n.inprocHandler), nil
+	return rpc.DialInProc(n.inprocHandler), nil
+}
+
+// AttachChain creates an RPC client attached to the in-process API handler of
+// a hosted chain.
+func (n *Node) AttachChain(name string) (*rpc.Client, error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if n.server == nil {
+		return nil, ErrNodeStopped
 	}
-	if n.subInprocHandler != nil {
-		return rpc.DialInProc(n.subInprocHandler), nil
+	c := n.chain(name)
+	if c == nil {
+		return nil, ErrChainUnknown
 	}
-	return nil, errors.New("inprocHandler or subInprocHandler must be not nil")
+	return rpc.DialInProc(c.rpc.inprocHandler), nil
 }
 
 // RPCHandler returns the in-process RPC request handler.
@@ -613,6 +612,40 @@ func (n *Node) Service(service interface{}) error {
 	return ErrServiceUnknown
 }
 
+// ChainServer retrieves the P2P network layer running the protocols of a hosted
+// chain, which is the node server unless the chain has its own networking.
+func (n *Node) ChainServer(name string) *p2p.Server {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if c := n.chain(name); c != nil && c.server != nil {
+		return c.server
+	}
+	return n.server
+}
+
+// ChainService retrieves a currently running service of a specific type registered
+// to a hosted chain.
+func (n *Node) ChainService(name string, service interface{}) error {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	// Short circuit if the node's not running
+	if n.server == nil {
+		return ErrNodeStopped
+	}
+	c := n.chain(name)
+	if c == nil {
+		return ErrChainUnknown
+	}
+	element := reflect.ValueOf(service).Elem()
+	if running, ok := c.services[element.Type()]; ok {
+		element.Set(reflect.ValueOf(running))
+		return nil
+	}
+	return ErrServiceUnknown
+}
+
 // DataDir retrieves the current datadir used by the protocol stack.
 // Deprecated: No files should be stored in this directory, use InstanceDir instead.
 func (n *Node) DataDir() string {
@@ -697,17 +730,29 @@ func (n *Node) ResolvePath(x string) string {
 	return n.config.ResolvePath(x)
 }
 
-// apis returns the collection of RPC descriptors this node offers.
-func (n *Node) apis() []rpc.API {
+// ResolveChainPath returns the absolute path of a resource in the namespace of
+// the named hosted chain.
+func (n *Node) ResolveChainPath(chain string, x string) (string, error) {
+	for i := range n.config.Chains {
+		if n.config.Chains[i].Name == chain {
+			return n.config.resolveChainPath(&n.config.Chains[i], x), nil
+		}
+	}
+	return "", ErrChainUnknown
+}
+
+// apis returns the collection of RPC descriptors this node offers to the given
+// chain (empty = the node itself).
+func (n *Node) apis(chain string) []rpc.API {
 	return []rpc.API{
 		{
 			Namespace: "admin",
 			Version:   "1.0",
-			Service:   NewPrivateAdminAPI(n),
+			Service:   &PrivateAdminAPI{node: n, chain: chain},
 		}, {
 			Namespace: "admin",
 			Version:   "1.0",
-			Service:   NewPublicAdminAPI(n),
+			Service:   &PublicAdminAPI{node: n, chain: chain},
 			Public:    true,
 		}, {
 			Namespace: "debug",
@@ -721,159 +766,3 @@ func (n *Node) apis() []rpc.API {
 		},
 	}
 }
-func (n *Node) startSubRPC(services map[reflect.Type]Service) error {
-	// Gather all the possible APIs to surface
-	apis := n.apis()
-	for key, service := range services {
-		if key.String() != "*eth.Ethereum" {
-			apis = append(apis, service.APIs()...)
-		}
-	}
-	// Start the various API endpoints, terminating all in case of errors
-	if err := n.startSubInProc(apis); err != nil {
-		return err
-	}
-	if err := n.startSubIPC(apis); err != nil {
-		n.stopInProc()
-		return err
-	}
-	if err := n.startSubHTTP(n.subHttpEndpoint, apis, n.config.SubHTTPModules, n.config.SubHTTPCors, n.config.SubHTTPVirtualHosts, n.config.HTTPTimeouts); err != nil {
-		n.stopSubIPC()
-		n.stopSubInProc()
-		return err
-	}
-	if err := n.startSubWS(n.subWsEndpoint, apis, n.config.SubWSModules, n.config.SubWSOrigins, n.config.SubWSExposeAll); err != nil {
-		n.stopSubHTTP()
-		n.stopSubIPC()
-		n.stopSubInProc()
-		return err
-	}
-	// All API endpoints started successfully
-	n.subRpcAPIs = apis
-	return nil
-}
-
-// startInProc initializes an in-process RPC endpoint.
-func (n *Node) startSubInProc(apis []rpc.API) error {
-	// Register all the APIs exposed by the services
-	handler := rpc.NewServer()
-	for _, api := range apis {
-		if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
-			return err
-		}
-		n.log.Debug("subchain InProc registered", "service", api.Service, "namespace", api.Namespace)
-	}
-	n.subInprocHandler = handler
-	return nil
-}
-
-// stopInProc terminates the in-process RPC endpoint.
-func (n *Node) stopSubInProc() {
-	if n.subInprocHandler != nil {
-		n.subInprocHandler.Stop()
-		n.subInprocHandler = nil
-	}
-}
-
-// startIPC initializes and starts the IPC RPC endpoint.
-func (n *Node) startSubIPC(apis []rpc.API) error {
-	if n.subIpcEndpoint == "" {
-		return nil // IPC disabled.
-	}
-	listener, handler, err := rpc.StartIPCEndpoint(n.subIpcEndpoint, apis)
-	if err != nil {
-		return err
-	}
-	n.subIpcListener = listener
-	n.subIpcHandler = handler
-	n.log.Info("subchain IPC endpoint opened", "url", n.subIpcEndpoint)
-	return nil
-}
-
-// stopIPC terminates the IPC RPC endpoint.
-func (n *Node) stopSubIPC() {
-	if n.subIpcListener != nil {
-		n.subIpcListener.Close()
-		n.subIpcListener = nil
-
-		n.log.Info("subchain IPC endpoint closed", "endpoint", n.subIpcEndpoint)
-	}
-	if n.subIpcHandler != nil {
-		n.subIpcHandler.Stop()
-		n.subIpcHandler = nil
-	}
-}
-
-// startHTTP initializes and starts the HTTP RPC endpoint.
-func (n *Node) startSubHTTP(endpoint string, apis []rpc.API, modules []string, cors []string, vhosts []string, timeouts rpc.HTTPTimeouts) error {
-	// Short circuit if the HTTP endpoint isn't being exposed
-	if endpoint == "" {
-		return nil
-	}
-	listener, handler, err := rpc.StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts, timeouts)
-	if err != nil {
-		return err
-	}
-	n.log.Info("subchain HTTP endpoint opened", "url", fmt.Sprintf("http://%s", endpoint), "cors", strings.Join(cors, ","), "vhosts", strings.Join(vhosts, ","))
-	// All listeners booted successfully
-	n.subHttpEndpoint = endpoint
-	n.subHttpListener = listener
-	n.subHttpHandler = handler
-
-	return nil
-}
-
-// stopHTTP terminates the HTTP RPC endpoint.
-func (n *Node) stopSubHTTP() {
-	if n.subHttpListener != nil {
-		n.subHttpListener.Close()
-		n.subHttpListener = nil
-		n.log.Info("subchain HTTP endpoint closed", "url", fmt.Sprintf("http://%s", n.subHttpEndpoint))
-	}
-	if n.subHttpHandler != nil {
-		n.subHttpHandler.Stop()
-		n.subHttpHandler = nil
-	}
-}
-
-// startWS initializes and starts the websocket RPC endpoint.
-func (n *Node) startSubWS(endpoint string, apis []rpc.API, modules []string, wsOrigins []string, exposeAll bool) error {
-	// Short circuit if the WS endpoint isn't being exposed
-	if endpoint == "" {
-		return nil
-	}
-	listener, handler, err := rpc.StartWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll)
-	if err != nil {
-		return err
-	}
-	n.log.Info("subchain WebSocket endpoint opened", "url", fmt.Sprintf("ws://%s", listener.Addr()))
-	// All listeners booted successfully
-	n.subWsEndpoint = endpoint
-	n.subWsListener = listener
-	n.subWsHandler = handler
-
-	return nil
-}
-
-// stopWS terminates the websocket RPC endpoint.
-func (n *Node) stopSubWS() {
-	if n.subWsListener != nil {
-		n.subWsListener.Close()
-		n.subWsListener = nil
-
-		n.log.Info("WebSocket endpoint closed", "url", fmt.Sprintf("ws://%s", n.subWsEndpoint))
-	}
-	if n.subWsHandler != nil {
-		n.subWsHandler.Stop()
-		n.subWsHandler = nil
-	}
-}
-func (n *Node) IsMainChain() bool {
-	return n.config.Role.IsMainChain()
-}
-func (n *Node) IsSubChain() bool {
-	return n.config.Role.IsSubChain()
-}
-func (n *Node) IsAnchor() bool {
-	return n.config.Role.IsAnchor()
-}
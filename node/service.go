@@ -34,6 +34,7 @@ import (
 // as well as utility methods to operate on the service environment.
 type ServiceContext struct {
 	config         *Config
+	chain          *ChainConfig             // Hosted chain of the service, nil for node services
 	services       map[reflect.Type]Service // Index of the already constructed services
 	EventMux       *event.TypeMux           // Event multiplexer used for decoupled notifications
 	AccountManager *accounts.Manager        // Account manager created by the node.
@@ -46,7 +47,7 @@ func (ctx *ServiceContext) OpenDatabase(name string, cache int, handles int, nam
 	if ctx.config.DataDir == "" {
 		return rawdb.NewMemoryDatabase(), nil
 	}
-	return rawdb.NewLevelDBDatabase(ctx.config.ResolvePath(name), cache, handles, namespace)
+	return rawdb.NewLevelDBDatabase(ctx.ResolvePath(name), cache, handles, namespace)
 }
 
 // OpenDatabaseWithFreezer opens an existing database with the given name (or
@@ -58,22 +59,32 @@ func (ctx *ServiceContext) OpenDatabaseWithFreezer(name string, cache int, handl
 	if ctx.config.DataDir == "" {
 		return rawdb.NewMemoryDatabase(), nil
 	}
-	root := ctx.config.ResolvePath(name)
+	root := ctx.ResolvePath(name)
 
 	switch {
 	case freezer == "":
 		freezer = filepath.Join(root, "ancient")
 	case !filepath.IsAbs(freezer):
-		freezer = ctx.config.ResolvePath(freezer)
+		freezer = ctx.ResolvePath(freezer)
 	}
 	return rawdb.NewLevelDBDatabaseWithFreezer(root, cache, handles, freezer, namespace)
 }
 
 // ResolvePath resolves a user path into the data directory if that was relative
 // and if the user actually uses persistent storage. It will return an empty string
-// for emphemeral storage and the user's own input for absolute paths.
+// for emphemeral storage and the user's own input for absolute paths. Paths of
+// the services of a hosted chain are resolved into the chain namespace.
 func (ctx *ServiceContext) ResolvePath(path string) string {
-	return ctx.config.ResolvePath(path)
+	return ctx.config.resolveChainPath(ctx.chain, path)
+}
+
+// Chain returns the name of the hosted chain the service is registered to, or
+// an empty string for the services of the node itself.
+func (ctx *ServiceContext) Chain() string {
+	if ctx.chain == nil {
+		return ""
+	}
+	return ctx.chain.Name
 }
 
 // Service retrieves a currently running service registered of a specific type.
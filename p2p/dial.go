@@ -69,6 +69,7 @@ func (t TCPDialer) Dial(dest *enode.Node) (net.Conn, error) {
 type dialstate struct {
 	maxDynDials int
 	netrestrict *netutil.Netlist
+	permitted   func(*enode.Node) bool // node permissioning, nil if not permissioned
 	self        enode.ID
 	bootnodes   []*enode.Node // default dials when there are no peers
 	log         log.Logger
@@ -203,6 +204,7 @@ var (
 	errAlreadyConnected = errors.New("already connected")
 	errRecentlyDialed   = errors.New("recently dialed")
 	errNotWhitelisted   = errors.New("not contained in netrestrict whitelist")
+	errNotPermitted     = errors.New("not permitted by node permissioning")
 )
 
 func (s *dialstate) checkDial(n *enode.Node, peers map[enode.ID]*Peer) error {
@@ -216,6 +218,8 @@ func (s *dialstate) checkDial(n *enode.Node, peers map[enode.ID]*Peer) error {
 		return errSelf
 	case s.netrestrict != nil && !s.netrestrict.Contains(n.IP()):
 		return errNotWhitelisted
+	case s.permitted != nil && !s.permitted(n):
+		return errNotPermitted
 	case s.hist.contains(string(n.ID().Bytes())):
 		return errRecentlyDialed
 	}
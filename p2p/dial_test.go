@@ -588,3 +588,16 @@ func (t *resolveMock) Resolve(n *enode.Node) *enode.Node {
 	t.calls = append(t.calls, n)
 	return t.answer
 }
+
+// This test checks that nodes not permitted are not dialed.
+func TestDialStatePermission(t *testing.T) {
+	state := newDialState(enode.ID{}, 10, &Config{})
+	state.permitted = func(n *enode.Node) bool { return n.ID() == uintID(1) }
+
+	if err := state.checkDial(newNode(uintID(1), nil), nil); err != nil {
+		t.Errorf("permitted node check failed: %v", err)
+	}
+	if err := state.checkDial(newNode(uintID(2), nil), nil); err != errNotPermitted {
+		t.Errorf("not permitted node check mismatch: have %v, want %v", err, errNotPermitted)
+	}
+}
@@ -203,6 +203,31 @@ type Server struct {
 
 	// raft peers info
 	checkPeerInRaft func(*enode.Node) bool
+
+	// node permissioning of the services sharing the server, a node must be
+	// permitted by all of the ones covering a protocol to run it
+	permissions   map[uint64]scopedPermission
+	permissionSeq uint64
+	permissionMu  sync.RWMutex
+}
+
+// NodePermission decides whether a remote node is allowed to join the p2p network.
+// It is checked for every consensus engine at dial time and after the encryption handshake.
+type NodePermission interface {
+	IsNodePermitted(node *enode.Node) bool
+}
+
+// scopedPermission is a node permissioning restricted to the protocols of the
+// service which added it.
+type scopedPermission struct {
+	NodePermission
+	protocols map[string]bool // Protocols the permission applies to, all if empty
+}
+
+// covers reports whether the permission applies to the named protocol, the
+// empty name stands for the connection itself.
+func (p scopedPermission) covers(name string) bool {
+	return len(p.protocols) == 0 || p.protocols[name]
 }
 
 type peerOpFunc func(map[enode.ID]*Peer)
@@ -482,6 +507,7 @@ func (srv *Server) Start() (err error) {
 
 	dynPeers := srv.maxDialedConns()
 	dialer := newDialState(srv.localnode.ID(), dynPeers, &srv.Config)
+	dialer.permitted = srv.isNodePermitted
 	srv.loopWG.Add(1)
 	go srv.run(dialer)
 	return nil
@@ -768,7 +794,7 @@ running:
 			err := srv.addPeerChecks(peers, inboundCount, c)
 			if err == nil {
 				// The handshakes are done and it passed all checks.
-				p := newPeer(srv.log, c, srv.Protocols)
+				p := newPeer(srv.log, c, srv.permittedProtocols(c.node))
 				// If message events are enabled, pass the peerFeed
 				// to the peer
 				if srv.EnableMsgEvents {
@@ -840,8 +866,8 @@ func (srv *Server) postHandshakeChecks(peers map[enode.ID]*Peer, inboundCount in
 }
 
 func (srv *Server) addPeerChecks(peers map[enode.ID]*Peer, inboundCount int, c *conn) error {
-	// Drop connections with no matching protocols.
-	if len(srv.Protocols) > 0 && countMatchingProtocols(srv.Protocols, c.caps) == 0 {
+	// Drop connections with no matching protocols the node is permitted to run.
+	if len(srv.Protocols) > 0 && countMatchingProtocols(srv.permittedProtocols(c.node), c.caps) == 0 {
 		return DiscUselessPeer
 	}
 	// Repeat the post-handshake checks because the
@@ -993,7 +1019,12 @@ func (srv *Server) setupConn(c *conn, flags connFlag, dialDest *enode.Node) erro
 		return newPeerError(errNotInRaftCluster, "id=%s…%s", node[:4], node[len(node)-4:])
 	}
 
-	//TODO: support QUORUM Permissioning
+	// Node not permitted by the node permissioning is not allowed to join the p2p network
+	if !srv.isNodePermitted(c.node) {
+		node := c.node.ID().String()
+		clog.Trace("Connection peer is not permitted")
+		return newPeerError(errNodeNotPermitted, "id=%s…%s", node[:4], node[len(node)-4:])
+	}
 
 	err = srv.checkpoint(c, srv.checkpointPostHandshake)
 	if err != nil {
@@ -1152,3 +1183,106 @@ func (srv *Server) PeersInfo() []*PeerInfo {
 func (srv *Server) SetCheckPeerInRaft(f func(*enode.Node) bool) {
 	srv.checkPeerInRaft = f
 }
+
+// AddNodePermission adds a node permissioning to the server, and drops the
+// connected peers which are not permitted. The permission applies to the given
+// protocols, or to every connection if none is given, so that the services
+// sharing the server only restrict the peers of their own protocols. A peer only
+// runs the protocols it is permitted for by all the covering permissions. The
+// returned function removes the permissioning.
+func (srv *Server) AddNodePermission(permission NodePermission, protocols ...string) func() {
+	scoped := scopedPermission{NodePermission: permission, protocols: make(map[string]bool)}
+	for _, name := range protocols {
+		scoped.protocols[name] = true
+	}
+	srv.permissionMu.Lock()
+	if srv.permissions == nil {
+		srv.permissions = make(map[uint64]scopedPermission)
+	}
+	id := srv.permissionSeq
+	srv.permissions[id] = scoped
+	srv.permissionSeq++
+	srv.permissionMu.Unlock()
+
+	srv.CheckPermissions()
+	return func() {
+		srv.permissionMu.Lock()
+		delete(srv.permissions, id)
+		srv.permissionMu.Unlock()
+	}
+}
+
+// isNodePermitted reports whether the node is permitted to run any of the
+// server protocols.
+func (srv *Server) isNodePermitted(node *enode.Node) bool {
+	srv.permissionMu.RLock()
+	defer srv.permissionMu.RUnlock()
+
+	if len(srv.Protocols) == 0 {
+		return srv.protocolPermitted(node, "")
+	}
+	for _, proto := range srv.Protocols {
+		if srv.protocolPermitted(node, proto.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// permittedProtocols returns the server protocols the node is permitted to run.
+func (srv *Server) permittedProtocols(node *enode.Node) []Protocol {
+	srv.permissionMu.RLock()
+	defer srv.permissionMu.RUnlock()
+
+	if len(srv.permissions) == 0 {
+		return srv.Protocols
+	}
+	protocols := make([]Protocol, 0, len(srv.Protocols))
+	for _, proto := range srv.Protocols {
+		if srv.protocolPermitted(node, proto.Name) {
+			protocols = append(protocols, proto)
+		}
+	}
+	return protocols
+}
+
+// protocolPermitted reports whether the node is permitted by all the permissions
+// covering the named protocol.
+//
+// The caller must hold permissionMu.
+func (srv *Server) protocolPermitted(node *enode.Node, name string) bool {
+	for _, permission := range srv.permissions {
+		if permission.covers(name) && !permission.IsNodePermitted(node) {
+			return false
+		}
+	}
+	return true
+}
+
+// runningPermitted reports whether the peer is still permitted to run all its
+// protocols.
+func (srv *Server) runningPermitted(p *Peer) bool {
+	srv.permissionMu.RLock()
+	defer srv.permissionMu.RUnlock()
+
+	for name := range p.running {
+		if !srv.protocolPermitted(p.Node(), name) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckPermissions drops the connected peers which are not permitted any more,
+// it returns the number of dropped peers.
+func (srv *Server) CheckPermissions() int {
+	var dropped int
+	for _, p := range srv.Peers() {
+		if !srv.isNodePermitted(p.Node()) || !srv.runningPermitted(p) {
+			srv.log.Info("Dropping revoked peer", "id", p.ID(), "addr", p.RemoteAddr())
+			p.Disconnect(DiscUselessPeer)
+			dropped++
+		}
+	}
+	return dropped
+}
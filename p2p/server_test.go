@@ -757,3 +757,96 @@ func (l *fakeAddrListener) Accept() (net.Conn, error) {
 func (c *fakeAddrConn) RemoteAddr() net.Addr {
 	return c.remoteAddr
 }
+
+type testPermission map[enode.ID]bool
+
+func (p testPermission) IsNodePermitted(node *enode.Node) bool { return p[node.ID()] }
+
+func TestServerSetupConn_whenNotPermitted(t *testing.T) {
+	var (
+		clientkey, srvkey = newkey(), newkey()
+		clientpub         = &clientkey.PublicKey
+	)
+
+	clientNode := enode.NewV4(clientpub, nil, 0, 0)
+	srv := &Server{
+		Config: Config{
+			PrivateKey:  srvkey,
+			NoDiscovery: true,
+		},
+		newTransport: func(fd net.Conn) transport { return newTestTransport(clientpub, fd) },
+		log:          log.New(),
+		permissions:  map[uint64]scopedPermission{0: {NodePermission: testPermission{}}},
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatalf("couldn't start server: %v", err)
+	}
+	defer srv.Stop()
+	p1, _ := net.Pipe()
+	err := srv.SetupConn(p1, inboundConn, clientNode)
+
+	assert.IsType(t, &peerError{}, err)
+	assert.Equal(t, errNodeNotPermitted, err.(*peerError).code)
+}
+
+// Tests that permissions added for the protocols of a service don't restrict
+// the other protocols sharing the server.
+func TestServerScopedPermissions(t *testing.T) {
+	node := enode.NewV4(&newkey().PublicKey, nil, 0, 0)
+	srv := &Server{
+		Config: Config{Protocols: []Protocol{{Name: "eth"}, {Name: "sub"}}},
+		permissions: map[uint64]scopedPermission{
+			0: {NodePermission: testPermission{}, protocols: map[string]bool{"sub": true}},
+		},
+	}
+	assert.True(t, srv.isNodePermitted(node))
+	protocols := srv.permittedProtocols(node)
+	if len(protocols) != 1 || protocols[0].Name != "eth" {
+		t.Fatalf("permitted protocols mismatch: have %v, want [eth]", protocols)
+	}
+
+	// the node is rejected once none of the protocols is permitted
+	srv.permissions[1] = scopedPermission{NodePermission: testPermission{}, protocols: map[string]bool{"eth": true}}
+	assert.False(t, srv.isNodePermitted(node))
+	assert.Empty(t, srv.permittedProtocols(node))
+
+	// permissions without protocols apply to every connection
+	srv.permissions = map[uint64]scopedPermission{0: {NodePermission: testPermission{node.ID(): true}}}
+	assert.True(t, srv.isNodePermitted(node))
+	srv.permissions[1] = scopedPermission{NodePermission: testPermission{}}
+	assert.False(t, srv.isNodePermitted(node))
+}
+
+func TestServerCheckPermissions(t *testing.T) {
+	connected := make(chan *Peer)
+	remid := &newkey().PublicKey
+	srv := startTestServer(t, remid, func(p *Peer) { connected <- p })
+	defer close(connected)
+	defer srv.Stop()
+
+	conn, err := net.DialTimeout("tcp", srv.ListenAddr, 5*time.Second)
+	if err != nil {
+		t.Fatalf("could not dial: %v", err)
+	}
+	defer conn.Close()
+
+	select {
+	case <-connected:
+	case <-time.After(1 * time.Second):
+		t.Fatal("server did not accept within one second")
+	}
+
+	// permitted peers are kept
+	remove := srv.AddNodePermission(testPermission{enode.PubkeyToIDV4(remid): true})
+	assert.Equal(t, 1, srv.PeerCount())
+
+	// permissions of the services sharing the server are combined
+	remove()
+	srv.AddNodePermission(testPermission{enode.PubkeyToIDV4(remid): true})
+	srv.AddNodePermission(testPermission{})
+	for start := time.Now(); srv.PeerCount() > 0; time.Sleep(10 * time.Millisecond) {
+		if time.Since(start) > time.Second {
+			t.Fatal("revoked peer is not dropped within one second")
+		}
+	}
+}
@@ -109,9 +109,9 @@ var (
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
 
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil, nil, false, nil}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, big.NewInt(0), big.NewInt(0), nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil, nil, false, nil, nil, nil, false}
 
-	AllDPoSProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, nil, nil, nil, &DPoSConfig{Period: 3, Epoch: 30000, MaxSignerCount: 21, MinVoterBalance: new(big.Int).Mul(big.NewInt(10000), big.NewInt(1000000000000000000))}, false, nil}
+	AllDPoSProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, big.NewInt(0), big.NewInt(0), nil, nil, nil, &DPoSConfig{Period: 3, Epoch: 30000, MaxSignerCount: 21, MinVoterBalance: new(big.Int).Mul(big.NewInt(10000), big.NewInt(1000000000000000000))}, false, nil, nil, nil, false}
 
 	// AllScryptProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Ethereum core developers into the Scrypt consensus.
@@ -119,13 +119,13 @@ var (
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
 
-	AllScryptProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, nil, nil, new(ScryptConfig), nil, false, nil}
+	AllScryptProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, big.NewInt(0), big.NewInt(0), nil, nil, new(ScryptConfig), nil, false, nil, nil, nil, false}
 
-	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, new(EthashConfig), nil, nil, nil, false, nil}
+	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, big.NewInt(0), big.NewInt(0), new(EthashConfig), nil, nil, nil, false, nil, nil, nil, false}
 
 	TestRules = TestChainConfig.Rules(new(big.Int))
 
-	RaftChainConfig = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, nil, nil, nil, nil, true, nil}
+	RaftChainConfig = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, nil, nil, nil, nil, nil, nil, true, nil, nil, nil, false}
 )
 
 // TrustedCheckpoint represents a set of post-processed trie roots (CHT and
@@ -180,6 +180,8 @@ type ChainConfig struct {
 
 	SingularityBlock *big.Int `json:"singularityBlock,omitempty"` // Singularity switch block (nil = no fork, 0 = already on singularity)
 	EWASMBlock       *big.Int `json:"ewasmBlock,omitempty"`       // EWASM switch block (nil = no fork, 0 = already activated)
+	AccessListBlock  *big.Int `json:"accessListBlock,omitempty"`  // EIP-2718 typed transactions and EIP-2930 access lists switch block (nil = no fork, 0 = already activated)
+	TxExpiryBlock    *big.Int `json:"txExpiryBlock,omitempty"`    // Expiring transactions switch block (nil = no fork, 0 = already activated)
 
 	// Various consensus engines
 	Ethash   *EthashConfig   `json:"ethash,omitempty"`
@@ -188,6 +190,38 @@ type ChainConfig struct {
 	DPoS     *DPoSConfig     `json:"dpos,omitempty"`
 	Raft     bool            `json:"raft,omitempty"`
 	Istanbul *IstanbulConfig `json:"istanbul,omitempty"`
+
+	Permission        *PermissionConfig        `json:"permission,omitempty"`        // Node permissioning, nil if the network is not permissioned
+	AccountPermission *AccountPermissionConfig `json:"accountPermission,omitempty"` // Account permissioning, nil if any account may transact
+
+	Privacy bool `json:"privacy,omitempty"` // Whether private transactions are executed against the private state of their participants
+}
+
+// PermissionConfig is the config of smart-contract based node permissioning.
+type PermissionConfig struct {
+	Contract common.Address `json:"contract"` // Node permission contract deployed in genesis
+}
+
+// AccountRole is a bit set of the permissions granted to an account.
+type AccountRole uint8
+
+const (
+	AccountCanTransact AccountRole = 1 << iota // Account may send transactions
+	AccountCanDeploy                           // Account may deploy contracts
+	AccountAdmin                               // Account may do anything, including managing roles
+)
+
+// Has returns whether the role set grants the given role, admins are granted every role.
+func (r AccountRole) Has(role AccountRole) bool {
+	return r&AccountAdmin != 0 || r&role == role
+}
+
+// AccountPermissionConfig is the config of account-level transaction permissioning.
+// Roles are read from the system contract if it is set, otherwise from the static
+// allowlist.
+type AccountPermissionConfig struct {
+	Accounts map[common.Address]AccountRole `json:"accounts,omitempty"` // Static allowlist of account roles
+	Contract *common.Address                `json:"contract,omitempty"` // Role contract deployed in genesis
 }
 
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
@@ -278,9 +312,11 @@ func (c *ChainConfig) String() string {
 	default:
 		engine = "unknown"
 	}
-	return fmt.Sprintf("{ChainID: %v Singularity: %v, Engine: %v}",
+	return fmt.Sprintf("{ChainID: %v Singularity: %v, AccessList: %v, TxExpiry: %v, Engine: %v}",
 		c.ChainID,
 		c.SingularityBlock,
+		c.AccessListBlock,
+		c.TxExpiryBlock,
 		engine,
 	)
 }
@@ -295,6 +331,18 @@ func (c *ChainConfig) IsEWASM(num *big.Int) bool {
 	return isForked(c.EWASMBlock, num)
 }
 
+// IsAccessList returns whether num is either equal to the access list fork block or
+// greater, enabling typed transactions and access lists.
+func (c *ChainConfig) IsAccessList(num *big.Int) bool {
+	return isForked(c.AccessListBlock, num)
+}
+
+// IsTxExpiry returns whether num is either equal to the transaction expiry fork
+// block or greater, enabling expiring transactions.
+func (c *ChainConfig) IsTxExpiry(num *big.Int) bool {
+	return isForked(c.TxExpiryBlock, num)
+}
+
 // CheckCompatible checks whether scheduled fork transitions have been imported
 // with a mismatching chain configuration.
 func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64) *ConfigCompatError {
@@ -323,6 +371,8 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 	var lastFork fork
 	for _, cur := range []fork{
 		{"singularityBlock", c.SingularityBlock},
+		{"accessListBlock", c.AccessListBlock},
+		{"txExpiryBlock", c.TxExpiryBlock},
 	} {
 		if lastFork.name != "" {
 			// Next one must be higher number
@@ -349,6 +399,12 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.EWASMBlock, newcfg.EWASMBlock, head) {
 		return newCompatError("ewasm fork block", c.EWASMBlock, newcfg.EWASMBlock)
 	}
+	if isForkIncompatible(c.AccessListBlock, newcfg.AccessListBlock, head) {
+		return newCompatError("access list fork block", c.AccessListBlock, newcfg.AccessListBlock)
+	}
+	if isForkIncompatible(c.TxExpiryBlock, newcfg.TxExpiryBlock, head) {
+		return newCompatError("transaction expiry fork block", c.TxExpiryBlock, newcfg.TxExpiryBlock)
+	}
 	return nil
 }
 
@@ -415,6 +471,8 @@ func (err *ConfigCompatError) Error() string {
 type Rules struct {
 	ChainID       *big.Int
 	IsSingularity bool
+	IsAccessList  bool
+	IsTxExpiry    bool
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -426,5 +484,7 @@ func (c *ChainConfig) Rules(num *big.Int) Rules {
 	return Rules{
 		ChainID:       new(big.Int).Set(chainID),
 		IsSingularity: c.IsSingularity(num),
+		IsAccessList:  c.IsAccessList(num),
+		IsTxExpiry:    c.IsTxExpiry(num),
 	}
 }
@@ -12,8 +12,12 @@ var (
 	AddAnchorsTopic    = common.HexToHash("0x775ea005805a6d88c3ac83f9e24f2c5d94e2ea99e7651bebeb9067e85691b3ab")
 	RemoveAnchorsTopic = common.HexToHash("0xf6b9271d4e28597a384466c107af5af249a32dc61f09d9a079e1367f39a75953")
 	UpdateAnchorTopic  = common.HexToHash("0x21c3c2e2611672924df81517929d90190258e543f08df36d2b06c88437f08cce")
-	CrossDemoAbi       = "0x5b0a097b0a090922696e70757473223a205b5d2c0a09092273746174654d75746162696c697479223a20226e6f6e70617961626c65222c0a09092274797065223a2022636f6e7374727563746f72220a097d2c0a097b0a090922616e6f6e796d6f7573223a2066616c73652c0a090922696e70757473223a205b0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e6465786564223a20747275652c0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a2022616e63686f72222c0a090909092274797065223a202261646472657373220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022726577617264222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a2022416363756d756c61746552657761726473222c0a09092274797065223a20226576656e74220a097d2c0a097b0a090922616e6f6e796d6f7573223a2066616c73652c0a090922696e70757473223a205b0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a2022416464416e63686f7273222c0a09092274797065223a20226576656e74220a097d2c0a097b0a090922616e6f6e796d6f7573223a2066616c73652c0a090922696e70757473223a205b0a0909097b0a0909090922696e6465786564223a20747275652c0a0909090922696e7465726e616c54797065223a202262797465733332222c0a09090909226e616d65223a202274784964222c0a090909092274797065223a202262797465733332220a0909097d2c0a0909097b0a0909090922696e6465786564223a20747275652c0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a2022746f222c0a090909092274797065223a202261646472657373220a0909097d0a09095d2c0a0909226e616d65223a20224d616b657246696e697368222c0a09092274797065223a20226576656e74220a097d2c0a097b0a090922616e6f6e796d6f7573223a2066616c73652c0a090922696e70757473223a205b0a0909097b0a0909090922696e6465786564223a20747275652c0a0909090922696e7465726e616c54797065223a202262797465733332222c0a09090909226e616d65223a202274784964222c0a090909092274797065223a202262797465733332220a0909097d2c0a0909097b0a0909090922696e6465786564223a20747275652c0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a202266726f6d222c0a090909092274797065223a202261646472657373220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a2022746f222c0a090909092274797065223a202261646472657373220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202276616c7565222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a20226465737456616c7565222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a20226279746573222c0a09090909226e616d65223a202264617461222c0a090909092274797065223a20226279746573220a0909097d0a09095d2c0a0909226e616d65223a20224d616b65725478222c0a09092274797065223a20226576656e74220a097d2c0a097b0a090922616e6f6e796d6f7573223a2066616c73652c0a090922696e70757473223a205b0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a202252656d6f7665416e63686f7273222c0a09092274797065223a20226576656e74220a097d2c0a097b0a090922616e6f6e796d6f7573223a2066616c73652c0a090922696e70757473223a205b0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a2022536574416e63686f72537461747573222c0a09092274797065223a20226576656e74220a097d2c0a097b0a090922616e6f6e796d6f7573223a2066616c73652c0a090922696e70757473223a205b0a0909097b0a0909090922696e6465786564223a20747275652c0a0909090922696e7465726e616c54797065223a202262797465733332222c0a09090909226e616d65223a202274784964222c0a090909092274797065223a202262797465733332220a0909097d2c0a0909097b0a0909090922696e6465786564223a20747275652c0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a2022746f222c0a090909092274797065223a202261646472657373220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a202266726f6d222c0a090909092274797065223a202261646472657373220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202276616c7565222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a20226465737456616c7565222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a202254616b65725478222c0a09092274797065223a20226576656e74220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a2022616464726573732070617961626c65222c0a09090909226e616d65223a2022616e63686f72222c0a090909092274797065223a202261646472657373220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022726577617264222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a2022616363756d756c61746552657761726473222c0a0909226f757470757473223a205b5d2c0a09092273746174654d75746162696c697479223a20226e6f6e70617961626c65222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a2022616464726573735b5d222c0a09090909226e616d65223a20225f616e63686f7273222c0a090909092274797065223a2022616464726573735b5d220a0909097d0a09095d2c0a0909226e616d65223a2022616464416e63686f7273222c0a0909226f757470757473223a205b5d2c0a09092273746174654d75746162696c697479223a20226e6f6e70617961626c65222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e743634222c0a09090909226e616d65223a20226e222c0a090909092274797065223a202275696e743634220a0909097d0a09095d2c0a0909226e616d65223a2022626974436f756e74222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e743634222c0a09090909226e616d65223a2022222c0a090909092274797065223a202275696e743634220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202270757265222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b5d2c0a0909226e616d65223a2022636861696e4964222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a20226964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202270757265222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a20226d617856616c7565222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e7438222c0a09090909226e616d65223a20227369676e436f6e6669726d436f756e74222c0a090909092274797065223a202275696e7438220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a2022616464726573735b5d222c0a09090909226e616d65223a20225f616e63686f7273222c0a090909092274797065223a2022616464726573735b5d220a0909097d0a09095d2c0a0909226e616d65223a2022636861696e5265676973746572222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a2022626f6f6c222c0a09090909226e616d65223a2022222c0a090909092274797065223a2022626f6f6c220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a20226e6f6e70617961626c65222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a202263726f7373436861696e73222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e7438222c0a09090909226e616d65223a20227369676e436f6e6669726d436f756e74222c0a090909092274797065223a202275696e7438220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a20226d617856616c7565222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e743634222c0a09090909226e616d65223a2022616e63686f7273506f736974696f6e426974222c0a090909092274797065223a202275696e743634220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e743634222c0a09090909226e616d65223a202264656c73506f736974696f6e426974222c0a090909092274797065223a202275696e743634220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e7438222c0a09090909226e616d65223a202264656c4964222c0a090909092274797065223a202275696e7438220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022726577617264222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022746f74616c526577617264222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202276696577222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a20225f616e63686f72222c0a090909092274797065223a202261646472657373220a0909097d0a09095d2c0a0909226e616d65223a2022676574416e63686f72576f726b436f756e74222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202276696577222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a2022676574416e63686f7273222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a2022616464726573735b5d222c0a09090909226e616d65223a20225f616e63686f7273222c0a090909092274797065223a2022616464726573735b5d220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e7438222c0a09090909226e616d65223a2022222c0a090909092274797065223a202275696e7438220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202276696577222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a2022676574436861696e526577617264222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202276696577222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a20225f616e63686f72222c0a090909092274797065223a202261646472657373220a0909097d0a09095d2c0a0909226e616d65223a202267657444656c416e63686f725369676e436f756e74222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202276696577222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202262797465733332222c0a09090909226e616d65223a202274784964222c0a090909092274797065223a202262797465733332220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a20226765744d616b65725478222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202276696577222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a20226765744d617856616c7565222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202276696577222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202262797465733332222c0a09090909226e616d65223a202274784964222c0a090909092274797065223a202262797465733332220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a20225f66726f6d222c0a090909092274797065223a202261646472657373220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a202267657454616b65725478222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202276696577222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a2022676574546f74616c526577617264222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202276696577222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b5d2c0a0909226e616d65223a20226c697374222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a20226c6c222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202270757265222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922636f6d706f6e656e7473223a205b0a09090909097b0a09090909090922696e7465726e616c54797065223a202262797465733332222c0a090909090909226e616d65223a202274784964222c0a0909090909092274797065223a202262797465733332220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a202262797465733332222c0a090909090909226e616d65223a2022747848617368222c0a0909090909092274797065223a202262797465733332220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a2022616464726573732070617961626c65222c0a090909090909226e616d65223a202266726f6d222c0a0909090909092274797065223a202261646472657373220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a2022616464726573732070617961626c65222c0a090909090909226e616d65223a2022746f222c0a0909090909092274797065223a202261646472657373220a09090909097d0a090909095d2c0a0909090922696e7465726e616c54797065223a20227374727563742063726f737344656d6f2e526563657074222c0a09090909226e616d65223a2022727478222c0a090909092274797065223a20227475706c65220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a20226d616b657246696e697368222c0a0909226f757470757473223a205b5d2c0a09092273746174654d75746162696c697479223a202270617961626c65222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a20226465737456616c7565222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a2022616464726573732070617961626c65222c0a09090909226e616d65223a2022666f637573222c0a090909092274797065223a202261646472657373220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a20226279746573222c0a09090909226e616d65223a202264617461222c0a090909092274797065223a20226279746573220a0909097d0a09095d2c0a0909226e616d65223a20226d616b65725374617274222c0a0909226f757470757473223a205b5d2c0a09092273746174654d75746162696c697479223a202270617961626c65222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b5d2c0a0909226e616d65223a20226f776e6572222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a2022222c0a090909092274797065223a202261646472657373220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202276696577222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a2022616464726573735b5d222c0a09090909226e616d65223a20225f616e63686f7273222c0a090909092274797065223a2022616464726573735b5d220a0909097d0a09095d2c0a0909226e616d65223a202272656d6f7665416e63686f7273222c0a0909226f757470757473223a205b5d2c0a09092273746174654d75746162696c697479223a20226e6f6e70617961626c65222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a20225f616e63686f72222c0a090909092274797065223a202261646472657373220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a2022626f6f6c222c0a09090909226e616d65223a2022737461747573222c0a090909092274797065223a2022626f6f6c220a0909097d0a09095d2c0a0909226e616d65223a2022736574416e63686f72537461747573222c0a0909226f757470757473223a205b5d2c0a09092273746174654d75746162696c697479223a20226e6f6e70617961626c65222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a20226d617856616c7565222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a20227365744d617856616c7565222c0a0909226f757470757473223a205b5d2c0a09092273746174654d75746162696c697479223a20226e6f6e70617961626c65222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a20225f726577617264222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a2022736574526577617264222c0a0909226f757470757473223a205b5d2c0a09092273746174654d75746162696c697479223a20226e6f6e70617961626c65222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e7438222c0a09090909226e616d65223a2022636f756e74222c0a090909092274797065223a202275696e7438220a0909097d0a09095d2c0a0909226e616d65223a20227365745369676e436f6e6669726d436f756e74222c0a0909226f757470757473223a205b5d2c0a09092273746174654d75746162696c697479223a20226e6f6e70617961626c65222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922636f6d706f6e656e7473223a205b0a09090909097b0a09090909090922696e7465726e616c54797065223a202275696e74323536222c0a090909090909226e616d65223a202276616c7565222c0a0909090909092274797065223a202275696e74323536220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a202262797465733332222c0a090909090909226e616d65223a202274784964222c0a0909090909092274797065223a202262797465733332220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a202262797465733332222c0a090909090909226e616d65223a2022747848617368222c0a0909090909092274797065223a202262797465733332220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a2022616464726573732070617961626c65222c0a090909090909226e616d65223a202266726f6d222c0a0909090909092274797065223a202261646472657373220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a202261646472657373222c0a090909090909226e616d65223a2022746f222c0a0909090909092274797065223a202261646472657373220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a202262797465733332222c0a090909090909226e616d65223a2022626c6f636b48617368222c0a0909090909092274797065223a202262797465733332220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a202275696e74323536222c0a090909090909226e616d65223a202264657374696e6174696f6e56616c7565222c0a0909090909092274797065223a202275696e74323536220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a20226279746573222c0a090909090909226e616d65223a202264617461222c0a0909090909092274797065223a20226279746573220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a202275696e743235365b5d222c0a090909090909226e616d65223a202276222c0a0909090909092274797065223a202275696e743235365b5d220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a2022627974657333325b5d222c0a090909090909226e616d65223a202272222c0a0909090909092274797065223a2022627974657333325b5d220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a2022627974657333325b5d222c0a090909090909226e616d65223a202273222c0a0909090909092274797065223a2022627974657333325b5d220a09090909097d0a090909095d2c0a0909090922696e7465726e616c54797065223a20227374727563742063726f737344656d6f2e4f72646572222c0a09090909226e616d65223a2022637478222c0a090909092274797065223a20227475706c65220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a202274616b6572222c0a0909226f757470757473223a205b5d2c0a09092273746174654d75746162696c697479223a202270617961626c65222c0a09092274797065223a202266756e6374696f6e220a097d0a5d"
+	MessageTopic       = common.HexToHash("0x4fc91c7049894280b09a12f12cd4fce78f4e89ad56ce08754927b560d15f4fe2")
+	DeliveredTopic     = common.HexToHash("0xc80b970243315fac790585284d71b08c2abc761f9228066f73fc3116b8b4f7fa")
+	CrossDemoAbi       = "0x5b0a097b0a090922696e70757473223a205b5d2c0a09092273746174654d75746162696c697479223a20226e6f6e70617961626c65222c0a09092274797065223a2022636f6e7374727563746f72220a097d2c0a097b0a090922616e6f6e796d6f7573223a2066616c73652c0a090922696e70757473223a205b0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e6465786564223a20747275652c0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a2022616e63686f72222c0a090909092274797065223a202261646472657373220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022726577617264222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a2022416363756d756c61746552657761726473222c0a09092274797065223a20226576656e74220a097d2c0a097b0a090922616e6f6e796d6f7573223a2066616c73652c0a090922696e70757473223a205b0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a2022416464416e63686f7273222c0a09092274797065223a20226576656e74220a097d2c0a097b0a090922616e6f6e796d6f7573223a2066616c73652c0a090922696e70757473223a205b0a0909097b0a0909090922696e6465786564223a20747275652c0a0909090922696e7465726e616c54797065223a202262797465733332222c0a09090909226e616d65223a202274784964222c0a090909092274797065223a202262797465733332220a0909097d2c0a0909097b0a0909090922696e6465786564223a20747275652c0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a2022746f222c0a090909092274797065223a202261646472657373220a0909097d0a09095d2c0a0909226e616d65223a20224d616b657246696e697368222c0a09092274797065223a20226576656e74220a097d2c0a097b0a090922616e6f6e796d6f7573223a2066616c73652c0a090922696e70757473223a205b0a0909097b0a0909090922696e6465786564223a20747275652c0a0909090922696e7465726e616c54797065223a202262797465733332222c0a09090909226e616d65223a202274784964222c0a090909092274797065223a202262797465733332220a0909097d2c0a0909097b0a0909090922696e6465786564223a20747275652c0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a202266726f6d222c0a090909092274797065223a202261646472657373220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a2022746f222c0a090909092274797065223a202261646472657373220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202276616c7565222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a20226465737456616c7565222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a20226279746573222c0a09090909226e616d65223a202264617461222c0a090909092274797065223a20226279746573220a0909097d0a09095d2c0a0909226e616d65223a20224d616b65725478222c0a09092274797065223a20226576656e74220a097d2c0a097b0a090922616e6f6e796d6f7573223a2066616c73652c0a090922696e70757473223a205b0a0909097b0a0909090922696e6465786564223a20747275652c0a0909090922696e7465726e616c54797065223a202262797465733332222c0a09090909226e616d65223a202274784964222c0a090909092274797065223a202262797465733332220a0909097d2c0a0909097b0a0909090922696e6465786564223a20747275652c0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a2022746172676574222c0a090909092274797065223a202261646472657373220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a202266726f6d222c0a090909092274797065223a202261646472657373220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a2022626f6f6c222c0a09090909226e616d65223a202273756363657373222c0a090909092274797065223a2022626f6f6c220a0909097d0a09095d2c0a0909226e616d65223a20224d65737361676544656c697665726564222c0a09092274797065223a20226576656e74220a097d2c0a097b0a090922616e6f6e796d6f7573223a2066616c73652c0a090922696e70757473223a205b0a0909097b0a0909090922696e6465786564223a20747275652c0a0909090922696e7465726e616c54797065223a202262797465733332222c0a09090909226e616d65223a202274784964222c0a090909092274797065223a202262797465733332220a0909097d2c0a0909097b0a0909090922696e6465786564223a20747275652c0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a202266726f6d222c0a090909092274797065223a202261646472657373220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a2022746172676574222c0a090909092274797065223a202261646472657373220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a20226279746573222c0a09090909226e616d65223a20227061796c6f6164222c0a090909092274797065223a20226279746573220a0909097d0a09095d2c0a0909226e616d65223a20224d6573736167655478222c0a09092274797065223a20226576656e74220a097d2c0a097b0a090922616e6f6e796d6f7573223a2066616c73652c0a090922696e70757473223a205b0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a202252656d6f7665416e63686f7273222c0a09092274797065223a20226576656e74220a097d2c0a097b0a090922616e6f6e796d6f7573223a2066616c73652c0a090922696e70757473223a205b0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a2022536574416e63686f72537461747573222c0a09092274797065223a20226576656e74220a097d2c0a097b0a090922616e6f6e796d6f7573223a2066616c73652c0a090922696e70757473223a205b0a0909097b0a0909090922696e6465786564223a20747275652c0a0909090922696e7465726e616c54797065223a202262797465733332222c0a09090909226e616d65223a202274784964222c0a090909092274797065223a202262797465733332220a0909097d2c0a0909097b0a0909090922696e6465786564223a20747275652c0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a2022746f222c0a090909092274797065223a202261646472657373220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a202266726f6d222c0a090909092274797065223a202261646472657373220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202276616c7565222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e6465786564223a2066616c73652c0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a20226465737456616c7565222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a202254616b65725478222c0a09092274797065223a20226576656e74220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a2022616464726573732070617961626c65222c0a09090909226e616d65223a2022616e63686f72222c0a090909092274797065223a202261646472657373220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022726577617264222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a2022616363756d756c61746552657761726473222c0a0909226f757470757473223a205b5d2c0a09092273746174654d75746162696c697479223a20226e6f6e70617961626c65222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a2022616464726573735b5d222c0a09090909226e616d65223a20225f616e63686f7273222c0a090909092274797065223a2022616464726573735b5d220a0909097d0a09095d2c0a0909226e616d65223a2022616464416e63686f7273222c0a0909226f757470757473223a205b5d2c0a09092273746174654d75746162696c697479223a20226e6f6e70617961626c65222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e743634222c0a09090909226e616d65223a20226e222c0a090909092274797065223a202275696e743634220a0909097d0a09095d2c0a0909226e616d65223a2022626974436f756e74222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e743634222c0a09090909226e616d65223a2022222c0a090909092274797065223a202275696e743634220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202270757265222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b5d2c0a0909226e616d65223a2022636861696e4964222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a20226964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202270757265222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a20226d617856616c7565222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e7438222c0a09090909226e616d65223a20227369676e436f6e6669726d436f756e74222c0a090909092274797065223a202275696e7438220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a2022616464726573735b5d222c0a09090909226e616d65223a20225f616e63686f7273222c0a090909092274797065223a2022616464726573735b5d220a0909097d0a09095d2c0a0909226e616d65223a2022636861696e5265676973746572222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a2022626f6f6c222c0a09090909226e616d65223a2022222c0a090909092274797065223a2022626f6f6c220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a20226e6f6e70617961626c65222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a202263726f7373436861696e73222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e7438222c0a09090909226e616d65223a20227369676e436f6e6669726d436f756e74222c0a090909092274797065223a202275696e7438220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a20226d617856616c7565222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e743634222c0a09090909226e616d65223a2022616e63686f7273506f736974696f6e426974222c0a090909092274797065223a202275696e743634220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e743634222c0a09090909226e616d65223a202264656c73506f736974696f6e426974222c0a090909092274797065223a202275696e743634220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e7438222c0a09090909226e616d65223a202264656c4964222c0a090909092274797065223a202275696e7438220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022726577617264222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022746f74616c526577617264222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202276696577222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a20225f616e63686f72222c0a090909092274797065223a202261646472657373220a0909097d0a09095d2c0a0909226e616d65223a2022676574416e63686f72576f726b436f756e74222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202276696577222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a2022676574416e63686f7273222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a2022616464726573735b5d222c0a09090909226e616d65223a20225f616e63686f7273222c0a090909092274797065223a2022616464726573735b5d220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e7438222c0a09090909226e616d65223a2022222c0a090909092274797065223a202275696e7438220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202276696577222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a2022676574436861696e526577617264222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202276696577222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a20225f616e63686f72222c0a090909092274797065223a202261646472657373220a0909097d0a09095d2c0a0909226e616d65223a202267657444656c416e63686f725369676e436f756e74222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202276696577222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202262797465733332222c0a09090909226e616d65223a202274784964222c0a090909092274797065223a202262797465733332220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a202267657444656c6976657265645478222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202276696577222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202262797465733332222c0a09090909226e616d65223a202274784964222c0a090909092274797065223a202262797465733332220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a20226765744d616b65725478222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202276696577222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a20226765744d617856616c7565222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202276696577222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202262797465733332222c0a09090909226e616d65223a202274784964222c0a090909092274797065223a202262797465733332220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a20226765744d6573736167655478222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202276696577222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202262797465733332222c0a09090909226e616d65223a202274784964222c0a090909092274797065223a202262797465733332220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a20225f66726f6d222c0a090909092274797065223a202261646472657373220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a202267657454616b65725478222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202276696577222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a2022676574546f74616c526577617264222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a2022222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202276696577222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b5d2c0a0909226e616d65223a20226c697374222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a20226c6c222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202270757265222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922636f6d706f6e656e7473223a205b0a09090909097b0a09090909090922696e7465726e616c54797065223a202262797465733332222c0a090909090909226e616d65223a202274784964222c0a0909090909092274797065223a202262797465733332220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a202262797465733332222c0a090909090909226e616d65223a2022747848617368222c0a0909090909092274797065223a202262797465733332220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a2022616464726573732070617961626c65222c0a090909090909226e616d65223a202266726f6d222c0a0909090909092274797065223a202261646472657373220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a2022616464726573732070617961626c65222c0a090909090909226e616d65223a2022746f222c0a0909090909092274797065223a202261646472657373220a09090909097d0a090909095d2c0a0909090922696e7465726e616c54797065223a20227374727563742063726f737344656d6f2e526563657074222c0a09090909226e616d65223a2022727478222c0a090909092274797065223a20227475706c65220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a20226d616b657246696e697368222c0a0909226f757470757473223a205b5d2c0a09092273746174654d75746162696c697479223a202270617961626c65222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a20226465737456616c7565222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a2022616464726573732070617961626c65222c0a09090909226e616d65223a2022666f637573222c0a090909092274797065223a202261646472657373220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a20226279746573222c0a09090909226e616d65223a202264617461222c0a090909092274797065223a20226279746573220a0909097d0a09095d2c0a0909226e616d65223a20226d616b65725374617274222c0a0909226f757470757473223a205b5d2c0a09092273746174654d75746162696c697479223a202270617961626c65222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922636f6d706f6e656e7473223a205b0a09090909097b0a09090909090922696e7465726e616c54797065223a202262797465733332222c0a090909090909226e616d65223a202274784964222c0a0909090909092274797065223a202262797465733332220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a202262797465733332222c0a090909090909226e616d65223a2022747848617368222c0a0909090909092274797065223a202262797465733332220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a202261646472657373222c0a090909090909226e616d65223a202266726f6d222c0a0909090909092274797065223a202261646472657373220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a202262797465733332222c0a090909090909226e616d65223a2022626c6f636b48617368222c0a0909090909092274797065223a202262797465733332220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a202261646472657373222c0a090909090909226e616d65223a2022746172676574222c0a0909090909092274797065223a202261646472657373220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a20226279746573222c0a090909090909226e616d65223a20227061796c6f6164222c0a0909090909092274797065223a20226279746573220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a202275696e743235365b5d222c0a090909090909226e616d65223a202276222c0a0909090909092274797065223a202275696e743235365b5d220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a2022627974657333325b5d222c0a090909090909226e616d65223a202272222c0a0909090909092274797065223a2022627974657333325b5d220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a2022627974657333325b5d222c0a090909090909226e616d65223a202273222c0a0909090909092274797065223a2022627974657333325b5d220a09090909097d0a090909095d2c0a0909090922696e7465726e616c54797065223a20227374727563742063726f737344656d6f2e4d657373616765222c0a09090909226e616d65223a20226d222c0a090909092274797065223a20227475706c65220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a20226d65737361676544656c69766572222c0a0909226f757470757473223a205b5d2c0a09092273746174654d75746162696c697479223a20226e6f6e70617961626c65222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a2022746172676574222c0a090909092274797065223a202261646472657373220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a20226279746573222c0a09090909226e616d65223a20227061796c6f6164222c0a090909092274797065223a20226279746573220a0909097d0a09095d2c0a0909226e616d65223a20226d6573736167655374617274222c0a0909226f757470757473223a205b5d2c0a09092273746174654d75746162696c697479223a20226e6f6e70617961626c65222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b5d2c0a0909226e616d65223a20226f776e6572222c0a0909226f757470757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a2022222c0a090909092274797065223a202261646472657373220a0909097d0a09095d2c0a09092273746174654d75746162696c697479223a202276696577222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a2022616464726573735b5d222c0a09090909226e616d65223a20225f616e63686f7273222c0a090909092274797065223a2022616464726573735b5d220a0909097d0a09095d2c0a0909226e616d65223a202272656d6f7665416e63686f7273222c0a0909226f757470757473223a205b5d2c0a09092273746174654d75746162696c697479223a20226e6f6e70617961626c65222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202261646472657373222c0a09090909226e616d65223a20225f616e63686f72222c0a090909092274797065223a202261646472657373220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a2022626f6f6c222c0a09090909226e616d65223a2022737461747573222c0a090909092274797065223a2022626f6f6c220a0909097d0a09095d2c0a0909226e616d65223a2022736574416e63686f72537461747573222c0a0909226f757470757473223a205b5d2c0a09092273746174654d75746162696c697479223a20226e6f6e70617961626c65222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a20226d617856616c7565222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a20227365744d617856616c7565222c0a0909226f757470757473223a205b5d2c0a09092273746174654d75746162696c697479223a20226e6f6e70617961626c65222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a20225f726577617264222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a2022736574526577617264222c0a0909226f757470757473223a205b5d2c0a09092273746174654d75746162696c697479223a20226e6f6e70617961626c65222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e7438222c0a09090909226e616d65223a2022636f756e74222c0a090909092274797065223a202275696e7438220a0909097d0a09095d2c0a0909226e616d65223a20227365745369676e436f6e6669726d436f756e74222c0a0909226f757470757473223a205b5d2c0a09092273746174654d75746162696c697479223a20226e6f6e70617961626c65222c0a09092274797065223a202266756e6374696f6e220a097d2c0a097b0a090922696e70757473223a205b0a0909097b0a0909090922636f6d706f6e656e7473223a205b0a09090909097b0a09090909090922696e7465726e616c54797065223a202275696e74323536222c0a090909090909226e616d65223a202276616c7565222c0a0909090909092274797065223a202275696e74323536220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a202262797465733332222c0a090909090909226e616d65223a202274784964222c0a0909090909092274797065223a202262797465733332220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a202262797465733332222c0a090909090909226e616d65223a2022747848617368222c0a0909090909092274797065223a202262797465733332220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a2022616464726573732070617961626c65222c0a090909090909226e616d65223a202266726f6d222c0a0909090909092274797065223a202261646472657373220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a202261646472657373222c0a090909090909226e616d65223a2022746f222c0a0909090909092274797065223a202261646472657373220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a202262797465733332222c0a090909090909226e616d65223a2022626c6f636b48617368222c0a0909090909092274797065223a202262797465733332220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a202275696e74323536222c0a090909090909226e616d65223a202264657374696e6174696f6e56616c7565222c0a0909090909092274797065223a202275696e74323536220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a20226279746573222c0a090909090909226e616d65223a202264617461222c0a0909090909092274797065223a20226279746573220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a202275696e743235365b5d222c0a090909090909226e616d65223a202276222c0a0909090909092274797065223a202275696e743235365b5d220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a2022627974657333325b5d222c0a090909090909226e616d65223a202272222c0a0909090909092274797065223a2022627974657333325b5d220a09090909097d2c0a09090909097b0a09090909090922696e7465726e616c54797065223a2022627974657333325b5d222c0a090909090909226e616d65223a202273222c0a0909090909092274797065223a2022627974657333325b5d220a09090909097d0a090909095d2c0a0909090922696e7465726e616c54797065223a20227374727563742063726f737344656d6f2e4f72646572222c0a09090909226e616d65223a2022637478222c0a090909092274797065223a20227475706c65220a0909097d2c0a0909097b0a0909090922696e7465726e616c54797065223a202275696e74323536222c0a09090909226e616d65223a202272656d6f7465436861696e4964222c0a090909092274797065223a202275696e74323536220a0909097d0a09095d2c0a0909226e616d65223a202274616b6572222c0a0909226f757470757473223a205b5d2c0a09092273746174654d75746162696c697479223a202270617961626c65222c0a09092274797065223a202266756e6374696f6e220a097d0a5d"
 	GetAnchorFn, _     = hexutil.Decode("0xe2ca8462")
 	GetMakerTxFn, _    = hexutil.Decode("0x9624005b")
 	GetTakerTxFn, _    = hexutil.Decode("0x60606edc")
+	GetMessageTxFn, _  = hexutil.Decode("0x9fbb74a8")
+	GetDeliveredFn, _  = hexutil.Decode("0x7a96ac36")
 )
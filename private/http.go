@@ -0,0 +1,161 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package private
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/common/hexutil"
+)
+
+const httpTimeout = 10 * time.Second
+
+type sendRequest struct {
+	Payload hexutil.Bytes `json:"payload"`
+	From    string        `json:"from,omitempty"`
+	To      []string      `json:"to"`
+}
+
+type sendResponse struct {
+	Key common.Hash `json:"key"`
+}
+
+type receiveResponse struct {
+	Payload hexutil.Bytes `json:"payload"`
+}
+
+// HTTPManager is a transaction manager reached over HTTP, serving the API of
+// NewHandler: POST /send and GET /receive/<hash>.
+type HTTPManager struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPManager creates a client of the transaction manager served at url.
+func NewHTTPManager(url string) *HTTPManager {
+	return &HTTPManager{
+		url:    strings.TrimRight(url, "/"),
+		client: &http.Client{Timeout: httpTimeout},
+	}
+}
+
+// Send implements TransactionManager.
+func (m *HTTPManager) Send(payload []byte, from string, to []string) (common.Hash, error) {
+	body, err := json.Marshal(sendRequest{Payload: payload, From: from, To: to})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	resp, err := m.client.Post(m.url+"/send", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return common.Hash{}, responseError(resp)
+	}
+	var result sendResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return common.Hash{}, err
+	}
+	return result.Key, nil
+}
+
+// Receive implements TransactionManager.
+func (m *HTTPManager) Receive(hash common.Hash) ([]byte, error) {
+	resp, err := m.client.Get(m.url + "/receive/" + hash.Hex())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var result receiveResponse
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return nil, err
+		}
+		return result.Payload, nil
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, responseError(resp)
+	}
+}
+
+func responseError(resp *http.Response) error {
+	msg, _ := ioutil.ReadAll(resp.Body)
+	return fmt.Errorf("transaction manager: %s: %s", resp.Status, bytes.TrimSpace(msg))
+}
+
+// NewHandler returns an HTTP handler exposing the transaction manager to the
+// nodes using an HTTPManager. Payloads the manager doesn't take part in are
+// reported as not found.
+func NewHandler(m TransactionManager) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/send", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var req sendRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		hash, err := m.Send(req.Payload, req.From, req.To)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, sendResponse{Key: hash})
+	})
+	mux.HandleFunc("/receive/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var hash common.Hash
+		if err := hash.UnmarshalText([]byte(strings.TrimPrefix(r.URL.Path, "/receive/"))); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		payload, err := m.Receive(hash)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if payload == nil {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, receiveResponse{Payload: payload})
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
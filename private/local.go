@@ -0,0 +1,163 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package private
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/common/hexutil"
+	"github.com/simplechain-org/go-simplechain/crypto"
+)
+
+// envelope is a sealed payload as stored by the local manager.
+type envelope struct {
+	Key          hexutil.Bytes `json:"key"`          // Symmetric key of the payload
+	Ciphertext   hexutil.Bytes `json:"ciphertext"`   // Nonce followed by the sealed payload
+	Participants []string      `json:"participants"` // Identities allowed to open the payload
+}
+
+// LocalManager is a stand-in transaction manager keeping the payloads in a
+// directory shared by the participants. Payloads are encrypted at rest, but the
+// keys are stored next to them, so it only keeps payloads away from nodes that
+// can't read the directory.
+type LocalManager struct {
+	dir      string
+	identity string
+}
+
+// NewLocalManager creates a local manager storing payloads in dir on behalf of
+// identity.
+func NewLocalManager(dir string, identity string) (*LocalManager, error) {
+	if identity == "" {
+		return nil, errors.New("local transaction manager requires an identity")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &LocalManager{dir: dir, identity: identity}, nil
+}
+
+// Send implements TransactionManager, sealing the payload and storing it under
+// the hash of the ciphertext.
+func (m *LocalManager) Send(payload []byte, from string, to []string) (common.Hash, error) {
+	if from == "" {
+		from = m.identity
+	}
+	if from != m.identity {
+		return common.Hash{}, ErrNotParticipant
+	}
+	if len(to) == 0 {
+		return common.Hash{}, ErrNoParticipants
+	}
+	if len(payload) == 0 {
+		return common.Hash{}, ErrEmptyPayload
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return common.Hash{}, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return common.Hash{}, err
+	}
+	env := envelope{
+		Key:          key,
+		Ciphertext:   aead.Seal(nonce, nonce, payload, nil),
+		Participants: append([]string{from}, to...),
+	}
+	hash := crypto.Keccak256Hash(env.Ciphertext)
+
+	blob, err := json.Marshal(env)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	// Write atomically, other participants may read the directory concurrently
+	tmp, err := ioutil.TempFile(m.dir, ".tmp-")
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if _, err := tmp.Write(blob); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return common.Hash{}, err
+	}
+	tmp.Close()
+	if err := os.Rename(tmp.Name(), m.path(hash)); err != nil {
+		os.Remove(tmp.Name())
+		return common.Hash{}, err
+	}
+	return hash, nil
+}
+
+// Receive implements TransactionManager.
+func (m *LocalManager) Receive(hash common.Hash) ([]byte, error) {
+	blob, err := ioutil.ReadFile(m.path(hash))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var env envelope
+	if err := json.Unmarshal(blob, &env); err != nil {
+		return nil, err
+	}
+	participant := false
+	for _, id := range env.Participants {
+		if id == m.identity {
+			participant = true
+			break
+		}
+	}
+	if !participant {
+		return nil, nil
+	}
+	aead, err := newAEAD(env.Key)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Ciphertext) < aead.NonceSize() {
+		return nil, errors.New("truncated private payload")
+	}
+	nonce, sealed := env.Ciphertext[:aead.NonceSize()], env.Ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, sealed, nil)
+}
+
+// path returns the file of the payload with the given hash.
+func (m *LocalManager) path(hash common.Hash) string {
+	return filepath.Join(m.dir, hash.Hex()[2:])
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,61 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package private implements the exchange of private transaction payloads among
+// the participants of consortium chains.
+package private
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/simplechain-org/go-simplechain/common"
+)
+
+var (
+	// ErrNotParticipant is returned if a payload is sent on behalf of an identity
+	// the manager doesn't hold.
+	ErrNotParticipant = errors.New("sender is not held by the transaction manager")
+
+	// ErrNoParticipants is returned if a payload is sent to nobody.
+	ErrNoParticipants = errors.New("no private participants")
+
+	// ErrEmptyPayload is returned if an empty payload is sent.
+	ErrEmptyPayload = errors.New("empty private payload")
+)
+
+// TransactionManager exchanges the payloads of private transactions among their
+// participants. The payload of a private transaction is replaced by the hash the
+// manager returns, which every participant resolves through its own manager.
+type TransactionManager interface {
+	// Send stores the encrypted payload for the sender and the given participants,
+	// returning the hash replacing it in the transaction. An empty sender stands for
+	// the identity of the manager.
+	Send(payload []byte, from string, to []string) (common.Hash, error)
+
+	// Receive returns the decrypted payload of the hash, or nil if the identity of
+	// the manager doesn't take part in the transaction.
+	Receive(hash common.Hash) ([]byte, error)
+}
+
+// New creates the transaction manager of the endpoint, an HTTP URL of a remote
+// manager or the directory of a local one acting on behalf of identity.
+func New(endpoint string, identity string) (TransactionManager, error) {
+	if strings.HasPrefix(endpoint, "http://") || strings.HasPrefix(endpoint, "https://") {
+		return NewHTTPManager(endpoint), nil
+	}
+	return NewLocalManager(endpoint, identity)
+}
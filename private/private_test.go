@@ -0,0 +1,96 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package private
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+// Tests that payloads stored by the local manager can only be opened by their
+// participants.
+func TestLocalManager(t *testing.T) {
+	dir, err := ioutil.TempDir("", "private-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	managers := make(map[string]TransactionManager)
+	for _, id := range []string{"alice", "bob", "carol"} {
+		if managers[id], err = New(dir, id); err != nil {
+			t.Fatalf("failed to create manager of %s: %v", id, err)
+		}
+	}
+	payload := []byte("private payload")
+	hash, err := managers["alice"].Send(payload, "", []string{"bob"})
+	if err != nil {
+		t.Fatalf("failed to send payload: %v", err)
+	}
+	for id, want := range map[string][]byte{"alice": payload, "bob": payload, "carol": nil} {
+		have, err := managers[id].Receive(hash)
+		if err != nil {
+			t.Fatalf("%s: failed to receive payload: %v", id, err)
+		}
+		if !bytes.Equal(have, want) {
+			t.Errorf("%s: payload mismatch: have %q, want %q", id, have, want)
+		}
+	}
+	if _, err := managers["alice"].Send(payload, "bob", []string{"carol"}); err != ErrNotParticipant {
+		t.Errorf("foreign sender error mismatch: have %v, want %v", err, ErrNotParticipant)
+	}
+	if _, err := managers["alice"].Send(payload, "", nil); err != ErrNoParticipants {
+		t.Errorf("missing participants error mismatch: have %v, want %v", err, ErrNoParticipants)
+	}
+}
+
+// Tests that a manager served over HTTP behaves as the local one.
+func TestHTTPManager(t *testing.T) {
+	dir, err := ioutil.TempDir("", "private-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	alice, _ := NewLocalManager(dir, "alice")
+	bob, _ := NewLocalManager(dir, "bob")
+
+	aliceSrv := httptest.NewServer(NewHandler(alice))
+	defer aliceSrv.Close()
+	bobSrv := httptest.NewServer(NewHandler(bob))
+	defer bobSrv.Close()
+
+	payload := []byte("private payload")
+	hash, err := NewHTTPManager(aliceSrv.URL).Send(payload, "", []string{"carol"})
+	if err != nil {
+		t.Fatalf("failed to send payload: %v", err)
+	}
+	have, err := NewHTTPManager(aliceSrv.URL).Receive(hash)
+	if err != nil || !bytes.Equal(have, payload) {
+		t.Errorf("sender payload mismatch: have %q, %v, want %q", have, err, payload)
+	}
+	have, err = NewHTTPManager(bobSrv.URL).Receive(hash)
+	if err != nil || have != nil {
+		t.Errorf("outsider received payload: have %q, %v", have, err)
+	}
+	if _, err := NewHTTPManager(aliceSrv.URL).Send(payload, "", nil); err == nil {
+		t.Errorf("send without participants succeeded")
+	}
+}
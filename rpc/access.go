@@ -0,0 +1,272 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/simplechain-org/go-simplechain/metrics"
+)
+
+// APIKeyHeader is the HTTP header carrying the API key of a request. The key may
+// also be given as the URL path of the endpoint, e.g. http://host:8545/<key>.
+const APIKeyHeader = "X-API-Key"
+
+var unauthorizedMeter = metrics.NewRegisteredMeter("rpc/apikey/unauthorized", nil)
+
+// APIKey is the access policy of a client of the HTTP and websocket endpoints.
+// The limits are enforced by each endpoint separately, zero meaning unlimited.
+type APIKey struct {
+	Name            string   `json:"name"`                      // Name of the key in the logs and metrics
+	Key             string   `json:"key"`                       // Secret sent by the client
+	Methods         []string `json:"methods,omitempty"`         // Allowed methods, "ns_*" for a namespace, all if empty
+	Rate            float64  `json:"rate,omitempty"`            // Requests per second
+	Burst           int      `json:"burst,omitempty"`           // Requests allowed at once, defaults to the rate
+	DailyQuota      uint64   `json:"dailyQuota,omitempty"`      // Requests per UTC day
+	MaxResponseSize int      `json:"maxResponseSize,omitempty"` // Size of a response in bytes
+	MaxLogsRange    uint64   `json:"maxLogsRange,omitempty"`    // Blocks spanned by a log query
+}
+
+// limitError is returned when a request exceeds the limits of its API key.
+type limitError struct{ message string }
+
+func (e *limitError) ErrorCode() int { return -32005 }
+
+func (e *limitError) Error() string { return e.message }
+
+// methodDeniedError is returned when a method isn't allowed for the API key.
+type methodDeniedError struct{ method string }
+
+func (e *methodDeniedError) ErrorCode() int { return -32004 }
+
+func (e *methodDeniedError) Error() string {
+	return fmt.Sprintf("the method %s is not allowed for this API key", e.method)
+}
+
+// apiKey is an API key along with its usage. Only the hash of the secret is
+// kept, the Key field is cleared.
+type apiKey struct {
+	APIKey
+	hash       [sha256.Size]byte
+	methods    map[string]bool // Allowed methods and namespaces, nil to allow all
+	namespaces map[string]bool
+
+	tokens float64   // Requests left in the rate bucket
+	last   time.Time // Last refill of the rate bucket
+	day    int64     // Day of the quota window
+	used   uint64    // Requests made in the quota window
+	lock   sync.Mutex
+
+	requestMeter metrics.Meter
+	deniedMeter  metrics.Meter
+	limitedMeter metrics.Meter
+	bytesCounter metrics.Counter
+}
+
+func newAPIKey(key APIKey) *apiKey {
+	k := &apiKey{
+		APIKey:       key,
+		tokens:       float64(key.Burst),
+		last:         time.Now(),
+		requestMeter: metrics.GetOrRegisterMeter("rpc/apikey/"+key.Name+"/requests", nil),
+		deniedMeter:  metrics.GetOrRegisterMeter("rpc/apikey/"+key.Name+"/denied", nil),
+		limitedMeter: metrics.GetOrRegisterMeter("rpc/apikey/"+key.Name+"/limited", nil),
+		bytesCounter: metrics.GetOrRegisterCounter("rpc/apikey/"+key.Name+"/bytes", nil),
+	}
+	k.hash = sha256.Sum256([]byte(key.Key))
+	k.Key = ""
+	if k.Burst <= 0 {
+		k.Burst = int(k.Rate)
+		if k.Burst < 1 {
+			k.Burst = 1
+		}
+		k.tokens = float64(k.Burst)
+	}
+	if len(key.Methods) > 0 {
+		k.methods = make(map[string]bool)
+		k.namespaces = make(map[string]bool)
+		for _, method := range key.Methods {
+			if strings.HasSuffix(method, "_*") {
+				k.namespaces[strings.TrimSuffix(method, "_*")] = true
+			} else {
+				k.methods[method] = true
+			}
+		}
+	}
+	return k
+}
+
+// allowed reports whether the key may call the method.
+func (k *apiKey) allowed(method string) bool {
+	if k.methods == nil || k.methods[method] || k.methods["*"] {
+		return true
+	}
+	if i := strings.Index(method, serviceMethodSeparator); i > 0 {
+		return k.namespaces[method[:i]]
+	}
+	return false
+}
+
+// admit checks a call against the allow-list, the rate limit and the quota of
+// the key.
+func (k *apiKey) admit(method string) error {
+	k.requestMeter.Mark(1)
+	if !k.allowed(method) {
+		k.deniedMeter.Mark(1)
+		return &methodDeniedError{method}
+	}
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	now := time.Now()
+	if k.Rate > 0 {
+		k.tokens += now.Sub(k.last).Seconds() * k.Rate
+		if k.tokens > float64(k.Burst) {
+			k.tokens = float64(k.Burst)
+		}
+		k.last = now
+		if k.tokens < 1 {
+			k.limitedMeter.Mark(1)
+			return &limitError{"request rate limit exceeded"}
+		}
+	}
+	if k.DailyQuota > 0 {
+		if day := now.Unix() / 86400; day != k.day {
+			k.day, k.used = day, 0
+		}
+		if k.used >= k.DailyQuota {
+			k.limitedMeter.Mark(1)
+			return &limitError{"daily request quota exhausted"}
+		}
+		k.used++
+	}
+	if k.Rate > 0 {
+		k.tokens--
+	}
+	return nil
+}
+
+// account checks the size of a response against the cap of the key.
+func (k *apiKey) account(size int) error {
+	if k.MaxResponseSize > 0 && size > k.MaxResponseSize {
+		k.limitedMeter.Mark(1)
+		return &limitError{fmt.Sprintf("response size exceeds the limit of %d bytes", k.MaxResponseSize)}
+	}
+	k.bytesCounter.Inc(int64(size))
+	return nil
+}
+
+// SetAPIKeys requires the HTTP and websocket clients of the server to present
+// one of the given API keys, and enforces their policies. It must be called
+// before the server starts serving, an empty list disabling the authentication.
+func (s *Server) SetAPIKeys(keys []APIKey) error {
+	if len(keys) == 0 {
+		s.keys = nil
+		return nil
+	}
+	var (
+		set    = make([]*apiKey, 0, len(keys))
+		names  = make(map[string]bool, len(keys))
+		hashes = make(map[[sha256.Size]byte]bool, len(keys))
+	)
+	for _, key := range keys {
+		if key.Key == "" {
+			return fmt.Errorf("API key %q has no secret", key.Name)
+		}
+		if key.Name == "" {
+			return errors.New("API key without name")
+		}
+		// The name keys the metrics of the key, so it must be unique too
+		if names[key.Name] {
+			return fmt.Errorf("duplicate API key name %q", key.Name)
+		}
+		k := newAPIKey(key)
+		if hashes[k.hash] {
+			return fmt.Errorf("duplicate API key %q", key.Name)
+		}
+		names[key.Name], hashes[k.hash] = true, true
+		set = append(set, k)
+	}
+	s.keys = set
+	return nil
+}
+
+// authenticate returns the API key of the request, or false if the server
+// requires keys and the request carries no valid one.
+func (s *Server) authenticate(r *http.Request) (*apiKey, bool) {
+	if s.keys == nil {
+		return nil, true
+	}
+	secret := r.Header.Get(APIKeyHeader)
+	if secret == "" {
+		secret = strings.Trim(r.URL.Path, "/")
+	}
+	// Compare against every key in constant time, not to leak the secrets
+	// through the response time
+	var (
+		hash = sha256.Sum256([]byte(secret))
+		key  *apiKey
+	)
+	for _, k := range s.keys {
+		if subtle.ConstantTimeCompare(k.hash[:], hash[:]) == 1 {
+			key = k
+		}
+	}
+	if key == nil {
+		unauthorizedMeter.Mark(1)
+		return nil, false
+	}
+	return key, true
+}
+
+type apiKeyContextKey struct{}
+
+// withAPIKey returns a copy of the context carrying the API key of the client.
+func withAPIKey(ctx context.Context, key *apiKey) context.Context {
+	if key == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
+
+// apiKeyFromContext returns the API key of the client, nil if none.
+func apiKeyFromContext(ctx context.Context) *apiKey {
+	key, _ := ctx.Value(apiKeyContextKey{}).(*apiKey)
+	return key
+}
+
+// CheckBlockRange checks that a query spanning the blocks from..to inclusive is
+// within the log range cap of the API key of the calling client, if any.
+func CheckBlockRange(ctx context.Context, from, to uint64) error {
+	key := apiKeyFromContext(ctx)
+	if key == nil || key.MaxLogsRange == 0 || to < from {
+		return nil
+	}
+	if to-from+1 > key.MaxLogsRange {
+		key.limitedMeter.Mark(1)
+		return &limitError{fmt.Sprintf("block range exceeds the limit of %d blocks", key.MaxLogsRange)}
+	}
+	return nil
+}
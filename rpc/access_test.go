@@ -0,0 +1,131 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// errorCode returns the JSON-RPC error code of a call error, zero if none.
+func errorCode(err error) int {
+	if ec, ok := err.(Error); ok {
+		return ec.ErrorCode()
+	}
+	return 0
+}
+
+// Tests that the HTTP and websocket endpoints require a valid API key and
+// enforce its method allow-list and limits.
+func TestAPIKeys(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+
+	err := server.SetAPIKeys([]APIKey{
+		{Name: "limited", Key: "secret1", Methods: []string{"test_echo"}, Rate: 0.001, Burst: 2},
+		{Name: "capped", Key: "secret2", Methods: []string{"test_*"}, MaxResponseSize: 8},
+	})
+	if err != nil {
+		t.Fatalf("failed to set keys: %v", err)
+	}
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	// Requests without a valid key must be rejected
+	client, _ := DialHTTP(httpsrv.URL + "/invalid")
+	if err := client.Call(nil, "test_noArgsRets"); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("unauthorized call error mismatch: %v", err)
+	}
+	// Methods outside the allow-list must be denied, the rest rate limited
+	client, _ = DialHTTP(httpsrv.URL + "/secret1")
+	if err := client.Call(nil, "test_noArgsRets"); errorCode(err) != -32004 {
+		t.Fatalf("denied call error mismatch: %v", err)
+	}
+	var res echoResult
+	for i := 0; i < 2; i++ {
+		if err := client.Call(&res, "test_echo", "x", 1, &echoArgs{"y"}); err != nil {
+			t.Fatalf("call %d failed: %v", i, err)
+		}
+	}
+	if err := client.Call(&res, "test_echo", "x", 1, &echoArgs{"y"}); errorCode(err) != -32005 {
+		t.Fatalf("rate limited call error mismatch: %v", err)
+	}
+	// Responses above the cap of the key must be dropped, over websocket too
+	wssrv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer wssrv.Close()
+
+	ws, err := DialWebsocket(context.Background(), "ws"+strings.TrimPrefix(wssrv.URL, "http")+"/secret2", "")
+	if err != nil {
+		t.Fatalf("failed to dial websocket: %v", err)
+	}
+	defer ws.Close()
+
+	if err := ws.Call(nil, "test_noArgsRets"); err != nil {
+		t.Fatalf("small response call failed: %v", err)
+	}
+	if err := ws.Call(&res, "test_echo", "x", 1, &echoArgs{"y"}); errorCode(err) != -32005 {
+		t.Fatalf("large response call error mismatch: %v", err)
+	}
+	if _, err := DialWebsocket(context.Background(), "ws"+strings.TrimPrefix(wssrv.URL, "http")+"/invalid", ""); err == nil {
+		t.Fatalf("unauthorized websocket accepted")
+	}
+	// Log queries must be capped by the key of the caller only
+	key := newAPIKey(APIKey{Name: "logs", Key: "secret3", MaxLogsRange: 10})
+	if err := CheckBlockRange(withAPIKey(context.Background(), key), 0, 10); errorCode(err) != -32005 {
+		t.Fatalf("log range error mismatch: %v", err)
+	}
+	if err := CheckBlockRange(withAPIKey(context.Background(), key), 1, 10); err != nil {
+		t.Fatalf("log range within cap rejected: %v", err)
+	}
+	if err := CheckBlockRange(context.Background(), 0, 1000); err != nil {
+		t.Fatalf("log range without key rejected: %v", err)
+	}
+}
+
+// Tests that invalid key lists are rejected and that only the hashes of the
+// secrets are kept.
+func TestSetAPIKeys(t *testing.T) {
+	tests := []struct {
+		keys []APIKey
+		err  string
+	}{
+		{[]APIKey{{Name: "a", Key: "secret1"}, {Name: "b", Key: "secret2"}}, ""},
+		{[]APIKey{{Name: "a"}}, `API key "a" has no secret`},
+		{[]APIKey{{Key: "secret1"}}, "API key without name"},
+		{[]APIKey{{Name: "a", Key: "secret1"}, {Name: "b", Key: "secret1"}}, `duplicate API key "b"`},
+		{[]APIKey{{Name: "a", Key: "secret1"}, {Name: "a", Key: "secret2"}}, `duplicate API key name "a"`},
+	}
+	for i, test := range tests {
+		server := NewServer()
+		err := server.SetAPIKeys(test.keys)
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("test %d: unexpected error: %v", i, err)
+		case test.err != "" && (err == nil || err.Error() != test.err):
+			t.Errorf("test %d: error mismatch: have %v, want %q", i, err, test.err)
+		}
+		if err == nil {
+			for _, key := range server.keys {
+				if key.Key != "" {
+					t.Errorf("test %d: secret of key %q kept in memory", i, key.Name)
+				}
+			}
+		}
+	}
+}
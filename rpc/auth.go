@@ -0,0 +1,238 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/simplechain-org/go-simplechain/log"
+	"github.com/simplechain-org/go-simplechain/metrics"
+)
+
+// jwtExpiryTimeout is the maximum drift of the issuance time of a bearer token
+// from the local clock.
+const jwtExpiryTimeout = 60 * time.Second
+
+var (
+	errMissingToken = errors.New("missing bearer token")
+	errInvalidToken = errors.New("invalid bearer token")
+	errStaleToken   = errors.New("stale bearer token")
+
+	jwtRejectedMeter = metrics.NewRegisteredMeter("rpc/jwt/rejected", nil)
+)
+
+// AuthConfig is the transport security of an HTTP or websocket endpoint. TLS is
+// enabled by a certificate, client certificates are required by a client CA, and
+// HS256 bearer tokens by a JWT secret.
+type AuthConfig struct {
+	TLSCert     string `toml:",omitempty"` // PEM certificate file of the endpoint
+	TLSKey      string `toml:",omitempty"` // PEM private key file of the certificate
+	TLSClientCA string `toml:",omitempty"` // PEM file of the CAs the client certificates must chain to
+	JWTSecret   string `toml:",omitempty"` // File holding the hex encoded 32 byte token secret
+}
+
+// Scheme returns the URL scheme of an endpoint secured by the config, with the
+// given plain text one.
+func (c *AuthConfig) Scheme(plain string) string {
+	if c == nil || c.TLSCert == "" {
+		return plain
+	}
+	return plain + "s"
+}
+
+// secure wraps the listener and handler of an endpoint with the TLS and token
+// checks of the config, if any.
+func (c *AuthConfig) secure(listener net.Listener, handler http.Handler) (net.Listener, http.Handler, error) {
+	if c == nil {
+		return listener, handler, nil
+	}
+	if c.JWTSecret != "" {
+		secret, err := ReadJWTSecret(c.JWTSecret)
+		if err != nil {
+			return nil, nil, err
+		}
+		handler = &jwtHandler{secret: secret, next: handler}
+	}
+	if c.TLSCert != "" {
+		config, err := c.serverTLSConfig()
+		if err != nil {
+			return nil, nil, err
+		}
+		listener = tls.NewListener(listener, config)
+	} else if c.TLSKey != "" || c.TLSClientCA != "" {
+		return nil, nil, errors.New("TLS key or client CA given without certificate")
+	}
+	return listener, handler, nil
+}
+
+// serverTLSConfig loads the certificate of the endpoint and the CAs of the
+// client certificates.
+func (c *AuthConfig) serverTLSConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(c.TLSCert, c.TLSKey)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if c.TLSClientCA != "" {
+		pool, err := loadCertPool(c.TLSClientCA)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// ClientTLSConfig creates the TLS config of a client verifying the server against
+// the given CA file, and presenting the given certificate. Empty paths fall back
+// to the system roots and no client certificate.
+func ClientTLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// loadCertPool loads the PEM certificates of a file into a pool.
+func loadCertPool(path string) (*x509.CertPool, error) {
+	blob, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(blob) {
+		return nil, fmt.Errorf("no certificates in %s", path)
+	}
+	return pool, nil
+}
+
+// ReadJWTSecret reads a hex encoded 32 byte token secret from a file.
+func ReadJWTSecret(path string) ([]byte, error) {
+	blob, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(blob)), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT secret: %v", err)
+	}
+	if len(secret) != 32 {
+		return nil, fmt.Errorf("invalid JWT secret length %d, want 32", len(secret))
+	}
+	return secret, nil
+}
+
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// jwtClaims are the claims of a bearer token checked by the server.
+type jwtClaims struct {
+	IssuedAt int64 `json:"iat"`
+}
+
+// newJWT creates an HS256 bearer token issued at the given time.
+func newJWT(secret []byte, now time.Time) string {
+	claims, _ := json.Marshal(&jwtClaims{IssuedAt: now.Unix()})
+	unsigned := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verifyJWT checks the signature of an HS256 bearer token, and that it was issued
+// around the given time.
+func verifyJWT(secret []byte, token string, now time.Time) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return errInvalidToken
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	blob, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || json.Unmarshal(blob, &header) != nil || header.Alg != "HS256" {
+		return errInvalidToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return errInvalidToken
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return errInvalidToken
+	}
+	var claims jwtClaims
+	if blob, err = base64.RawURLEncoding.DecodeString(parts[1]); err != nil || json.Unmarshal(blob, &claims) != nil {
+		return errInvalidToken
+	}
+	issued := time.Unix(claims.IssuedAt, 0)
+	if issued.Before(now.Add(-jwtExpiryTimeout)) || issued.After(now.Add(jwtExpiryTimeout)) {
+		return errStaleToken
+	}
+	return nil
+}
+
+// jwtHandler is a handler requiring the requests to carry a valid bearer token.
+type jwtHandler struct {
+	secret []byte
+	next   http.Handler
+}
+
+// ServeHTTP implements http.Handler.
+func (h *jwtHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	err := errMissingToken
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		err = verifyJWT(h.secret, strings.TrimPrefix(auth, "Bearer "), time.Now())
+	}
+	if err != nil {
+		jwtRejectedMeter.Mark(1)
+		log.Debug("Rejected unauthenticated RPC request", "remote", r.RemoteAddr, "err", err)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	h.next.ServeHTTP(w, r)
+}
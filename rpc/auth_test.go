@@ -0,0 +1,169 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeCert creates a certificate signed by the parent one, or self-signed if
+// nil, and writes it along with its key to the directory.
+func writeCert(t *testing.T, dir, name string, template *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDer, _ := x509.MarshalECPrivateKey(key)
+	ioutil.WriteFile(filepath.Join(dir, name+".crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	ioutil.WriteFile(filepath.Join(dir, name+".key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+
+	cert, _ := x509.ParseCertificate(der)
+	return cert, key
+}
+
+// Tests that the bearer tokens are checked for their signature and age.
+func TestJWT(t *testing.T) {
+	secret, other := make([]byte, 32), make([]byte, 32)
+	other[0] = 1
+
+	now := time.Now()
+	if err := verifyJWT(secret, newJWT(secret, now), now); err != nil {
+		t.Fatalf("valid token rejected: %v", err)
+	}
+	if err := verifyJWT(secret, newJWT(other, now), now); err != errInvalidToken {
+		t.Fatalf("forged token error mismatch: %v", err)
+	}
+	if err := verifyJWT(secret, newJWT(secret, now.Add(-2*jwtExpiryTimeout)), now); err != errStaleToken {
+		t.Fatalf("stale token error mismatch: %v", err)
+	}
+	if err := verifyJWT(secret, "garbage", now); err != errInvalidToken {
+		t.Fatalf("malformed token error mismatch: %v", err)
+	}
+}
+
+// Tests that an endpoint secured by TLS, client certificates and bearer tokens
+// only serves the clients presenting both.
+func TestSecuredEndpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpc-auth-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca, caKey := writeCert(t, dir, "ca", &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil, nil)
+	writeCert(t, dir, "server", &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "server"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca, caKey)
+	writeCert(t, dir, "client", &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca, caKey)
+
+	secret := make([]byte, 32)
+	rand.Read(secret)
+	ioutil.WriteFile(filepath.Join(dir, "jwt.hex"), []byte(hex.EncodeToString(secret)), 0600)
+
+	auth := &AuthConfig{
+		TLSCert:     filepath.Join(dir, "server.crt"),
+		TLSKey:      filepath.Join(dir, "server.key"),
+		TLSClientCA: filepath.Join(dir, "ca.crt"),
+		JWTSecret:   filepath.Join(dir, "jwt.hex"),
+	}
+	apis := []API{{Namespace: "test", Service: new(testService)}}
+	listener, server, err := StartHTTPEndpoint("127.0.0.1:0", apis, []string{"test"}, nil, nil, DefaultHTTPTimeouts, nil, auth)
+	if err != nil {
+		t.Fatalf("failed to start endpoint: %v", err)
+	}
+	defer server.Stop()
+	defer listener.Close()
+
+	url := "https://" + listener.Addr().String()
+	withCert, err := ClientTLSConfig(filepath.Join(dir, "ca.crt"), filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key"))
+	if err != nil {
+		t.Fatalf("failed to load client TLS config: %v", err)
+	}
+	withoutCert, _ := ClientTLSConfig(filepath.Join(dir, "ca.crt"), "", "")
+
+	// Clients presenting a certificate and a token must be served
+	client, _ := DialOptions(context.Background(), url, WithTLSConfig(withCert), WithHTTPAuth(NewJWTAuth(secret)))
+	if err := client.Call(nil, "test_noArgsRets"); err != nil {
+		t.Fatalf("authenticated call failed: %v", err)
+	}
+	// Clients missing either must be rejected
+	client, _ = DialOptions(context.Background(), url, WithTLSConfig(withCert))
+	if err := client.Call(nil, "test_noArgsRets"); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("call without token error mismatch: %v", err)
+	}
+	client, _ = DialOptions(context.Background(), url, WithTLSConfig(withoutCert), WithHTTPAuth(NewJWTAuth(secret)))
+	if err := client.Call(nil, "test_noArgsRets"); err == nil {
+		t.Fatalf("call without client certificate succeeded")
+	}
+	// Websocket dials must carry the token too
+	listener, server, err = StartWSEndpoint("127.0.0.1:0", apis, []string{"test"}, nil, false, nil, &AuthConfig{JWTSecret: auth.JWTSecret})
+	if err != nil {
+		t.Fatalf("failed to start websocket endpoint: %v", err)
+	}
+	defer server.Stop()
+	defer listener.Close()
+
+	if _, err := DialOptions(context.Background(), "ws://"+listener.Addr().String()); err == nil {
+		t.Fatalf("websocket dial without token succeeded")
+	}
+	ws, err := DialOptions(context.Background(), "ws://"+listener.Addr().String(), WithHTTPAuth(NewJWTAuth(secret)))
+	if err != nil {
+		t.Fatalf("authenticated websocket dial failed: %v", err)
+	}
+	defer ws.Close()
+	if err := ws.Call(nil, "test_noArgsRets"); err != nil {
+		t.Fatalf("authenticated websocket call failed: %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// ClientOption is a configuration option for DialOptions.
+type ClientOption func(*clientConfig)
+
+// clientConfig is the transport configuration of a dialed client.
+type clientConfig struct {
+	httpClient *http.Client
+	tlsConfig  *tls.Config
+	header     http.Header
+	auth       HTTPAuth
+}
+
+// HTTPAuth adds the authentication headers of a request. It's called for every
+// HTTP request and websocket dial.
+type HTTPAuth func(header http.Header) error
+
+// WithHTTPClient configures the client used for HTTP connections.
+func WithHTTPClient(c *http.Client) ClientOption {
+	return func(cfg *clientConfig) {
+		cfg.httpClient = c
+	}
+}
+
+// WithTLSConfig configures the TLS settings of HTTPS and WSS connections, used
+// unless an HTTP client is given.
+func WithTLSConfig(tlsConfig *tls.Config) ClientOption {
+	return func(cfg *clientConfig) {
+		cfg.tlsConfig = tlsConfig
+	}
+}
+
+// WithHeader adds a header to the HTTP requests and websocket dials.
+func WithHeader(key, value string) ClientOption {
+	return func(cfg *clientConfig) {
+		if cfg.header == nil {
+			cfg.header = make(http.Header)
+		}
+		cfg.header.Set(key, value)
+	}
+}
+
+// WithHTTPAuth configures the authentication of the HTTP requests and websocket
+// dials.
+func WithHTTPAuth(auth HTTPAuth) ClientOption {
+	return func(cfg *clientConfig) {
+		cfg.auth = auth
+	}
+}
+
+// NewJWTAuth creates an authentication sending a fresh HS256 bearer token signed
+// with the given secret along each request.
+func NewJWTAuth(secret []byte) HTTPAuth {
+	return func(header http.Header) error {
+		header.Set("Authorization", "Bearer "+newJWT(secret, time.Now()))
+		return nil
+	}
+}
+
+// applyHeaders sets the configured headers and authentication on a request header.
+func (cfg *clientConfig) applyHeaders(header http.Header) error {
+	for key, values := range cfg.header {
+		header[key] = values
+	}
+	if cfg.auth != nil {
+		return cfg.auth(header)
+	}
+	return nil
+}
+
+// client returns the HTTP client of the config.
+func (cfg *clientConfig) client() *http.Client {
+	if cfg.httpClient != nil {
+		return cfg.httpClient
+	}
+	if cfg.tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = cfg.tlsConfig
+		return &http.Client{Transport: transport}
+	}
+	return new(http.Client)
+}
@@ -18,12 +18,14 @@ package rpc
 
 import (
 	"net"
+	"net/http"
 
 	"github.com/simplechain-org/go-simplechain/log"
 )
 
-// StartHTTPEndpoint starts the HTTP RPC endpoint, configured with cors/vhosts/modules
-func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []string, vhosts []string, timeouts HTTPTimeouts) (net.Listener, *Server, error) {
+// StartHTTPEndpoint starts the HTTP RPC endpoint, configured with cors/vhosts/modules,
+// the API keys required from the clients and the transport security, if any.
+func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []string, vhosts []string, timeouts HTTPTimeouts, keys []APIKey, auth *AuthConfig) (net.Listener, *Server, error) {
 	// Generate the whitelist based on the allowed modules
 	whitelist := make(map[string]bool)
 	for _, module := range modules {
@@ -39,6 +41,9 @@ func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []str
 			log.Debug("HTTP registered", "namespace", api.Namespace)
 		}
 	}
+	if err := handler.SetAPIKeys(keys); err != nil {
+		return nil, nil, err
+	}
 	// All APIs registered, start the HTTP listener
 	var (
 		listener net.Listener
@@ -47,12 +52,18 @@ func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []str
 	if listener, err = net.Listen("tcp", endpoint); err != nil {
 		return nil, nil, err
 	}
-	go NewHTTPServer(cors, vhosts, timeouts, handler).Serve(listener)
+	secured, srv, err := auth.secure(listener, handler)
+	if err != nil {
+		listener.Close()
+		return nil, nil, err
+	}
+	go NewHTTPServer(cors, vhosts, timeouts, srv).Serve(secured)
 	return listener, handler, err
 }
 
-// StartWSEndpoint starts a websocket endpoint
-func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []string, exposeAll bool) (net.Listener, *Server, error) {
+// StartWSEndpoint starts a websocket endpoint, requiring the given API keys from
+// the clients and securing the transport, if any.
+func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []string, exposeAll bool, keys []APIKey, auth *AuthConfig) (net.Listener, *Server, error) {
 
 	// Generate the whitelist based on the allowed modules
 	whitelist := make(map[string]bool)
@@ -69,6 +80,9 @@ func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []
 			log.Debug("WebSocket registered", "service", api.Service, "namespace", api.Namespace)
 		}
 	}
+	if err := handler.SetAPIKeys(keys); err != nil {
+		return nil, nil, err
+	}
 	// All APIs registered, start the HTTP listener
 	var (
 		listener net.Listener
@@ -77,7 +91,12 @@ func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []
 	if listener, err = net.Listen("tcp", endpoint); err != nil {
 		return nil, nil, err
 	}
-	go NewWSServer(wsOrigins, handler).Serve(listener)
+	secured, srv, err := auth.secure(listener, handler.WebsocketHandler(wsOrigins))
+	if err != nil {
+		listener.Close()
+		return nil, nil, err
+	}
+	go (&http.Server{Handler: srv}).Serve(secured)
 	return listener, handler, err
 
 }
@@ -311,6 +311,11 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 
 // handleCall processes method calls.
 func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+	if key := apiKeyFromContext(cp.ctx); key != nil && !msg.isUnsubscribe() {
+		if err := key.admit(msg.Method); err != nil {
+			return msg.errorResponse(err)
+		}
+	}
 	if msg.isSubscribe() {
 		return h.handleSubscribe(cp, msg)
 	}
@@ -370,7 +375,13 @@ func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *cal
 	if err != nil {
 		return msg.errorResponse(err)
 	}
-	return msg.response(result)
+	resp := msg.response(result)
+	if key := apiKeyFromContext(ctx); key != nil && resp.Error == nil {
+		if err := key.account(len(resp.Result)); err != nil {
+			return msg.errorResponse(err)
+		}
+	}
+	return resp
 }
 
 // unsubscribe is the callback function for all *_unsubscribe calls.
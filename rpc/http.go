@@ -46,6 +46,7 @@ var acceptedContentTypes = []string{contentType, "application/json-rpc", "applic
 type httpConn struct {
 	client    *http.Client
 	req       *http.Request
+	auth      HTTPAuth // Per request authentication, nil if none
 	closeOnce sync.Once
 	closeCh   chan interface{}
 }
@@ -107,16 +108,26 @@ var DefaultHTTPTimeouts = HTTPTimeouts{
 // DialHTTPWithClient creates a new RPC client that connects to an RPC server over HTTP
 // using the provided HTTP Client.
 func DialHTTPWithClient(endpoint string, client *http.Client) (*Client, error) {
+	return dialHTTP(endpoint, &clientConfig{httpClient: client})
+}
+
+// dialHTTP creates a new RPC client that connects to an RPC server over HTTP with
+// the given transport options.
+func dialHTTP(endpoint string, cfg *clientConfig) (*Client, error) {
 	req, err := http.NewRequest(http.MethodPost, endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Content-Type", contentType)
 	req.Header.Set("Accept", contentType)
+	for key, values := range cfg.header {
+		req.Header[key] = values
+	}
+	client := cfg.client()
 
 	initctx := context.Background()
 	return newClient(initctx, func(context.Context) (ServerCodec, error) {
-		return &httpConn{client: client, req: req, closeCh: make(chan interface{})}, nil
+		return &httpConn{client: client, req: req, auth: cfg.auth, closeCh: make(chan interface{})}, nil
 	})
 }
 
@@ -174,6 +185,12 @@ func (hc *httpConn) doRequest(ctx context.Context, msg interface{}) (io.ReadClos
 	req := hc.req.WithContext(ctx)
 	req.Body = ioutil.NopCloser(bytes.NewReader(body))
 	req.ContentLength = int64(len(body))
+	if hc.auth != nil {
+		req.Header = hc.req.Header.Clone()
+		if err := hc.auth(req.Header); err != nil {
+			return nil, err
+		}
+	}
 
 	resp, err := hc.client.Do(req)
 	if err != nil {
@@ -250,6 +267,11 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), code)
 		return
 	}
+	key, ok := s.authenticate(r)
+	if !ok {
+		http.Error(w, "invalid API key", http.StatusUnauthorized)
+		return
+	}
 	// All checks passed, create a codec that reads direct from the request body
 	// untilEOF and writes the response to w and order the server to process a
 	// single request.
@@ -263,6 +285,7 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if origin := r.Header.Get("Origin"); origin != "" {
 		ctx = context.WithValue(ctx, "Origin", origin)
 	}
+	ctx = withAPIKey(ctx, key)
 
 	w.Header().Set("content-type", contentType)
 	codec := newHTTPServerConn(r, w)
@@ -57,13 +57,18 @@ func (s *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 		CheckOrigin:     wsHandshakeValidator(allowedOrigins),
 	}
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, ok := s.authenticate(r)
+		if !ok {
+			http.Error(w, "invalid API key", http.StatusUnauthorized)
+			return
+		}
 		conn, err := upgrader.Upgrade(w, r, nil)
 		if err != nil {
 			log.Debug("WebSocket upgrade failed", "err", err)
 			return
 		}
 		codec := newWebsocketCodec(conn)
-		s.ServeCodec(codec, 0)
+		s.serveCodec(withAPIKey(context.Background(), key), codec)
 	})
 }
 
@@ -130,6 +135,12 @@ func (e wsHandshakeError) Error() string {
 // The context is used for the initial connection establishment. It does not
 // affect subsequent interactions with the client.
 func DialWebsocket(ctx context.Context, endpoint, origin string) (*Client, error) {
+	return dialWebsocket(ctx, endpoint, origin, new(clientConfig))
+}
+
+// dialWebsocket creates a new RPC client that communicates with a JSON-RPC server
+// over websocket with the given transport options.
+func dialWebsocket(ctx context.Context, endpoint, origin string, cfg *clientConfig) (*Client, error) {
 	endpoint, header, err := wsClientHeaders(endpoint, origin)
 	if err != nil {
 		return nil, err
@@ -138,8 +149,14 @@ func DialWebsocket(ctx context.Context, endpoint, origin string) (*Client, error
 		ReadBufferSize:  wsReadBuffer,
 		WriteBufferSize: wsWriteBuffer,
 		WriteBufferPool: wsBufferPool,
+		TLSClientConfig: cfg.tlsConfig,
 	}
 	return newClient(ctx, func(ctx context.Context) (ServerCodec, error) {
+		// Authenticate every dial, the tokens being short lived
+		header := header.Clone()
+		if err := cfg.applyHeaders(header); err != nil {
+			return nil, err
+		}
 		conn, resp, err := dialer.DialContext(ctx, endpoint, header)
 		if err != nil {
 			hErr := wsHandshakeError{err: err}
@@ -0,0 +1,315 @@
+// Copyright 2016 The go-simplechain Authors
+// This file is part of the go-simplechain library.
+//
+// The go-simplechain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-simplechain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-simplechain library. If not, see <http://www.gnu.org/licenses/>.
+
+package sub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/simplechain-org/go-simplechain/common"
+	"github.com/simplechain-org/go-simplechain/common/hexutil"
+	"github.com/simplechain-org/go-simplechain/core/rawdb"
+	"github.com/simplechain-org/go-simplechain/core/types"
+	"github.com/simplechain-org/go-simplechain/eth/tracers"
+	"github.com/simplechain-org/go-simplechain/rpc"
+)
+
+// maxTraceFilterBlocks is the maximum number of blocks a single trace_filter
+// request is allowed to trace.
+const maxTraceFilterBlocks = 10000
+
+// callTracerName is the tracer used to produce the flat call traces.
+var callTracerName = "callTracer"
+
+// FlatTraceAction is the call, creation or self destruct reported by a flat trace.
+type FlatTraceAction struct {
+	CallType      string          `json:"callType,omitempty"`
+	From          *common.Address `json:"from,omitempty"`
+	To            *common.Address `json:"to,omitempty"`
+	Gas           *hexutil.Uint64 `json:"gas,omitempty"`
+	Input         *hexutil.Bytes  `json:"input,omitempty"`
+	Init          *hexutil.Bytes  `json:"init,omitempty"`
+	Value         *hexutil.Big    `json:"value,omitempty"`
+	Address       *common.Address `json:"address,omitempty"`
+	RefundAddress *common.Address `json:"refundAddress,omitempty"`
+	Balance       *hexutil.Big    `json:"balance,omitempty"`
+}
+
+// FlatTraceResult is the outcome of a successful call or creation.
+type FlatTraceResult struct {
+	GasUsed *hexutil.Uint64 `json:"gasUsed,omitempty"`
+	Output  *hexutil.Bytes  `json:"output,omitempty"`
+	Address *common.Address `json:"address,omitempty"`
+	Code    *hexutil.Bytes  `json:"code,omitempty"`
+}
+
+// FlatTrace is a single call of a transaction in the flat, parity style format.
+// The trace address is the path of the call in the call tree of the transaction.
+type FlatTrace struct {
+	Action              FlatTraceAction  `json:"action"`
+	BlockHash           common.Hash      `json:"blockHash"`
+	BlockNumber         uint64           `json:"blockNumber"`
+	Error               string           `json:"error,omitempty"`
+	Result              *FlatTraceResult `json:"result"`
+	Subtraces           int              `json:"subtraces"`
+	TraceAddress        []int            `json:"traceAddress"`
+	TransactionHash     common.Hash      `json:"transactionHash"`
+	TransactionPosition uint64           `json:"transactionPosition"`
+	Type                string           `json:"type"`
+}
+
+// TraceFilterArgs are the criteria of a trace_filter request. Traces match if
+// their sender is any of FromAddress and their recipient any of ToAddress, an
+// empty list matching everything.
+type TraceFilterArgs struct {
+	FromBlock   *rpc.BlockNumber `json:"fromBlock"`
+	ToBlock     *rpc.BlockNumber `json:"toBlock"`
+	FromAddress []common.Address `json:"fromAddress"`
+	ToAddress   []common.Address `json:"toAddress"`
+	After       uint64           `json:"after"`
+	Count       *uint64          `json:"count"`
+}
+
+// PrivateTraceAPI provides the flat call traces of the trace namespace, built
+// on the native call tracer.
+type PrivateTraceAPI struct {
+	debug *PrivateDebugAPI
+}
+
+// NewPrivateTraceAPI creates a new API definition for the flat tracing methods.
+func NewPrivateTraceAPI(eth *Ethereum) *PrivateTraceAPI {
+	return &PrivateTraceAPI{debug: NewPrivateDebugAPI(eth)}
+}
+
+// Block returns the flat traces of all the transactions in the given block.
+func (api *PrivateTraceAPI) Block(ctx context.Context, number rpc.BlockNumber) ([]*FlatTrace, error) {
+	block, err := api.blockByNumber(number)
+	if err != nil {
+		return nil, err
+	}
+	return api.traceBlock(ctx, block)
+}
+
+// Transaction returns the flat traces of the given transaction.
+func (api *PrivateTraceAPI) Transaction(ctx context.Context, hash common.Hash) ([]*FlatTrace, error) {
+	tx, blockHash, blockNumber, index := rawdb.ReadTransaction(api.debug.eth.ChainDb(), hash)
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %#x not found", hash)
+	}
+	msg, vmctx, statedb, err := api.debug.computeTxEnv(blockHash, int(index), defaultTraceReexec)
+	if err != nil {
+		return nil, err
+	}
+	res, err := api.debug.traceTx(ctx, msg, vmctx, statedb, &TraceConfig{Tracer: &callTracerName})
+	if err != nil {
+		return nil, err
+	}
+	return flattenTrace(res, blockHash, blockNumber, hash, index)
+}
+
+// Filter returns the flat traces of the given block range matching the filter
+// criteria.
+func (api *PrivateTraceAPI) Filter(ctx context.Context, args TraceFilterArgs) ([]*FlatTrace, error) {
+	start, end := rpc.EarliestBlockNumber, rpc.LatestBlockNumber
+	if args.FromBlock != nil {
+		start = *args.FromBlock
+	}
+	if args.ToBlock != nil {
+		end = *args.ToBlock
+	}
+	from, err := api.blockByNumber(start)
+	if err != nil {
+		return nil, err
+	}
+	to, err := api.blockByNumber(end)
+	if err != nil {
+		return nil, err
+	}
+	if from.NumberU64() > to.NumberU64() {
+		return nil, fmt.Errorf("invalid block range %d-%d", from.NumberU64(), to.NumberU64())
+	}
+	if to.NumberU64()-from.NumberU64() >= maxTraceFilterBlocks {
+		return nil, fmt.Errorf("block range too large, maximum %d blocks", maxTraceFilterBlocks)
+	}
+	var (
+		senders    = make(map[common.Address]bool)
+		recipients = make(map[common.Address]bool)
+		skipped    uint64
+		matches    []*FlatTrace
+	)
+	for _, addr := range args.FromAddress {
+		senders[addr] = true
+	}
+	for _, addr := range args.ToAddress {
+		recipients[addr] = true
+	}
+	for number := from.NumberU64(); number <= to.NumberU64(); number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		block := api.debug.eth.blockchain.GetBlockByNumber(number)
+		if block == nil {
+			return nil, fmt.Errorf("block #%d not found", number)
+		}
+		if len(block.Transactions()) == 0 {
+			continue
+		}
+		traces, err := api.traceBlock(ctx, block)
+		if err != nil {
+			return nil, err
+		}
+		for _, trace := range traces {
+			sender, recipient := trace.parties()
+			if len(senders) > 0 && !senders[sender] {
+				continue
+			}
+			if len(recipients) > 0 && !recipients[recipient] {
+				continue
+			}
+			if skipped < args.After {
+				skipped++
+				continue
+			}
+			matches = append(matches, trace)
+			if args.Count != nil && uint64(len(matches)) >= *args.Count {
+				return matches, nil
+			}
+		}
+	}
+	return matches, nil
+}
+
+// blockByNumber retrieves a block by number, resolving the special ones.
+func (api *PrivateTraceAPI) blockByNumber(number rpc.BlockNumber) (*types.Block, error) {
+	var block *types.Block
+
+	switch number {
+	case rpc.PendingBlockNumber:
+		block = api.debug.eth.miner.PendingBlock()
+	case rpc.LatestBlockNumber:
+		block = api.debug.eth.blockchain.CurrentBlock()
+	default:
+		block = api.debug.eth.blockchain.GetBlockByNumber(uint64(number))
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", number)
+	}
+	return block, nil
+}
+
+// traceBlock traces all the transactions of a block with the call tracer and
+// flattens the results.
+func (api *PrivateTraceAPI) traceBlock(ctx context.Context, block *types.Block) ([]*FlatTrace, error) {
+	if block.NumberU64() == 0 {
+		return nil, errors.New("genesis is not traceable")
+	}
+	results, err := api.debug.traceBlock(ctx, block, &TraceConfig{Tracer: &callTracerName})
+	if err != nil {
+		return nil, err
+	}
+	var traces []*FlatTrace
+	for i, result := range results {
+		tx := block.Transactions()[i]
+		if result.Error != "" {
+			return nil, fmt.Errorf("failed to trace transaction %#x: %s", tx.Hash(), result.Error)
+		}
+		flat, err := flattenTrace(result.Result, block.Hash(), block.NumberU64(), tx.Hash(), uint64(i))
+		if err != nil {
+			return nil, err
+		}
+		traces = append(traces, flat...)
+	}
+	return traces, nil
+}
+
+// flattenTrace converts the result of the call tracer into a list of flat traces
+// in depth first order.
+func flattenTrace(result interface{}, blockHash common.Hash, blockNumber uint64, txHash common.Hash, txIndex uint64) ([]*FlatTrace, error) {
+	blob, ok := result.(json.RawMessage)
+	if !ok {
+		return nil, fmt.Errorf("unexpected trace result %T", result)
+	}
+	root := new(tracers.CallFrame)
+	if err := json.Unmarshal(blob, root); err != nil {
+		return nil, err
+	}
+	var (
+		traces []*FlatTrace
+		walk   func(frame *tracers.CallFrame, address []int)
+	)
+	walk = func(frame *tracers.CallFrame, address []int) {
+		trace := newFlatTrace(frame)
+		trace.BlockHash, trace.BlockNumber = blockHash, blockNumber
+		trace.TransactionHash, trace.TransactionPosition = txHash, txIndex
+		trace.TraceAddress = address
+		trace.Subtraces = len(frame.Calls)
+		traces = append(traces, trace)
+
+		for i, call := range frame.Calls {
+			walk(call, append(append([]int{}, address...), i))
+		}
+	}
+	walk(root, []int{})
+	return traces, nil
+}
+
+// newFlatTrace converts a single call frame into a flat trace, without position.
+func newFlatTrace(frame *tracers.CallFrame) *FlatTrace {
+	trace := &FlatTrace{Error: frame.Error}
+
+	switch frame.Type {
+	case "CREATE", "CREATE2":
+		trace.Type = "create"
+		trace.Action = FlatTraceAction{From: frame.From, Gas: frame.Gas, Init: frame.Input, Value: frame.Value}
+		if frame.Error == "" {
+			trace.Result = &FlatTraceResult{GasUsed: frame.GasUsed, Address: frame.To, Code: frame.Output}
+		}
+	case "SELFDESTRUCT":
+		trace.Type = "suicide"
+		trace.Action = FlatTraceAction{Address: frame.From, RefundAddress: frame.To, Balance: frame.Value}
+	default:
+		trace.Type = "call"
+		trace.Action = FlatTraceAction{CallType: strings.ToLower(frame.Type), From: frame.From, To: frame.To, Gas: frame.Gas, Input: frame.Input, Value: frame.Value}
+		if frame.Error == "" {
+			trace.Result = &FlatTraceResult{GasUsed: frame.GasUsed, Output: frame.Output}
+		}
+	}
+	return trace
+}
+
+// parties returns the sender and recipient of the traced action.
+func (trace *FlatTrace) parties() (sender common.Address, recipient common.Address) {
+	action := trace.Action
+	switch {
+	case action.Address != nil:
+		sender = *action.Address
+	case action.From != nil:
+		sender = *action.From
+	}
+	switch {
+	case action.RefundAddress != nil:
+		recipient = *action.RefundAddress
+	case action.To != nil:
+		recipient = *action.To
+	case trace.Result != nil && trace.Result.Address != nil:
+		recipient = *trace.Result.Address
+	}
+	return sender, recipient
+}
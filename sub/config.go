@@ -51,6 +51,7 @@ var DefaultConfig = eth.Config{
 	TrieCleanCache:     256,
 	TrieDirtyCache:     256,
 	TrieTimeout:        60 * time.Minute,
+	SnapshotCache:      102,
 	Miner: miner.Config{
 		GasFloor: 8000000,
 		GasCeil:  8000000,